@@ -1,8 +1,12 @@
 package client
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
 	"io"
 	"math"
+	"sort"
 
 	"github.com/pachyderm/pachyderm/src/client/pfs"
 	"go.pedge.io/proto/stream"
@@ -60,6 +64,33 @@ func (c APIClient) CreateRepo(repoName string) error {
 	return err
 }
 
+// CreateRepoWithQuota creates a new Repo like CreateRepo, writes to the Repo
+// which would exceed quota fail with codes.ResourceExhausted.
+func (c APIClient) CreateRepoWithQuota(repoName string, quota *pfs.Quota) error {
+	_, err := c.PfsAPIClient.CreateRepo(
+		context.Background(),
+		&pfs.CreateRepoRequest{
+			Repo:  NewRepo(repoName),
+			Quota: quota,
+		},
+	)
+	return err
+}
+
+// SetQuota replaces the quota of a Repo, a limit of 0 removes that limit.
+// Data already in the Repo isn't affected but new commits must stay within
+// the quota.
+func (c APIClient) SetQuota(repoName string, quota *pfs.Quota) error {
+	_, err := c.PfsAPIClient.SetQuota(
+		context.Background(),
+		&pfs.SetQuotaRequest{
+			Repo:  NewRepo(repoName),
+			Quota: quota,
+		},
+	)
+	return err
+}
+
 // InspectRepo returns info about a specific Repo.
 func (c APIClient) InspectRepo(repoName string) (*pfs.RepoInfo, error) {
 	repoInfo, err := c.PfsAPIClient.InspectRepo(
@@ -87,10 +118,9 @@ func (c APIClient) ListRepo() ([]*pfs.RepoInfo, error) {
 }
 
 // DeleteRepo deletes a repo and reclaims the storage space it was using. Note
-// that as of 1.0 we do not reclaim the blocks that the Repo was referencing,
-// this is because they may also be referenced by other Repos and deleting them
-// would make those Repos inaccessible. This will be resolved in later
-// versions.
+// that the blocks that the Repo was referencing aren't deleted, this is
+// because they may also be referenced by other Repos and deleting them would
+// make those Repos inaccessible. Use GarbageCollect to reclaim them.
 func (c APIClient) DeleteRepo(repoName string) error {
 	_, err := c.PfsAPIClient.DeleteRepo(
 		context.Background(),
@@ -130,6 +160,70 @@ func (c APIClient) StartCommit(repoName string, parentCommit string, branch stri
 	return commit, nil
 }
 
+// StartMergeCommit begins the process of committing data to a Repo, the new
+// Commit merges mergeParent, which is a commit or a branch, into its parent.
+// parentCommit and branch are the same as for StartCommit, one of them must
+// be set.
+// Files changed since the two parents' common ancestor by only one of them
+// are taken from that parent, files changed by both are resolved by the
+// MergePolicy with the longest Path matching them. The merge fails if there's
+// no matching policy.
+func (c APIClient) StartMergeCommit(repoName string, parentCommit string, branch string, mergeParent string, mergePolicies []*pfs.MergePolicy) (*pfs.Commit, error) {
+	commit, err := c.PfsAPIClient.StartCommit(
+		context.Background(),
+		&pfs.StartCommitRequest{
+			Repo:          NewRepo(repoName),
+			ParentID:      parentCommit,
+			Branch:        branch,
+			MergeParentID: mergeParent,
+			MergePolicies: mergePolicies,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return commit, nil
+}
+
+// StartCommitWithProvenance begins the process of committing data to a Repo
+// like StartCommit, the new Commit records that it was derived from the
+// Commits in provenance, along with the Commits they were derived from.
+func (c APIClient) StartCommitWithProvenance(repoName string, parentCommit string, branch string, provenance []*pfs.Commit) (*pfs.Commit, error) {
+	commit, err := c.PfsAPIClient.StartCommit(
+		context.Background(),
+		&pfs.StartCommitRequest{
+			Repo:       NewRepo(repoName),
+			ParentID:   parentCommit,
+			Branch:     branch,
+			Provenance: provenance,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return commit, nil
+}
+
+// StartCommitWithMetadata begins the process of committing data to a Repo
+// like StartCommit, message describes the new Commit and labels are arbitrary
+// key/value pairs which ListCommitByLabels can find it by.
+func (c APIClient) StartCommitWithMetadata(repoName string, parentCommit string, branch string, message string, labels map[string]string) (*pfs.Commit, error) {
+	commit, err := c.PfsAPIClient.StartCommit(
+		context.Background(),
+		&pfs.StartCommitRequest{
+			Repo:     NewRepo(repoName),
+			ParentID: parentCommit,
+			Branch:   branch,
+			Message:  message,
+			Labels:   labels,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return commit, nil
+}
+
 // FinishCommit ends the process of committing data to a Repo and persists the
 // Commit. Once a Commit is finished the data becomes immutable and future
 // attempts to write to it with PutFile will error.
@@ -143,6 +237,21 @@ func (c APIClient) FinishCommit(repoName string, commitID string) error {
 	return err
 }
 
+// FinishCommitWithMetadata finishes a Commit like FinishCommit, message
+// replaces the Commit's message unless it's empty and labels are added to the
+// Commit's labels, a label with an empty value is removed.
+func (c APIClient) FinishCommitWithMetadata(repoName string, commitID string, message string, labels map[string]string) error {
+	_, err := c.PfsAPIClient.FinishCommit(
+		context.Background(),
+		&pfs.FinishCommitRequest{
+			Commit:  NewCommit(repoName, commitID),
+			Message: message,
+			Labels:  labels,
+		},
+	)
+	return err
+}
+
 // CancelCommit ends the process of committing data to a repo. It differs from
 // FinishCommit in that the Commit will not be used as a source for downstream
 // pipelines. CancelCommit is used primarily by PPS for the output commits of
@@ -209,7 +318,59 @@ func (c APIClient) ListCommit(repoNames []string, fromCommitIDs []string,
 	return commitInfos.CommitInfo, nil
 }
 
-// ListBranch lists the active branches on a Repo.
+// ListCommitByProvenance returns info about the commits derived from all of
+// the Commits in provenance, directly or through other commits.
+// repoNames defines a set of Repos to consider commits from, if it's nil or
+// empty commits from every Repo are considered.
+// commitType, block and all are the same as for ListCommit.
+func (c APIClient) ListCommitByProvenance(repoNames []string, provenance []*pfs.Commit,
+	commitType pfs.CommitType, block bool, all bool) ([]*pfs.CommitInfo, error) {
+	var repos []*pfs.Repo
+	for _, repoName := range repoNames {
+		repos = append(repos, NewRepo(repoName))
+	}
+	commitInfos, err := c.PfsAPIClient.ListCommit(
+		context.Background(),
+		&pfs.ListCommitRequest{
+			Repo:       repos,
+			CommitType: commitType,
+			Provenance: provenance,
+			Block:      block,
+			All:        all,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return commitInfos.CommitInfo, nil
+}
+
+// ListCommitByLabels returns info about the commits in repoNames which have
+// all of labels. commitType, block and all are the same as for ListCommit.
+func (c APIClient) ListCommitByLabels(repoNames []string, labels map[string]string,
+	commitType pfs.CommitType, block bool, all bool) ([]*pfs.CommitInfo, error) {
+	var repos []*pfs.Repo
+	for _, repoName := range repoNames {
+		repos = append(repos, NewRepo(repoName))
+	}
+	commitInfos, err := c.PfsAPIClient.ListCommit(
+		context.Background(),
+		&pfs.ListCommitRequest{
+			Repo:       repos,
+			CommitType: commitType,
+			Labels:     labels,
+			Block:      block,
+			All:        all,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return commitInfos.CommitInfo, nil
+}
+
+// ListBranch returns info about the heads of the branches on a Repo.
+// Deprecated: use ListBranchInfo, which also returns the branches' names.
 func (c APIClient) ListBranch(repoName string) ([]*pfs.CommitInfo, error) {
 	commitInfos, err := c.PfsAPIClient.ListBranch(
 		context.Background(),
@@ -223,20 +384,99 @@ func (c APIClient) ListBranch(repoName string) ([]*pfs.CommitInfo, error) {
 	return commitInfos.CommitInfo, nil
 }
 
+// ListBranchInfo lists the branches on a Repo and their heads.
+func (c APIClient) ListBranchInfo(repoName string) ([]*pfs.BranchInfo, error) {
+	branchInfos, err := c.PfsAPIClient.ListBranchInfo(
+		context.Background(),
+		&pfs.ListBranchRequest{
+			Repo: NewRepo(repoName),
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return branchInfos.BranchInfo, nil
+}
+
+// CreateBranch creates a branch whose head is commit, commit may itself be a
+// branch. Commits started on the branch have its head as their parent.
+func (c APIClient) CreateBranch(repoName string, commit string, branch string) error {
+	_, err := c.PfsAPIClient.CreateBranch(
+		context.Background(),
+		&pfs.CreateBranchRequest{
+			Commit: NewCommit(repoName, commit),
+			Branch: branch,
+		},
+	)
+	return err
+}
+
+// SetBranch moves the head of an existing branch to commit.
+func (c APIClient) SetBranch(repoName string, commit string, branch string) error {
+	_, err := c.PfsAPIClient.SetBranch(
+		context.Background(),
+		&pfs.SetBranchRequest{
+			Commit: NewCommit(repoName, commit),
+			Branch: branch,
+		},
+	)
+	return err
+}
+
+// DeleteBranch deletes a branch, the commits on it aren't deleted.
+func (c APIClient) DeleteBranch(repoName string, branch string) error {
+	_, err := c.PfsAPIClient.DeleteBranch(
+		context.Background(),
+		&pfs.DeleteBranchRequest{
+			Repo:   NewRepo(repoName),
+			Branch: branch,
+		},
+	)
+	return err
+}
+
+// RenameBranch renames a branch.
+func (c APIClient) RenameBranch(repoName string, branch string, newBranch string) error {
+	_, err := c.PfsAPIClient.RenameBranch(
+		context.Background(),
+		&pfs.RenameBranchRequest{
+			Repo:      NewRepo(repoName),
+			Branch:    branch,
+			NewBranch: newBranch,
+		},
+	)
+	return err
+}
+
 // DeleteCommit deletes a commit.
-// Note it is currently not implemented.
+// If the commit is still open it's cancelled before being deleted.
+// DeleteCommit refuses to delete commits which have children, use
+// DeleteCommitForce to delete them anyway.
 func (c APIClient) DeleteCommit(repoName string, commitID string) error {
+	return c.deleteCommit(repoName, commitID, false)
+}
+
+// DeleteCommitForce deletes a commit even if it has children. The children
+// are reparented onto the commit's parent, this means the changes made in the
+// deleted commit will no longer be visible in them.
+func (c APIClient) DeleteCommitForce(repoName string, commitID string) error {
+	return c.deleteCommit(repoName, commitID, true)
+}
+
+func (c APIClient) deleteCommit(repoName string, commitID string, force bool) error {
 	_, err := c.PfsAPIClient.DeleteCommit(
 		context.Background(),
 		&pfs.DeleteCommitRequest{
 			Commit: NewCommit(repoName, commitID),
+			Force:  force,
 		},
 	)
 	return err
 }
 
 // PutBlock takes a reader and splits the data in it into blocks.
-// Blocks are guaranteed to be new line delimited.
+// Block boundaries are content defined, they're chosen using a rolling hash
+// and then moved forward to the next new line so text data stays line aligned.
 // Blocks are content addressed and are thus identified by hashes of the content.
 // NOTE: this is lower level function that's used internally and might not be
 // useful to users.
@@ -303,14 +543,54 @@ func (c APIClient) InspectBlock(hash string) (*pfs.BlockInfo, error) {
 
 // ListBlock returns info about all Blocks.
 func (c APIClient) ListBlock() ([]*pfs.BlockInfo, error) {
-	blockInfos, err := c.BlockAPIClient.ListBlock(
-		context.Background(),
+	var result []*pfs.BlockInfo
+	if err := c.ListBlockF(func(blockInfo *pfs.BlockInfo) error {
+		result = append(result, blockInfo)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ListBlockF calls f with info about each Block as it's received, unlike
+// ListBlock it doesn't hold all of the BlockInfos in memory at once.
+// If f returns an error ListBlockF stops and returns it.
+func (c APIClient) ListBlockF(f func(*pfs.BlockInfo) error) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	listBlockClient, err := c.BlockAPIClient.ListBlockStream(
+		ctx,
 		&pfs.ListBlockRequest{},
 	)
 	if err != nil {
-		return nil, err
+		return err
+	}
+	for {
+		blockInfo, err := listBlockClient.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := f(blockInfo); err != nil {
+			return err
+		}
 	}
-	return blockInfos.BlockInfo, nil
+}
+
+// GarbageCollect deletes blocks that aren't referenced by any commit.
+// If dryRun is true nothing is deleted, the response reports the blocks and
+// bytes which would have been reclaimed.
+// GarbageCollect is safe to call while commits are being written.
+func (c APIClient) GarbageCollect(dryRun bool) (*pfs.GarbageCollectResponse, error) {
+	return c.PfsAPIClient.GarbageCollect(
+		context.Background(),
+		&pfs.GarbageCollectRequest{
+			DryRun: dryRun,
+		},
+	)
 }
 
 // PutFileWriter writes a file to PFS.
@@ -320,7 +600,14 @@ func (c APIClient) ListBlock() ([]*pfs.BlockInfo, error) {
 // NOTE: PutFileWriter returns an io.WriteCloser you must call Close on it when
 // you are done writing.
 func (c APIClient) PutFileWriter(repoName string, commitID string, path string, handle string) (io.WriteCloser, error) {
-	return c.newPutFileWriteCloser(repoName, commitID, path, handle)
+	return c.newPutFileWriteCloser(repoName, commitID, path, handle, false)
+}
+
+// PutFileWriterOverwrite is like PutFileWriter but the file is truncated,
+// including anything written to it under other handles in the commit, before
+// the new data is written. The file is truncated even if nothing is written.
+func (c APIClient) PutFileWriterOverwrite(repoName string, commitID string, path string, handle string) (io.WriteCloser, error) {
+	return c.newPutFileWriteCloser(repoName, commitID, path, handle, true)
 }
 
 // PutFile writes a file to PFS from a reader.
@@ -338,6 +625,37 @@ func (c APIClient) PutFile(repoName string, commitID string, path string, reader
 	return int(written), err
 }
 
+// PutFileOverwrite writes a file to PFS from a reader, replacing its previous
+// content rather than appending to it.
+func (c APIClient) PutFileOverwrite(repoName string, commitID string, path string, reader io.Reader) (_ int, retErr error) {
+	writer, err := c.PutFileWriterOverwrite(repoName, commitID, path, "")
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err := writer.Close(); err != nil && retErr == nil {
+			retErr = err
+		}
+	}()
+	written, err := io.Copy(writer, reader)
+	return int(written), err
+}
+
+// PutFileURL writes a file to PFS from a URL, which pachd fetches itself so
+// the data never passes through the client. url may be http(s)://, s3:// or
+// gs:// or an absolute path on the server. Local paths and s3:// and gs://
+// URLs, which pachd reads with its own credentials, are only allowed if
+// pachd is configured to allow them. If overwrite is true the file's
+// previous content is replaced rather than appended to.
+func (c APIClient) PutFileURL(repoName string, commitID string, path string, url string, overwrite bool) error {
+	writer, err := c.newPutFileWriteCloser(repoName, commitID, path, "", overwrite)
+	if err != nil {
+		return err
+	}
+	writer.request.Url = url
+	return writer.Close()
+}
+
 // GetFile returns the contents of a file at a specific Commit.
 // offset specifies a number of bytes that should be skipped in the beginning of the file.
 // size limits the total amount of data returned, note you will get fewer bytes
@@ -354,6 +672,24 @@ func (c APIClient) GetFileUnsafe(repoName string, commitID string, path string,
 	return c.getFile(repoName, commitID, path, offset, size, fromCommitID, shard, true, writer)
 }
 
+// GetFileVerified writes the contents of a file at a specific Commit to
+// writer and checks them against the file's SHA-256. If they don't match an
+// error is returned, but the contents have already been written.
+func (c APIClient) GetFileVerified(repoName string, commitID string, path string, writer io.Writer) error {
+	fileInfo, err := c.InspectFileSHA256(repoName, commitID, path, "", nil)
+	if err != nil {
+		return err
+	}
+	hash := sha256.New()
+	if err := c.GetFile(repoName, commitID, path, 0, 0, "", nil, io.MultiWriter(writer, hash)); err != nil {
+		return err
+	}
+	if sum := hex.EncodeToString(hash.Sum(nil)); sum != fileInfo.Sha256 {
+		return fmt.Errorf("checksum mismatch for %s/%s/%s: got %s, expected %s", repoName, commitID, path, sum, fileInfo.Sha256)
+	}
+	return nil
+}
+
 func (c APIClient) getFile(repoName string, commitID string, path string, offset int64, size int64, fromCommitID string, shard *pfs.Shard, unsafe bool, writer io.Writer) error {
 	if size == 0 {
 		size = math.MaxInt64
@@ -384,14 +720,20 @@ func (c APIClient) getFile(repoName string, commitID string, path string, offset
 // shard may be left nil in which case info about the entire file will be
 // returned
 func (c APIClient) InspectFile(repoName string, commitID string, path string, fromCommitID string, shard *pfs.Shard) (*pfs.FileInfo, error) {
-	return c.inspectFile(repoName, commitID, path, fromCommitID, shard, false)
+	return c.inspectFile(repoName, commitID, path, fromCommitID, shard, false, false)
 }
 
 func (c APIClient) InspectFileUnsafe(repoName string, commitID string, path string, fromCommitID string, shard *pfs.Shard) (*pfs.FileInfo, error) {
-	return c.inspectFile(repoName, commitID, path, fromCommitID, shard, true)
+	return c.inspectFile(repoName, commitID, path, fromCommitID, shard, true, false)
+}
+
+// InspectFileSHA256 is like InspectFile but it also returns the SHA-256 of a
+// regular file's content, which PFS has to read the first time it's asked for.
+func (c APIClient) InspectFileSHA256(repoName string, commitID string, path string, fromCommitID string, shard *pfs.Shard) (*pfs.FileInfo, error) {
+	return c.inspectFile(repoName, commitID, path, fromCommitID, shard, false, true)
 }
 
-func (c APIClient) inspectFile(repoName string, commitID string, path string, fromCommitID string, shard *pfs.Shard, unsafe bool) (*pfs.FileInfo, error) {
+func (c APIClient) inspectFile(repoName string, commitID string, path string, fromCommitID string, shard *pfs.Shard, unsafe bool, sha256 bool) (*pfs.FileInfo, error) {
 	fileInfo, err := c.PfsAPIClient.InspectFile(
 		context.Background(),
 		&pfs.InspectFileRequest{
@@ -399,6 +741,7 @@ func (c APIClient) inspectFile(repoName string, commitID string, path string, fr
 			Shard:      shard,
 			FromCommit: newFromCommit(repoName, fromCommitID),
 			Unsafe:     unsafe,
+			Sha256:     sha256,
 		},
 	)
 	if err != nil {
@@ -439,6 +782,112 @@ func (c APIClient) listFile(repoName string, commitID string, path string, fromC
 	return fileInfos.FileInfo, nil
 }
 
+// ListFileStream is like ListFile but it calls f with each file as it's
+// received, in order of path, rather than buffering them all. Only the files
+// after pageToken, a path, are listed and at most limit of them unless limit
+// is 0. To list the next page pass the path of the last file as pageToken.
+func (c APIClient) ListFileStream(repoName string, commitID string, path string, fromCommitID string, shard *pfs.Shard, recurse bool, pageToken string, limit uint64, f func(*pfs.FileInfo) error) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	listFileClient, err := c.PfsAPIClient.ListFileStream(
+		ctx,
+		&pfs.ListFileRequest{
+			File:       NewFile(repoName, commitID, path),
+			Shard:      shard,
+			FromCommit: newFromCommit(repoName, fromCommitID),
+			Recurse:    recurse,
+			PageToken:  pageToken,
+			Limit:      limit,
+		},
+	)
+	if err != nil {
+		return err
+	}
+	for {
+		fileInfo, err := listFileClient.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := f(fileInfo); err != nil {
+			return err
+		}
+	}
+}
+
+// GlobFile returns info about the files and directories in a Commit which
+// match pattern, sorted by path. Within a path component * ? and [] match as
+// they do in path.Match, a component of ** matches any number of components.
+// fromCommitID and shard work as they do in ListFile. Directories are returned
+// without their children.
+func (c APIClient) GlobFile(repoName string, commitID string, pattern string, fromCommitID string, shard *pfs.Shard) ([]*pfs.FileInfo, error) {
+	return c.globFile(repoName, commitID, pattern, fromCommitID, shard, false)
+}
+
+func (c APIClient) GlobFileUnsafe(repoName string, commitID string, pattern string, fromCommitID string, shard *pfs.Shard) ([]*pfs.FileInfo, error) {
+	return c.globFile(repoName, commitID, pattern, fromCommitID, shard, true)
+}
+
+func (c APIClient) globFile(repoName string, commitID string, pattern string, fromCommitID string, shard *pfs.Shard, unsafe bool) ([]*pfs.FileInfo, error) {
+	globFileClient, err := c.PfsAPIClient.GlobFile(
+		context.Background(),
+		&pfs.GlobFileRequest{
+			File:       NewFile(repoName, commitID, pattern),
+			Shard:      shard,
+			FromCommit: newFromCommit(repoName, fromCommitID),
+			Unsafe:     unsafe,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	var fileInfos []*pfs.FileInfo
+	for {
+		fileInfo, err := globFileClient.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		fileInfos = append(fileInfos, fileInfo)
+	}
+	sort.Sort(sortFileInfos(fileInfos))
+	return fileInfos, nil
+}
+
+type sortFileInfos []*pfs.FileInfo
+
+func (a sortFileInfos) Len() int {
+	return len(a)
+}
+
+func (a sortFileInfos) Less(i, j int) bool {
+	return a[i].File.Path < a[j].File.Path
+}
+
+func (a sortFileInfos) Swap(i, j int) {
+	a[i], a[j] = a[j], a[i]
+}
+
+// CopyFile copies a file, or a directory and everything under it, from one
+// Commit to another without copying any data, the Commits may be in different
+// repos. The destination Commit must be writeable. If overwrite is true the
+// destination's content is replaced rather than appended to.
+func (c APIClient) CopyFile(srcRepo string, srcCommit string, srcPath string, dstRepo string, dstCommit string, dstPath string, overwrite bool) error {
+	_, err := c.PfsAPIClient.CopyFile(
+		context.Background(),
+		&pfs.CopyFileRequest{
+			Src:       NewFile(srcRepo, srcCommit, srcPath),
+			Dst:       NewFile(dstRepo, dstCommit, dstPath),
+			Overwrite: overwrite,
+		},
+	)
+	return err
+}
+
 // DeleteFile deletes a file from a Commit.
 // DeleteFile leaves a tombstone in the Commit, assuming the file isn't written
 // to later attempting to get the file from the finished commit will result in
@@ -454,6 +903,24 @@ func (c APIClient) DeleteFile(repoName string, commitID string, path string) err
 	return err
 }
 
+// DiffCommit returns the files which were added, modified and deleted after
+// fromCommitID up to toCommitID, sorted by path. fromCommitID must be an
+// ancestor of toCommitID, if it's "" every file in toCommitID is returned as
+// added. Only regular files are returned, directories are implied by them.
+func (c APIClient) DiffCommit(repoName string, fromCommitID string, toCommitID string) ([]*pfs.FileChange, error) {
+	fileChanges, err := c.PfsAPIClient.DiffCommit(
+		context.Background(),
+		&pfs.DiffCommitRequest{
+			FromCommit: newFromCommit(repoName, fromCommitID),
+			ToCommit:   NewCommit(repoName, toCommitID),
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return fileChanges.FileChange, nil
+}
+
 // MakeDirectory creates a directory in PFS.
 // Note directories are created implicitly by PutFile, so you technically never
 // need this function unless you want to create an empty directory.
@@ -480,16 +947,17 @@ type putFileWriteCloser struct {
 	putFileClient pfs.API_PutFileClient
 }
 
-func (c APIClient) newPutFileWriteCloser(repoName string, commitID string, path string, handle string) (*putFileWriteCloser, error) {
+func (c APIClient) newPutFileWriteCloser(repoName string, commitID string, path string, handle string, overwrite bool) (*putFileWriteCloser, error) {
 	putFileClient, err := c.PfsAPIClient.PutFile(context.Background())
 	if err != nil {
 		return nil, err
 	}
 	return &putFileWriteCloser{
 		request: &pfs.PutFileRequest{
-			File:     NewFile(repoName, commitID, path),
-			FileType: pfs.FileType_FILE_TYPE_REGULAR,
-			Handle:   handle,
+			File:      NewFile(repoName, commitID, path),
+			FileType:  pfs.FileType_FILE_TYPE_REGULAR,
+			Handle:    handle,
+			Overwrite: overwrite,
 		},
 		putFileClient: putFileClient,
 	}, nil
@@ -500,12 +968,20 @@ func (w *putFileWriteCloser) Write(p []byte) (int, error) {
 	if err := w.putFileClient.Send(w.request); err != nil {
 		return 0, err
 	}
-	// File is only needed on the first request
+	// File and Overwrite are only needed on the first request
 	w.request.File = nil
+	w.request.Overwrite = false
 	return len(p), nil
 }
 
 func (w *putFileWriteCloser) Close() error {
+	if w.request.File != nil && (w.request.Overwrite || w.request.Url != "") {
+		// nothing's been written but the file still needs to be truncated
+		// or fetched
+		if err := w.putFileClient.Send(w.request); err != nil {
+			return err
+		}
+	}
 	_, err := w.putFileClient.CloseAndRecv()
 	return err
 }
@@ -14,10 +14,13 @@ It has these top-level messages:
 	File
 	Block
 	Diff
+	Quota
 	RepoInfo
 	RepoInfos
 	CommitInfo
 	CommitInfos
+	BranchInfo
+	BranchInfos
 	FileInfo
 	FileInfos
 	ByteRange
@@ -26,30 +29,48 @@ It has these top-level messages:
 	Append
 	BlockInfo
 	BlockInfos
+	Checkpoint
 	DiffInfo
 	Shard
 	CreateRepoRequest
 	InspectRepoRequest
 	ListRepoRequest
 	DeleteRepoRequest
+	SetQuotaRequest
+	MergePolicy
 	StartCommitRequest
 	FinishCommitRequest
 	InspectCommitRequest
 	ListCommitRequest
 	ListBranchRequest
+	CreateBranchRequest
+	SetBranchRequest
+	DeleteBranchRequest
+	RenameBranchRequest
 	DeleteCommitRequest
 	GetFileRequest
 	PutFileRequest
 	InspectFileRequest
 	ListFileRequest
+	GlobFileRequest
 	DeleteFileRequest
+	CopyFileRequest
+	PutFileBlockRefsRequest
+	FileChange
+	FileChanges
+	DiffCommitRequest
 	GetBlockRequest
 	DeleteBlockRequest
 	InspectBlockRequest
 	ListBlockRequest
+	GarbageCollectRequest
+	GarbageCollectResponse
 	InspectDiffRequest
 	ListDiffRequest
 	DeleteDiffRequest
+	ShardSnapshot
+	GetShardSnapshotRequest
+	DeleteShardSnapshotRequest
 */
 package pfs
 
@@ -124,6 +145,86 @@ func (x FileType) String() string {
 }
 func (FileType) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
 
+type Compression int32
+
+const (
+	Compression_COMPRESSION_NONE Compression = 0
+	Compression_COMPRESSION_GZIP Compression = 1
+)
+
+var Compression_name = map[int32]string{
+	0: "COMPRESSION_NONE",
+	1: "COMPRESSION_GZIP",
+}
+var Compression_value = map[string]int32{
+	"COMPRESSION_NONE": 0,
+	"COMPRESSION_GZIP": 1,
+}
+
+func (x Compression) String() string {
+	return proto.EnumName(Compression_name, int32(x))
+}
+func (Compression) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+
+type MergeStrategy int32
+
+const (
+	// Fail the merge if both sides changed the file.
+	MergeStrategy_MERGE_STRATEGY_FAIL MergeStrategy = 0
+	// Keep the file from the first parent.
+	MergeStrategy_MERGE_STRATEGY_OURS MergeStrategy = 1
+	// Take the file from the merge parent.
+	MergeStrategy_MERGE_STRATEGY_THEIRS MergeStrategy = 2
+	// Append what the merge parent added to the file to the first parent's
+	// version of it.
+	MergeStrategy_MERGE_STRATEGY_CONCATENATE MergeStrategy = 3
+)
+
+var MergeStrategy_name = map[int32]string{
+	0: "MERGE_STRATEGY_FAIL",
+	1: "MERGE_STRATEGY_OURS",
+	2: "MERGE_STRATEGY_THEIRS",
+	3: "MERGE_STRATEGY_CONCATENATE",
+}
+var MergeStrategy_value = map[string]int32{
+	"MERGE_STRATEGY_FAIL":        0,
+	"MERGE_STRATEGY_OURS":        1,
+	"MERGE_STRATEGY_THEIRS":      2,
+	"MERGE_STRATEGY_CONCATENATE": 3,
+}
+
+func (x MergeStrategy) String() string {
+	return proto.EnumName(MergeStrategy_name, int32(x))
+}
+func (MergeStrategy) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+
+type ChangeType int32
+
+const (
+	ChangeType_CHANGE_TYPE_NONE     ChangeType = 0
+	ChangeType_CHANGE_TYPE_ADDED    ChangeType = 1
+	ChangeType_CHANGE_TYPE_MODIFIED ChangeType = 2
+	ChangeType_CHANGE_TYPE_DELETED  ChangeType = 3
+)
+
+var ChangeType_name = map[int32]string{
+	0: "CHANGE_TYPE_NONE",
+	1: "CHANGE_TYPE_ADDED",
+	2: "CHANGE_TYPE_MODIFIED",
+	3: "CHANGE_TYPE_DELETED",
+}
+var ChangeType_value = map[string]int32{
+	"CHANGE_TYPE_NONE":     0,
+	"CHANGE_TYPE_ADDED":    1,
+	"CHANGE_TYPE_MODIFIED": 2,
+	"CHANGE_TYPE_DELETED":  3,
+}
+
+func (x ChangeType) String() string {
+	return proto.EnumName(ChangeType_name, int32(x))
+}
+func (ChangeType) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
+
 type Repo struct {
 	Name string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
 }
@@ -193,16 +294,32 @@ func (m *Diff) GetCommit() *Commit {
 	return nil
 }
 
+// Quota limits what can be written to a repo, a limit of 0 is unlimited.
+type Quota struct {
+	// MaxBytes limits the total size of the repo's commits.
+	MaxBytes uint64 `protobuf:"varint,1,opt,name=max_bytes,json=maxBytes" json:"max_bytes,omitempty"`
+	// MaxFilesPerCommit limits how many files a commit can write to.
+	MaxFilesPerCommit uint64 `protobuf:"varint,2,opt,name=max_files_per_commit,json=maxFilesPerCommit" json:"max_files_per_commit,omitempty"`
+	// MaxFileSizeBytes limits the size of each file.
+	MaxFileSizeBytes uint64 `protobuf:"varint,3,opt,name=max_file_size_bytes,json=maxFileSizeBytes" json:"max_file_size_bytes,omitempty"`
+}
+
+func (m *Quota) Reset()                    { *m = Quota{} }
+func (m *Quota) String() string            { return proto.CompactTextString(m) }
+func (*Quota) ProtoMessage()               {}
+func (*Quota) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
+
 type RepoInfo struct {
 	Repo      *Repo                       `protobuf:"bytes,1,opt,name=repo" json:"repo,omitempty"`
 	Created   *google_protobuf2.Timestamp `protobuf:"bytes,2,opt,name=created" json:"created,omitempty"`
 	SizeBytes uint64                      `protobuf:"varint,3,opt,name=size_bytes,json=sizeBytes" json:"size_bytes,omitempty"`
+	Quota     *Quota                      `protobuf:"bytes,4,opt,name=quota" json:"quota,omitempty"`
 }
 
 func (m *RepoInfo) Reset()                    { *m = RepoInfo{} }
 func (m *RepoInfo) String() string            { return proto.CompactTextString(m) }
 func (*RepoInfo) ProtoMessage()               {}
-func (*RepoInfo) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
+func (*RepoInfo) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
 
 func (m *RepoInfo) GetRepo() *Repo {
 	if m != nil {
@@ -218,6 +335,13 @@ func (m *RepoInfo) GetCreated() *google_protobuf2.Timestamp {
 	return nil
 }
 
+func (m *RepoInfo) GetQuota() *Quota {
+	if m != nil {
+		return m.Quota
+	}
+	return nil
+}
+
 type RepoInfos struct {
 	RepoInfo []*RepoInfo `protobuf:"bytes,1,rep,name=repo_info,json=repoInfo" json:"repo_info,omitempty"`
 }
@@ -225,7 +349,7 @@ type RepoInfos struct {
 func (m *RepoInfos) Reset()                    { *m = RepoInfos{} }
 func (m *RepoInfos) String() string            { return proto.CompactTextString(m) }
 func (*RepoInfos) ProtoMessage()               {}
-func (*RepoInfos) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
+func (*RepoInfos) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
 
 func (m *RepoInfos) GetRepoInfo() []*RepoInfo {
 	if m != nil {
@@ -243,12 +367,24 @@ type CommitInfo struct {
 	Finished     *google_protobuf2.Timestamp `protobuf:"bytes,6,opt,name=finished" json:"finished,omitempty"`
 	SizeBytes    uint64                      `protobuf:"varint,7,opt,name=size_bytes,json=sizeBytes" json:"size_bytes,omitempty"`
 	Cancelled    bool                        `protobuf:"varint,8,opt,name=cancelled" json:"cancelled,omitempty"`
+	// MergeParent is the second parent of a merge commit, ParentCommit being
+	// the first.
+	MergeParent *Commit `protobuf:"bytes,9,opt,name=merge_parent,json=mergeParent" json:"merge_parent,omitempty"`
+	// Provenance is the commits the commit was derived from, including the
+	// commits they were derived from.
+	Provenance []*Commit `protobuf:"bytes,10,rep,name=provenance" json:"provenance,omitempty"`
+	// Message describes the commit.
+	Message string `protobuf:"bytes,11,opt,name=message" json:"message,omitempty"`
+	// Labels are arbitrary key/value metadata, ListCommit can filter by them.
+	Labels map[string]string `protobuf:"bytes,12,rep,name=labels" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// FileCount is the number of files the commit writes to.
+	FileCount uint64 `protobuf:"varint,13,opt,name=file_count,json=fileCount" json:"file_count,omitempty"`
 }
 
 func (m *CommitInfo) Reset()                    { *m = CommitInfo{} }
 func (m *CommitInfo) String() string            { return proto.CompactTextString(m) }
 func (*CommitInfo) ProtoMessage()               {}
-func (*CommitInfo) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
+func (*CommitInfo) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
 
 func (m *CommitInfo) GetCommit() *Commit {
 	if m != nil {
@@ -278,6 +414,27 @@ func (m *CommitInfo) GetFinished() *google_protobuf2.Timestamp {
 	return nil
 }
 
+func (m *CommitInfo) GetMergeParent() *Commit {
+	if m != nil {
+		return m.MergeParent
+	}
+	return nil
+}
+
+func (m *CommitInfo) GetProvenance() []*Commit {
+	if m != nil {
+		return m.Provenance
+	}
+	return nil
+}
+
+func (m *CommitInfo) GetLabels() map[string]string {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
+}
+
 type CommitInfos struct {
 	CommitInfo []*CommitInfo `protobuf:"bytes,1,rep,name=commit_info,json=commitInfo" json:"commit_info,omitempty"`
 }
@@ -285,7 +442,7 @@ type CommitInfos struct {
 func (m *CommitInfos) Reset()                    { *m = CommitInfos{} }
 func (m *CommitInfos) String() string            { return proto.CompactTextString(m) }
 func (*CommitInfos) ProtoMessage()               {}
-func (*CommitInfos) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
+func (*CommitInfos) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
 
 func (m *CommitInfos) GetCommitInfo() []*CommitInfo {
 	if m != nil {
@@ -294,6 +451,39 @@ func (m *CommitInfos) GetCommitInfo() []*CommitInfo {
 	return nil
 }
 
+type BranchInfo struct {
+	Name string  `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	Head *Commit `protobuf:"bytes,2,opt,name=head" json:"head,omitempty"`
+}
+
+func (m *BranchInfo) Reset()                    { *m = BranchInfo{} }
+func (m *BranchInfo) String() string            { return proto.CompactTextString(m) }
+func (*BranchInfo) ProtoMessage()               {}
+func (*BranchInfo) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
+
+func (m *BranchInfo) GetHead() *Commit {
+	if m != nil {
+		return m.Head
+	}
+	return nil
+}
+
+type BranchInfos struct {
+	BranchInfo []*BranchInfo `protobuf:"bytes,1,rep,name=branch_info,json=branchInfo" json:"branch_info,omitempty"`
+}
+
+func (m *BranchInfos) Reset()                    { *m = BranchInfos{} }
+func (m *BranchInfos) String() string            { return proto.CompactTextString(m) }
+func (*BranchInfos) ProtoMessage()               {}
+func (*BranchInfos) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{11} }
+
+func (m *BranchInfos) GetBranchInfo() []*BranchInfo {
+	if m != nil {
+		return m.BranchInfo
+	}
+	return nil
+}
+
 type FileInfo struct {
 	File           *File                       `protobuf:"bytes,1,opt,name=file" json:"file,omitempty"`
 	FileType       FileType                    `protobuf:"varint,2,opt,name=file_type,json=fileType,enum=pfs.FileType" json:"file_type,omitempty"`
@@ -301,12 +491,19 @@ type FileInfo struct {
 	Modified       *google_protobuf2.Timestamp `protobuf:"bytes,4,opt,name=modified" json:"modified,omitempty"`
 	CommitModified *Commit                     `protobuf:"bytes,5,opt,name=commit_modified,json=commitModified" json:"commit_modified,omitempty"`
 	Children       []*File                     `protobuf:"bytes,6,rep,name=children" json:"children,omitempty"`
+	// Digest is a hash of a regular file's blocks and the ranges of them it
+	// uses, in order. Files with the same digest have the same content, files
+	// with the same content usually but not always have the same digest.
+	Digest string `protobuf:"bytes,7,opt,name=digest" json:"digest,omitempty"`
+	// SHA256 is the hex encoded SHA-256 of a regular file's content, it's only
+	// set by InspectFile if it's requested.
+	Sha256 string `protobuf:"bytes,8,opt,name=sha256" json:"sha256,omitempty"`
 }
 
 func (m *FileInfo) Reset()                    { *m = FileInfo{} }
 func (m *FileInfo) String() string            { return proto.CompactTextString(m) }
 func (*FileInfo) ProtoMessage()               {}
-func (*FileInfo) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
+func (*FileInfo) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{12} }
 
 func (m *FileInfo) GetFile() *File {
 	if m != nil {
@@ -343,7 +540,7 @@ type FileInfos struct {
 func (m *FileInfos) Reset()                    { *m = FileInfos{} }
 func (m *FileInfos) String() string            { return proto.CompactTextString(m) }
 func (*FileInfos) ProtoMessage()               {}
-func (*FileInfos) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
+func (*FileInfos) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{13} }
 
 func (m *FileInfos) GetFileInfo() []*FileInfo {
 	if m != nil {
@@ -360,7 +557,7 @@ type ByteRange struct {
 func (m *ByteRange) Reset()                    { *m = ByteRange{} }
 func (m *ByteRange) String() string            { return proto.CompactTextString(m) }
 func (*ByteRange) ProtoMessage()               {}
-func (*ByteRange) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{11} }
+func (*ByteRange) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{14} }
 
 type BlockRef struct {
 	Block *Block     `protobuf:"bytes,1,opt,name=block" json:"block,omitempty"`
@@ -370,7 +567,7 @@ type BlockRef struct {
 func (m *BlockRef) Reset()                    { *m = BlockRef{} }
 func (m *BlockRef) String() string            { return proto.CompactTextString(m) }
 func (*BlockRef) ProtoMessage()               {}
-func (*BlockRef) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{12} }
+func (*BlockRef) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{15} }
 
 func (m *BlockRef) GetBlock() *Block {
 	if m != nil {
@@ -393,7 +590,7 @@ type BlockRefs struct {
 func (m *BlockRefs) Reset()                    { *m = BlockRefs{} }
 func (m *BlockRefs) String() string            { return proto.CompactTextString(m) }
 func (*BlockRefs) ProtoMessage()               {}
-func (*BlockRefs) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{13} }
+func (*BlockRefs) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{16} }
 
 func (m *BlockRefs) GetBlockRef() []*BlockRef {
 	if m != nil {
@@ -413,7 +610,7 @@ type Append struct {
 func (m *Append) Reset()                    { *m = Append{} }
 func (m *Append) String() string            { return proto.CompactTextString(m) }
 func (*Append) ProtoMessage()               {}
-func (*Append) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{14} }
+func (*Append) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{17} }
 
 func (m *Append) GetBlockRefs() []*BlockRef {
 	if m != nil {
@@ -444,15 +641,19 @@ func (m *Append) GetHandles() map[string]*BlockRefs {
 }
 
 type BlockInfo struct {
-	Block     *Block                      `protobuf:"bytes,1,opt,name=block" json:"block,omitempty"`
-	Created   *google_protobuf2.Timestamp `protobuf:"bytes,2,opt,name=created" json:"created,omitempty"`
-	SizeBytes uint64                      `protobuf:"varint,3,opt,name=size_bytes,json=sizeBytes" json:"size_bytes,omitempty"`
+	Block   *Block                      `protobuf:"bytes,1,opt,name=block" json:"block,omitempty"`
+	Created *google_protobuf2.Timestamp `protobuf:"bytes,2,opt,name=created" json:"created,omitempty"`
+	// SizeBytes is the logical (uncompressed) size of the block.
+	SizeBytes uint64 `protobuf:"varint,3,opt,name=size_bytes,json=sizeBytes" json:"size_bytes,omitempty"`
+	// StoredSizeBytes is the size of the block in the block store.
+	StoredSizeBytes uint64      `protobuf:"varint,4,opt,name=stored_size_bytes,json=storedSizeBytes" json:"stored_size_bytes,omitempty"`
+	Compression     Compression `protobuf:"varint,5,opt,name=compression,enum=pfs.Compression" json:"compression,omitempty"`
 }
 
 func (m *BlockInfo) Reset()                    { *m = BlockInfo{} }
 func (m *BlockInfo) String() string            { return proto.CompactTextString(m) }
 func (*BlockInfo) ProtoMessage()               {}
-func (*BlockInfo) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{15} }
+func (*BlockInfo) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{18} }
 
 func (m *BlockInfo) GetBlock() *Block {
 	if m != nil {
@@ -475,7 +676,7 @@ type BlockInfos struct {
 func (m *BlockInfos) Reset()                    { *m = BlockInfos{} }
 func (m *BlockInfos) String() string            { return proto.CompactTextString(m) }
 func (*BlockInfos) ProtoMessage()               {}
-func (*BlockInfos) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{16} }
+func (*BlockInfos) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{19} }
 
 func (m *BlockInfos) GetBlockInfo() []*BlockInfo {
 	if m != nil {
@@ -484,6 +685,34 @@ func (m *BlockInfos) GetBlockInfo() []*BlockInfo {
 	return nil
 }
 
+// Checkpoint is the state of a file as of a commit, reads stop at it instead
+// of following the file's appends back through earlier commits.
+type Checkpoint struct {
+	FileType  FileType    `protobuf:"varint,1,opt,name=file_type,json=fileType,enum=pfs.FileType" json:"file_type,omitempty"`
+	BlockRefs []*BlockRef `protobuf:"bytes,2,rep,name=block_refs,json=blockRefs" json:"block_refs,omitempty"`
+	// Children maps a directory's children to the commit they were last added in.
+	Children map[string]*Commit `protobuf:"bytes,3,rep,name=children" json:"children,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+}
+
+func (m *Checkpoint) Reset()                    { *m = Checkpoint{} }
+func (m *Checkpoint) String() string            { return proto.CompactTextString(m) }
+func (*Checkpoint) ProtoMessage()               {}
+func (*Checkpoint) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{20} }
+
+func (m *Checkpoint) GetBlockRefs() []*BlockRef {
+	if m != nil {
+		return m.BlockRefs
+	}
+	return nil
+}
+
+func (m *Checkpoint) GetChildren() map[string]*Commit {
+	if m != nil {
+		return m.Children
+	}
+	return nil
+}
+
 type DiffInfo struct {
 	Diff         *Diff                       `protobuf:"bytes,1,opt,name=diff" json:"diff,omitempty"`
 	ParentCommit *Commit                     `protobuf:"bytes,2,opt,name=parent_commit,json=parentCommit" json:"parent_commit,omitempty"`
@@ -494,12 +723,27 @@ type DiffInfo struct {
 	Appends   map[string]*Append `protobuf:"bytes,6,rep,name=appends" json:"appends,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	SizeBytes uint64             `protobuf:"varint,7,opt,name=size_bytes,json=sizeBytes" json:"size_bytes,omitempty"`
 	Cancelled bool               `protobuf:"varint,8,opt,name=cancelled" json:"cancelled,omitempty"`
+	// Checkpoints are written by compaction for files with long append chains,
+	// indexed by path.
+	Checkpoints map[string]*Checkpoint `protobuf:"bytes,9,rep,name=checkpoints" json:"checkpoints,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// MergeParent is the commit merged into ParentCommit by a merge commit,
+	// the files it brings in are written to Appends when the commit is started.
+	MergeParent *Commit `protobuf:"bytes,10,opt,name=merge_parent,json=mergeParent" json:"merge_parent,omitempty"`
+	// Branches is only set on the diffs of a repo's root commit, it's the
+	// repo's branches. It's nil for repos whose branches were never persisted,
+	// their branches are the last commits started on them.
+	Branches   *BranchInfos      `protobuf:"bytes,11,opt,name=branches" json:"branches,omitempty"`
+	Provenance []*Commit         `protobuf:"bytes,12,rep,name=provenance" json:"provenance,omitempty"`
+	Message    string            `protobuf:"bytes,13,opt,name=message" json:"message,omitempty"`
+	Labels     map[string]string `protobuf:"bytes,14,rep,name=labels" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// Quota is only set on the diffs of a repo's root commit.
+	Quota *Quota `protobuf:"bytes,15,opt,name=quota" json:"quota,omitempty"`
 }
 
 func (m *DiffInfo) Reset()                    { *m = DiffInfo{} }
 func (m *DiffInfo) String() string            { return proto.CompactTextString(m) }
 func (*DiffInfo) ProtoMessage()               {}
-func (*DiffInfo) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{17} }
+func (*DiffInfo) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{21} }
 
 func (m *DiffInfo) GetDiff() *Diff {
 	if m != nil {
@@ -536,6 +780,48 @@ func (m *DiffInfo) GetAppends() map[string]*Append {
 	return nil
 }
 
+func (m *DiffInfo) GetCheckpoints() map[string]*Checkpoint {
+	if m != nil {
+		return m.Checkpoints
+	}
+	return nil
+}
+
+func (m *DiffInfo) GetMergeParent() *Commit {
+	if m != nil {
+		return m.MergeParent
+	}
+	return nil
+}
+
+func (m *DiffInfo) GetBranches() *BranchInfos {
+	if m != nil {
+		return m.Branches
+	}
+	return nil
+}
+
+func (m *DiffInfo) GetProvenance() []*Commit {
+	if m != nil {
+		return m.Provenance
+	}
+	return nil
+}
+
+func (m *DiffInfo) GetLabels() map[string]string {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
+}
+
+func (m *DiffInfo) GetQuota() *Quota {
+	if m != nil {
+		return m.Quota
+	}
+	return nil
+}
+
 type Shard struct {
 	FileNumber   uint64 `protobuf:"varint,1,opt,name=file_number,json=fileNumber" json:"file_number,omitempty"`
 	FileModulus  uint64 `protobuf:"varint,2,opt,name=file_modulus,json=fileModulus" json:"file_modulus,omitempty"`
@@ -546,17 +832,18 @@ type Shard struct {
 func (m *Shard) Reset()                    { *m = Shard{} }
 func (m *Shard) String() string            { return proto.CompactTextString(m) }
 func (*Shard) ProtoMessage()               {}
-func (*Shard) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{18} }
+func (*Shard) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{22} }
 
 type CreateRepoRequest struct {
 	Repo    *Repo                       `protobuf:"bytes,1,opt,name=repo" json:"repo,omitempty"`
 	Created *google_protobuf2.Timestamp `protobuf:"bytes,2,opt,name=created" json:"created,omitempty"`
+	Quota   *Quota                      `protobuf:"bytes,3,opt,name=quota" json:"quota,omitempty"`
 }
 
 func (m *CreateRepoRequest) Reset()                    { *m = CreateRepoRequest{} }
 func (m *CreateRepoRequest) String() string            { return proto.CompactTextString(m) }
 func (*CreateRepoRequest) ProtoMessage()               {}
-func (*CreateRepoRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{19} }
+func (*CreateRepoRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{23} }
 
 func (m *CreateRepoRequest) GetRepo() *Repo {
 	if m != nil {
@@ -572,6 +859,13 @@ func (m *CreateRepoRequest) GetCreated() *google_protobuf2.Timestamp {
 	return nil
 }
 
+func (m *CreateRepoRequest) GetQuota() *Quota {
+	if m != nil {
+		return m.Quota
+	}
+	return nil
+}
+
 type InspectRepoRequest struct {
 	Repo *Repo `protobuf:"bytes,1,opt,name=repo" json:"repo,omitempty"`
 }
@@ -579,7 +873,7 @@ type InspectRepoRequest struct {
 func (m *InspectRepoRequest) Reset()                    { *m = InspectRepoRequest{} }
 func (m *InspectRepoRequest) String() string            { return proto.CompactTextString(m) }
 func (*InspectRepoRequest) ProtoMessage()               {}
-func (*InspectRepoRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{20} }
+func (*InspectRepoRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{24} }
 
 func (m *InspectRepoRequest) GetRepo() *Repo {
 	if m != nil {
@@ -594,7 +888,7 @@ type ListRepoRequest struct {
 func (m *ListRepoRequest) Reset()                    { *m = ListRepoRequest{} }
 func (m *ListRepoRequest) String() string            { return proto.CompactTextString(m) }
 func (*ListRepoRequest) ProtoMessage()               {}
-func (*ListRepoRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{21} }
+func (*ListRepoRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{25} }
 
 type DeleteRepoRequest struct {
 	Repo *Repo `protobuf:"bytes,1,opt,name=repo" json:"repo,omitempty"`
@@ -603,7 +897,7 @@ type DeleteRepoRequest struct {
 func (m *DeleteRepoRequest) Reset()                    { *m = DeleteRepoRequest{} }
 func (m *DeleteRepoRequest) String() string            { return proto.CompactTextString(m) }
 func (*DeleteRepoRequest) ProtoMessage()               {}
-func (*DeleteRepoRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{22} }
+func (*DeleteRepoRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{26} }
 
 func (m *DeleteRepoRequest) GetRepo() *Repo {
 	if m != nil {
@@ -612,18 +906,63 @@ func (m *DeleteRepoRequest) GetRepo() *Repo {
 	return nil
 }
 
+type SetQuotaRequest struct {
+	Repo  *Repo  `protobuf:"bytes,1,opt,name=repo" json:"repo,omitempty"`
+	Quota *Quota `protobuf:"bytes,2,opt,name=quota" json:"quota,omitempty"`
+}
+
+func (m *SetQuotaRequest) Reset()                    { *m = SetQuotaRequest{} }
+func (m *SetQuotaRequest) String() string            { return proto.CompactTextString(m) }
+func (*SetQuotaRequest) ProtoMessage()               {}
+func (*SetQuotaRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{27} }
+
+func (m *SetQuotaRequest) GetRepo() *Repo {
+	if m != nil {
+		return m.Repo
+	}
+	return nil
+}
+
+func (m *SetQuotaRequest) GetQuota() *Quota {
+	if m != nil {
+		return m.Quota
+	}
+	return nil
+}
+
+// MergePolicy sets the strategy used for conflicting changes to path and
+// everything under it, the policy with the longest matching path is used.
+type MergePolicy struct {
+	Path     string        `protobuf:"bytes,1,opt,name=path" json:"path,omitempty"`
+	Strategy MergeStrategy `protobuf:"varint,2,opt,name=strategy,enum=pfs.MergeStrategy" json:"strategy,omitempty"`
+}
+
+func (m *MergePolicy) Reset()                    { *m = MergePolicy{} }
+func (m *MergePolicy) String() string            { return proto.CompactTextString(m) }
+func (*MergePolicy) ProtoMessage()               {}
+func (*MergePolicy) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{28} }
+
 type StartCommitRequest struct {
-	Repo     *Repo                       `protobuf:"bytes,1,opt,name=repo" json:"repo,omitempty"`
-	ID       string                      `protobuf:"bytes,2,opt,name=id" json:"id,omitempty"`
-	ParentID string                      `protobuf:"bytes,3,opt,name=parent_id,json=parentId" json:"parent_id,omitempty"`
-	Branch   string                      `protobuf:"bytes,4,opt,name=branch" json:"branch,omitempty"`
-	Started  *google_protobuf2.Timestamp `protobuf:"bytes,5,opt,name=started" json:"started,omitempty"`
+	Repo          *Repo                       `protobuf:"bytes,1,opt,name=repo" json:"repo,omitempty"`
+	ID            string                      `protobuf:"bytes,2,opt,name=id" json:"id,omitempty"`
+	ParentID      string                      `protobuf:"bytes,3,opt,name=parent_id,json=parentId" json:"parent_id,omitempty"`
+	Branch        string                      `protobuf:"bytes,4,opt,name=branch" json:"branch,omitempty"`
+	Started       *google_protobuf2.Timestamp `protobuf:"bytes,5,opt,name=started" json:"started,omitempty"`
+	MergeParentID string                      `protobuf:"bytes,6,opt,name=merge_parent_id,json=mergeParentId" json:"merge_parent_id,omitempty"`// MergeParentID makes the commit a merge of the parent and this commit,
+	// it can be a branch.
+
+	MergePolicies []*MergePolicy `protobuf:"bytes,7,rep,name=merge_policies,json=mergePolicies" json:"merge_policies,omitempty"`
+	// Provenance is the commits the new commit is derived from, the commits
+	// they're derived from are added to it.
+	Provenance []*Commit         `protobuf:"bytes,8,rep,name=provenance" json:"provenance,omitempty"`
+	Message    string            `protobuf:"bytes,9,opt,name=message" json:"message,omitempty"`
+	Labels     map[string]string `protobuf:"bytes,10,rep,name=labels" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 }
 
 func (m *StartCommitRequest) Reset()                    { *m = StartCommitRequest{} }
 func (m *StartCommitRequest) String() string            { return proto.CompactTextString(m) }
 func (*StartCommitRequest) ProtoMessage()               {}
-func (*StartCommitRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{23} }
+func (*StartCommitRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{29} }
 
 func (m *StartCommitRequest) GetRepo() *Repo {
 	if m != nil {
@@ -639,16 +978,42 @@ func (m *StartCommitRequest) GetStarted() *google_protobuf2.Timestamp {
 	return nil
 }
 
+func (m *StartCommitRequest) GetMergePolicies() []*MergePolicy {
+	if m != nil {
+		return m.MergePolicies
+	}
+	return nil
+}
+
+func (m *StartCommitRequest) GetProvenance() []*Commit {
+	if m != nil {
+		return m.Provenance
+	}
+	return nil
+}
+
+func (m *StartCommitRequest) GetLabels() map[string]string {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
+}
+
 type FinishCommitRequest struct {
 	Commit   *Commit                     `protobuf:"bytes,1,opt,name=commit" json:"commit,omitempty"`
 	Cancel   bool                        `protobuf:"varint,2,opt,name=cancel" json:"cancel,omitempty"`
 	Finished *google_protobuf2.Timestamp `protobuf:"bytes,3,opt,name=finished" json:"finished,omitempty"`
+	// Message replaces the commit's message if it's set.
+	Message string `protobuf:"bytes,4,opt,name=message" json:"message,omitempty"`
+	// Labels are added to the commit's labels, a label with an empty value is
+	// removed.
+	Labels map[string]string `protobuf:"bytes,5,rep,name=labels" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 }
 
 func (m *FinishCommitRequest) Reset()                    { *m = FinishCommitRequest{} }
 func (m *FinishCommitRequest) String() string            { return proto.CompactTextString(m) }
 func (*FinishCommitRequest) ProtoMessage()               {}
-func (*FinishCommitRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{24} }
+func (*FinishCommitRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{30} }
 
 func (m *FinishCommitRequest) GetCommit() *Commit {
 	if m != nil {
@@ -664,6 +1029,13 @@ func (m *FinishCommitRequest) GetFinished() *google_protobuf2.Timestamp {
 	return nil
 }
 
+func (m *FinishCommitRequest) GetLabels() map[string]string {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
+}
+
 type InspectCommitRequest struct {
 	Commit *Commit `protobuf:"bytes,1,opt,name=commit" json:"commit,omitempty"`
 }
@@ -671,7 +1043,7 @@ type InspectCommitRequest struct {
 func (m *InspectCommitRequest) Reset()                    { *m = InspectCommitRequest{} }
 func (m *InspectCommitRequest) String() string            { return proto.CompactTextString(m) }
 func (*InspectCommitRequest) ProtoMessage()               {}
-func (*InspectCommitRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{25} }
+func (*InspectCommitRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{31} }
 
 func (m *InspectCommitRequest) GetCommit() *Commit {
 	if m != nil {
@@ -686,12 +1058,17 @@ type ListCommitRequest struct {
 	FromCommit []*Commit  `protobuf:"bytes,3,rep,name=from_commit,json=fromCommit" json:"from_commit,omitempty"`
 	All        bool       `protobuf:"varint,4,opt,name=all" json:"all,omitempty"`
 	Block      bool       `protobuf:"varint,5,opt,name=block" json:"block,omitempty"`
+	// Provenance only lists commits derived from all of these commits, if no
+	// repos are given every repo is considered.
+	Provenance []*Commit `protobuf:"bytes,6,rep,name=provenance" json:"provenance,omitempty"`
+	// Labels only lists commits which have all of these labels.
+	Labels map[string]string `protobuf:"bytes,7,rep,name=labels" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 }
 
 func (m *ListCommitRequest) Reset()                    { *m = ListCommitRequest{} }
 func (m *ListCommitRequest) String() string            { return proto.CompactTextString(m) }
 func (*ListCommitRequest) ProtoMessage()               {}
-func (*ListCommitRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{26} }
+func (*ListCommitRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{32} }
 
 func (m *ListCommitRequest) GetRepo() []*Repo {
 	if m != nil {
@@ -707,6 +1084,20 @@ func (m *ListCommitRequest) GetFromCommit() []*Commit {
 	return nil
 }
 
+func (m *ListCommitRequest) GetProvenance() []*Commit {
+	if m != nil {
+		return m.Provenance
+	}
+	return nil
+}
+
+func (m *ListCommitRequest) GetLabels() map[string]string {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
+}
+
 type ListBranchRequest struct {
 	Repo *Repo `protobuf:"bytes,1,opt,name=repo" json:"repo,omitempty"`
 }
@@ -714,7 +1105,7 @@ type ListBranchRequest struct {
 func (m *ListBranchRequest) Reset()                    { *m = ListBranchRequest{} }
 func (m *ListBranchRequest) String() string            { return proto.CompactTextString(m) }
 func (*ListBranchRequest) ProtoMessage()               {}
-func (*ListBranchRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{27} }
+func (*ListBranchRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{33} }
 
 func (m *ListBranchRequest) GetRepo() *Repo {
 	if m != nil {
@@ -723,14 +1114,88 @@ func (m *ListBranchRequest) GetRepo() *Repo {
 	return nil
 }
 
+type CreateBranchRequest struct {
+	// Commit is the head of the new branch.
+	Commit *Commit `protobuf:"bytes,1,opt,name=commit" json:"commit,omitempty"`
+	Branch string  `protobuf:"bytes,2,opt,name=branch" json:"branch,omitempty"`
+}
+
+func (m *CreateBranchRequest) Reset()                    { *m = CreateBranchRequest{} }
+func (m *CreateBranchRequest) String() string            { return proto.CompactTextString(m) }
+func (*CreateBranchRequest) ProtoMessage()               {}
+func (*CreateBranchRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{34} }
+
+func (m *CreateBranchRequest) GetCommit() *Commit {
+	if m != nil {
+		return m.Commit
+	}
+	return nil
+}
+
+type SetBranchRequest struct {
+	// Commit is the new head of the branch.
+	Commit *Commit `protobuf:"bytes,1,opt,name=commit" json:"commit,omitempty"`
+	Branch string  `protobuf:"bytes,2,opt,name=branch" json:"branch,omitempty"`
+}
+
+func (m *SetBranchRequest) Reset()                    { *m = SetBranchRequest{} }
+func (m *SetBranchRequest) String() string            { return proto.CompactTextString(m) }
+func (*SetBranchRequest) ProtoMessage()               {}
+func (*SetBranchRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{35} }
+
+func (m *SetBranchRequest) GetCommit() *Commit {
+	if m != nil {
+		return m.Commit
+	}
+	return nil
+}
+
+type DeleteBranchRequest struct {
+	Repo   *Repo  `protobuf:"bytes,1,opt,name=repo" json:"repo,omitempty"`
+	Branch string `protobuf:"bytes,2,opt,name=branch" json:"branch,omitempty"`
+}
+
+func (m *DeleteBranchRequest) Reset()                    { *m = DeleteBranchRequest{} }
+func (m *DeleteBranchRequest) String() string            { return proto.CompactTextString(m) }
+func (*DeleteBranchRequest) ProtoMessage()               {}
+func (*DeleteBranchRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{36} }
+
+func (m *DeleteBranchRequest) GetRepo() *Repo {
+	if m != nil {
+		return m.Repo
+	}
+	return nil
+}
+
+type RenameBranchRequest struct {
+	Repo      *Repo  `protobuf:"bytes,1,opt,name=repo" json:"repo,omitempty"`
+	Branch    string `protobuf:"bytes,2,opt,name=branch" json:"branch,omitempty"`
+	NewBranch string `protobuf:"bytes,3,opt,name=new_branch,json=newBranch" json:"new_branch,omitempty"`
+}
+
+func (m *RenameBranchRequest) Reset()                    { *m = RenameBranchRequest{} }
+func (m *RenameBranchRequest) String() string            { return proto.CompactTextString(m) }
+func (*RenameBranchRequest) ProtoMessage()               {}
+func (*RenameBranchRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{37} }
+
+func (m *RenameBranchRequest) GetRepo() *Repo {
+	if m != nil {
+		return m.Repo
+	}
+	return nil
+}
+
 type DeleteCommitRequest struct {
 	Commit *Commit `protobuf:"bytes,1,opt,name=commit" json:"commit,omitempty"`
+	// Force deletes the commit even if it has children, its children are
+	// reparented onto its parent.
+	Force bool `protobuf:"varint,2,opt,name=force" json:"force,omitempty"`
 }
 
 func (m *DeleteCommitRequest) Reset()                    { *m = DeleteCommitRequest{} }
 func (m *DeleteCommitRequest) String() string            { return proto.CompactTextString(m) }
 func (*DeleteCommitRequest) ProtoMessage()               {}
-func (*DeleteCommitRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{28} }
+func (*DeleteCommitRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{38} }
 
 func (m *DeleteCommitRequest) GetCommit() *Commit {
 	if m != nil {
@@ -751,7 +1216,7 @@ type GetFileRequest struct {
 func (m *GetFileRequest) Reset()                    { *m = GetFileRequest{} }
 func (m *GetFileRequest) String() string            { return proto.CompactTextString(m) }
 func (*GetFileRequest) ProtoMessage()               {}
-func (*GetFileRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{29} }
+func (*GetFileRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{39} }
 
 func (m *GetFileRequest) GetFile() *File {
 	if m != nil {
@@ -779,12 +1244,19 @@ type PutFileRequest struct {
 	FileType FileType `protobuf:"varint,2,opt,name=file_type,json=fileType,enum=pfs.FileType" json:"file_type,omitempty"`
 	Value    []byte   `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
 	Handle   string   `protobuf:"bytes,4,opt,name=handle" json:"handle,omitempty"`
+	// Overwrite truncates the file, including what's been written to it in
+	// the commit under any handle, before the value is written.
+	Overwrite bool `protobuf:"varint,5,opt,name=overwrite" json:"overwrite,omitempty"`
+	// URL, if set, is fetched by pachd and written to the file in place of
+	// value. It may be an http(s)://, s3:// or gs:// URL or a path on the
+	// server (optionally prefixed by file://).
+	Url string `protobuf:"bytes,6,opt,name=url" json:"url,omitempty"`
 }
 
 func (m *PutFileRequest) Reset()                    { *m = PutFileRequest{} }
 func (m *PutFileRequest) String() string            { return proto.CompactTextString(m) }
 func (*PutFileRequest) ProtoMessage()               {}
-func (*PutFileRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{30} }
+func (*PutFileRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{40} }
 
 func (m *PutFileRequest) GetFile() *File {
 	if m != nil {
@@ -798,12 +1270,15 @@ type InspectFileRequest struct {
 	Shard      *Shard  `protobuf:"bytes,2,opt,name=shard" json:"shard,omitempty"`
 	FromCommit *Commit `protobuf:"bytes,3,opt,name=from_commit,json=fromCommit" json:"from_commit,omitempty"`
 	Unsafe     bool    `protobuf:"varint,4,opt,name=unsafe" json:"unsafe,omitempty"`
+	// SHA256 computes the SHA-256 of the file's content, which requires
+	// reading it the first time it's requested.
+	Sha256 bool `protobuf:"varint,5,opt,name=sha256" json:"sha256,omitempty"`
 }
 
 func (m *InspectFileRequest) Reset()                    { *m = InspectFileRequest{} }
 func (m *InspectFileRequest) String() string            { return proto.CompactTextString(m) }
 func (*InspectFileRequest) ProtoMessage()               {}
-func (*InspectFileRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{31} }
+func (*InspectFileRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{41} }
 
 func (m *InspectFileRequest) GetFile() *File {
 	if m != nil {
@@ -826,18 +1301,30 @@ func (m *InspectFileRequest) GetFromCommit() *Commit {
 	return nil
 }
 
+// ListFileRequest's file.path may be a glob, in which case the files and
+// directories which match it are listed rather than a directory's children.
+// A path with glob metacharacters which exists is listed as a path rather
+// than a glob. Files are listed in order of path.
 type ListFileRequest struct {
 	File       *File   `protobuf:"bytes,1,opt,name=file" json:"file,omitempty"`
 	Shard      *Shard  `protobuf:"bytes,2,opt,name=shard" json:"shard,omitempty"`
 	FromCommit *Commit `protobuf:"bytes,3,opt,name=from_commit,json=fromCommit" json:"from_commit,omitempty"`
 	Recurse    bool    `protobuf:"varint,4,opt,name=recurse" json:"recurse,omitempty"`
 	Unsafe     bool    `protobuf:"varint,5,opt,name=unsafe" json:"unsafe,omitempty"`
+	// PageToken, if set, is the path of the last file of a previous page, only
+	// the files after it are listed.
+	PageToken string `protobuf:"bytes,6,opt,name=page_token,json=pageToken" json:"page_token,omitempty"`
+	// Limit, if non-zero, is how many files are listed.
+	Limit uint64 `protobuf:"varint,7,opt,name=limit" json:"limit,omitempty"`
+	// Literal treats file.path as a path even if it contains glob
+	// metacharacters.
+	Literal bool `protobuf:"varint,8,opt,name=literal" json:"literal,omitempty"`
 }
 
 func (m *ListFileRequest) Reset()                    { *m = ListFileRequest{} }
 func (m *ListFileRequest) String() string            { return proto.CompactTextString(m) }
 func (*ListFileRequest) ProtoMessage()               {}
-func (*ListFileRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{32} }
+func (*ListFileRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{42} }
 
 func (m *ListFileRequest) GetFile() *File {
 	if m != nil {
@@ -860,6 +1347,41 @@ func (m *ListFileRequest) GetFromCommit() *Commit {
 	return nil
 }
 
+// GlobFileRequest's file.path is a glob, * ? and [] match within a path
+// component as they do in path.Match and ** matches any number of components.
+type GlobFileRequest struct {
+	File       *File   `protobuf:"bytes,1,opt,name=file" json:"file,omitempty"`
+	Shard      *Shard  `protobuf:"bytes,2,opt,name=shard" json:"shard,omitempty"`
+	FromCommit *Commit `protobuf:"bytes,3,opt,name=from_commit,json=fromCommit" json:"from_commit,omitempty"`
+	Unsafe     bool    `protobuf:"varint,4,opt,name=unsafe" json:"unsafe,omitempty"`
+}
+
+func (m *GlobFileRequest) Reset()                    { *m = GlobFileRequest{} }
+func (m *GlobFileRequest) String() string            { return proto.CompactTextString(m) }
+func (*GlobFileRequest) ProtoMessage()               {}
+func (*GlobFileRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{43} }
+
+func (m *GlobFileRequest) GetFile() *File {
+	if m != nil {
+		return m.File
+	}
+	return nil
+}
+
+func (m *GlobFileRequest) GetShard() *Shard {
+	if m != nil {
+		return m.Shard
+	}
+	return nil
+}
+
+func (m *GlobFileRequest) GetFromCommit() *Commit {
+	if m != nil {
+		return m.FromCommit
+	}
+	return nil
+}
+
 type DeleteFileRequest struct {
 	File *File `protobuf:"bytes,1,opt,name=file" json:"file,omitempty"`
 }
@@ -867,7 +1389,7 @@ type DeleteFileRequest struct {
 func (m *DeleteFileRequest) Reset()                    { *m = DeleteFileRequest{} }
 func (m *DeleteFileRequest) String() string            { return proto.CompactTextString(m) }
 func (*DeleteFileRequest) ProtoMessage()               {}
-func (*DeleteFileRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{33} }
+func (*DeleteFileRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{44} }
 
 func (m *DeleteFileRequest) GetFile() *File {
 	if m != nil {
@@ -876,52 +1398,167 @@ func (m *DeleteFileRequest) GetFile() *File {
 	return nil
 }
 
-type GetBlockRequest struct {
-	Block       *Block `protobuf:"bytes,1,opt,name=block" json:"block,omitempty"`
-	OffsetBytes uint64 `protobuf:"varint,2,opt,name=offset_bytes,json=offsetBytes" json:"offset_bytes,omitempty"`
-	SizeBytes   uint64 `protobuf:"varint,3,opt,name=size_bytes,json=sizeBytes" json:"size_bytes,omitempty"`
+// CopyFileRequest copies src, which may be a directory, to dst without
+// copying its data, dst's commit must be writeable.
+type CopyFileRequest struct {
+	Src *File `protobuf:"bytes,1,opt,name=src" json:"src,omitempty"`
+	Dst *File `protobuf:"bytes,2,opt,name=dst" json:"dst,omitempty"`
+	// Overwrite replaces dst's content rather than appending src's to it.
+	Overwrite bool `protobuf:"varint,3,opt,name=overwrite" json:"overwrite,omitempty"`
 }
 
-func (m *GetBlockRequest) Reset()                    { *m = GetBlockRequest{} }
-func (m *GetBlockRequest) String() string            { return proto.CompactTextString(m) }
-func (*GetBlockRequest) ProtoMessage()               {}
-func (*GetBlockRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{34} }
+func (m *CopyFileRequest) Reset()                    { *m = CopyFileRequest{} }
+func (m *CopyFileRequest) String() string            { return proto.CompactTextString(m) }
+func (*CopyFileRequest) ProtoMessage()               {}
+func (*CopyFileRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{45} }
 
-func (m *GetBlockRequest) GetBlock() *Block {
+func (m *CopyFileRequest) GetSrc() *File {
 	if m != nil {
-		return m.Block
+		return m.Src
 	}
 	return nil
 }
 
-type DeleteBlockRequest struct {
-	Block *Block `protobuf:"bytes,1,opt,name=block" json:"block,omitempty"`
-}
-
-func (m *DeleteBlockRequest) Reset()                    { *m = DeleteBlockRequest{} }
-func (m *DeleteBlockRequest) String() string            { return proto.CompactTextString(m) }
-func (*DeleteBlockRequest) ProtoMessage()               {}
-func (*DeleteBlockRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{35} }
-
-func (m *DeleteBlockRequest) GetBlock() *Block {
+func (m *CopyFileRequest) GetDst() *File {
 	if m != nil {
-		return m.Block
+		return m.Dst
 	}
 	return nil
 }
 
-type InspectBlockRequest struct {
-	Block *Block `protobuf:"bytes,1,opt,name=block" json:"block,omitempty"`
+// PutFileBlockRefsRequest appends blocks which are already in the block store
+// to a file, it's used internally by CopyFile.
+type PutFileBlockRefsRequest struct {
+	File      *File       `protobuf:"bytes,1,opt,name=file" json:"file,omitempty"`
+	BlockRef  []*BlockRef `protobuf:"bytes,2,rep,name=block_ref,json=blockRef" json:"block_ref,omitempty"`
+	Overwrite bool        `protobuf:"varint,3,opt,name=overwrite" json:"overwrite,omitempty"`
 }
 
-func (m *InspectBlockRequest) Reset()                    { *m = InspectBlockRequest{} }
-func (m *InspectBlockRequest) String() string            { return proto.CompactTextString(m) }
-func (*InspectBlockRequest) ProtoMessage()               {}
-func (*InspectBlockRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{36} }
+func (m *PutFileBlockRefsRequest) Reset()                    { *m = PutFileBlockRefsRequest{} }
+func (m *PutFileBlockRefsRequest) String() string            { return proto.CompactTextString(m) }
+func (*PutFileBlockRefsRequest) ProtoMessage()               {}
+func (*PutFileBlockRefsRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{46} }
 
-func (m *InspectBlockRequest) GetBlock() *Block {
+func (m *PutFileBlockRefsRequest) GetFile() *File {
 	if m != nil {
-		return m.Block
+		return m.File
+	}
+	return nil
+}
+
+func (m *PutFileBlockRefsRequest) GetBlockRef() []*BlockRef {
+	if m != nil {
+		return m.BlockRef
+	}
+	return nil
+}
+
+type FileChange struct {
+	File       *File      `protobuf:"bytes,1,opt,name=file" json:"file,omitempty"`
+	ChangeType ChangeType `protobuf:"varint,2,opt,name=change_type,json=changeType,enum=pfs.ChangeType" json:"change_type,omitempty"`
+	// SizeDeltaBytes is how much the file grew (or shrank) between the commits.
+	SizeDeltaBytes int64 `protobuf:"varint,3,opt,name=size_delta_bytes,json=sizeDeltaBytes" json:"size_delta_bytes,omitempty"`
+}
+
+func (m *FileChange) Reset()                    { *m = FileChange{} }
+func (m *FileChange) String() string            { return proto.CompactTextString(m) }
+func (*FileChange) ProtoMessage()               {}
+func (*FileChange) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{47} }
+
+func (m *FileChange) GetFile() *File {
+	if m != nil {
+		return m.File
+	}
+	return nil
+}
+
+type FileChanges struct {
+	FileChange []*FileChange `protobuf:"bytes,1,rep,name=file_change,json=fileChange" json:"file_change,omitempty"`
+}
+
+func (m *FileChanges) Reset()                    { *m = FileChanges{} }
+func (m *FileChanges) String() string            { return proto.CompactTextString(m) }
+func (*FileChanges) ProtoMessage()               {}
+func (*FileChanges) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{48} }
+
+func (m *FileChanges) GetFileChange() []*FileChange {
+	if m != nil {
+		return m.FileChange
+	}
+	return nil
+}
+
+type DiffCommitRequest struct {
+	// FromCommit can be nil, in which case every file in ToCommit is added.
+	FromCommit *Commit `protobuf:"bytes,1,opt,name=from_commit,json=fromCommit" json:"from_commit,omitempty"`
+	ToCommit   *Commit `protobuf:"bytes,2,opt,name=to_commit,json=toCommit" json:"to_commit,omitempty"`
+}
+
+func (m *DiffCommitRequest) Reset()                    { *m = DiffCommitRequest{} }
+func (m *DiffCommitRequest) String() string            { return proto.CompactTextString(m) }
+func (*DiffCommitRequest) ProtoMessage()               {}
+func (*DiffCommitRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{49} }
+
+func (m *DiffCommitRequest) GetFromCommit() *Commit {
+	if m != nil {
+		return m.FromCommit
+	}
+	return nil
+}
+
+func (m *DiffCommitRequest) GetToCommit() *Commit {
+	if m != nil {
+		return m.ToCommit
+	}
+	return nil
+}
+
+type GetBlockRequest struct {
+	Block       *Block `protobuf:"bytes,1,opt,name=block" json:"block,omitempty"`
+	OffsetBytes uint64 `protobuf:"varint,2,opt,name=offset_bytes,json=offsetBytes" json:"offset_bytes,omitempty"`
+	SizeBytes   uint64 `protobuf:"varint,3,opt,name=size_bytes,json=sizeBytes" json:"size_bytes,omitempty"`
+}
+
+func (m *GetBlockRequest) Reset()                    { *m = GetBlockRequest{} }
+func (m *GetBlockRequest) String() string            { return proto.CompactTextString(m) }
+func (*GetBlockRequest) ProtoMessage()               {}
+func (*GetBlockRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{50} }
+
+func (m *GetBlockRequest) GetBlock() *Block {
+	if m != nil {
+		return m.Block
+	}
+	return nil
+}
+
+type DeleteBlockRequest struct {
+	Block *Block `protobuf:"bytes,1,opt,name=block" json:"block,omitempty"`
+}
+
+func (m *DeleteBlockRequest) Reset()                    { *m = DeleteBlockRequest{} }
+func (m *DeleteBlockRequest) String() string            { return proto.CompactTextString(m) }
+func (*DeleteBlockRequest) ProtoMessage()               {}
+func (*DeleteBlockRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{51} }
+
+func (m *DeleteBlockRequest) GetBlock() *Block {
+	if m != nil {
+		return m.Block
+	}
+	return nil
+}
+
+type InspectBlockRequest struct {
+	Block *Block `protobuf:"bytes,1,opt,name=block" json:"block,omitempty"`
+}
+
+func (m *InspectBlockRequest) Reset()                    { *m = InspectBlockRequest{} }
+func (m *InspectBlockRequest) String() string            { return proto.CompactTextString(m) }
+func (*InspectBlockRequest) ProtoMessage()               {}
+func (*InspectBlockRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{52} }
+
+func (m *InspectBlockRequest) GetBlock() *Block {
+	if m != nil {
+		return m.Block
 	}
 	return nil
 }
@@ -932,7 +1569,40 @@ type ListBlockRequest struct {
 func (m *ListBlockRequest) Reset()                    { *m = ListBlockRequest{} }
 func (m *ListBlockRequest) String() string            { return proto.CompactTextString(m) }
 func (*ListBlockRequest) ProtoMessage()               {}
-func (*ListBlockRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{37} }
+func (*ListBlockRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{53} }
+
+type GarbageCollectRequest struct {
+	// Blocks written after before are never collected, blocks written in the
+	// last few minutes never are either. Open commits haven't written their
+	// diffs yet so API.GarbageCollect moves before back to when the oldest of
+	// them started, which BlockAPI.GarbageCollect can't do on its own.
+	Before *google_protobuf2.Timestamp `protobuf:"bytes,1,opt,name=before" json:"before,omitempty"`
+	// DryRun reports what would be collected without deleting anything.
+	DryRun bool `protobuf:"varint,2,opt,name=dry_run,json=dryRun" json:"dry_run,omitempty"`
+}
+
+func (m *GarbageCollectRequest) Reset()                    { *m = GarbageCollectRequest{} }
+func (m *GarbageCollectRequest) String() string            { return proto.CompactTextString(m) }
+func (*GarbageCollectRequest) ProtoMessage()               {}
+func (*GarbageCollectRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{54} }
+
+func (m *GarbageCollectRequest) GetBefore() *google_protobuf2.Timestamp {
+	if m != nil {
+		return m.Before
+	}
+	return nil
+}
+
+type GarbageCollectResponse struct {
+	LiveBlocks      uint64 `protobuf:"varint,1,opt,name=live_blocks,json=liveBlocks" json:"live_blocks,omitempty"`
+	CollectedBlocks uint64 `protobuf:"varint,2,opt,name=collected_blocks,json=collectedBlocks" json:"collected_blocks,omitempty"`
+	CollectedBytes  uint64 `protobuf:"varint,3,opt,name=collected_bytes,json=collectedBytes" json:"collected_bytes,omitempty"`
+}
+
+func (m *GarbageCollectResponse) Reset()                    { *m = GarbageCollectResponse{} }
+func (m *GarbageCollectResponse) String() string            { return proto.CompactTextString(m) }
+func (*GarbageCollectResponse) ProtoMessage()               {}
+func (*GarbageCollectResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{55} }
 
 type InspectDiffRequest struct {
 	Diff *Diff `protobuf:"bytes,1,opt,name=diff" json:"diff,omitempty"`
@@ -941,7 +1611,7 @@ type InspectDiffRequest struct {
 func (m *InspectDiffRequest) Reset()                    { *m = InspectDiffRequest{} }
 func (m *InspectDiffRequest) String() string            { return proto.CompactTextString(m) }
 func (*InspectDiffRequest) ProtoMessage()               {}
-func (*InspectDiffRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{38} }
+func (*InspectDiffRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{56} }
 
 func (m *InspectDiffRequest) GetDiff() *Diff {
 	if m != nil {
@@ -952,12 +1622,22 @@ func (m *InspectDiffRequest) GetDiff() *Diff {
 
 type ListDiffRequest struct {
 	Shard uint64 `protobuf:"varint,1,opt,name=shard" json:"shard,omitempty"`
+	// If modified_after is set only diffs written at or after it (to the
+	// second) are listed, by the block server's clock.
+	ModifiedAfter *google_protobuf2.Timestamp `protobuf:"bytes,2,opt,name=modified_after,json=modifiedAfter" json:"modified_after,omitempty"`
 }
 
 func (m *ListDiffRequest) Reset()                    { *m = ListDiffRequest{} }
 func (m *ListDiffRequest) String() string            { return proto.CompactTextString(m) }
 func (*ListDiffRequest) ProtoMessage()               {}
-func (*ListDiffRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{39} }
+func (*ListDiffRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{57} }
+
+func (m *ListDiffRequest) GetModifiedAfter() *google_protobuf2.Timestamp {
+	if m != nil {
+		return m.ModifiedAfter
+	}
+	return nil
+}
 
 type DeleteDiffRequest struct {
 	Diff *Diff `protobuf:"bytes,1,opt,name=diff" json:"diff,omitempty"`
@@ -966,7 +1646,7 @@ type DeleteDiffRequest struct {
 func (m *DeleteDiffRequest) Reset()                    { *m = DeleteDiffRequest{} }
 func (m *DeleteDiffRequest) String() string            { return proto.CompactTextString(m) }
 func (*DeleteDiffRequest) ProtoMessage()               {}
-func (*DeleteDiffRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{40} }
+func (*DeleteDiffRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{58} }
 
 func (m *DeleteDiffRequest) GetDiff() *Diff {
 	if m != nil {
@@ -975,16 +1655,66 @@ func (m *DeleteDiffRequest) GetDiff() *Diff {
 	return nil
 }
 
+// ShardSnapshot is part of a snapshot of the finished diffs of a shard, a
+// snapshot is streamed as a ShardSnapshot per diff.
+type ShardSnapshot struct {
+	Shard     uint64      `protobuf:"varint,1,opt,name=shard" json:"shard,omitempty"`
+	DiffInfos []*DiffInfo `protobuf:"bytes,2,rep,name=diff_infos,json=diffInfos" json:"diff_infos,omitempty"`
+	// created is when the snapshot was written by the block server's clock,
+	// it's set in the first message of GetShardSnapshot.
+	Created *google_protobuf2.Timestamp `protobuf:"bytes,3,opt,name=created" json:"created,omitempty"`
+}
+
+func (m *ShardSnapshot) Reset()                    { *m = ShardSnapshot{} }
+func (m *ShardSnapshot) String() string            { return proto.CompactTextString(m) }
+func (*ShardSnapshot) ProtoMessage()               {}
+func (*ShardSnapshot) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{59} }
+
+func (m *ShardSnapshot) GetDiffInfos() []*DiffInfo {
+	if m != nil {
+		return m.DiffInfos
+	}
+	return nil
+}
+
+func (m *ShardSnapshot) GetCreated() *google_protobuf2.Timestamp {
+	if m != nil {
+		return m.Created
+	}
+	return nil
+}
+
+type GetShardSnapshotRequest struct {
+	Shard uint64 `protobuf:"varint,1,opt,name=shard" json:"shard,omitempty"`
+}
+
+func (m *GetShardSnapshotRequest) Reset()                    { *m = GetShardSnapshotRequest{} }
+func (m *GetShardSnapshotRequest) String() string            { return proto.CompactTextString(m) }
+func (*GetShardSnapshotRequest) ProtoMessage()               {}
+func (*GetShardSnapshotRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{60} }
+
+type DeleteShardSnapshotRequest struct {
+	Shard uint64 `protobuf:"varint,1,opt,name=shard" json:"shard,omitempty"`
+}
+
+func (m *DeleteShardSnapshotRequest) Reset()                    { *m = DeleteShardSnapshotRequest{} }
+func (m *DeleteShardSnapshotRequest) String() string            { return proto.CompactTextString(m) }
+func (*DeleteShardSnapshotRequest) ProtoMessage()               {}
+func (*DeleteShardSnapshotRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{61} }
+
 func init() {
 	proto.RegisterType((*Repo)(nil), "pfs.Repo")
 	proto.RegisterType((*Commit)(nil), "pfs.Commit")
 	proto.RegisterType((*File)(nil), "pfs.File")
 	proto.RegisterType((*Block)(nil), "pfs.Block")
 	proto.RegisterType((*Diff)(nil), "pfs.Diff")
+	proto.RegisterType((*Quota)(nil), "pfs.Quota")
 	proto.RegisterType((*RepoInfo)(nil), "pfs.RepoInfo")
 	proto.RegisterType((*RepoInfos)(nil), "pfs.RepoInfos")
 	proto.RegisterType((*CommitInfo)(nil), "pfs.CommitInfo")
 	proto.RegisterType((*CommitInfos)(nil), "pfs.CommitInfos")
+	proto.RegisterType((*BranchInfo)(nil), "pfs.BranchInfo")
+	proto.RegisterType((*BranchInfos)(nil), "pfs.BranchInfos")
 	proto.RegisterType((*FileInfo)(nil), "pfs.FileInfo")
 	proto.RegisterType((*FileInfos)(nil), "pfs.FileInfos")
 	proto.RegisterType((*ByteRange)(nil), "pfs.ByteRange")
@@ -993,32 +1723,53 @@ func init() {
 	proto.RegisterType((*Append)(nil), "pfs.Append")
 	proto.RegisterType((*BlockInfo)(nil), "pfs.BlockInfo")
 	proto.RegisterType((*BlockInfos)(nil), "pfs.BlockInfos")
+	proto.RegisterType((*Checkpoint)(nil), "pfs.Checkpoint")
 	proto.RegisterType((*DiffInfo)(nil), "pfs.DiffInfo")
 	proto.RegisterType((*Shard)(nil), "pfs.Shard")
 	proto.RegisterType((*CreateRepoRequest)(nil), "pfs.CreateRepoRequest")
 	proto.RegisterType((*InspectRepoRequest)(nil), "pfs.InspectRepoRequest")
 	proto.RegisterType((*ListRepoRequest)(nil), "pfs.ListRepoRequest")
 	proto.RegisterType((*DeleteRepoRequest)(nil), "pfs.DeleteRepoRequest")
+	proto.RegisterType((*SetQuotaRequest)(nil), "pfs.SetQuotaRequest")
+	proto.RegisterType((*MergePolicy)(nil), "pfs.MergePolicy")
 	proto.RegisterType((*StartCommitRequest)(nil), "pfs.StartCommitRequest")
 	proto.RegisterType((*FinishCommitRequest)(nil), "pfs.FinishCommitRequest")
 	proto.RegisterType((*InspectCommitRequest)(nil), "pfs.InspectCommitRequest")
 	proto.RegisterType((*ListCommitRequest)(nil), "pfs.ListCommitRequest")
 	proto.RegisterType((*ListBranchRequest)(nil), "pfs.ListBranchRequest")
+	proto.RegisterType((*CreateBranchRequest)(nil), "pfs.CreateBranchRequest")
+	proto.RegisterType((*SetBranchRequest)(nil), "pfs.SetBranchRequest")
+	proto.RegisterType((*DeleteBranchRequest)(nil), "pfs.DeleteBranchRequest")
+	proto.RegisterType((*RenameBranchRequest)(nil), "pfs.RenameBranchRequest")
 	proto.RegisterType((*DeleteCommitRequest)(nil), "pfs.DeleteCommitRequest")
 	proto.RegisterType((*GetFileRequest)(nil), "pfs.GetFileRequest")
 	proto.RegisterType((*PutFileRequest)(nil), "pfs.PutFileRequest")
 	proto.RegisterType((*InspectFileRequest)(nil), "pfs.InspectFileRequest")
 	proto.RegisterType((*ListFileRequest)(nil), "pfs.ListFileRequest")
+	proto.RegisterType((*GlobFileRequest)(nil), "pfs.GlobFileRequest")
 	proto.RegisterType((*DeleteFileRequest)(nil), "pfs.DeleteFileRequest")
+	proto.RegisterType((*CopyFileRequest)(nil), "pfs.CopyFileRequest")
+	proto.RegisterType((*PutFileBlockRefsRequest)(nil), "pfs.PutFileBlockRefsRequest")
+	proto.RegisterType((*FileChange)(nil), "pfs.FileChange")
+	proto.RegisterType((*FileChanges)(nil), "pfs.FileChanges")
+	proto.RegisterType((*DiffCommitRequest)(nil), "pfs.DiffCommitRequest")
 	proto.RegisterType((*GetBlockRequest)(nil), "pfs.GetBlockRequest")
 	proto.RegisterType((*DeleteBlockRequest)(nil), "pfs.DeleteBlockRequest")
 	proto.RegisterType((*InspectBlockRequest)(nil), "pfs.InspectBlockRequest")
 	proto.RegisterType((*ListBlockRequest)(nil), "pfs.ListBlockRequest")
+	proto.RegisterType((*GarbageCollectRequest)(nil), "pfs.GarbageCollectRequest")
+	proto.RegisterType((*GarbageCollectResponse)(nil), "pfs.GarbageCollectResponse")
 	proto.RegisterType((*InspectDiffRequest)(nil), "pfs.InspectDiffRequest")
 	proto.RegisterType((*ListDiffRequest)(nil), "pfs.ListDiffRequest")
 	proto.RegisterType((*DeleteDiffRequest)(nil), "pfs.DeleteDiffRequest")
+	proto.RegisterType((*ShardSnapshot)(nil), "pfs.ShardSnapshot")
+	proto.RegisterType((*GetShardSnapshotRequest)(nil), "pfs.GetShardSnapshotRequest")
+	proto.RegisterType((*DeleteShardSnapshotRequest)(nil), "pfs.DeleteShardSnapshotRequest")
 	proto.RegisterEnum("pfs.CommitType", CommitType_name, CommitType_value)
 	proto.RegisterEnum("pfs.FileType", FileType_name, FileType_value)
+	proto.RegisterEnum("pfs.Compression", Compression_name, Compression_value)
+	proto.RegisterEnum("pfs.MergeStrategy", MergeStrategy_name, MergeStrategy_value)
+	proto.RegisterEnum("pfs.ChangeType", ChangeType_name, ChangeType_value)
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -1042,6 +1793,8 @@ type APIClient interface {
 	ListRepo(ctx context.Context, in *ListRepoRequest, opts ...grpc.CallOption) (*RepoInfos, error)
 	// DeleteRepo deletes a repo.
 	DeleteRepo(ctx context.Context, in *DeleteRepoRequest, opts ...grpc.CallOption) (*google_protobuf1.Empty, error)
+	// SetQuota replaces a repo's quota.
+	SetQuota(ctx context.Context, in *SetQuotaRequest, opts ...grpc.CallOption) (*google_protobuf1.Empty, error)
 	// Commit rpcs
 	// StartCommit creates a new write commit from a parent commit.
 	StartCommit(ctx context.Context, in *StartCommitRequest, opts ...grpc.CallOption) (*Commit, error)
@@ -1054,7 +1807,18 @@ type APIClient interface {
 	// DeleteCommit deletes a commit.
 	DeleteCommit(ctx context.Context, in *DeleteCommitRequest, opts ...grpc.CallOption) (*google_protobuf1.Empty, error)
 	// ListBranch returns info about the heads of branches.
+	// Deprecated: use ListBranchInfo, which also returns the branches' names.
 	ListBranch(ctx context.Context, in *ListBranchRequest, opts ...grpc.CallOption) (*CommitInfos, error)
+	// ListBranchInfo returns the branches of a repo and their heads.
+	ListBranchInfo(ctx context.Context, in *ListBranchRequest, opts ...grpc.CallOption) (*BranchInfos, error)
+	// CreateBranch creates a branch whose head is an existing commit.
+	CreateBranch(ctx context.Context, in *CreateBranchRequest, opts ...grpc.CallOption) (*google_protobuf1.Empty, error)
+	// SetBranch moves the head of a branch to another commit.
+	SetBranch(ctx context.Context, in *SetBranchRequest, opts ...grpc.CallOption) (*google_protobuf1.Empty, error)
+	// DeleteBranch deletes a branch, its commits aren't deleted.
+	DeleteBranch(ctx context.Context, in *DeleteBranchRequest, opts ...grpc.CallOption) (*google_protobuf1.Empty, error)
+	// RenameBranch renames a branch.
+	RenameBranch(ctx context.Context, in *RenameBranchRequest, opts ...grpc.CallOption) (*google_protobuf1.Empty, error)
 	// File rpcs
 	// PutFile writes the specified file to pfs.
 	PutFile(ctx context.Context, opts ...grpc.CallOption) (API_PutFileClient, error)
@@ -1064,8 +1828,23 @@ type APIClient interface {
 	InspectFile(ctx context.Context, in *InspectFileRequest, opts ...grpc.CallOption) (*FileInfo, error)
 	// ListFile returns info about all files.
 	ListFile(ctx context.Context, in *ListFileRequest, opts ...grpc.CallOption) (*FileInfos, error)
+	// ListFileStream is like ListFile but it streams the files rather than
+	// returning them in a single message, for directories with many files.
+	ListFileStream(ctx context.Context, in *ListFileRequest, opts ...grpc.CallOption) (API_ListFileStreamClient, error)
+	// GlobFile streams info about the files and directories matching a glob.
+	GlobFile(ctx context.Context, in *GlobFileRequest, opts ...grpc.CallOption) (API_GlobFileClient, error)
 	// DeleteFile deletes a file.
 	DeleteFile(ctx context.Context, in *DeleteFileRequest, opts ...grpc.CallOption) (*google_protobuf1.Empty, error)
+	// CopyFile copies a file or directory between commits, which may be in
+	// different repos, without copying its data.
+	CopyFile(ctx context.Context, in *CopyFileRequest, opts ...grpc.CallOption) (*google_protobuf1.Empty, error)
+	// DiffCommit returns the files which were added, modified and deleted
+	// between two commits.
+	DiffCommit(ctx context.Context, in *DiffCommitRequest, opts ...grpc.CallOption) (*FileChanges, error)
+	// GarbageCollect deletes blocks which aren't referenced by any commit. It
+	// protects the blocks written by open commits and passes the request on to
+	// the block store.
+	GarbageCollect(ctx context.Context, in *GarbageCollectRequest, opts ...grpc.CallOption) (*GarbageCollectResponse, error)
 }
 
 type aPIClient struct {
@@ -1112,6 +1891,15 @@ func (c *aPIClient) DeleteRepo(ctx context.Context, in *DeleteRepoRequest, opts
 	return out, nil
 }
 
+func (c *aPIClient) SetQuota(ctx context.Context, in *SetQuotaRequest, opts ...grpc.CallOption) (*google_protobuf1.Empty, error) {
+	out := new(google_protobuf1.Empty)
+	err := grpc.Invoke(ctx, "/pfs.API/SetQuota", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *aPIClient) StartCommit(ctx context.Context, in *StartCommitRequest, opts ...grpc.CallOption) (*Commit, error) {
 	out := new(Commit)
 	err := grpc.Invoke(ctx, "/pfs.API/StartCommit", in, out, c.cc, opts...)
@@ -1166,6 +1954,51 @@ func (c *aPIClient) ListBranch(ctx context.Context, in *ListBranchRequest, opts
 	return out, nil
 }
 
+func (c *aPIClient) ListBranchInfo(ctx context.Context, in *ListBranchRequest, opts ...grpc.CallOption) (*BranchInfos, error) {
+	out := new(BranchInfos)
+	err := grpc.Invoke(ctx, "/pfs.API/ListBranchInfo", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aPIClient) CreateBranch(ctx context.Context, in *CreateBranchRequest, opts ...grpc.CallOption) (*google_protobuf1.Empty, error) {
+	out := new(google_protobuf1.Empty)
+	err := grpc.Invoke(ctx, "/pfs.API/CreateBranch", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aPIClient) SetBranch(ctx context.Context, in *SetBranchRequest, opts ...grpc.CallOption) (*google_protobuf1.Empty, error) {
+	out := new(google_protobuf1.Empty)
+	err := grpc.Invoke(ctx, "/pfs.API/SetBranch", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aPIClient) DeleteBranch(ctx context.Context, in *DeleteBranchRequest, opts ...grpc.CallOption) (*google_protobuf1.Empty, error) {
+	out := new(google_protobuf1.Empty)
+	err := grpc.Invoke(ctx, "/pfs.API/DeleteBranch", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aPIClient) RenameBranch(ctx context.Context, in *RenameBranchRequest, opts ...grpc.CallOption) (*google_protobuf1.Empty, error) {
+	out := new(google_protobuf1.Empty)
+	err := grpc.Invoke(ctx, "/pfs.API/RenameBranch", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *aPIClient) PutFile(ctx context.Context, opts ...grpc.CallOption) (API_PutFileClient, error) {
 	stream, err := grpc.NewClientStream(ctx, &_API_serviceDesc.Streams[0], c.cc, "/pfs.API/PutFile", opts...)
 	if err != nil {
@@ -1250,6 +2083,70 @@ func (c *aPIClient) ListFile(ctx context.Context, in *ListFileRequest, opts ...g
 	return out, nil
 }
 
+func (c *aPIClient) ListFileStream(ctx context.Context, in *ListFileRequest, opts ...grpc.CallOption) (API_ListFileStreamClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_API_serviceDesc.Streams[2], c.cc, "/pfs.API/ListFileStream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &aPIListFileStreamClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type API_ListFileStreamClient interface {
+	Recv() (*FileInfo, error)
+	grpc.ClientStream
+}
+
+type aPIListFileStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *aPIListFileStreamClient) Recv() (*FileInfo, error) {
+	m := new(FileInfo)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *aPIClient) GlobFile(ctx context.Context, in *GlobFileRequest, opts ...grpc.CallOption) (API_GlobFileClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_API_serviceDesc.Streams[3], c.cc, "/pfs.API/GlobFile", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &aPIGlobFileClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type API_GlobFileClient interface {
+	Recv() (*FileInfo, error)
+	grpc.ClientStream
+}
+
+type aPIGlobFileClient struct {
+	grpc.ClientStream
+}
+
+func (x *aPIGlobFileClient) Recv() (*FileInfo, error) {
+	m := new(FileInfo)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func (c *aPIClient) DeleteFile(ctx context.Context, in *DeleteFileRequest, opts ...grpc.CallOption) (*google_protobuf1.Empty, error) {
 	out := new(google_protobuf1.Empty)
 	err := grpc.Invoke(ctx, "/pfs.API/DeleteFile", in, out, c.cc, opts...)
@@ -1259,6 +2156,33 @@ func (c *aPIClient) DeleteFile(ctx context.Context, in *DeleteFileRequest, opts
 	return out, nil
 }
 
+func (c *aPIClient) CopyFile(ctx context.Context, in *CopyFileRequest, opts ...grpc.CallOption) (*google_protobuf1.Empty, error) {
+	out := new(google_protobuf1.Empty)
+	err := grpc.Invoke(ctx, "/pfs.API/CopyFile", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aPIClient) DiffCommit(ctx context.Context, in *DiffCommitRequest, opts ...grpc.CallOption) (*FileChanges, error) {
+	out := new(FileChanges)
+	err := grpc.Invoke(ctx, "/pfs.API/DiffCommit", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aPIClient) GarbageCollect(ctx context.Context, in *GarbageCollectRequest, opts ...grpc.CallOption) (*GarbageCollectResponse, error) {
+	out := new(GarbageCollectResponse)
+	err := grpc.Invoke(ctx, "/pfs.API/GarbageCollect", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for API service
 
 type APIServer interface {
@@ -1272,6 +2196,8 @@ type APIServer interface {
 	ListRepo(context.Context, *ListRepoRequest) (*RepoInfos, error)
 	// DeleteRepo deletes a repo.
 	DeleteRepo(context.Context, *DeleteRepoRequest) (*google_protobuf1.Empty, error)
+	// SetQuota replaces a repo's quota.
+	SetQuota(context.Context, *SetQuotaRequest) (*google_protobuf1.Empty, error)
 	// Commit rpcs
 	// StartCommit creates a new write commit from a parent commit.
 	StartCommit(context.Context, *StartCommitRequest) (*Commit, error)
@@ -1284,7 +2210,18 @@ type APIServer interface {
 	// DeleteCommit deletes a commit.
 	DeleteCommit(context.Context, *DeleteCommitRequest) (*google_protobuf1.Empty, error)
 	// ListBranch returns info about the heads of branches.
+	// Deprecated: use ListBranchInfo, which also returns the branches' names.
 	ListBranch(context.Context, *ListBranchRequest) (*CommitInfos, error)
+	// ListBranchInfo returns the branches of a repo and their heads.
+	ListBranchInfo(context.Context, *ListBranchRequest) (*BranchInfos, error)
+	// CreateBranch creates a branch whose head is an existing commit.
+	CreateBranch(context.Context, *CreateBranchRequest) (*google_protobuf1.Empty, error)
+	// SetBranch moves the head of a branch to another commit.
+	SetBranch(context.Context, *SetBranchRequest) (*google_protobuf1.Empty, error)
+	// DeleteBranch deletes a branch, its commits aren't deleted.
+	DeleteBranch(context.Context, *DeleteBranchRequest) (*google_protobuf1.Empty, error)
+	// RenameBranch renames a branch.
+	RenameBranch(context.Context, *RenameBranchRequest) (*google_protobuf1.Empty, error)
 	// File rpcs
 	// PutFile writes the specified file to pfs.
 	PutFile(API_PutFileServer) error
@@ -1294,8 +2231,23 @@ type APIServer interface {
 	InspectFile(context.Context, *InspectFileRequest) (*FileInfo, error)
 	// ListFile returns info about all files.
 	ListFile(context.Context, *ListFileRequest) (*FileInfos, error)
+	// ListFileStream is like ListFile but it streams the files rather than
+	// returning them in a single message, for directories with many files.
+	ListFileStream(*ListFileRequest, API_ListFileStreamServer) error
+	// GlobFile streams info about the files and directories matching a glob.
+	GlobFile(*GlobFileRequest, API_GlobFileServer) error
 	// DeleteFile deletes a file.
 	DeleteFile(context.Context, *DeleteFileRequest) (*google_protobuf1.Empty, error)
+	// CopyFile copies a file or directory between commits, which may be in
+	// different repos, without copying its data.
+	CopyFile(context.Context, *CopyFileRequest) (*google_protobuf1.Empty, error)
+	// DiffCommit returns the files which were added, modified and deleted
+	// between two commits.
+	DiffCommit(context.Context, *DiffCommitRequest) (*FileChanges, error)
+	// GarbageCollect deletes blocks which aren't referenced by any commit. It
+	// protects the blocks written by open commits and passes the request on to
+	// the block store.
+	GarbageCollect(context.Context, *GarbageCollectRequest) (*GarbageCollectResponse, error)
 }
 
 func RegisterAPIServer(s *grpc.Server, srv APIServer) {
@@ -1374,6 +2326,24 @@ func _API_DeleteRepo_Handler(srv interface{}, ctx context.Context, dec func(inte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _API_SetQuota_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetQuotaRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).SetQuota(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pfs.API/SetQuota",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).SetQuota(ctx, req.(*SetQuotaRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _API_StartCommit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(StartCommitRequest)
 	if err := dec(in); err != nil {
@@ -1482,19 +2452,109 @@ func _API_ListBranch_Handler(srv interface{}, ctx context.Context, dec func(inte
 	return interceptor(ctx, in, info, handler)
 }
 
-func _API_PutFile_Handler(srv interface{}, stream grpc.ServerStream) error {
-	return srv.(APIServer).PutFile(&aPIPutFileServer{stream})
-}
-
-type API_PutFileServer interface {
-	SendAndClose(*google_protobuf1.Empty) error
-	Recv() (*PutFileRequest, error)
-	grpc.ServerStream
-}
-
-type aPIPutFileServer struct {
-	grpc.ServerStream
-}
+func _API_ListBranchInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListBranchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).ListBranchInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pfs.API/ListBranchInfo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).ListBranchInfo(ctx, req.(*ListBranchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_CreateBranch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateBranchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).CreateBranch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pfs.API/CreateBranch",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).CreateBranch(ctx, req.(*CreateBranchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_SetBranch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetBranchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).SetBranch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pfs.API/SetBranch",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).SetBranch(ctx, req.(*SetBranchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_DeleteBranch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteBranchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).DeleteBranch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pfs.API/DeleteBranch",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).DeleteBranch(ctx, req.(*DeleteBranchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_RenameBranch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RenameBranchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).RenameBranch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pfs.API/RenameBranch",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).RenameBranch(ctx, req.(*RenameBranchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_PutFile_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(APIServer).PutFile(&aPIPutFileServer{stream})
+}
+
+type API_PutFileServer interface {
+	SendAndClose(*google_protobuf1.Empty) error
+	Recv() (*PutFileRequest, error)
+	grpc.ServerStream
+}
+
+type aPIPutFileServer struct {
+	grpc.ServerStream
+}
 
 func (x *aPIPutFileServer) SendAndClose(m *google_protobuf1.Empty) error {
 	return x.ServerStream.SendMsg(m)
@@ -1565,6 +2625,48 @@ func _API_ListFile_Handler(srv interface{}, ctx context.Context, dec func(interf
 	return interceptor(ctx, in, info, handler)
 }
 
+func _API_ListFileStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListFileRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(APIServer).ListFileStream(m, &aPIListFileStreamServer{stream})
+}
+
+type API_ListFileStreamServer interface {
+	Send(*FileInfo) error
+	grpc.ServerStream
+}
+
+type aPIListFileStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *aPIListFileStreamServer) Send(m *FileInfo) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _API_GlobFile_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GlobFileRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(APIServer).GlobFile(m, &aPIGlobFileServer{stream})
+}
+
+type API_GlobFileServer interface {
+	Send(*FileInfo) error
+	grpc.ServerStream
+}
+
+type aPIGlobFileServer struct {
+	grpc.ServerStream
+}
+
+func (x *aPIGlobFileServer) Send(m *FileInfo) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 func _API_DeleteFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(DeleteFileRequest)
 	if err := dec(in); err != nil {
@@ -1583,6 +2685,60 @@ func _API_DeleteFile_Handler(srv interface{}, ctx context.Context, dec func(inte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _API_CopyFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CopyFileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).CopyFile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pfs.API/CopyFile",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).CopyFile(ctx, req.(*CopyFileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_DiffCommit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DiffCommitRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).DiffCommit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pfs.API/DiffCommit",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).DiffCommit(ctx, req.(*DiffCommitRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_GarbageCollect_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GarbageCollectRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).GarbageCollect(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pfs.API/GarbageCollect",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).GarbageCollect(ctx, req.(*GarbageCollectRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _API_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "pfs.API",
 	HandlerType: (*APIServer)(nil),
@@ -1603,6 +2759,10 @@ var _API_serviceDesc = grpc.ServiceDesc{
 			MethodName: "DeleteRepo",
 			Handler:    _API_DeleteRepo_Handler,
 		},
+		{
+			MethodName: "SetQuota",
+			Handler:    _API_SetQuota_Handler,
+		},
 		{
 			MethodName: "StartCommit",
 			Handler:    _API_StartCommit_Handler,
@@ -1627,6 +2787,26 @@ var _API_serviceDesc = grpc.ServiceDesc{
 			MethodName: "ListBranch",
 			Handler:    _API_ListBranch_Handler,
 		},
+		{
+			MethodName: "ListBranchInfo",
+			Handler:    _API_ListBranchInfo_Handler,
+		},
+		{
+			MethodName: "CreateBranch",
+			Handler:    _API_CreateBranch_Handler,
+		},
+		{
+			MethodName: "SetBranch",
+			Handler:    _API_SetBranch_Handler,
+		},
+		{
+			MethodName: "DeleteBranch",
+			Handler:    _API_DeleteBranch_Handler,
+		},
+		{
+			MethodName: "RenameBranch",
+			Handler:    _API_RenameBranch_Handler,
+		},
 		{
 			MethodName: "InspectFile",
 			Handler:    _API_InspectFile_Handler,
@@ -1639,6 +2819,18 @@ var _API_serviceDesc = grpc.ServiceDesc{
 			MethodName: "DeleteFile",
 			Handler:    _API_DeleteFile_Handler,
 		},
+		{
+			MethodName: "CopyFile",
+			Handler:    _API_CopyFile_Handler,
+		},
+		{
+			MethodName: "DiffCommit",
+			Handler:    _API_DiffCommit_Handler,
+		},
+		{
+			MethodName: "GarbageCollect",
+			Handler:    _API_GarbageCollect_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -1651,6 +2843,16 @@ var _API_serviceDesc = grpc.ServiceDesc{
 			Handler:       _API_GetFile_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "ListFileStream",
+			Handler:       _API_ListFileStream_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "GlobFile",
+			Handler:       _API_GlobFile_Handler,
+			ServerStreams: true,
+		},
 	},
 }
 
@@ -1667,6 +2869,8 @@ type InternalAPIClient interface {
 	ListRepo(ctx context.Context, in *ListRepoRequest, opts ...grpc.CallOption) (*RepoInfos, error)
 	// DeleteRepo deletes a repo.
 	DeleteRepo(ctx context.Context, in *DeleteRepoRequest, opts ...grpc.CallOption) (*google_protobuf1.Empty, error)
+	// SetQuota replaces a repo's quota.
+	SetQuota(ctx context.Context, in *SetQuotaRequest, opts ...grpc.CallOption) (*google_protobuf1.Empty, error)
 	// Commit rpcs
 	// StartCommit creates a new write commit from a parent commit.
 	StartCommit(ctx context.Context, in *StartCommitRequest, opts ...grpc.CallOption) (*google_protobuf1.Empty, error)
@@ -1679,7 +2883,18 @@ type InternalAPIClient interface {
 	// DeleteCommit deletes a commit.
 	DeleteCommit(ctx context.Context, in *DeleteCommitRequest, opts ...grpc.CallOption) (*google_protobuf1.Empty, error)
 	// ListBranch returns info about the heads of branches.
+	// Deprecated: use ListBranchInfo, which also returns the branches' names.
 	ListBranch(ctx context.Context, in *ListBranchRequest, opts ...grpc.CallOption) (*CommitInfos, error)
+	// ListBranchInfo returns the branches of a repo and their heads.
+	ListBranchInfo(ctx context.Context, in *ListBranchRequest, opts ...grpc.CallOption) (*BranchInfos, error)
+	// CreateBranch creates a branch whose head is an existing commit.
+	CreateBranch(ctx context.Context, in *CreateBranchRequest, opts ...grpc.CallOption) (*google_protobuf1.Empty, error)
+	// SetBranch moves the head of a branch to another commit.
+	SetBranch(ctx context.Context, in *SetBranchRequest, opts ...grpc.CallOption) (*google_protobuf1.Empty, error)
+	// DeleteBranch deletes a branch, its commits aren't deleted.
+	DeleteBranch(ctx context.Context, in *DeleteBranchRequest, opts ...grpc.CallOption) (*google_protobuf1.Empty, error)
+	// RenameBranch renames a branch.
+	RenameBranch(ctx context.Context, in *RenameBranchRequest, opts ...grpc.CallOption) (*google_protobuf1.Empty, error)
 	// File rpcs
 	// PutFile writes the specified file to pfs.
 	PutFile(ctx context.Context, opts ...grpc.CallOption) (InternalAPI_PutFileClient, error)
@@ -1689,8 +2904,21 @@ type InternalAPIClient interface {
 	InspectFile(ctx context.Context, in *InspectFileRequest, opts ...grpc.CallOption) (*FileInfo, error)
 	// ListFile returns info about all files.
 	ListFile(ctx context.Context, in *ListFileRequest, opts ...grpc.CallOption) (*FileInfos, error)
+	// ListFileStream is like ListFile but it streams the files rather than
+	// returning them in a single message, for directories with many files.
+	ListFileStream(ctx context.Context, in *ListFileRequest, opts ...grpc.CallOption) (InternalAPI_ListFileStreamClient, error)
+	// GlobFile streams info about the files and directories matching a glob.
+	GlobFile(ctx context.Context, in *GlobFileRequest, opts ...grpc.CallOption) (InternalAPI_GlobFileClient, error)
 	// DeleteFile deletes a file.
 	DeleteFile(ctx context.Context, in *DeleteFileRequest, opts ...grpc.CallOption) (*google_protobuf1.Empty, error)
+	// CopyFile is implemented by the API service with these, GetFileBlockRefs
+	// returns the blocks of a regular file and PutFileBlockRefs appends blocks
+	// to one.
+	GetFileBlockRefs(ctx context.Context, in *InspectFileRequest, opts ...grpc.CallOption) (*BlockRefs, error)
+	PutFileBlockRefs(ctx context.Context, in *PutFileBlockRefsRequest, opts ...grpc.CallOption) (*google_protobuf1.Empty, error)
+	// DiffCommit returns the files which were added, modified and deleted
+	// between two commits.
+	DiffCommit(ctx context.Context, in *DiffCommitRequest, opts ...grpc.CallOption) (*FileChanges, error)
 }
 
 type internalAPIClient struct {
@@ -1737,6 +2965,15 @@ func (c *internalAPIClient) DeleteRepo(ctx context.Context, in *DeleteRepoReques
 	return out, nil
 }
 
+func (c *internalAPIClient) SetQuota(ctx context.Context, in *SetQuotaRequest, opts ...grpc.CallOption) (*google_protobuf1.Empty, error) {
+	out := new(google_protobuf1.Empty)
+	err := grpc.Invoke(ctx, "/pfs.InternalAPI/SetQuota", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *internalAPIClient) StartCommit(ctx context.Context, in *StartCommitRequest, opts ...grpc.CallOption) (*google_protobuf1.Empty, error) {
 	out := new(google_protobuf1.Empty)
 	err := grpc.Invoke(ctx, "/pfs.InternalAPI/StartCommit", in, out, c.cc, opts...)
@@ -1791,6 +3028,51 @@ func (c *internalAPIClient) ListBranch(ctx context.Context, in *ListBranchReques
 	return out, nil
 }
 
+func (c *internalAPIClient) ListBranchInfo(ctx context.Context, in *ListBranchRequest, opts ...grpc.CallOption) (*BranchInfos, error) {
+	out := new(BranchInfos)
+	err := grpc.Invoke(ctx, "/pfs.InternalAPI/ListBranchInfo", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *internalAPIClient) CreateBranch(ctx context.Context, in *CreateBranchRequest, opts ...grpc.CallOption) (*google_protobuf1.Empty, error) {
+	out := new(google_protobuf1.Empty)
+	err := grpc.Invoke(ctx, "/pfs.InternalAPI/CreateBranch", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *internalAPIClient) SetBranch(ctx context.Context, in *SetBranchRequest, opts ...grpc.CallOption) (*google_protobuf1.Empty, error) {
+	out := new(google_protobuf1.Empty)
+	err := grpc.Invoke(ctx, "/pfs.InternalAPI/SetBranch", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *internalAPIClient) DeleteBranch(ctx context.Context, in *DeleteBranchRequest, opts ...grpc.CallOption) (*google_protobuf1.Empty, error) {
+	out := new(google_protobuf1.Empty)
+	err := grpc.Invoke(ctx, "/pfs.InternalAPI/DeleteBranch", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *internalAPIClient) RenameBranch(ctx context.Context, in *RenameBranchRequest, opts ...grpc.CallOption) (*google_protobuf1.Empty, error) {
+	out := new(google_protobuf1.Empty)
+	err := grpc.Invoke(ctx, "/pfs.InternalAPI/RenameBranch", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *internalAPIClient) PutFile(ctx context.Context, opts ...grpc.CallOption) (InternalAPI_PutFileClient, error) {
 	stream, err := grpc.NewClientStream(ctx, &_InternalAPI_serviceDesc.Streams[0], c.cc, "/pfs.InternalAPI/PutFile", opts...)
 	if err != nil {
@@ -1875,6 +3157,70 @@ func (c *internalAPIClient) ListFile(ctx context.Context, in *ListFileRequest, o
 	return out, nil
 }
 
+func (c *internalAPIClient) ListFileStream(ctx context.Context, in *ListFileRequest, opts ...grpc.CallOption) (InternalAPI_ListFileStreamClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_InternalAPI_serviceDesc.Streams[2], c.cc, "/pfs.InternalAPI/ListFileStream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &internalAPIListFileStreamClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type InternalAPI_ListFileStreamClient interface {
+	Recv() (*FileInfo, error)
+	grpc.ClientStream
+}
+
+type internalAPIListFileStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *internalAPIListFileStreamClient) Recv() (*FileInfo, error) {
+	m := new(FileInfo)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *internalAPIClient) GlobFile(ctx context.Context, in *GlobFileRequest, opts ...grpc.CallOption) (InternalAPI_GlobFileClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_InternalAPI_serviceDesc.Streams[3], c.cc, "/pfs.InternalAPI/GlobFile", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &internalAPIGlobFileClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type InternalAPI_GlobFileClient interface {
+	Recv() (*FileInfo, error)
+	grpc.ClientStream
+}
+
+type internalAPIGlobFileClient struct {
+	grpc.ClientStream
+}
+
+func (x *internalAPIGlobFileClient) Recv() (*FileInfo, error) {
+	m := new(FileInfo)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func (c *internalAPIClient) DeleteFile(ctx context.Context, in *DeleteFileRequest, opts ...grpc.CallOption) (*google_protobuf1.Empty, error) {
 	out := new(google_protobuf1.Empty)
 	err := grpc.Invoke(ctx, "/pfs.InternalAPI/DeleteFile", in, out, c.cc, opts...)
@@ -1884,6 +3230,33 @@ func (c *internalAPIClient) DeleteFile(ctx context.Context, in *DeleteFileReques
 	return out, nil
 }
 
+func (c *internalAPIClient) GetFileBlockRefs(ctx context.Context, in *InspectFileRequest, opts ...grpc.CallOption) (*BlockRefs, error) {
+	out := new(BlockRefs)
+	err := grpc.Invoke(ctx, "/pfs.InternalAPI/GetFileBlockRefs", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *internalAPIClient) PutFileBlockRefs(ctx context.Context, in *PutFileBlockRefsRequest, opts ...grpc.CallOption) (*google_protobuf1.Empty, error) {
+	out := new(google_protobuf1.Empty)
+	err := grpc.Invoke(ctx, "/pfs.InternalAPI/PutFileBlockRefs", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *internalAPIClient) DiffCommit(ctx context.Context, in *DiffCommitRequest, opts ...grpc.CallOption) (*FileChanges, error) {
+	out := new(FileChanges)
+	err := grpc.Invoke(ctx, "/pfs.InternalAPI/DiffCommit", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for InternalAPI service
 
 type InternalAPIServer interface {
@@ -1897,6 +3270,8 @@ type InternalAPIServer interface {
 	ListRepo(context.Context, *ListRepoRequest) (*RepoInfos, error)
 	// DeleteRepo deletes a repo.
 	DeleteRepo(context.Context, *DeleteRepoRequest) (*google_protobuf1.Empty, error)
+	// SetQuota replaces a repo's quota.
+	SetQuota(context.Context, *SetQuotaRequest) (*google_protobuf1.Empty, error)
 	// Commit rpcs
 	// StartCommit creates a new write commit from a parent commit.
 	StartCommit(context.Context, *StartCommitRequest) (*google_protobuf1.Empty, error)
@@ -1909,7 +3284,18 @@ type InternalAPIServer interface {
 	// DeleteCommit deletes a commit.
 	DeleteCommit(context.Context, *DeleteCommitRequest) (*google_protobuf1.Empty, error)
 	// ListBranch returns info about the heads of branches.
+	// Deprecated: use ListBranchInfo, which also returns the branches' names.
 	ListBranch(context.Context, *ListBranchRequest) (*CommitInfos, error)
+	// ListBranchInfo returns the branches of a repo and their heads.
+	ListBranchInfo(context.Context, *ListBranchRequest) (*BranchInfos, error)
+	// CreateBranch creates a branch whose head is an existing commit.
+	CreateBranch(context.Context, *CreateBranchRequest) (*google_protobuf1.Empty, error)
+	// SetBranch moves the head of a branch to another commit.
+	SetBranch(context.Context, *SetBranchRequest) (*google_protobuf1.Empty, error)
+	// DeleteBranch deletes a branch, its commits aren't deleted.
+	DeleteBranch(context.Context, *DeleteBranchRequest) (*google_protobuf1.Empty, error)
+	// RenameBranch renames a branch.
+	RenameBranch(context.Context, *RenameBranchRequest) (*google_protobuf1.Empty, error)
 	// File rpcs
 	// PutFile writes the specified file to pfs.
 	PutFile(InternalAPI_PutFileServer) error
@@ -1919,8 +3305,21 @@ type InternalAPIServer interface {
 	InspectFile(context.Context, *InspectFileRequest) (*FileInfo, error)
 	// ListFile returns info about all files.
 	ListFile(context.Context, *ListFileRequest) (*FileInfos, error)
+	// ListFileStream is like ListFile but it streams the files rather than
+	// returning them in a single message, for directories with many files.
+	ListFileStream(*ListFileRequest, InternalAPI_ListFileStreamServer) error
+	// GlobFile streams info about the files and directories matching a glob.
+	GlobFile(*GlobFileRequest, InternalAPI_GlobFileServer) error
 	// DeleteFile deletes a file.
 	DeleteFile(context.Context, *DeleteFileRequest) (*google_protobuf1.Empty, error)
+	// CopyFile is implemented by the API service with these, GetFileBlockRefs
+	// returns the blocks of a regular file and PutFileBlockRefs appends blocks
+	// to one.
+	GetFileBlockRefs(context.Context, *InspectFileRequest) (*BlockRefs, error)
+	PutFileBlockRefs(context.Context, *PutFileBlockRefsRequest) (*google_protobuf1.Empty, error)
+	// DiffCommit returns the files which were added, modified and deleted
+	// between two commits.
+	DiffCommit(context.Context, *DiffCommitRequest) (*FileChanges, error)
 }
 
 func RegisterInternalAPIServer(s *grpc.Server, srv InternalAPIServer) {
@@ -1999,6 +3398,24 @@ func _InternalAPI_DeleteRepo_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _InternalAPI_SetQuota_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetQuotaRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InternalAPIServer).SetQuota(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pfs.InternalAPI/SetQuota",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InternalAPIServer).SetQuota(ctx, req.(*SetQuotaRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _InternalAPI_StartCommit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(StartCommitRequest)
 	if err := dec(in); err != nil {
@@ -2102,108 +3519,294 @@ func _InternalAPI_ListBranch_Handler(srv interface{}, ctx context.Context, dec f
 		FullMethod: "/pfs.InternalAPI/ListBranch",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(InternalAPIServer).ListBranch(ctx, req.(*ListBranchRequest))
+		return srv.(InternalAPIServer).ListBranch(ctx, req.(*ListBranchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InternalAPI_ListBranchInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListBranchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InternalAPIServer).ListBranchInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pfs.InternalAPI/ListBranchInfo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InternalAPIServer).ListBranchInfo(ctx, req.(*ListBranchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InternalAPI_CreateBranch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateBranchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InternalAPIServer).CreateBranch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pfs.InternalAPI/CreateBranch",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InternalAPIServer).CreateBranch(ctx, req.(*CreateBranchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InternalAPI_SetBranch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetBranchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InternalAPIServer).SetBranch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pfs.InternalAPI/SetBranch",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InternalAPIServer).SetBranch(ctx, req.(*SetBranchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InternalAPI_DeleteBranch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteBranchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InternalAPIServer).DeleteBranch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pfs.InternalAPI/DeleteBranch",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InternalAPIServer).DeleteBranch(ctx, req.(*DeleteBranchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InternalAPI_RenameBranch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RenameBranchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InternalAPIServer).RenameBranch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pfs.InternalAPI/RenameBranch",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InternalAPIServer).RenameBranch(ctx, req.(*RenameBranchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InternalAPI_PutFile_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(InternalAPIServer).PutFile(&internalAPIPutFileServer{stream})
+}
+
+type InternalAPI_PutFileServer interface {
+	SendAndClose(*google_protobuf1.Empty) error
+	Recv() (*PutFileRequest, error)
+	grpc.ServerStream
+}
+
+type internalAPIPutFileServer struct {
+	grpc.ServerStream
+}
+
+func (x *internalAPIPutFileServer) SendAndClose(m *google_protobuf1.Empty) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *internalAPIPutFileServer) Recv() (*PutFileRequest, error) {
+	m := new(PutFileRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _InternalAPI_GetFile_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GetFileRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(InternalAPIServer).GetFile(m, &internalAPIGetFileServer{stream})
+}
+
+type InternalAPI_GetFileServer interface {
+	Send(*google_protobuf3.BytesValue) error
+	grpc.ServerStream
+}
+
+type internalAPIGetFileServer struct {
+	grpc.ServerStream
+}
+
+func (x *internalAPIGetFileServer) Send(m *google_protobuf3.BytesValue) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _InternalAPI_InspectFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InspectFileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InternalAPIServer).InspectFile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pfs.InternalAPI/InspectFile",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InternalAPIServer).InspectFile(ctx, req.(*InspectFileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InternalAPI_ListFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListFileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InternalAPIServer).ListFile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pfs.InternalAPI/ListFile",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InternalAPIServer).ListFile(ctx, req.(*ListFileRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _InternalAPI_PutFile_Handler(srv interface{}, stream grpc.ServerStream) error {
-	return srv.(InternalAPIServer).PutFile(&internalAPIPutFileServer{stream})
+func _InternalAPI_ListFileStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListFileRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(InternalAPIServer).ListFileStream(m, &internalAPIListFileStreamServer{stream})
 }
 
-type InternalAPI_PutFileServer interface {
-	SendAndClose(*google_protobuf1.Empty) error
-	Recv() (*PutFileRequest, error)
+type InternalAPI_ListFileStreamServer interface {
+	Send(*FileInfo) error
 	grpc.ServerStream
 }
 
-type internalAPIPutFileServer struct {
+type internalAPIListFileStreamServer struct {
 	grpc.ServerStream
 }
 
-func (x *internalAPIPutFileServer) SendAndClose(m *google_protobuf1.Empty) error {
+func (x *internalAPIListFileStreamServer) Send(m *FileInfo) error {
 	return x.ServerStream.SendMsg(m)
 }
 
-func (x *internalAPIPutFileServer) Recv() (*PutFileRequest, error) {
-	m := new(PutFileRequest)
-	if err := x.ServerStream.RecvMsg(m); err != nil {
-		return nil, err
-	}
-	return m, nil
-}
-
-func _InternalAPI_GetFile_Handler(srv interface{}, stream grpc.ServerStream) error {
-	m := new(GetFileRequest)
+func _InternalAPI_GlobFile_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GlobFileRequest)
 	if err := stream.RecvMsg(m); err != nil {
 		return err
 	}
-	return srv.(InternalAPIServer).GetFile(m, &internalAPIGetFileServer{stream})
+	return srv.(InternalAPIServer).GlobFile(m, &internalAPIGlobFileServer{stream})
 }
 
-type InternalAPI_GetFileServer interface {
-	Send(*google_protobuf3.BytesValue) error
+type InternalAPI_GlobFileServer interface {
+	Send(*FileInfo) error
 	grpc.ServerStream
 }
 
-type internalAPIGetFileServer struct {
+type internalAPIGlobFileServer struct {
 	grpc.ServerStream
 }
 
-func (x *internalAPIGetFileServer) Send(m *google_protobuf3.BytesValue) error {
+func (x *internalAPIGlobFileServer) Send(m *FileInfo) error {
 	return x.ServerStream.SendMsg(m)
 }
 
-func _InternalAPI_InspectFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+func _InternalAPI_DeleteFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteFileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InternalAPIServer).DeleteFile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pfs.InternalAPI/DeleteFile",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InternalAPIServer).DeleteFile(ctx, req.(*DeleteFileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InternalAPI_GetFileBlockRefs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(InspectFileRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(InternalAPIServer).InspectFile(ctx, in)
+		return srv.(InternalAPIServer).GetFileBlockRefs(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/pfs.InternalAPI/InspectFile",
+		FullMethod: "/pfs.InternalAPI/GetFileBlockRefs",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(InternalAPIServer).InspectFile(ctx, req.(*InspectFileRequest))
+		return srv.(InternalAPIServer).GetFileBlockRefs(ctx, req.(*InspectFileRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _InternalAPI_ListFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(ListFileRequest)
+func _InternalAPI_PutFileBlockRefs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PutFileBlockRefsRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(InternalAPIServer).ListFile(ctx, in)
+		return srv.(InternalAPIServer).PutFileBlockRefs(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/pfs.InternalAPI/ListFile",
+		FullMethod: "/pfs.InternalAPI/PutFileBlockRefs",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(InternalAPIServer).ListFile(ctx, req.(*ListFileRequest))
+		return srv.(InternalAPIServer).PutFileBlockRefs(ctx, req.(*PutFileBlockRefsRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _InternalAPI_DeleteFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(DeleteFileRequest)
+func _InternalAPI_DiffCommit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DiffCommitRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(InternalAPIServer).DeleteFile(ctx, in)
+		return srv.(InternalAPIServer).DiffCommit(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/pfs.InternalAPI/DeleteFile",
+		FullMethod: "/pfs.InternalAPI/DiffCommit",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(InternalAPIServer).DeleteFile(ctx, req.(*DeleteFileRequest))
+		return srv.(InternalAPIServer).DiffCommit(ctx, req.(*DiffCommitRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -2228,6 +3831,10 @@ var _InternalAPI_serviceDesc = grpc.ServiceDesc{
 			MethodName: "DeleteRepo",
 			Handler:    _InternalAPI_DeleteRepo_Handler,
 		},
+		{
+			MethodName: "SetQuota",
+			Handler:    _InternalAPI_SetQuota_Handler,
+		},
 		{
 			MethodName: "StartCommit",
 			Handler:    _InternalAPI_StartCommit_Handler,
@@ -2252,6 +3859,26 @@ var _InternalAPI_serviceDesc = grpc.ServiceDesc{
 			MethodName: "ListBranch",
 			Handler:    _InternalAPI_ListBranch_Handler,
 		},
+		{
+			MethodName: "ListBranchInfo",
+			Handler:    _InternalAPI_ListBranchInfo_Handler,
+		},
+		{
+			MethodName: "CreateBranch",
+			Handler:    _InternalAPI_CreateBranch_Handler,
+		},
+		{
+			MethodName: "SetBranch",
+			Handler:    _InternalAPI_SetBranch_Handler,
+		},
+		{
+			MethodName: "DeleteBranch",
+			Handler:    _InternalAPI_DeleteBranch_Handler,
+		},
+		{
+			MethodName: "RenameBranch",
+			Handler:    _InternalAPI_RenameBranch_Handler,
+		},
 		{
 			MethodName: "InspectFile",
 			Handler:    _InternalAPI_InspectFile_Handler,
@@ -2264,6 +3891,18 @@ var _InternalAPI_serviceDesc = grpc.ServiceDesc{
 			MethodName: "DeleteFile",
 			Handler:    _InternalAPI_DeleteFile_Handler,
 		},
+		{
+			MethodName: "GetFileBlockRefs",
+			Handler:    _InternalAPI_GetFileBlockRefs_Handler,
+		},
+		{
+			MethodName: "PutFileBlockRefs",
+			Handler:    _InternalAPI_PutFileBlockRefs_Handler,
+		},
+		{
+			MethodName: "DiffCommit",
+			Handler:    _InternalAPI_DiffCommit_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -2276,6 +3915,16 @@ var _InternalAPI_serviceDesc = grpc.ServiceDesc{
 			Handler:       _InternalAPI_GetFile_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "ListFileStream",
+			Handler:       _InternalAPI_ListFileStream_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "GlobFile",
+			Handler:       _InternalAPI_GlobFile_Handler,
+			ServerStreams: true,
+		},
 	},
 }
 
@@ -2286,11 +3935,25 @@ type BlockAPIClient interface {
 	GetBlock(ctx context.Context, in *GetBlockRequest, opts ...grpc.CallOption) (BlockAPI_GetBlockClient, error)
 	DeleteBlock(ctx context.Context, in *DeleteBlockRequest, opts ...grpc.CallOption) (*google_protobuf1.Empty, error)
 	InspectBlock(ctx context.Context, in *InspectBlockRequest, opts ...grpc.CallOption) (*BlockInfo, error)
+	// ListBlock returns info about every block.
+	// Deprecated: use ListBlockStream, ListBlock fails once there are more
+	// blocks than fit in a single message.
 	ListBlock(ctx context.Context, in *ListBlockRequest, opts ...grpc.CallOption) (*BlockInfos, error)
+	// ListBlockStream streams info about every block, it's a stream so that it
+	// scales to stores with more blocks than fit in a single message.
+	ListBlockStream(ctx context.Context, in *ListBlockRequest, opts ...grpc.CallOption) (BlockAPI_ListBlockStreamClient, error)
+	GarbageCollect(ctx context.Context, in *GarbageCollectRequest, opts ...grpc.CallOption) (*GarbageCollectResponse, error)
 	CreateDiff(ctx context.Context, in *DiffInfo, opts ...grpc.CallOption) (*google_protobuf1.Empty, error)
 	InspectDiff(ctx context.Context, in *InspectDiffRequest, opts ...grpc.CallOption) (*DiffInfo, error)
 	ListDiff(ctx context.Context, in *ListDiffRequest, opts ...grpc.CallOption) (BlockAPI_ListDiffClient, error)
 	DeleteDiff(ctx context.Context, in *DeleteDiffRequest, opts ...grpc.CallOption) (*google_protobuf1.Empty, error)
+	// CreateShardSnapshot replaces the shard's snapshot, the shard is read
+	// from the first message.
+	CreateShardSnapshot(ctx context.Context, opts ...grpc.CallOption) (BlockAPI_CreateShardSnapshotClient, error)
+	// GetShardSnapshot streams the shard's snapshot, the stream is empty if
+	// the shard doesn't have one.
+	GetShardSnapshot(ctx context.Context, in *GetShardSnapshotRequest, opts ...grpc.CallOption) (BlockAPI_GetShardSnapshotClient, error)
+	DeleteShardSnapshot(ctx context.Context, in *DeleteShardSnapshotRequest, opts ...grpc.CallOption) (*google_protobuf1.Empty, error)
 }
 
 type blockAPIClient struct {
@@ -2394,6 +4057,47 @@ func (c *blockAPIClient) ListBlock(ctx context.Context, in *ListBlockRequest, op
 	return out, nil
 }
 
+func (c *blockAPIClient) ListBlockStream(ctx context.Context, in *ListBlockRequest, opts ...grpc.CallOption) (BlockAPI_ListBlockStreamClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_BlockAPI_serviceDesc.Streams[2], c.cc, "/pfs.BlockAPI/ListBlockStream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &blockAPIListBlockStreamClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type BlockAPI_ListBlockStreamClient interface {
+	Recv() (*BlockInfo, error)
+	grpc.ClientStream
+}
+
+type blockAPIListBlockStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *blockAPIListBlockStreamClient) Recv() (*BlockInfo, error) {
+	m := new(BlockInfo)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *blockAPIClient) GarbageCollect(ctx context.Context, in *GarbageCollectRequest, opts ...grpc.CallOption) (*GarbageCollectResponse, error) {
+	out := new(GarbageCollectResponse)
+	err := grpc.Invoke(ctx, "/pfs.BlockAPI/GarbageCollect", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *blockAPIClient) CreateDiff(ctx context.Context, in *DiffInfo, opts ...grpc.CallOption) (*google_protobuf1.Empty, error) {
 	out := new(google_protobuf1.Empty)
 	err := grpc.Invoke(ctx, "/pfs.BlockAPI/CreateDiff", in, out, c.cc, opts...)
@@ -2413,7 +4117,7 @@ func (c *blockAPIClient) InspectDiff(ctx context.Context, in *InspectDiffRequest
 }
 
 func (c *blockAPIClient) ListDiff(ctx context.Context, in *ListDiffRequest, opts ...grpc.CallOption) (BlockAPI_ListDiffClient, error) {
-	stream, err := grpc.NewClientStream(ctx, &_BlockAPI_serviceDesc.Streams[2], c.cc, "/pfs.BlockAPI/ListDiff", opts...)
+	stream, err := grpc.NewClientStream(ctx, &_BlockAPI_serviceDesc.Streams[3], c.cc, "/pfs.BlockAPI/ListDiff", opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -2453,6 +4157,81 @@ func (c *blockAPIClient) DeleteDiff(ctx context.Context, in *DeleteDiffRequest,
 	return out, nil
 }
 
+func (c *blockAPIClient) CreateShardSnapshot(ctx context.Context, opts ...grpc.CallOption) (BlockAPI_CreateShardSnapshotClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_BlockAPI_serviceDesc.Streams[4], c.cc, "/pfs.BlockAPI/CreateShardSnapshot", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &blockAPICreateShardSnapshotClient{stream}
+	return x, nil
+}
+
+type BlockAPI_CreateShardSnapshotClient interface {
+	Send(*ShardSnapshot) error
+	CloseAndRecv() (*google_protobuf1.Empty, error)
+	grpc.ClientStream
+}
+
+type blockAPICreateShardSnapshotClient struct {
+	grpc.ClientStream
+}
+
+func (x *blockAPICreateShardSnapshotClient) Send(m *ShardSnapshot) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *blockAPICreateShardSnapshotClient) CloseAndRecv() (*google_protobuf1.Empty, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(google_protobuf1.Empty)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *blockAPIClient) GetShardSnapshot(ctx context.Context, in *GetShardSnapshotRequest, opts ...grpc.CallOption) (BlockAPI_GetShardSnapshotClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_BlockAPI_serviceDesc.Streams[5], c.cc, "/pfs.BlockAPI/GetShardSnapshot", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &blockAPIGetShardSnapshotClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type BlockAPI_GetShardSnapshotClient interface {
+	Recv() (*ShardSnapshot, error)
+	grpc.ClientStream
+}
+
+type blockAPIGetShardSnapshotClient struct {
+	grpc.ClientStream
+}
+
+func (x *blockAPIGetShardSnapshotClient) Recv() (*ShardSnapshot, error) {
+	m := new(ShardSnapshot)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *blockAPIClient) DeleteShardSnapshot(ctx context.Context, in *DeleteShardSnapshotRequest, opts ...grpc.CallOption) (*google_protobuf1.Empty, error) {
+	out := new(google_protobuf1.Empty)
+	err := grpc.Invoke(ctx, "/pfs.BlockAPI/DeleteShardSnapshot", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for BlockAPI service
 
 type BlockAPIServer interface {
@@ -2460,11 +4239,25 @@ type BlockAPIServer interface {
 	GetBlock(*GetBlockRequest, BlockAPI_GetBlockServer) error
 	DeleteBlock(context.Context, *DeleteBlockRequest) (*google_protobuf1.Empty, error)
 	InspectBlock(context.Context, *InspectBlockRequest) (*BlockInfo, error)
+	// ListBlock returns info about every block.
+	// Deprecated: use ListBlockStream, ListBlock fails once there are more
+	// blocks than fit in a single message.
 	ListBlock(context.Context, *ListBlockRequest) (*BlockInfos, error)
+	// ListBlockStream streams info about every block, it's a stream so that it
+	// scales to stores with more blocks than fit in a single message.
+	ListBlockStream(*ListBlockRequest, BlockAPI_ListBlockStreamServer) error
+	GarbageCollect(context.Context, *GarbageCollectRequest) (*GarbageCollectResponse, error)
 	CreateDiff(context.Context, *DiffInfo) (*google_protobuf1.Empty, error)
 	InspectDiff(context.Context, *InspectDiffRequest) (*DiffInfo, error)
 	ListDiff(*ListDiffRequest, BlockAPI_ListDiffServer) error
 	DeleteDiff(context.Context, *DeleteDiffRequest) (*google_protobuf1.Empty, error)
+	// CreateShardSnapshot replaces the shard's snapshot, the shard is read
+	// from the first message.
+	CreateShardSnapshot(BlockAPI_CreateShardSnapshotServer) error
+	// GetShardSnapshot streams the shard's snapshot, the stream is empty if
+	// the shard doesn't have one.
+	GetShardSnapshot(*GetShardSnapshotRequest, BlockAPI_GetShardSnapshotServer) error
+	DeleteShardSnapshot(context.Context, *DeleteShardSnapshotRequest) (*google_protobuf1.Empty, error)
 }
 
 func RegisterBlockAPIServer(s *grpc.Server, srv BlockAPIServer) {
@@ -2572,6 +4365,45 @@ func _BlockAPI_ListBlock_Handler(srv interface{}, ctx context.Context, dec func(
 	return interceptor(ctx, in, info, handler)
 }
 
+func _BlockAPI_ListBlockStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListBlockRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(BlockAPIServer).ListBlockStream(m, &blockAPIListBlockStreamServer{stream})
+}
+
+type BlockAPI_ListBlockStreamServer interface {
+	Send(*BlockInfo) error
+	grpc.ServerStream
+}
+
+type blockAPIListBlockStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *blockAPIListBlockStreamServer) Send(m *BlockInfo) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _BlockAPI_GarbageCollect_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GarbageCollectRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BlockAPIServer).GarbageCollect(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pfs.BlockAPI/GarbageCollect",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BlockAPIServer).GarbageCollect(ctx, req.(*GarbageCollectRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _BlockAPI_CreateDiff_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(DiffInfo)
 	if err := dec(in); err != nil {
@@ -2647,6 +4479,71 @@ func _BlockAPI_DeleteDiff_Handler(srv interface{}, ctx context.Context, dec func
 	return interceptor(ctx, in, info, handler)
 }
 
+func _BlockAPI_CreateShardSnapshot_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(BlockAPIServer).CreateShardSnapshot(&blockAPICreateShardSnapshotServer{stream})
+}
+
+type BlockAPI_CreateShardSnapshotServer interface {
+	SendAndClose(*google_protobuf1.Empty) error
+	Recv() (*ShardSnapshot, error)
+	grpc.ServerStream
+}
+
+type blockAPICreateShardSnapshotServer struct {
+	grpc.ServerStream
+}
+
+func (x *blockAPICreateShardSnapshotServer) SendAndClose(m *google_protobuf1.Empty) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *blockAPICreateShardSnapshotServer) Recv() (*ShardSnapshot, error) {
+	m := new(ShardSnapshot)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _BlockAPI_GetShardSnapshot_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GetShardSnapshotRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(BlockAPIServer).GetShardSnapshot(m, &blockAPIGetShardSnapshotServer{stream})
+}
+
+type BlockAPI_GetShardSnapshotServer interface {
+	Send(*ShardSnapshot) error
+	grpc.ServerStream
+}
+
+type blockAPIGetShardSnapshotServer struct {
+	grpc.ServerStream
+}
+
+func (x *blockAPIGetShardSnapshotServer) Send(m *ShardSnapshot) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _BlockAPI_DeleteShardSnapshot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteShardSnapshotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BlockAPIServer).DeleteShardSnapshot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pfs.BlockAPI/DeleteShardSnapshot",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BlockAPIServer).DeleteShardSnapshot(ctx, req.(*DeleteShardSnapshotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _BlockAPI_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "pfs.BlockAPI",
 	HandlerType: (*BlockAPIServer)(nil),
@@ -2663,6 +4560,10 @@ var _BlockAPI_serviceDesc = grpc.ServiceDesc{
 			MethodName: "ListBlock",
 			Handler:    _BlockAPI_ListBlock_Handler,
 		},
+		{
+			MethodName: "GarbageCollect",
+			Handler:    _BlockAPI_GarbageCollect_Handler,
+		},
 		{
 			MethodName: "CreateDiff",
 			Handler:    _BlockAPI_CreateDiff_Handler,
@@ -2675,6 +4576,10 @@ var _BlockAPI_serviceDesc = grpc.ServiceDesc{
 			MethodName: "DeleteDiff",
 			Handler:    _BlockAPI_DeleteDiff_Handler,
 		},
+		{
+			MethodName: "DeleteShardSnapshot",
+			Handler:    _BlockAPI_DeleteShardSnapshot_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -2687,131 +4592,235 @@ var _BlockAPI_serviceDesc = grpc.ServiceDesc{
 			Handler:       _BlockAPI_GetBlock_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "ListBlockStream",
+			Handler:       _BlockAPI_ListBlockStream_Handler,
+			ServerStreams: true,
+		},
 		{
 			StreamName:    "ListDiff",
 			Handler:       _BlockAPI_ListDiff_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "CreateShardSnapshot",
+			Handler:       _BlockAPI_CreateShardSnapshot_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "GetShardSnapshot",
+			Handler:       _BlockAPI_GetShardSnapshot_Handler,
+			ServerStreams: true,
+		},
 	},
 }
 
 var fileDescriptor0 = []byte{
-	// 1857 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xec, 0x59, 0x4b, 0x73, 0x1b, 0xc5,
-	0x13, 0x8f, 0x5e, 0xd6, 0xaa, 0x65, 0xc9, 0xf2, 0xd8, 0xc9, 0xdf, 0x59, 0xe7, 0x4f, 0xcc, 0x26,
-	0x40, 0x2a, 0x15, 0xec, 0x94, 0x42, 0x1e, 0xe5, 0x14, 0x04, 0x3f, 0x94, 0x44, 0x54, 0x9c, 0xa4,
-	0x36, 0x06, 0x8a, 0x93, 0x6b, 0x25, 0xad, 0xac, 0xad, 0xac, 0x56, 0x62, 0x77, 0x45, 0xca, 0x1c,
-	0x38, 0x70, 0x0b, 0x07, 0x2e, 0x1c, 0x29, 0x0e, 0x54, 0x71, 0xe6, 0xc0, 0x91, 0x4f, 0x02, 0x5f,
-	0x81, 0x4f, 0xc1, 0x4c, 0xcf, 0xcc, 0x6a, 0x56, 0x92, 0xa5, 0x55, 0xa8, 0x14, 0x07, 0x72, 0x80,
-	0xcc, 0xa3, 0xbb, 0xa7, 0xfb, 0x37, 0xbf, 0xee, 0x69, 0xad, 0x61, 0xb5, 0xe9, 0x3a, 0xb6, 0x17,
-	0x6e, 0xf5, 0xdb, 0x01, 0xfb, 0x6f, 0xb3, 0xef, 0xf7, 0xc2, 0x1e, 0xc9, 0xd0, 0xa1, 0x7e, 0xe1,
-	0xb8, 0xd7, 0x3b, 0x76, 0xed, 0x2d, 0xab, 0xef, 0x6c, 0x59, 0x9e, 0xd7, 0x0b, 0xad, 0xd0, 0xe9,
-	0x79, 0x42, 0x44, 0x5f, 0x17, 0xbb, 0x38, 0x6b, 0x0c, 0xda, 0x5b, 0x76, 0xb7, 0x1f, 0x9e, 0x88,
-	0xcd, 0x8b, 0xa3, 0x9b, 0xa1, 0xd3, 0xb5, 0x83, 0xd0, 0xea, 0xf6, 0x85, 0xc0, 0x5b, 0xa3, 0x02,
-	0x2f, 0x7c, 0xab, 0xdf, 0xb7, 0x7d, 0x69, 0xfd, 0x82, 0x74, 0xeb, 0xf9, 0xf1, 0x56, 0xd0, 0xb1,
-	0xfc, 0x16, 0xff, 0x3f, 0xdf, 0x35, 0x74, 0xc8, 0x9a, 0x76, 0xbf, 0x47, 0x08, 0x64, 0x3d, 0xab,
-	0x6b, 0xaf, 0xa5, 0x36, 0x52, 0x57, 0x0a, 0x26, 0x8e, 0x8d, 0xdb, 0xb0, 0xb0, 0xd7, 0xeb, 0x76,
-	0x9d, 0x90, 0xfc, 0x1f, 0xb2, 0x3e, 0x95, 0xc2, 0xdd, 0x62, 0xb5, 0xb0, 0xc9, 0xc2, 0x63, 0x6a,
-	0x26, 0x2e, 0x93, 0x32, 0xa4, 0x9d, 0xd6, 0x5a, 0x1a, 0x55, 0xe9, 0xc8, 0xb8, 0x07, 0xd9, 0xfb,
-	0x8e, 0x6b, 0x93, 0x4b, 0xb0, 0xd0, 0x44, 0x03, 0x42, 0xb1, 0x88, 0x8a, 0xdc, 0xa6, 0x29, 0xb6,
-	0xd8, 0xc9, 0x7d, 0x2b, 0xec, 0x08, 0x75, 0x1c, 0x1b, 0xeb, 0x90, 0xdb, 0x75, 0x7b, 0xcd, 0xe7,
-	0x6c, 0xb3, 0x63, 0x05, 0x1d, 0xe9, 0x16, 0x1b, 0x1b, 0x3b, 0x90, 0xdd, 0x77, 0xda, 0xed, 0x64,
-	0xd6, 0x57, 0x21, 0x87, 0xe1, 0xa2, 0xf9, 0xac, 0xc9, 0x27, 0xc6, 0x37, 0xa0, 0x31, 0xf7, 0xeb,
-	0x5e, 0xbb, 0x37, 0x2b, 0xb6, 0x0f, 0x20, 0xdf, 0xf4, 0x6d, 0x2b, 0xb4, 0xb9, 0x89, 0x62, 0x55,
-	0xdf, 0xe4, 0x80, 0x6f, 0x4a, 0xc0, 0x37, 0x0f, 0xe5, 0x8d, 0x98, 0x52, 0x94, 0x1a, 0x85, 0xc0,
-	0xf9, 0xda, 0x3e, 0x6a, 0x9c, 0x84, 0x76, 0xb0, 0x96, 0xc1, 0xb3, 0x0b, 0x6c, 0x65, 0x97, 0x2d,
-	0x50, 0x64, 0x0b, 0xf2, 0xfc, 0x80, 0x5c, 0x85, 0x02, 0x3b, 0xe9, 0xc8, 0xa1, 0x33, 0xea, 0x45,
-	0x86, 0x9e, 0x51, 0x8a, 0xbc, 0x60, 0x22, 0xa6, 0xe6, 0x8b, 0x91, 0xf1, 0x47, 0x1a, 0x80, 0x47,
-	0x88, 0xbe, 0x27, 0x82, 0xe0, 0x1c, 0x2c, 0x34, 0x7c, 0xcb, 0x6b, 0x4a, 0x88, 0xc5, 0x8c, 0x5c,
-	0x87, 0x22, 0x97, 0x38, 0x0a, 0x4f, 0xfa, 0x36, 0x3a, 0x59, 0xae, 0x2e, 0x29, 0x16, 0x0e, 0xe9,
-	0xb2, 0x09, 0xcd, 0x68, 0x4c, 0x35, 0x4a, 0x7d, 0xcb, 0xa7, 0x64, 0x3a, 0x12, 0xa7, 0x66, 0xc7,
-	0x4f, 0x5d, 0xe4, 0x12, 0x82, 0x38, 0x14, 0x3d, 0x8a, 0x8c, 0xcf, 0xd0, 0xcb, 0xcd, 0x46, 0x4f,
-	0x88, 0x92, 0x5b, 0xa0, 0xb5, 0x1d, 0xcf, 0x09, 0x3a, 0x54, 0x6d, 0x61, 0xa6, 0x5a, 0x24, 0x3b,
-	0x82, 0x7a, 0x7e, 0x04, 0x75, 0x72, 0x01, 0x0a, 0x4d, 0x1a, 0xb9, 0xed, 0xba, 0xd4, 0xae, 0x46,
-	0x77, 0x35, 0x73, 0xb8, 0x40, 0x49, 0x5b, 0x1c, 0x22, 0x1b, 0x28, 0xe8, 0x28, 0xf7, 0xa2, 0xa2,
-	0x83, 0x37, 0x23, 0xd0, 0xc1, 0xbb, 0x79, 0x99, 0x06, 0x8d, 0xd1, 0x5e, 0xb2, 0xaa, 0x4d, 0xc7,
-	0x31, 0x56, 0xb1, 0x4d, 0x13, 0x97, 0xd9, 0x9d, 0xb3, 0x7f, 0x39, 0xf2, 0x69, 0x44, 0xbe, 0x14,
-	0xc9, 0x20, 0xee, 0x5a, 0x5b, 0x8c, 0x66, 0x70, 0x89, 0x81, 0xd5, 0xed, 0xb5, 0x9c, 0xb6, 0x43,
-	0x83, 0xca, 0xce, 0x06, 0x4b, 0xca, 0xd2, 0xab, 0x59, 0x12, 0x01, 0x46, 0xea, 0xb9, 0xf1, 0xeb,
-	0x2c, 0x73, 0x99, 0x03, 0xa9, 0xf5, 0x0e, 0x68, 0xcd, 0x8e, 0xe3, 0xb6, 0xe8, 0x1d, 0xd3, 0xab,
-	0xc9, 0xc4, 0x63, 0x8b, 0xb6, 0x18, 0xc1, 0x25, 0x14, 0x41, 0x14, 0xec, 0x18, 0xc1, 0xa5, 0x08,
-	0x0f, 0x16, 0x41, 0xa4, 0x8a, 0x2c, 0x2c, 0xd3, 0xf2, 0x8e, 0x6d, 0x96, 0xbc, 0x6e, 0xef, 0x85,
-	0xed, 0x23, 0x8a, 0x34, 0x79, 0x71, 0xc2, 0x56, 0x07, 0xac, 0xc0, 0xc9, 0x94, 0xc6, 0x89, 0x61,
-	0x82, 0x86, 0x25, 0xc3, 0xb4, 0xdb, 0x64, 0x03, 0x72, 0x0d, 0x36, 0x16, 0xe8, 0x03, 0x1e, 0xc6,
-	0x77, 0xf9, 0x06, 0xb9, 0x0c, 0x39, 0x9f, 0x1d, 0x21, 0x72, 0xba, 0xcc, 0x25, 0xe4, 0xc1, 0x26,
-	0xdf, 0x44, 0x67, 0x84, 0x4d, 0x8c, 0x02, 0x75, 0x8f, 0x7c, 0xbb, 0x1d, 0x8b, 0x42, 0x8a, 0x98,
-	0x5a, 0x43, 0x8c, 0x8c, 0xbf, 0xd2, 0xb0, 0xb0, 0x43, 0xdd, 0xf2, 0x5a, 0xe4, 0x1a, 0x40, 0xa4,
-	0x16, 0x4c, 0xd6, 0x2b, 0x34, 0xa2, 0x43, 0x6e, 0x2a, 0xf0, 0xa6, 0x51, 0xf6, 0x3c, 0xca, 0x72,
-	0x63, 0x9b, 0x7b, 0x62, 0xaf, 0xe6, 0x85, 0xfe, 0xc9, 0x10, 0x6e, 0xf2, 0x2e, 0x68, 0xae, 0x15,
-	0x84, 0xe8, 0x5a, 0x66, 0xfc, 0x12, 0xf3, 0x6c, 0x93, 0x01, 0x43, 0x4b, 0x41, 0xcb, 0x76, 0xed,
-	0xd0, 0x46, 0xa6, 0x68, 0xa6, 0x98, 0x91, 0x2a, 0xe4, 0x3b, 0x96, 0xd7, 0x72, 0x29, 0xbf, 0x72,
-	0x78, 0xea, 0x9a, 0x7a, 0xea, 0x43, 0xbe, 0xc5, 0x0f, 0x95, 0x82, 0xfa, 0x5d, 0x28, 0xc5, 0xdc,
-	0x21, 0x15, 0xc8, 0x3c, 0xb7, 0x4f, 0x44, 0xa9, 0x66, 0x43, 0x76, 0x53, 0x5f, 0x59, 0xee, 0x80,
-	0xa3, 0xac, 0x99, 0x7c, 0xb2, 0x9d, 0xbe, 0x93, 0xd2, 0x3f, 0x81, 0x45, 0xd5, 0xea, 0x04, 0xdd,
-	0xcb, 0xaa, 0x6e, 0x74, 0x43, 0x12, 0x28, 0xc5, 0x96, 0xf1, 0x6d, 0x4a, 0x5c, 0x13, 0x26, 0xde,
-	0xec, 0xbb, 0x7f, 0x2d, 0x15, 0xfd, 0x2e, 0x40, 0xe4, 0x43, 0x40, 0xde, 0x97, 0x97, 0xae, 0x50,
-	0x5e, 0x89, 0x00, 0x39, 0xcf, 0x6f, 0x1d, 0x49, 0xff, 0x53, 0x06, 0x34, 0xf6, 0xa4, 0xc9, 0xca,
-	0x41, 0x73, 0xad, 0x1d, 0xab, 0x1c, 0x6c, 0xd3, 0xc4, 0xe5, 0xf1, 0x1a, 0x9c, 0x9e, 0x55, 0x83,
-	0x87, 0xf5, 0x3f, 0x13, 0xab, 0xff, 0x4a, 0x6d, 0xce, 0xbe, 0x5a, 0x6d, 0xce, 0xcd, 0x51, 0x9b,
-	0xe9, 0x69, 0x16, 0xd2, 0x29, 0x10, 0x75, 0x43, 0x8f, 0x22, 0x63, 0x61, 0x0b, 0xae, 0x49, 0x92,
-	0x09, 0xd1, 0x7f, 0x54, 0xd1, 0xf5, 0x07, 0xb0, 0xa8, 0x5a, 0x9d, 0x40, 0xb2, 0xb7, 0xe3, 0x24,
-	0x2b, 0x2a, 0xac, 0x57, 0x19, 0xf6, 0x43, 0x0a, 0x72, 0xcf, 0x58, 0xe3, 0x40, 0x2e, 0x42, 0x11,
-	0x4b, 0x99, 0x37, 0xe8, 0x36, 0xa2, 0xba, 0x04, 0x6c, 0xe9, 0x31, 0xae, 0x50, 0x8b, 0x8b, 0x28,
-	0x40, 0x6b, 0xea, 0xc0, 0x1d, 0x04, 0xa2, 0x46, 0xa1, 0xd2, 0x01, 0x5f, 0x62, 0x22, 0x9c, 0x1c,
-	0xc2, 0x08, 0xe7, 0x52, 0x11, 0xd7, 0x84, 0x95, 0x4b, 0x50, 0xe2, 0x22, 0xd2, 0x4c, 0x16, 0x65,
-	0xb8, 0x9e, 0xb0, 0x63, 0x74, 0x60, 0x79, 0x0f, 0xc9, 0x89, 0xdd, 0x8a, 0xfd, 0xe5, 0x80, 0x62,
-	0xfe, 0x5a, 0xba, 0x19, 0xe3, 0x06, 0x90, 0xba, 0x17, 0xf4, 0xed, 0x66, 0x98, 0xfc, 0x28, 0x63,
-	0x19, 0x96, 0x1e, 0x39, 0x81, 0xaa, 0x61, 0x54, 0x61, 0x79, 0x1f, 0x0b, 0xce, 0x1c, 0x66, 0x7e,
-	0x4d, 0x01, 0x79, 0xc6, 0xb8, 0x27, 0xb8, 0x9d, 0x2c, 0xce, 0x91, 0x8e, 0x94, 0xac, 0x43, 0x41,
-	0x64, 0x0d, 0x5d, 0xe6, 0x69, 0xa0, 0xf1, 0x85, 0x7a, 0x4b, 0x49, 0x90, 0xec, 0x69, 0x09, 0x92,
-	0xbc, 0x79, 0x31, 0xbe, 0x4b, 0xc1, 0xca, 0x7d, 0x64, 0x7d, 0xdc, 0xe3, 0xa4, 0xbd, 0x1a, 0xe7,
-	0xaf, 0x28, 0x99, 0x62, 0x16, 0xcb, 0xba, 0x4c, 0xf2, 0xac, 0xa3, 0x65, 0x69, 0x55, 0xdc, 0xdc,
-	0xfc, 0xce, 0x18, 0xbf, 0xa7, 0x60, 0x99, 0x5d, 0xe1, 0x69, 0xc8, 0x67, 0x26, 0x21, 0x3f, 0xd2,
-	0x55, 0xa6, 0x67, 0x77, 0x95, 0xd7, 0x68, 0x4e, 0xf9, 0xbd, 0xae, 0xac, 0x67, 0x19, 0xb4, 0x1b,
-	0x73, 0x08, 0xd8, 0xbe, 0xa8, 0x66, 0x34, 0x89, 0x2d, 0xd7, 0x15, 0xef, 0x17, 0x1b, 0xb2, 0x57,
-	0x86, 0x57, 0xfc, 0x1c, 0x7f, 0x65, 0x70, 0xc2, 0xb8, 0xc6, 0x7c, 0xdf, 0xc5, 0xab, 0x4c, 0xc8,
-	0xb5, 0x6d, 0x58, 0xe1, 0xfc, 0x7c, 0x05, 0xb0, 0xfe, 0x4c, 0x41, 0xf9, 0x81, 0x1d, 0x62, 0x1f,
-	0x34, 0x3c, 0x6d, 0x5a, 0x0f, 0x48, 0xeb, 0x40, 0xaf, 0xdd, 0x0e, 0xec, 0x50, 0x54, 0x37, 0x06,
-	0x55, 0xc6, 0x2c, 0xf2, 0x35, 0x5e, 0xdf, 0xc6, 0x1f, 0x9d, 0x8c, 0x5a, 0xfe, 0x36, 0xe4, 0x8f,
-	0x9b, 0xac, 0xf2, 0xd6, 0x61, 0xa1, 0x12, 0x3f, 0x74, 0x46, 0xb1, 0x9d, 0xd0, 0xe0, 0xa9, 0xd8,
-	0x52, 0xf6, 0x0d, 0xbc, 0xc0, 0x6a, 0xdb, 0xd8, 0x75, 0x53, 0xf6, 0xf1, 0x99, 0xf1, 0x92, 0xc6,
-	0xf6, 0x74, 0x30, 0x4f, 0x6c, 0xf3, 0xf4, 0xb7, 0x51, 0x97, 0xc0, 0xe2, 0x5b, 0x14, 0x75, 0x97,
-	0xf9, 0xc2, 0x3b, 0x0d, 0x99, 0x94, 0x7c, 0x66, 0xfc, 0x98, 0x8a, 0x8a, 0xd1, 0x1c, 0xfe, 0x6c,
-	0xa8, 0x3f, 0x03, 0x93, 0x20, 0x95, 0x49, 0x8a, 0x54, 0x36, 0x86, 0xd4, 0x6f, 0x29, 0x5e, 0xf5,
-	0xfe, 0x45, 0xd7, 0xd6, 0x20, 0xef, 0xdb, 0xcd, 0x81, 0x1f, 0x48, 0xdf, 0xe4, 0x54, 0x71, 0x3a,
-	0x17, 0x73, 0x3a, 0x2a, 0xcb, 0xc9, 0xbd, 0x36, 0x06, 0xb0, 0x44, 0xd9, 0x2e, 0xfa, 0x31, 0xae,
-	0x31, 0xbb, 0xf3, 0x9a, 0xc4, 0xf8, 0xec, 0x2c, 0xc6, 0xc7, 0xda, 0xac, 0x5b, 0x40, 0xb8, 0xab,
-	0xf3, 0x9d, 0x4c, 0x3b, 0xf9, 0x15, 0x41, 0x9a, 0x39, 0x15, 0x09, 0x54, 0xb0, 0x8c, 0x28, 0x5a,
-	0xca, 0x73, 0x88, 0x7d, 0xd9, 0x10, 0xb0, 0x29, 0x7d, 0x9b, 0xf1, 0x1e, 0x27, 0x86, 0xaa, 0x11,
-	0x7d, 0x9b, 0x48, 0xa9, 0xdf, 0x26, 0xa2, 0xdb, 0x48, 0x6e, 0xfc, 0xea, 0x13, 0xf9, 0x55, 0x40,
-	0x24, 0x54, 0x65, 0xef, 0xc9, 0xc1, 0x41, 0xfd, 0xf0, 0xe8, 0xf0, 0x8b, 0xa7, 0xb5, 0xa3, 0xc7,
-	0x4f, 0x1e, 0xd7, 0x2a, 0x67, 0x46, 0x57, 0xcd, 0xda, 0xce, 0x7e, 0x25, 0x45, 0xce, 0xd2, 0x26,
-	0x42, 0x59, 0xfd, 0xdc, 0xac, 0x1f, 0xd6, 0x2a, 0xe9, 0xab, 0x0f, 0xf9, 0x4f, 0x59, 0x34, 0x47,
-	0xa0, 0x7c, 0xbf, 0xfe, 0xa8, 0x16, 0x33, 0x46, 0xd5, 0x86, 0x6b, 0x66, 0xed, 0xc1, 0xa7, 0x8f,
-	0x76, 0x4c, 0x6a, 0x6d, 0x19, 0x4a, 0xc3, 0xe5, 0xfd, 0xba, 0x59, 0x49, 0x57, 0xbf, 0xcf, 0x43,
-	0x66, 0xe7, 0x69, 0x9d, 0x7c, 0x44, 0x5d, 0x8c, 0xba, 0x15, 0x72, 0x8e, 0xb3, 0x77, 0xb4, 0x7d,
-	0xd1, 0xcf, 0x8d, 0xbd, 0x6a, 0x35, 0xf6, 0x2d, 0xcc, 0x38, 0x43, 0x6e, 0x43, 0x51, 0xe9, 0x41,
-	0xc8, 0xff, 0xd0, 0xc0, 0x78, 0x57, 0xa2, 0xc7, 0x3f, 0x9d, 0x50, 0xc5, 0x2a, 0x68, 0xb2, 0x0f,
-	0x21, 0xab, 0xb8, 0x39, 0xd2, 0x96, 0xe8, 0xe5, 0x98, 0x4a, 0x40, 0x75, 0xa8, 0xb3, 0xc3, 0x46,
-	0x45, 0x38, 0x3b, 0xd6, 0xb9, 0x4c, 0x71, 0xf6, 0x26, 0x14, 0x95, 0x9e, 0x45, 0x38, 0x3b, 0xde,
-	0xc5, 0xe8, 0x6a, 0x12, 0x53, 0xb5, 0x5d, 0x58, 0x54, 0x3b, 0x07, 0xb2, 0x26, 0xb2, 0x6e, 0xac,
-	0x99, 0x98, 0x72, 0xf4, 0x87, 0x50, 0x8a, 0xbd, 0xf8, 0xe4, 0xbc, 0x8a, 0x54, 0xdc, 0xca, 0xe8,
-	0xe7, 0x0c, 0xaa, 0x7e, 0x07, 0x60, 0xf8, 0xe4, 0x8b, 0xc8, 0xc7, 0x7a, 0x00, 0xbd, 0x32, 0xa2,
-	0x18, 0x70, 0xe7, 0xd5, 0xc7, 0x53, 0x38, 0x3f, 0xe1, 0x3d, 0x9d, 0xe2, 0xbc, 0x38, 0x9d, 0x3f,
-	0xda, 0xca, 0xe9, 0xb1, 0x57, 0x7c, 0xe2, 0xe9, 0xdb, 0x90, 0x17, 0x2f, 0x14, 0x59, 0xc1, 0xed,
-	0xf8, 0x7b, 0x75, 0xfa, 0x99, 0x57, 0x52, 0xe4, 0x1e, 0xe4, 0xc5, 0xcb, 0x2d, 0x74, 0xe3, 0xef,
-	0xb8, 0xbe, 0x3e, 0xa6, 0x8b, 0xc5, 0xe8, 0x33, 0xf6, 0x4e, 0x19, 0x67, 0xae, 0xa7, 0x14, 0x6e,
-	0xa2, 0x91, 0x18, 0x37, 0x55, 0x43, 0xf1, 0xaf, 0x1e, 0x43, 0x6e, 0xa2, 0xd6, 0x90, 0x9b, 0xaa,
-	0x4a, 0x39, 0xa6, 0x12, 0xe3, 0x26, 0x6a, 0xa9, 0xdc, 0x4c, 0x14, 0x6f, 0xf5, 0xe7, 0x3c, 0xf3,
-	0x36, 0xb4, 0x7d, 0xcf, 0x72, 0xff, 0x73, 0x89, 0xf9, 0x71, 0xc2, 0xc4, 0x3c, 0xdd, 0xc2, 0x9b,
-	0x1c, 0x7d, 0x93, 0xa3, 0xaf, 0x3d, 0x47, 0x7f, 0xc9, 0x8a, 0xaf, 0x99, 0x2c, 0x41, 0xef, 0x82,
-	0x46, 0x81, 0xe2, 0x7f, 0x0f, 0x99, 0x16, 0xa6, 0x3e, 0xf2, 0x8d, 0x0c, 0x71, 0xdb, 0x01, 0x4d,
-	0xf6, 0x69, 0xc2, 0xfb, 0x91, 0xb6, 0x6d, 0x36, 0x72, 0x34, 0x67, 0x94, 0x9e, 0x4b, 0x20, 0x37,
-	0xde, 0x85, 0x4d, 0xa1, 0xcc, 0x36, 0x2c, 0xaa, 0xdd, 0x97, 0xa0, 0xdd, 0x84, 0x86, 0x4c, 0x1f,
-	0xf9, 0x48, 0x86, 0x4f, 0x69, 0x21, 0x6a, 0xc0, 0xc8, 0xd9, 0x21, 0xdb, 0x54, 0xad, 0xa5, 0xb8,
-	0x56, 0x80, 0x6a, 0xa2, 0xaa, 0xe1, 0x9f, 0x8a, 0x4a, 0xb1, 0x6f, 0x4d, 0x89, 0x8a, 0x19, 0xea,
-	0xc5, 0x58, 0xa2, 0xf4, 0x63, 0x7a, 0xdc, 0x20, 0x55, 0xbc, 0xc1, 0x59, 0x82, 0x5a, 0x43, 0x96,
-	0x4c, 0x53, 0xa1, 0xc8, 0x46, 0x34, 0x41, 0x35, 0x95, 0x26, 0xaa, 0xe2, 0xa9, 0xde, 0x36, 0x16,
-	0x70, 0xe5, 0xc6, 0xdf, 0x01, 0x00, 0x00, 0xff, 0xff, 0x7b, 0xeb, 0xc9, 0x1b, 0x7a, 0x1c, 0x00,
-	0x00,
+	// 3287 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xec, 0x5a, 0x4b, 0x73, 0x1b, 0xc7,
+	0x11, 0xe6, 0x02, 0x20, 0x09, 0x34, 0x48, 0x00, 0x1c, 0xea, 0x01, 0x43, 0x92, 0x25, 0xaf, 0x1f,
+	0x51, 0x18, 0x99, 0x54, 0x20, 0x4b, 0xb2, 0x2c, 0x27, 0x32, 0x44, 0x42, 0x14, 0x1c, 0xbe, 0xbc,
+	0xa4, 0x93, 0x72, 0x2e, 0xa8, 0x05, 0x30, 0x20, 0x37, 0x5c, 0xec, 0xc2, 0xbb, 0x0b, 0xc9, 0x74,
+	0xe5, 0x92, 0x54, 0xaa, 0x52, 0x49, 0x55, 0x2e, 0xc9, 0x25, 0x97, 0x54, 0xe5, 0x3f, 0xe4, 0xe4,
+	0x1f, 0x90, 0x6b, 0xee, 0xc9, 0xc1, 0x87, 0x54, 0xe5, 0x92, 0xfc, 0x89, 0xd4, 0xf4, 0xcc, 0xec,
+	0xce, 0x2e, 0x9e, 0xb4, 0xe4, 0x4a, 0x0e, 0x3a, 0x90, 0x35, 0x8f, 0xee, 0x9e, 0x9e, 0x9e, 0xee,
+	0x9e, 0x9e, 0x6f, 0x01, 0x17, 0xda, 0xb6, 0x45, 0x9d, 0x60, 0xa3, 0xdf, 0xf5, 0xd9, 0xdf, 0x7a,
+	0xdf, 0x73, 0x03, 0x97, 0xa4, 0xfb, 0x5d, 0xbf, 0x72, 0xf5, 0xd8, 0x75, 0x8f, 0x6d, 0xba, 0x61,
+	0xf6, 0xad, 0x0d, 0xd3, 0x71, 0xdc, 0xc0, 0x0c, 0x2c, 0xd7, 0x11, 0x24, 0x95, 0x2b, 0x62, 0x16,
+	0x7b, 0xad, 0x41, 0x77, 0x83, 0xf6, 0xfa, 0xc1, 0x99, 0x98, 0xbc, 0x9e, 0x9c, 0x0c, 0xac, 0x1e,
+	0xf5, 0x03, 0xb3, 0xd7, 0x17, 0x04, 0xaf, 0x27, 0x09, 0x9e, 0x7b, 0x66, 0xbf, 0x4f, 0x3d, 0x29,
+	0xfd, 0xaa, 0x54, 0xeb, 0xf4, 0x78, 0xc3, 0x3f, 0x31, 0xbd, 0x0e, 0xff, 0xcf, 0x67, 0xf5, 0x0a,
+	0x64, 0x0c, 0xda, 0x77, 0x09, 0x81, 0x8c, 0x63, 0xf6, 0x68, 0x59, 0xbb, 0xa1, 0xdd, 0xcc, 0x19,
+	0xd8, 0xd6, 0xef, 0xc3, 0xc2, 0xa6, 0xdb, 0xeb, 0x59, 0x01, 0xb9, 0x06, 0x19, 0x8f, 0xf6, 0x5d,
+	0x9c, 0xcd, 0x57, 0x73, 0xeb, 0x6c, 0x7b, 0x8c, 0xcd, 0xc0, 0x61, 0x52, 0x80, 0x94, 0xd5, 0x29,
+	0xa7, 0x90, 0x35, 0x65, 0x75, 0xf4, 0x47, 0x90, 0x79, 0x62, 0xd9, 0x94, 0xbc, 0x09, 0x0b, 0x6d,
+	0x14, 0x20, 0x18, 0xf3, 0xc8, 0xc8, 0x65, 0x1a, 0x62, 0x8a, 0xad, 0xdc, 0x37, 0x83, 0x13, 0xc1,
+	0x8e, 0x6d, 0xfd, 0x0a, 0xcc, 0x3f, 0xb6, 0xdd, 0xf6, 0x29, 0x9b, 0x3c, 0x31, 0xfd, 0x13, 0xa9,
+	0x16, 0x6b, 0xeb, 0x35, 0xc8, 0x6c, 0x59, 0xdd, 0xee, 0x6c, 0xd2, 0x2f, 0xc0, 0x3c, 0x6e, 0x17,
+	0xc5, 0x67, 0x0c, 0xde, 0xd1, 0x7f, 0xa5, 0xc1, 0xfc, 0x27, 0x03, 0x37, 0x30, 0xc9, 0x15, 0xc8,
+	0xf5, 0xcc, 0x2f, 0x9a, 0xad, 0xb3, 0x80, 0xfa, 0x28, 0x27, 0x63, 0x64, 0x7b, 0xe6, 0x17, 0x8f,
+	0x59, 0x9f, 0x6c, 0xc0, 0x05, 0x36, 0xd9, 0xb5, 0x6c, 0xea, 0x37, 0xfb, 0xd4, 0x6b, 0x8a, 0xf5,
+	0xb8, 0xac, 0x95, 0x9e, 0xf9, 0x05, 0xdb, 0xa6, 0x7f, 0x40, 0x3d, 0x61, 0xa7, 0x77, 0x61, 0x55,
+	0x32, 0x34, 0x7d, 0xeb, 0x4b, 0x2a, 0xe4, 0xa6, 0x91, 0xbe, 0x24, 0xe8, 0x0f, 0xad, 0x2f, 0x29,
+	0xca, 0xd7, 0xff, 0xac, 0x41, 0x96, 0x99, 0xb1, 0xe1, 0x74, 0xdd, 0x69, 0x36, 0x7e, 0x0f, 0x16,
+	0xdb, 0x1e, 0x35, 0x03, 0xca, 0xb7, 0x92, 0xaf, 0x56, 0xd6, 0xf9, 0xc1, 0xaf, 0xcb, 0x83, 0x5f,
+	0x3f, 0x92, 0x9e, 0x61, 0x48, 0x52, 0x72, 0x0d, 0x60, 0x48, 0x8f, 0x9c, 0x2f, 0x15, 0x20, 0x37,
+	0x60, 0xfe, 0x73, 0x66, 0x86, 0x72, 0x06, 0x45, 0x02, 0x2e, 0x8a, 0x86, 0x31, 0xf8, 0x84, 0x7e,
+	0x1f, 0x72, 0x52, 0x43, 0x9f, 0xac, 0x41, 0x8e, 0xe9, 0xd2, 0xb4, 0x9c, 0x2e, 0xd3, 0x33, 0x7d,
+	0x33, 0x5f, 0x5d, 0x0e, 0xf5, 0x64, 0x24, 0x46, 0xd6, 0x13, 0x2d, 0xfd, 0xeb, 0x0c, 0x00, 0xb7,
+	0x0a, 0xee, 0x6e, 0xa6, 0xc3, 0xba, 0x04, 0x0b, 0x2d, 0xcf, 0x74, 0xda, 0xd2, 0x19, 0x44, 0x8f,
+	0xdc, 0x86, 0x3c, 0xa7, 0x68, 0x06, 0x67, 0x7d, 0x8a, 0xdb, 0x28, 0x54, 0x8b, 0x8a, 0x84, 0xa3,
+	0xb3, 0x3e, 0x35, 0xa0, 0x1d, 0xb6, 0xc9, 0x6d, 0x58, 0xee, 0x9b, 0x1e, 0x75, 0x02, 0x79, 0x64,
+	0x99, 0xe1, 0x55, 0x97, 0x38, 0x05, 0xef, 0x31, 0xfb, 0xfa, 0x81, 0xe9, 0x31, 0xfb, 0xce, 0x4f,
+	0xb7, 0xaf, 0x20, 0x25, 0xf7, 0x20, 0xdb, 0xb5, 0x1c, 0xcb, 0x3f, 0xa1, 0x9d, 0xf2, 0xc2, 0x54,
+	0xb6, 0x90, 0x36, 0x71, 0x2e, 0x8b, 0xc9, 0x73, 0xb9, 0x0a, 0xb9, 0xb6, 0xe9, 0xb4, 0xa9, 0x6d,
+	0xd3, 0x4e, 0x39, 0x7b, 0x43, 0xbb, 0x99, 0x35, 0xa2, 0x01, 0xb2, 0x0e, 0x4b, 0x3d, 0xea, 0x1d,
+	0xd3, 0x26, 0xdf, 0x40, 0x39, 0x37, 0xbc, 0xb7, 0x3c, 0x12, 0x1c, 0xe0, 0x3c, 0xf9, 0x1e, 0x40,
+	0xdf, 0x73, 0x9f, 0x51, 0x87, 0x49, 0x28, 0xc3, 0x8d, 0x74, 0x92, 0x5a, 0x99, 0x26, 0x65, 0x58,
+	0xec, 0x51, 0xdf, 0x37, 0x8f, 0x69, 0x39, 0x8f, 0x87, 0x20, 0xbb, 0xe4, 0x0e, 0x2c, 0xd8, 0x66,
+	0x8b, 0xda, 0x7e, 0x79, 0x09, 0x45, 0x5c, 0x51, 0x44, 0xb0, 0x33, 0x5e, 0xdf, 0xc1, 0xd9, 0xba,
+	0x13, 0x78, 0x67, 0x86, 0x20, 0x65, 0x1b, 0xc5, 0x68, 0x68, 0xbb, 0x03, 0x27, 0x28, 0x2f, 0xf3,
+	0x8d, 0xb2, 0x91, 0x4d, 0x36, 0x50, 0x79, 0x00, 0x79, 0x85, 0x8b, 0x94, 0x20, 0x7d, 0x4a, 0xcf,
+	0x44, 0xb4, 0xb3, 0x26, 0x8b, 0xdf, 0x67, 0xa6, 0x3d, 0xa0, 0xc2, 0x23, 0x78, 0xe7, 0x83, 0xd4,
+	0xfb, 0x9a, 0xfe, 0x08, 0xf2, 0xd1, 0xda, 0xbe, 0xe2, 0x23, 0x8a, 0x77, 0x16, 0x13, 0x2a, 0x4a,
+	0x1f, 0x41, 0x0f, 0xad, 0x01, 0x3c, 0x46, 0xff, 0x62, 0xbd, 0x51, 0x09, 0x90, 0x5c, 0x87, 0xcc,
+	0x09, 0x35, 0x65, 0xc0, 0xc5, 0x4c, 0x86, 0x13, 0x4c, 0x87, 0x48, 0x04, 0xea, 0xc0, 0x3d, 0x76,
+	0x58, 0x87, 0x88, 0xcc, 0x80, 0x56, 0xd8, 0xd6, 0xbf, 0x4a, 0x41, 0x96, 0xe5, 0x04, 0x99, 0x01,
+	0x98, 0x65, 0x62, 0x19, 0x80, 0x4d, 0x1a, 0x38, 0xcc, 0xa2, 0x0f, 0x4d, 0x89, 0x31, 0x90, 0xc2,
+	0x18, 0x58, 0x0e, 0x69, 0x30, 0x02, 0xb2, 0x5d, 0xd1, 0x9a, 0x16, 0xf7, 0xf7, 0x20, 0xdb, 0x73,
+	0x3b, 0x56, 0xd7, 0xa2, 0x9d, 0x72, 0x66, 0xba, 0xdb, 0x4a, 0x5a, 0xf2, 0x1e, 0x14, 0x85, 0x91,
+	0x43, 0xf6, 0xf9, 0x61, 0xdb, 0x14, 0x38, 0xcd, 0xae, 0xe4, 0x7a, 0x1b, 0xb2, 0xed, 0x13, 0xcb,
+	0xee, 0x78, 0xd4, 0x29, 0x2f, 0xdc, 0x48, 0xc7, 0xf7, 0x16, 0x4e, 0xb1, 0xe8, 0xef, 0x58, 0xc7,
+	0xd4, 0x0f, 0x30, 0x1e, 0x72, 0x86, 0xe8, 0xb1, 0x71, 0xff, 0xc4, 0xac, 0xde, 0xbd, 0x87, 0x91,
+	0x90, 0x33, 0x44, 0x8f, 0xa5, 0x26, 0x69, 0x3a, 0x3f, 0x34, 0xce, 0x50, 0x6a, 0x92, 0x24, 0xdc,
+	0x38, 0x68, 0xf4, 0xfb, 0x90, 0x63, 0x66, 0x30, 0x4c, 0xe7, 0x98, 0x32, 0x07, 0xb3, 0xdd, 0xe7,
+	0xd4, 0x13, 0xc9, 0x9f, 0x77, 0xd8, 0xe8, 0x80, 0x5d, 0xa2, 0xf2, 0xda, 0xc0, 0x8e, 0x6e, 0x40,
+	0x16, 0xaf, 0x25, 0x83, 0x76, 0x59, 0xea, 0x6c, 0xb1, 0x76, 0x59, 0x53, 0x52, 0x27, 0x9f, 0xe5,
+	0x13, 0xe4, 0x2d, 0x98, 0xf7, 0xd8, 0x12, 0xc2, 0x7d, 0x0a, 0x9c, 0x42, 0x2e, 0x6c, 0xf0, 0x49,
+	0x54, 0x46, 0xc8, 0xc4, 0x5d, 0x20, 0x6f, 0xd3, 0xa3, 0xdd, 0xd8, 0x2e, 0x24, 0x89, 0x91, 0x6d,
+	0x89, 0x96, 0xfe, 0xef, 0x14, 0x2c, 0xd4, 0xfa, 0x7d, 0xea, 0x74, 0xc8, 0x2d, 0x80, 0x90, 0xcd,
+	0x1f, 0xcd, 0x97, 0x6b, 0x85, 0x8b, 0xdc, 0x55, 0x8e, 0x23, 0x85, 0xb4, 0xaf, 0x21, 0x2d, 0x17,
+	0xb6, 0xbe, 0x29, 0xe6, 0x78, 0x1c, 0x47, 0xc7, 0xf3, 0x0e, 0x64, 0x6d, 0xd3, 0x0f, 0x50, 0xb5,
+	0xf4, 0xf0, 0xa1, 0x2f, 0xb2, 0x49, 0x66, 0x18, 0x76, 0x8c, 0xd4, 0xa6, 0x01, 0x45, 0xcf, 0xca,
+	0x1a, 0xa2, 0x47, 0xaa, 0xb0, 0x78, 0x62, 0x3a, 0x1d, 0x9b, 0xfa, 0xe5, 0x79, 0x5c, 0xb5, 0xac,
+	0xae, 0xfa, 0x94, 0x4f, 0xf1, 0x45, 0x25, 0x61, 0xe5, 0x21, 0x2c, 0xc7, 0xd4, 0x99, 0x96, 0x20,
+	0xb2, 0x4a, 0x82, 0xa8, 0x7c, 0x0c, 0x4b, 0xaa, 0xd4, 0x11, 0xbc, 0x6f, 0xa9, 0xbc, 0xe1, 0x09,
+	0x49, 0x43, 0xa9, 0xc9, 0xe6, 0x9f, 0x9a, 0x38, 0x26, 0x0c, 0xd4, 0xe9, 0x67, 0xff, 0xad, 0xdc,
+	0xd6, 0x6b, 0xb0, 0xe2, 0x07, 0xae, 0x47, 0x3b, 0x6a, 0x6d, 0x91, 0x41, 0xaa, 0x22, 0x9f, 0x08,
+	0x4b, 0x0b, 0x52, 0xc5, 0x74, 0xd8, 0xf7, 0xa8, 0xef, 0x5b, 0xae, 0x83, 0x51, 0x5a, 0xa8, 0x96,
+	0xe4, 0x81, 0xc9, 0x71, 0x43, 0x25, 0xd2, 0x1f, 0x02, 0x84, 0x7b, 0xf4, 0xc9, 0xbb, 0xd2, 0xa9,
+	0x94, 0x90, 0x52, 0x2c, 0x84, 0x31, 0x95, 0x6b, 0xc9, 0xa6, 0xfe, 0x1f, 0x0d, 0x60, 0xf3, 0x84,
+	0xb6, 0x4f, 0xfb, 0xae, 0xe5, 0x04, 0xf1, 0x64, 0xa5, 0x4d, 0x4e, 0x56, 0x71, 0xf7, 0x4d, 0x4d,
+	0x71, 0xdf, 0x07, 0x8a, 0xfb, 0xa6, 0x91, 0xf6, 0x1a, 0xdf, 0x56, 0xb8, 0xf8, 0x38, 0x17, 0xae,
+	0x3c, 0x9d, 0xee, 0x4e, 0x6f, 0xc4, 0x5d, 0x22, 0xe6, 0xe2, 0x8a, 0x3f, 0xfc, 0x6b, 0x01, 0xb2,
+	0xac, 0x08, 0x95, 0x79, 0xbb, 0x63, 0x75, 0xbb, 0xb1, 0xbc, 0xcd, 0x26, 0x0d, 0x1c, 0x1e, 0xae,
+	0x45, 0x52, 0xd3, 0x6a, 0x91, 0xa8, 0x0e, 0x4a, 0xc7, 0xea, 0x20, 0xa5, 0x46, 0xc9, 0x7c, 0xb3,
+	0x1a, 0x65, 0xfe, 0x1c, 0x35, 0xca, 0x7b, 0xb0, 0x68, 0x62, 0x70, 0xfa, 0x22, 0x6b, 0x57, 0xc2,
+	0x9d, 0xe1, 0x75, 0xcf, 0x23, 0x57, 0x86, 0xac, 0x20, 0x7d, 0xb1, 0xca, 0xe6, 0x23, 0xc8, 0xb7,
+	0xc3, 0x63, 0xf4, 0xcb, 0x39, 0x5c, 0xf6, 0xf5, 0xf8, 0xb2, 0xd1, 0x39, 0x8b, 0xa5, 0x55, 0x96,
+	0xa1, 0xda, 0x08, 0xa6, 0xd4, 0x46, 0xb7, 0x20, 0xcb, 0x8d, 0x4b, 0x7d, 0xac, 0x77, 0xf2, 0xd5,
+	0x52, 0xe2, 0xbe, 0xf6, 0x8d, 0x90, 0x22, 0x51, 0x49, 0x2d, 0xcd, 0x5c, 0x49, 0x2d, 0xc7, 0x2b,
+	0xa9, 0xef, 0x87, 0x95, 0x54, 0x41, 0xc9, 0xbf, 0xe1, 0x0e, 0x47, 0xd5, 0x51, 0x61, 0xa5, 0x5e,
+	0x1c, 0x53, 0xa9, 0x57, 0xb6, 0x61, 0x49, 0x3d, 0x91, 0x59, 0x7d, 0x9b, 0xf3, 0xa8, 0x79, 0x73,
+	0x1f, 0x4a, 0x49, 0x1b, 0x8f, 0x10, 0xf6, 0x76, 0x5c, 0x58, 0x31, 0x11, 0x83, 0xaa, 0xc0, 0x17,
+	0x28, 0xf2, 0xfe, 0xa0, 0xc1, 0xfc, 0x21, 0x7b, 0xb2, 0x91, 0xeb, 0x90, 0xc7, 0x84, 0xe2, 0x0c,
+	0x7a, 0xad, 0xf0, 0xb6, 0xc6, 0xda, 0x72, 0x0f, 0x47, 0xc8, 0x1b, 0xb0, 0x84, 0x04, 0x3d, 0xb7,
+	0x33, 0xb0, 0x07, 0xbe, 0xb8, 0xb9, 0x91, 0x69, 0x97, 0x0f, 0x31, 0x12, 0x9e, 0x68, 0x84, 0x10,
+	0x9e, 0x61, 0xf3, 0x38, 0x26, 0xa4, 0xbc, 0x09, 0xcb, 0x9c, 0x44, 0x8a, 0xe1, 0xf9, 0x95, 0xf3,
+	0x09, 0x39, 0xfa, 0x6f, 0x35, 0x58, 0xd9, 0xc4, 0x9c, 0x8d, 0x0f, 0x34, 0xfa, 0xf9, 0x80, 0xfa,
+	0xc1, 0xb7, 0xf3, 0x80, 0x0b, 0xcf, 0x3d, 0x3d, 0xee, 0x85, 0x76, 0x07, 0x48, 0xc3, 0xf1, 0xfb,
+	0xb4, 0x1d, 0xcc, 0xae, 0x8c, 0xbe, 0x02, 0xc5, 0x1d, 0xcb, 0x57, 0x39, 0xf4, 0x2a, 0xac, 0x6c,
+	0xe1, 0x4d, 0x7d, 0x0e, 0x31, 0x06, 0x14, 0x0f, 0x69, 0xc0, 0xd5, 0x99, 0xcd, 0x0a, 0xe1, 0x7e,
+	0x52, 0xe3, 0xf6, 0xf3, 0x09, 0xe4, 0x77, 0x31, 0x40, 0x5d, 0xdb, 0x6a, 0x9f, 0x85, 0xf0, 0x80,
+	0x16, 0xc1, 0x03, 0x64, 0x1d, 0xb2, 0x7e, 0xe0, 0x99, 0x01, 0x3d, 0x3e, 0x13, 0x85, 0x30, 0x41,
+	0x39, 0xc8, 0x77, 0x28, 0x66, 0x8c, 0x90, 0x46, 0xff, 0x5b, 0x1a, 0xc8, 0x21, 0xcb, 0x86, 0x22,
+	0x4a, 0x67, 0x53, 0x35, 0x81, 0x6a, 0x30, 0xa8, 0x40, 0xe4, 0x71, 0xab, 0x23, 0x12, 0x73, 0x96,
+	0x0f, 0x34, 0x3a, 0x4a, 0xca, 0xce, 0x8c, 0x4b, 0xd9, 0xe7, 0x78, 0x56, 0xbe, 0x03, 0x45, 0x35,
+	0x8b, 0x35, 0x2d, 0xfe, 0xba, 0xcc, 0x19, 0xcb, 0x4a, 0xee, 0x6a, 0x74, 0xc8, 0x7d, 0x28, 0x08,
+	0x3a, 0x66, 0x2c, 0x0b, 0x13, 0x6e, 0x3a, 0xcc, 0x61, 0x8a, 0x19, 0x25, 0xa3, 0x20, 0x4b, 0x24,
+	0xb2, 0xec, 0xcc, 0x89, 0x2c, 0x17, 0x4f, 0x64, 0x0f, 0xc3, 0x44, 0xc6, 0x5f, 0x95, 0x6f, 0xa2,
+	0x88, 0x61, 0x53, 0x8f, 0x4a, 0x69, 0x2f, 0x92, 0x16, 0xfe, 0x94, 0x82, 0xd5, 0x27, 0x78, 0x4f,
+	0xc5, 0x4f, 0x74, 0x56, 0x94, 0x81, 0xdf, 0x38, 0xa2, 0x64, 0x14, 0xbd, 0xd8, 0x3d, 0x99, 0x3e,
+	0xc7, 0x3d, 0xa9, 0x98, 0x27, 0x13, 0x37, 0xcf, 0x87, 0xa1, 0x79, 0x78, 0xc5, 0xfb, 0x96, 0xa8,
+	0x80, 0x86, 0x14, 0x7f, 0xd9, 0xf6, 0x79, 0x08, 0x17, 0x44, 0x4e, 0x38, 0xbf, 0x7d, 0xf4, 0xaf,
+	0x53, 0xb0, 0xc2, 0x92, 0xc3, 0xb8, 0x60, 0x49, 0x8f, 0x0a, 0x96, 0x04, 0x44, 0x93, 0x9a, 0x0e,
+	0xd1, 0xdc, 0x82, 0x7c, 0xd7, 0x73, 0x7b, 0xb2, 0x28, 0x4a, 0x8f, 0xf0, 0x41, 0x36, 0xcf, 0xdb,
+	0x6c, 0xf7, 0xa6, 0x6d, 0x8b, 0x27, 0x05, 0x6b, 0xb2, 0xdd, 0xf3, 0x22, 0x7c, 0x1e, 0xc7, 0x78,
+	0x27, 0xe1, 0xd8, 0x0b, 0x93, 0x1d, 0xfb, 0x83, 0xf0, 0x7c, 0x78, 0xd8, 0xe8, 0x48, 0x38, 0xb4,
+	0xf7, 0x97, 0x7d, 0x3a, 0x55, 0x6e, 0x5f, 0x5e, 0x62, 0xcc, 0x9c, 0x69, 0x57, 0xf9, 0x8d, 0x13,
+	0xe7, 0x7a, 0x11, 0x58, 0x4d, 0xdf, 0x87, 0xd2, 0x21, 0x0d, 0x5e, 0xa2, 0xc0, 0x1d, 0x58, 0xe5,
+	0x57, 0xc8, 0x79, 0xb6, 0x36, 0x56, 0xda, 0x29, 0xac, 0x1a, 0x94, 0xe1, 0x30, 0x2f, 0x43, 0x1a,
+	0xab, 0x4b, 0x1d, 0xfa, 0xbc, 0x19, 0xab, 0xab, 0x73, 0x0e, 0x7d, 0xce, 0x85, 0xeb, 0x07, 0x52,
+	0xf5, 0x6f, 0x90, 0x50, 0x2e, 0xc0, 0x7c, 0xd7, 0xf5, 0xda, 0xe1, 0x13, 0x14, 0x3b, 0xfa, 0xdf,
+	0x35, 0x28, 0x6c, 0xd3, 0x00, 0x41, 0x8e, 0x48, 0xf5, 0x49, 0x00, 0xcf, 0x1b, 0xb0, 0xe4, 0x76,
+	0xbb, 0x3e, 0x0d, 0x44, 0xf1, 0xcc, 0xc4, 0xa5, 0x8d, 0x3c, 0x1f, 0xe3, 0xe5, 0xf3, 0xf0, 0x0b,
+	0x31, 0x9d, 0xc0, 0x73, 0x39, 0xda, 0xad, 0xe2, 0xb9, 0x58, 0x3f, 0x09, 0xe4, 0x3b, 0x19, 0x75,
+	0x23, 0xd0, 0x1b, 0x35, 0xea, 0x2e, 0xc1, 0xc2, 0xc0, 0xf1, 0xcd, 0x2e, 0xc5, 0xeb, 0x27, 0x6b,
+	0x88, 0x9e, 0xfe, 0x95, 0x06, 0x85, 0x83, 0xc1, 0x79, 0xf6, 0x76, 0x1e, 0xf0, 0x2a, 0x8c, 0x1c,
+	0xb6, 0xbf, 0x25, 0x11, 0x39, 0x4c, 0x17, 0x0e, 0x0b, 0xc8, 0x1b, 0x96, 0xf7, 0xd8, 0x8b, 0xc2,
+	0x7d, 0x46, 0xbd, 0xe7, 0x9e, 0x15, 0x50, 0x91, 0x0b, 0xa2, 0x01, 0x16, 0x97, 0x03, 0xcf, 0x16,
+	0xb7, 0x27, 0x6b, 0xea, 0x7f, 0xd1, 0xc2, 0x82, 0xe9, 0x1c, 0xfa, 0xdf, 0x50, 0xbf, 0x23, 0xcc,
+	0x62, 0xd9, 0xf4, 0xac, 0x96, 0xcd, 0xa8, 0x96, 0x55, 0xc0, 0x2e, 0xbe, 0x15, 0xd1, 0xd3, 0x7f,
+	0x91, 0xe2, 0x15, 0xdb, 0xff, 0x50, 0xe5, 0x32, 0x2c, 0x7a, 0xb4, 0x3d, 0xf0, 0x7c, 0xa9, 0xb3,
+	0xec, 0x2a, 0x9b, 0x99, 0x8f, 0x6d, 0xe6, 0x1a, 0x40, 0xdf, 0x3c, 0xa6, 0xcd, 0xc0, 0x3d, 0xa5,
+	0x8e, 0x38, 0x83, 0x1c, 0x1b, 0x39, 0x62, 0x03, 0x08, 0xbd, 0x59, 0x6c, 0xe1, 0x45, 0x01, 0xbd,
+	0x59, 0x62, 0x19, 0xdb, 0x0a, 0xa8, 0x67, 0xda, 0xe2, 0x7d, 0x28, 0xbb, 0xfa, 0x1f, 0x35, 0x28,
+	0x6e, 0xdb, 0x6e, 0xeb, 0xff, 0xef, 0xd8, 0xa2, 0xe2, 0x79, 0x76, 0xdd, 0x74, 0x0b, 0x8a, 0x9b,
+	0x6e, 0xff, 0x4c, 0xe5, 0xb8, 0x02, 0x69, 0xdf, 0x6b, 0x0f, 0x33, 0xb0, 0x51, 0x36, 0xd9, 0xf1,
+	0x25, 0x7a, 0xa0, 0x4e, 0x76, 0xfc, 0x20, 0x1e, 0x05, 0xe9, 0x44, 0x14, 0xe8, 0xbf, 0xd4, 0xe0,
+	0xb2, 0x88, 0xd7, 0x08, 0xde, 0x9a, 0x39, 0x70, 0x23, 0x48, 0x32, 0x35, 0x11, 0x92, 0x9c, 0xa2,
+	0xc4, 0xaf, 0x35, 0x00, 0x26, 0x78, 0xf3, 0x04, 0x81, 0xd7, 0x29, 0xeb, 0xb2, 0x82, 0x02, 0x09,
+	0x47, 0x14, 0x14, 0x38, 0x2e, 0x0a, 0x8a, 0xb0, 0x4d, 0x6e, 0x42, 0x09, 0x73, 0x63, 0x87, 0xda,
+	0x81, 0x19, 0xcb, 0x90, 0x05, 0x36, 0xbe, 0xc5, 0x86, 0xf9, 0x77, 0xb7, 0x47, 0x90, 0x8f, 0x14,
+	0x41, 0xd8, 0x9e, 0x7f, 0xa3, 0xc0, 0x7e, 0x0c, 0xb6, 0x8f, 0xc8, 0xf8, 0x5b, 0x93, 0xb7, 0xf5,
+	0x53, 0x58, 0x61, 0xaf, 0xf5, 0xf8, 0x5d, 0x91, 0xf0, 0x24, 0x6d, 0xb2, 0x27, 0xdd, 0x84, 0x5c,
+	0xe0, 0x4e, 0x40, 0x84, 0xb2, 0x81, 0xcb, 0x5b, 0xfa, 0x00, 0x8a, 0xdb, 0x34, 0x10, 0xe6, 0xe6,
+	0x4b, 0x4d, 0x07, 0x20, 0x47, 0xdd, 0x25, 0x99, 0x69, 0x77, 0x89, 0x8a, 0xd4, 0xe8, 0xf7, 0x80,
+	0x88, 0xcb, 0xfc, 0x5c, 0x2b, 0xeb, 0xf7, 0x61, 0x55, 0xa4, 0xd7, 0x73, 0x32, 0x12, 0x28, 0x61,
+	0x59, 0xa4, 0x70, 0xe9, 0x1d, 0xb8, 0xb8, 0x6d, 0x7a, 0x2d, 0xf3, 0x98, 0x6e, 0xba, 0xb6, 0x8d,
+	0x6f, 0x5c, 0x2e, 0xae, 0x0a, 0x0b, 0x2d, 0xda, 0x75, 0x3d, 0xe9, 0x3f, 0x93, 0x4a, 0x75, 0x41,
+	0x49, 0x2e, 0xc3, 0x62, 0xc7, 0x3b, 0x6b, 0x7a, 0x03, 0x47, 0x56, 0xfe, 0x1d, 0xef, 0xcc, 0x18,
+	0x38, 0xfa, 0xef, 0x34, 0xb8, 0x94, 0x5c, 0xc6, 0xef, 0xbb, 0x8e, 0xcf, 0x3e, 0x01, 0xe5, 0x6d,
+	0xeb, 0x19, 0x6d, 0xa2, 0x8a, 0xf2, 0x0b, 0x31, 0xb0, 0x21, 0xd4, 0xd3, 0x27, 0xdf, 0x85, 0x52,
+	0x9b, 0xf3, 0xd0, 0x8e, 0xa4, 0xe2, 0xc6, 0x2e, 0x86, 0xe3, 0x82, 0xf4, 0x3b, 0x50, 0x54, 0x48,
+	0x15, 0xab, 0x17, 0x22, 0x4a, 0x34, 0x7d, 0xf4, 0xa4, 0x47, 0x18, 0x31, 0x0a, 0xd4, 0x09, 0x30,
+	0xa3, 0xfe, 0x33, 0x7e, 0x41, 0xa8, 0x1c, 0xe1, 0xc7, 0x6f, 0x4d, 0xf9, 0xf8, 0x4d, 0x6a, 0x50,
+	0x90, 0x5f, 0x6f, 0x9a, 0x66, 0x37, 0x10, 0x1f, 0x39, 0x26, 0x9b, 0x70, 0x59, 0x72, 0xd4, 0x18,
+	0x43, 0x94, 0xee, 0xce, 0xa1, 0xdf, 0x6f, 0x34, 0x58, 0xc6, 0xcc, 0x7b, 0xe8, 0x98, 0x7d, 0xff,
+	0xc4, 0x1d, 0xa7, 0xde, 0x2d, 0x00, 0x46, 0x8f, 0xb0, 0x73, 0x1c, 0x0d, 0x96, 0x00, 0x99, 0x91,
+	0xeb, 0x88, 0x96, 0xaf, 0xa2, 0x2a, 0xe9, 0x99, 0x51, 0x15, 0x7d, 0x03, 0x2e, 0x6f, 0xd3, 0x20,
+	0xa6, 0xcd, 0x44, 0x9b, 0xe9, 0x55, 0xa8, 0xf0, 0x0d, 0xcf, 0xce, 0xb3, 0xb6, 0x2f, 0x3f, 0x80,
+	0x8b, 0xa2, 0xa6, 0xb4, 0xb9, 0xbf, 0xbb, 0xdb, 0x38, 0x6a, 0x1e, 0x7d, 0x76, 0x50, 0x6f, 0xee,
+	0xed, 0xef, 0xd5, 0x4b, 0x73, 0xc9, 0x51, 0xa3, 0x5e, 0xdb, 0x2a, 0x69, 0xe4, 0x22, 0xac, 0xa8,
+	0xa3, 0x3f, 0x31, 0x1a, 0x47, 0xf5, 0x52, 0x6a, 0xed, 0x29, 0xff, 0x56, 0x88, 0xe2, 0x08, 0x14,
+	0x9e, 0x34, 0x76, 0xea, 0x31, 0x61, 0x17, 0x61, 0x25, 0x1a, 0x33, 0xea, 0xdb, 0x9f, 0xee, 0xd4,
+	0x8c, 0x92, 0x46, 0x56, 0x60, 0x39, 0x1a, 0xde, 0x6a, 0x18, 0xa5, 0xd4, 0xda, 0x03, 0xfc, 0x76,
+	0x2a, 0x81, 0x7f, 0xa1, 0xc5, 0x81, 0x51, 0x3f, 0x3c, 0x6c, 0xec, 0xef, 0xc5, 0x75, 0x0b, 0x47,
+	0xb7, 0x7f, 0xda, 0x38, 0x28, 0x69, 0x6b, 0x3f, 0x87, 0xe5, 0x18, 0xcc, 0x42, 0x2e, 0xc3, 0xea,
+	0x6e, 0xdd, 0xd8, 0xae, 0x37, 0x0f, 0x8f, 0x8c, 0xda, 0x51, 0x7d, 0xfb, 0xb3, 0xe6, 0x93, 0x5a,
+	0x63, 0xa7, 0x34, 0x37, 0x62, 0x62, 0xff, 0x53, 0xe3, 0xb0, 0xa4, 0x91, 0xd7, 0xe0, 0x62, 0x62,
+	0xe2, 0xe8, 0x69, 0xbd, 0x61, 0x1c, 0x96, 0x52, 0xe4, 0x75, 0xa8, 0x24, 0xa6, 0x36, 0xf7, 0xf7,
+	0x36, 0x6b, 0x47, 0xf5, 0xbd, 0xda, 0x51, 0xbd, 0x94, 0x5e, 0xb3, 0x01, 0x78, 0x0a, 0x0e, 0x6d,
+	0xfa, 0xb4, 0xb6, 0xb7, 0x3d, 0x64, 0x06, 0x75, 0xb4, 0xb6, 0xb5, 0x55, 0x67, 0x46, 0x2d, 0xc3,
+	0x05, 0x75, 0x78, 0x77, 0x7f, 0xab, 0xf1, 0xa4, 0x51, 0xdf, 0x2a, 0xa5, 0x98, 0xa2, 0xea, 0xcc,
+	0x56, 0x7d, 0xa7, 0x7e, 0x54, 0xdf, 0x2a, 0xa5, 0xab, 0x7f, 0x5d, 0x82, 0x74, 0xed, 0xa0, 0x41,
+	0x7e, 0x08, 0x10, 0xc1, 0x7d, 0xe4, 0x12, 0x4f, 0xd3, 0x49, 0xfc, 0xaf, 0x72, 0x69, 0xc8, 0xf3,
+	0xea, 0xec, 0x77, 0x3c, 0xfa, 0x1c, 0xb9, 0x0f, 0x79, 0x05, 0xa2, 0x23, 0x97, 0x51, 0xc0, 0x30,
+	0x68, 0x57, 0x89, 0xff, 0x98, 0x42, 0x9f, 0x23, 0x55, 0xc8, 0x4a, 0x98, 0x8e, 0x5c, 0x08, 0x1f,
+	0xa7, 0x2a, 0x4b, 0x21, 0xc6, 0xe2, 0xeb, 0x73, 0x4c, 0xd9, 0x08, 0xc7, 0x13, 0xca, 0x0e, 0x01,
+	0x7b, 0x13, 0x94, 0xfd, 0x00, 0xb2, 0x12, 0xd3, 0x13, 0x6b, 0x26, 0x20, 0xbe, 0x09, 0xbc, 0x77,
+	0x21, 0xaf, 0x80, 0x3f, 0x62, 0xa3, 0xc3, 0x70, 0x50, 0x45, 0xbd, 0xe9, 0xf4, 0x39, 0xf2, 0x18,
+	0x96, 0x54, 0x50, 0x84, 0x94, 0xc7, 0xe1, 0x24, 0x13, 0x96, 0xfe, 0x01, 0x2c, 0xc7, 0x20, 0x0f,
+	0xf2, 0x9a, 0x6a, 0xe5, 0xb8, 0x94, 0xe4, 0xcf, 0x02, 0xf4, 0x39, 0xf2, 0x3e, 0x40, 0xf4, 0xee,
+	0x17, 0x56, 0x1b, 0x02, 0x02, 0x2a, 0xa5, 0x04, 0xa3, 0xcf, 0x95, 0x57, 0x5f, 0x8e, 0x42, 0xf9,
+	0x11, 0x8f, 0xc9, 0x09, 0xca, 0x8b, 0xd5, 0xf9, 0x5b, 0x54, 0x59, 0x3d, 0xf6, 0xf2, 0x1d, 0xb9,
+	0xfa, 0x87, 0x50, 0x88, 0x08, 0xd9, 0xe0, 0x14, 0xee, 0x88, 0x50, 0xe8, 0xae, 0xa2, 0x0a, 0x42,
+	0xf7, 0x11, 0x40, 0xc3, 0x04, 0xdd, 0x3f, 0x84, 0x5c, 0x88, 0x22, 0x90, 0x8b, 0xd2, 0x61, 0x66,
+	0xe5, 0x0e, 0xad, 0x17, 0xd3, 0x60, 0x04, 0x8a, 0x30, 0x59, 0x86, 0x0a, 0x14, 0x08, 0x19, 0x23,
+	0xb0, 0x83, 0x89, 0x5e, 0xbf, 0x28, 0x0a, 0x64, 0xb2, 0x8a, 0xec, 0xf1, 0xe7, 0xed, 0x78, 0xce,
+	0x9b, 0x1a, 0x79, 0x04, 0x8b, 0xdb, 0x54, 0xe5, 0x8d, 0x3f, 0xfb, 0x2b, 0x57, 0x86, 0x78, 0xf1,
+	0x9a, 0xff, 0x31, 0x7b, 0xd6, 0xea, 0x73, 0xb7, 0x35, 0x25, 0x3f, 0xa0, 0x90, 0x58, 0x7e, 0x50,
+	0x05, 0xc5, 0x7f, 0xd1, 0x10, 0xe5, 0x07, 0xe4, 0x8a, 0xf2, 0x83, 0xca, 0x52, 0x88, 0xb1, 0xb0,
+	0x33, 0x7f, 0xc0, 0x3d, 0x86, 0x0d, 0x1d, 0x06, 0x1e, 0x35, 0x7b, 0x63, 0x38, 0x93, 0x8b, 0xdd,
+	0xd6, 0xc8, 0x1d, 0xc8, 0xca, 0xf7, 0x97, 0x60, 0x4a, 0x3c, 0xc7, 0x46, 0x31, 0x85, 0xf9, 0x08,
+	0xd9, 0xd4, 0x7c, 0x34, 0x93, 0x7d, 0x59, 0x3e, 0x92, 0xcf, 0x24, 0xb1, 0x68, 0xe2, 0xd5, 0x34,
+	0x39, 0xae, 0xa2, 0x3a, 0x5d, 0xae, 0x9d, 0x2c, 0xdc, 0x45, 0x64, 0x28, 0x2f, 0x02, 0x7d, 0x8e,
+	0xfc, 0x08, 0x0a, 0xf1, 0x8a, 0x90, 0xf0, 0xaf, 0x9f, 0x23, 0xab, 0xd1, 0xca, 0x95, 0x91, 0x73,
+	0xbc, 0x84, 0xd4, 0xe7, 0xaa, 0xff, 0x58, 0x62, 0x07, 0x1c, 0x50, 0xcf, 0x31, 0xed, 0x57, 0xf7,
+	0xc9, 0x39, 0xee, 0x93, 0x8f, 0x66, 0xbc, 0x4f, 0x26, 0xe6, 0x86, 0x57, 0x57, 0xcb, 0xab, 0xab,
+	0xe5, 0xd5, 0xd5, 0xf2, 0xea, 0x6a, 0x19, 0x15, 0xd8, 0x25, 0x61, 0xcf, 0xe8, 0x27, 0x78, 0x63,
+	0x2d, 0x94, 0xf8, 0x75, 0x98, 0x3e, 0x47, 0x3e, 0x86, 0x52, 0x12, 0x54, 0x23, 0x57, 0xd5, 0x13,
+	0x4e, 0x62, 0x6d, 0xdf, 0xc6, 0x4d, 0x55, 0xfd, 0xfd, 0xa2, 0xf8, 0x55, 0x22, 0xbb, 0x59, 0x1e,
+	0x42, 0xf6, 0x60, 0xc0, 0x21, 0x14, 0x32, 0xc9, 0x37, 0x86, 0x77, 0x73, 0x53, 0x23, 0x35, 0xc8,
+	0x4a, 0xa0, 0x49, 0x9e, 0x41, 0x1c, 0x77, 0x9a, 0xee, 0x6e, 0x1f, 0x41, 0x5e, 0x01, 0x8d, 0x84,
+	0x31, 0x87, 0x61, 0xa4, 0x89, 0xd1, 0xb2, 0xa4, 0xc2, 0x47, 0x22, 0xe2, 0x46, 0x20, 0x4a, 0x95,
+	0xc4, 0x8f, 0xd1, 0xf0, 0xf9, 0x91, 0x0b, 0x11, 0x24, 0x91, 0x2f, 0x92, 0x88, 0x92, 0x48, 0xd0,
+	0x21, 0x17, 0x4f, 0x74, 0xc5, 0x90, 0x4c, 0xf8, 0xed, 0x18, 0xe6, 0xa1, 0x25, 0x6f, 0x6b, 0x2f,
+	0xb5, 0x52, 0x20, 0x77, 0x65, 0x65, 0xc0, 0xce, 0x9e, 0xc4, 0x61, 0x8f, 0x99, 0x0a, 0x02, 0xe4,
+	0x8b, 0xf9, 0xb0, 0x02, 0xd1, 0x54, 0xe2, 0x02, 0xf5, 0x39, 0x16, 0x76, 0x12, 0x34, 0x52, 0x62,
+	0x75, 0x12, 0x8b, 0x1a, 0x76, 0xc8, 0xa6, 0x86, 0x9d, 0xca, 0x38, 0x5e, 0xdb, 0xba, 0xfc, 0x96,
+	0x19, 0x87, 0x83, 0x48, 0x04, 0xce, 0xcb, 0xb1, 0x89, 0xb9, 0xf1, 0x29, 0x46, 0x6f, 0x5c, 0xc6,
+	0x55, 0xe9, 0xb6, 0xa3, 0x70, 0x9a, 0xca, 0x88, 0x15, 0x70, 0x43, 0xe1, 0xc7, 0xbf, 0xb8, 0xb0,
+	0xeb, 0xca, 0xce, 0x46, 0xca, 0x1b, 0xab, 0x5d, 0x6b, 0x01, 0x47, 0xee, 0xfc, 0x77, 0x00, 0xb1,
+	0xd1, 0xfd, 0xfd, 0x14, 0x34, 0x00, 0x00,
 }
@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"strings"
 
 	pfsclient "github.com/pachyderm/pachyderm/src/client/pfs"
 	"github.com/pachyderm/pachyderm/src/client/pkg/discovery"
@@ -28,17 +29,27 @@ import (
 )
 
 type appEnv struct {
-	Port            uint16 `env:"PORT,default=650"`
-	NumShards       uint64 `env:"NUM_SHARDS,default=32"`
-	StorageRoot     string `env:"PACH_ROOT,required"`
-	StorageBackend  string `env:"STORAGE_BACKEND,default="`
-	DatabaseAddress string `env:"RETHINK_PORT_28015_TCP_ADDR,required"`
-	DatabaseName    string `env:"DATABASE_NAME,default=pachyderm"`
-	KubeAddress     string `env:"KUBERNETES_PORT_443_TCP_ADDR,required"`
-	EtcdAddress     string `env:"ETCD_PORT_2379_TCP_ADDR,required"`
-	Namespace       string `env:"NAMESPACE,default=default"`
-	Metrics         bool   `env:"METRICS,default=true"`
-	Init            bool   `env:"INIT,default=false"`
+	Port               uint16 `env:"PORT,default=650"`
+	NumShards          uint64 `env:"NUM_SHARDS,default=32"`
+	StorageRoot        string `env:"PACH_ROOT,required"`
+	StorageBackend     string `env:"STORAGE_BACKEND,default="`
+	ChunkMinSize       int    `env:"CHUNK_MIN_SIZE,default=2097152"`
+	ChunkAvgSize       int    `env:"CHUNK_AVG_SIZE,default=8388608"`
+	ChunkMaxSize       int    `env:"CHUNK_MAX_SIZE,default=33554432"`
+	BlockCompression   string `env:"BLOCK_COMPRESSION,default="`
+	BlockCacheSize     uint64 `env:"BLOCK_CACHE_SIZE,default=1073741824"`
+	URLLocalDirs       string `env:"PUT_FILE_URL_LOCAL_DIRS,default="`
+	URLObjectStorage   bool   `env:"PUT_FILE_URL_OBJECT_STORAGE,default=false"`
+	URLHTTPHosts       string `env:"PUT_FILE_URL_HTTP_HOSTS,default="`
+	SnapshotInterval   uint64 `env:"SNAPSHOT_INTERVAL,default=1000"`
+	CheckpointInterval uint64 `env:"CHECKPOINT_INTERVAL,default=100"`
+	DatabaseAddress    string `env:"RETHINK_PORT_28015_TCP_ADDR,required"`
+	DatabaseName       string `env:"DATABASE_NAME,default=pachyderm"`
+	KubeAddress        string `env:"KUBERNETES_PORT_443_TCP_ADDR,required"`
+	EtcdAddress        string `env:"ETCD_PORT_2379_TCP_ADDR,required"`
+	Namespace          string `env:"NAMESPACE,default=default"`
+	Metrics            bool   `env:"METRICS,default=true"`
+	Init               bool   `env:"INIT,default=false"`
 }
 
 func main() {
@@ -87,7 +98,10 @@ func do(appEnvObj interface{}) error {
 			protolion.Printf("Error from sharder.AssignRoles: %s", err.Error())
 		}
 	}()
-	driver, err := drive.NewDriver(address)
+	driverOptions := drive.DefaultOptions()
+	driverOptions.SnapshotInterval = appEnv.SnapshotInterval
+	driverOptions.CheckpointInterval = appEnv.CheckpointInterval
+	driver, err := drive.NewDriver(address, driverOptions)
 	if err != nil {
 		return err
 	}
@@ -122,6 +136,16 @@ func do(appEnvObj interface{}) error {
 			address,
 		),
 		driver,
+		// put-file can only read local paths under PUT_FILE_URL_LOCAL_DIRS,
+		// a comma separated list, s3:// and gs:// URLs, which are read with
+		// pachd's credentials, only if PUT_FILE_URL_OBJECT_STORAGE is set
+		// and http(s):// URLs only from the hosts in PUT_FILE_URL_HTTP_HOSTS,
+		// also comma separated
+		pfs_server.URLAccess{
+			LocalDirs:     strings.Split(appEnv.URLLocalDirs, ","),
+			ObjectStorage: appEnv.URLObjectStorage,
+			HTTPHosts:     strings.Split(appEnv.URLHTTPHosts, ","),
+		},
 	)
 	ppsAPIServer := pps_server.NewAPIServer(
 		ppsserver.NewHasher(appEnv.NumShards, appEnv.NumShards),
@@ -133,7 +157,19 @@ func do(appEnvObj interface{}) error {
 			protolion.Printf("Error from sharder.Register %s", err.Error())
 		}
 	}()
-	blockAPIServer, err := pfs_server.NewBlockAPIServer(appEnv.StorageRoot, appEnv.StorageBackend)
+	blockAPIServerOptions := pfs_server.DefaultBlockAPIServerOptions()
+	blockAPIServerOptions.ChunkSizes = pfs_server.ChunkSizes{
+		Min: appEnv.ChunkMinSize,
+		Avg: appEnv.ChunkAvgSize,
+		Max: appEnv.ChunkMaxSize,
+	}
+	if blockAPIServerOptions.Compression, err = pfs_server.ParseBlockCompression(appEnv.BlockCompression); err != nil {
+		return err
+	}
+	// BLOCK_CACHE_SIZE is ignored if object storage is encrypted since cached
+	// blocks are stored decrypted
+	blockAPIServerOptions.CacheSize = appEnv.BlockCacheSize
+	blockAPIServer, err := pfs_server.NewBlockAPIServer(appEnv.StorageRoot, appEnv.StorageBackend, blockAPIServerOptions)
 	if err != nil {
 		return err
 	}
@@ -9,12 +9,14 @@ import (
 
 	"github.com/pachyderm/pachyderm/src/client"
 	pfsclient "github.com/pachyderm/pachyderm/src/client/pfs"
+	pfsserver "github.com/pachyderm/pachyderm/src/server/pfs"
 	"github.com/pachyderm/pachyderm/src/server/pfs/fuse"
 	"github.com/pachyderm/pachyderm/src/server/pfs/pretty"
 	"github.com/pachyderm/pachyderm/src/server/pkg/cmd"
 
 	"github.com/spf13/cobra"
 	"go.pedge.io/pkg/cobra"
+	"golang.org/x/net/context"
 )
 
 func Cmds(address string) []*cobra.Command {
@@ -49,18 +51,45 @@ Repos are created with create-repo.`,
 		}),
 	}
 
+	var quota pfsclient.Quota
+	addQuotaFlags := func(command *cobra.Command) {
+		command.Flags().Uint64Var(&quota.MaxBytes, "max-bytes", 0, "the most bytes the repo can hold, 0 is unlimited")
+		command.Flags().Uint64Var(&quota.MaxFilesPerCommit, "max-files-per-commit", 0, "the most files a commit can write to, 0 is unlimited")
+		command.Flags().Uint64Var(&quota.MaxFileSizeBytes, "max-file-size", 0, "the largest a file can be in bytes, 0 is unlimited")
+	}
+
 	createRepo := &cobra.Command{
 		Use:   "create-repo repo-name",
 		Short: "Create a new repo.",
-		Long:  "Create a new repo.",
+		Long:  "Create a new repo, optionally with a quota limiting what can be written to it.",
+		Run: cmd.RunFixedArgs(1, func(args []string) error {
+			client, err := client.NewFromAddress(address)
+			if err != nil {
+				return err
+			}
+			if quota == (pfsclient.Quota{}) {
+				return client.CreateRepo(args[0])
+			}
+			return client.CreateRepoWithQuota(args[0], &quota)
+		}),
+	}
+	addQuotaFlags(createRepo)
+
+	setQuota := &cobra.Command{
+		Use:   "set-quota repo-name",
+		Short: "Set the quota of a repo.",
+		Long: `Set the quota of a repo, replacing its current quota. Limits which aren't
+given are removed. Data already in the repo isn't affected, but writes which
+would exceed the quota fail.`,
 		Run: cmd.RunFixedArgs(1, func(args []string) error {
 			client, err := client.NewFromAddress(address)
 			if err != nil {
 				return err
 			}
-			return client.CreateRepo(args[0])
+			return client.SetQuota(args[0], &quota)
 		}),
 	}
+	addQuotaFlags(setQuota)
 
 	inspectRepo := &cobra.Command{
 		Use:   "inspect-repo repo-name",
@@ -141,10 +170,27 @@ This layers the data in the commit over the data in the parent.`,
 	}
 
 	var parentCommitID string
+	var mergeCommitID string
+	var mergePolicies []string
+	var startCommitMessage string
+	var startCommitLabels []string
 	startCommit := &cobra.Command{
 		Use:   "start-commit repo-name [branch]",
 		Short: "Start a new commit.",
-		Long:  "Start a new commit with parent-commit-id as the parent.",
+		Long: `Start a new commit with parent-commit-id as the parent.
+
+With --merge the new commit merges a commit or branch into its parent. Files
+changed on both sides since their common ancestor are a conflict, which fails
+the merge unless a --merge-policy covers the file. A policy is path=strategy,
+where strategy is one of fail, ours, theirs or concatenate, and applies to
+everything under path; the policy with the longest path wins. Examples:
+
+	# merge the branch feature into master
+	$ pachctl start-commit repo master --merge feature
+
+	# keep master's files except for logs, which are concatenated
+	$ pachctl start-commit repo master --merge feature --merge-policy /=ours --merge-policy logs=concatenate
+`,
 		Run: cmd.RunBoundedArgs(1, 2, func(args []string) error {
 			client, err := client.NewFromAddress(address)
 			if err != nil {
@@ -154,8 +200,26 @@ This layers the data in the commit over the data in the parent.`,
 			if len(args) == 2 {
 				branch = args[1]
 			}
-			commit, err := client.StartCommit(args[0],
-				parentCommitID, branch)
+			policies, err := parseMergePolicies(mergePolicies)
+			if err != nil {
+				return err
+			}
+			labels, err := parseLabels(startCommitLabels)
+			if err != nil {
+				return err
+			}
+			commit, err := client.PfsAPIClient.StartCommit(
+				context.Background(),
+				&pfsclient.StartCommitRequest{
+					Repo:          &pfsclient.Repo{Name: args[0]},
+					ParentID:      parentCommitID,
+					Branch:        branch,
+					MergeParentID: mergeCommitID,
+					MergePolicies: policies,
+					Message:       startCommitMessage,
+					Labels:        labels,
+				},
+			)
 			if err != nil {
 				return err
 			}
@@ -164,24 +228,45 @@ This layers the data in the commit over the data in the parent.`,
 		}),
 	}
 	startCommit.Flags().StringVarP(&parentCommitID, "parent", "p", "", "parent id")
+	startCommit.Flags().StringVar(&mergeCommitID, "merge", "", "commit or branch to merge into the parent")
+	startCommit.Flags().StringSliceVar(&mergePolicies, "merge-policy", nil, "path=strategy, how conflicting changes under path are merged, strategy is one of fail, ours, theirs or concatenate")
+	startCommit.Flags().StringVarP(&startCommitMessage, "message", "m", "", "a description of the commit")
+	startCommit.Flags().StringSliceVarP(&startCommitLabels, "label", "l", nil, "key=value, a label to add to the commit")
 
 	var cancel bool
+	var finishCommitMessage string
+	var finishCommitLabels []string
 	finishCommit := &cobra.Command{
 		Use:   "finish-commit repo-name commit-id",
 		Short: "Finish a started commit.",
-		Long:  "Finish a started commit. Commit-id must be a writeable commit.",
+		Long: `Finish a started commit. Commit-id must be a writeable commit.
+
+--message replaces the message the commit was started with and --label adds
+labels to it, key= removes the label key.`,
 		Run: cmd.RunFixedArgs(2, func(args []string) error {
 			client, err := client.NewFromAddress(address)
 			if err != nil {
 				return err
 			}
-			if cancel {
-				return client.CancelCommit(args[0], args[1])
+			labels, err := parseLabels(finishCommitLabels)
+			if err != nil {
+				return err
 			}
-			return client.FinishCommit(args[0], args[1])
+			_, err = client.PfsAPIClient.FinishCommit(
+				context.Background(),
+				&pfsclient.FinishCommitRequest{
+					Commit:  &pfsclient.Commit{Repo: &pfsclient.Repo{Name: args[0]}, ID: args[1]},
+					Cancel:  cancel,
+					Message: finishCommitMessage,
+					Labels:  labels,
+				},
+			)
+			return err
 		}),
 	}
 	finishCommit.Flags().BoolVarP(&cancel, "cancel", "c", false, "cancel the commit")
+	finishCommit.Flags().StringVarP(&finishCommitMessage, "message", "m", "", "a description of the commit, replaces the one it was started with")
+	finishCommit.Flags().StringSliceVarP(&finishCommitLabels, "label", "l", nil, "key=value, a label to add to the commit")
 
 	inspectCommit := &cobra.Command{
 		Use:   "inspect-commit repo-name commit-id",
@@ -208,6 +293,8 @@ This layers the data in the commit over the data in the parent.`,
 
 	var all bool
 	var block bool
+	var provenance []string
+	var listCommitLabels []string
 	listCommit := &cobra.Command{
 		Use:   "list-commit repo-name",
 		Short: "Return all commits on a set of repos",
@@ -221,6 +308,12 @@ Examples:
 	# return commits in repo "foo" since commit abc123 and those in repo "bar" since commit def456
 	$ pachctl list-commit foo/abc123 bar/def456
 
+	# return commits in every repo derived from commit abc123 in repo "foo"
+	$ pachctl list-commit -p foo/abc123
+
+	# return commits in repo "foo" labelled with source=sensors
+	$ pachctl list-commit foo -l source=sensors
+
 `,
 		Run: pkgcobra.Run(func(args []string) error {
 			commits, err := cmd.ParseCommits(args)
@@ -228,11 +321,17 @@ Examples:
 				return err
 			}
 
-			var repos []string
-			var fromCommits []string
+			var repos []*pfsclient.Repo
 			for _, commit := range commits {
-				repos = append(repos, commit.Repo.Name)
-				fromCommits = append(fromCommits, commit.ID)
+				repos = append(repos, commit.Repo)
+			}
+			provenanceCommits, err := cmd.ParseCommits(provenance)
+			if err != nil {
+				return err
+			}
+			labels, err := parseLabels(listCommitLabels)
+			if err != nil {
+				return err
 			}
 
 			_client, err := client.NewFromAddress(address)
@@ -240,14 +339,24 @@ Examples:
 				return err
 			}
 
-			commitInfos, err := _client.ListCommit(repos, fromCommits, client.CommitTypeNone, block, all)
+			commitInfos, err := _client.PfsAPIClient.ListCommit(
+				context.Background(),
+				&pfsclient.ListCommitRequest{
+					Repo:       repos,
+					FromCommit: commits,
+					Provenance: provenanceCommits,
+					Labels:     labels,
+					Block:      block,
+					All:        all,
+				},
+			)
 			if err != nil {
 				return err
 			}
 
 			writer := tabwriter.NewWriter(os.Stdout, 20, 1, 3, ' ', 0)
 			pretty.PrintCommitInfoHeader(writer)
-			for _, commitInfo := range commitInfos {
+			for _, commitInfo := range commitInfos.CommitInfo {
 				pretty.PrintCommitInfo(writer, commitInfo)
 			}
 			return writer.Flush()
@@ -255,6 +364,30 @@ Examples:
 	}
 	listCommit.Flags().BoolVarP(&all, "all", "a", false, "list all commits including cancelled commits")
 	listCommit.Flags().BoolVarP(&block, "block", "b", false, "block until there are new commits since the `from` commits")
+	listCommit.Flags().StringSliceVarP(&provenance, "provenance", "p", []string{}, "list only commits derived from these commits, given as repo/commit-id")
+	listCommit.Flags().StringSliceVarP(&listCommitLabels, "label", "l", []string{}, "list only commits with this label, given as key=value")
+
+	var force bool
+	deleteCommit := &cobra.Command{
+		Use:   "delete-commit repo-name commit-id",
+		Short: "Delete a commit.",
+		Long: `Delete a commit. Open commits are cancelled before being deleted.
+
+Commits with children can only be deleted with --force, the children are
+reparented onto the deleted commit's parent so the changes made in the deleted
+commit will no longer be visible in them.`,
+		Run: cmd.RunFixedArgs(2, func(args []string) error {
+			client, err := client.NewFromAddress(address)
+			if err != nil {
+				return err
+			}
+			if force {
+				return client.DeleteCommitForce(args[0], args[1])
+			}
+			return client.DeleteCommit(args[0], args[1])
+		}),
+	}
+	deleteCommit.Flags().BoolVarP(&force, "force", "f", false, "delete the commit even if it has children")
 
 	listBranch := &cobra.Command{
 		Use:   "list-branch repo-name",
@@ -265,14 +398,92 @@ Examples:
 			if err != nil {
 				return err
 			}
-			commitInfos, err := client.ListBranch(args[0])
+			branchInfos, err := client.ListBranchInfo(args[0])
 			if err != nil {
 				return err
 			}
 			writer := tabwriter.NewWriter(os.Stdout, 20, 1, 3, ' ', 0)
-			pretty.PrintCommitInfoHeader(writer)
-			for _, commitInfo := range commitInfos {
-				pretty.PrintCommitInfo(writer, commitInfo)
+			pretty.PrintBranchInfoHeader(writer)
+			for _, branchInfo := range branchInfos {
+				pretty.PrintBranchInfo(writer, branchInfo)
+			}
+			return writer.Flush()
+		}),
+	}
+
+	createBranch := &cobra.Command{
+		Use:   "create-branch repo-name commit-id branch-name",
+		Short: "Create a new branch pointing at a commit.",
+		Long: `Create a new branch pointing at a commit. commit-id may itself be a branch,
+in which case the new branch points at its head.`,
+		Run: cmd.RunFixedArgs(3, func(args []string) error {
+			client, err := client.NewFromAddress(address)
+			if err != nil {
+				return err
+			}
+			return client.CreateBranch(args[0], args[1], args[2])
+		}),
+	}
+
+	setBranch := &cobra.Command{
+		Use:   "set-branch repo-name commit-id branch-name",
+		Short: "Point an existing branch at a different commit.",
+		Long:  "Point an existing branch at a different commit.",
+		Run: cmd.RunFixedArgs(3, func(args []string) error {
+			client, err := client.NewFromAddress(address)
+			if err != nil {
+				return err
+			}
+			return client.SetBranch(args[0], args[1], args[2])
+		}),
+	}
+
+	deleteBranch := &cobra.Command{
+		Use:   "delete-branch repo-name branch-name",
+		Short: "Delete a branch.",
+		Long:  "Delete a branch. The commits on the branch are not deleted.",
+		Run: cmd.RunFixedArgs(2, func(args []string) error {
+			client, err := client.NewFromAddress(address)
+			if err != nil {
+				return err
+			}
+			return client.DeleteBranch(args[0], args[1])
+		}),
+	}
+
+	renameBranch := &cobra.Command{
+		Use:   "rename-branch repo-name branch-name new-branch-name",
+		Short: "Rename a branch.",
+		Long:  "Rename a branch.",
+		Run: cmd.RunFixedArgs(3, func(args []string) error {
+			client, err := client.NewFromAddress(address)
+			if err != nil {
+				return err
+			}
+			return client.RenameBranch(args[0], args[1], args[2])
+		}),
+	}
+
+	diffCommit := &cobra.Command{
+		Use:   "diff-commit repo-name from-commit-id to-commit-id",
+		Short: "Return the files changed between two commits.",
+		Long: `Return the files which were added, modified and deleted after from-commit-id
+up to to-commit-id. from-commit-id must be an ancestor of to-commit-id.
+
+Only regular files are listed, directories are implied by the files in them.`,
+		Run: cmd.RunFixedArgs(3, func(args []string) error {
+			client, err := client.NewFromAddress(address)
+			if err != nil {
+				return err
+			}
+			fileChanges, err := client.DiffCommit(args[0], args[1], args[2])
+			if err != nil {
+				return err
+			}
+			writer := tabwriter.NewWriter(os.Stdout, 20, 1, 3, ' ', 0)
+			pretty.PrintFileChangeHeader(writer)
+			for _, fileChange := range fileChanges {
+				pretty.PrintFileChange(writer, fileChange)
 			}
 			return writer.Flush()
 		}),
@@ -290,38 +501,123 @@ Files can be read from finished commits with get-file.`,
 		}),
 	}
 
+	var overwrite bool
+	var url string
+	var recursive string
+	var manifest string
+	var parallelism int
 	putFile := &cobra.Command{
 		Use:   "put-file repo-name commit-id path/to/file",
-		Short: "Put a file from stdin",
-		Long:  "Put a file from stdin. commit-id must be a writeable commit.",
+		Short: "Put a file from stdin, a URL or local files",
+		Long: `Put a file from stdin, a URL or local files. commit-id must be a writeable commit.
+
+The data is appended to the file unless --overwrite is given, in which case it
+replaces the file's content.
+
+If --url is given the data is fetched by pachd rather than read from stdin, the
+URL may be http(s)://, s3:// or gs:// or an absolute path on the server. Local
+paths are only allowed under the directories in pachd's PUT_FILE_URL_LOCAL_DIRS,
+s3:// and gs:// URLs, which pachd reads with its own credentials, only if
+PUT_FILE_URL_OBJECT_STORAGE is set and http(s):// URLs only from the hosts in
+PUT_FILE_URL_HTTP_HOSTS. A host of * allows any host which doesn't resolve to a
+loopback or link-local address, those have to be listed by name.
+
+If --recursive is given every file under the local directory is uploaded to
+the same relative path under path/to/file. If --input-file is given each path
+listed in it, one per line, is uploaded to that path under path/to/file,
+directories are uploaded recursively. Files are uploaded concurrently and
+those which fail are listed once the upload finishes.`,
 		Run: cmd.RunFixedArgs(3, func(args []string) error {
 			client, err := client.NewFromAddress(address)
 			if err != nil {
 				return err
 			}
+			if recursive != "" || manifest != "" {
+				if url != "" {
+					return fmt.Errorf("--url can't be used with --recursive or --input-file")
+				}
+				var tasks []putFileTask
+				if recursive != "" {
+					recursiveTasks, err := walkPutFileTasks(recursive, args[2])
+					if err != nil {
+						return err
+					}
+					tasks = append(tasks, recursiveTasks...)
+				}
+				if manifest != "" {
+					manifestTasks, err := manifestPutFileTasks(manifest, args[2])
+					if err != nil {
+						return err
+					}
+					tasks = append(tasks, manifestTasks...)
+				}
+				failures := putFiles(tasks, parallelism, os.Stderr, func(task putFileTask) (int64, error) {
+					return putLocalFile(client, args[0], args[1], task, overwrite)
+				})
+				for _, failure := range failures {
+					fmt.Fprintf(os.Stderr, "%s: %v\n", failure.task.localPath, failure.err)
+				}
+				if len(failures) > 0 {
+					return fmt.Errorf("%d of %d files failed to upload", len(failures), len(tasks))
+				}
+				return nil
+			}
+			if url != "" {
+				return client.PutFileURL(args[0], args[1], args[2], url, overwrite)
+			}
+			if overwrite {
+				_, err = client.PutFileOverwrite(args[0], args[1], args[2], os.Stdin)
+				return err
+			}
 			_, err = client.PutFile(args[0], args[1], args[2], os.Stdin)
 			return err
 		}),
 	}
+	putFile.Flags().BoolVarP(&overwrite, "overwrite", "o", false, "overwrite the existing content of the file rather than appending to it")
+	putFile.Flags().StringVarP(&url, "url", "u", "", "fetch the file's content from this URL rather than stdin")
+	putFile.Flags().StringVarP(&recursive, "recursive", "r", "", "upload every file under this local directory")
+	putFile.Flags().StringVarP(&manifest, "input-file", "i", "", "upload the local paths listed in this file, one per line")
+	putFile.Flags().IntVarP(&parallelism, "parallelism", "p", 16, "how many files to upload at once with --recursive or --input-file")
 
 	var fromCommitID string
 	var unsafe bool
+	var verify bool
 	getFile := &cobra.Command{
 		Use:   "get-file repo-name commit-id path/to/file",
 		Short: "Return the contents of a file.",
-		Long:  "Return the contents of a file.",
+		Long: `Return the contents of a file.
+
+If path/to/file is a glob the files which match it are written as a tar
+stream, directories which match are written with everything under them. Within
+a path component * ? and [] match as they do in shells and ** matches any
+number of path components.`,
 		Run: cmd.RunFixedArgs(3, func(args []string) error {
 			client, err := client.NewFromAddress(address)
 			if err != nil {
 				return err
 			}
+			if pfsserver.IsGlob(args[2]) {
+				return getFileTar(client, args[0], args[1], args[2], fromCommitID, shard(), unsafe, os.Stdout)
+			}
+			if verify {
+				// the SHA-256 is of the whole file, as of a finished commit
+				if fromCommitID != "" || fileModulus > 1 || blockModulus > 1 || unsafe {
+					return fmt.Errorf("--verify can't be used with --from, --unsafe or the shard flags")
+				}
+				return client.GetFileVerified(args[0], args[1], args[2], os.Stdout)
+			}
+			if unsafe {
+				return client.GetFileUnsafe(args[0], args[1], args[2], 0, 0, fromCommitID, shard(), os.Stdout)
+			}
 			return client.GetFile(args[0], args[1], args[2], 0, 0, fromCommitID, shard(), os.Stdout)
 		}),
 	}
 	addShardFlags(getFile)
 	getFile.Flags().StringVarP(&fromCommitID, "from", "f", "", "from commit")
+	getFile.Flags().BoolVar(&verify, "verify", false, "check the file's content against its SHA-256, an error is returned if it doesn't match")
 	getFile.Flags().BoolVar(&unsafe, "unsafe", false, "use this flag if you need to read data written in the current commit; this operation will race with concurrent writes")
 
+	var sha256 bool
 	inspectFile := &cobra.Command{
 		Use:   "inspect-file repo-name commit-id path/to/file",
 		Short: "Return info about a file.",
@@ -331,7 +627,11 @@ Files can be read from finished commits with get-file.`,
 			if err != nil {
 				return err
 			}
-			fileInfo, err := client.InspectFile(args[0], args[1], args[2], "", shard())
+			inspectFile := client.InspectFile
+			if sha256 {
+				inspectFile = client.InspectFileSHA256
+			}
+			fileInfo, err := inspectFile(args[0], args[1], args[2], "", shard())
 			if err != nil {
 				return err
 			}
@@ -339,18 +639,27 @@ Files can be read from finished commits with get-file.`,
 				return fmt.Errorf("file %s not found", args[2])
 			}
 			writer := tabwriter.NewWriter(os.Stdout, 20, 1, 3, ' ', 0)
-			pretty.PrintFileInfoHeader(writer)
-			pretty.PrintFileInfo(writer, fileInfo)
+			pretty.PrintDetailedFileInfoHeader(writer)
+			pretty.PrintDetailedFileInfo(writer, fileInfo)
 			return writer.Flush()
 		}),
 	}
 	addShardFlags(inspectFile)
+	inspectFile.Flags().BoolVar(&sha256, "sha256", false, "compute the SHA-256 of the file's content, PFS reads the file the first time it's computed")
 	inspectFile.Flags().BoolVar(&unsafe, "unsafe", false, "use this flag if you need to inspect files written in the current commit; this operation will race with concurrent writes")
 
+	var pageToken string
+	var limit uint64
 	listFile := &cobra.Command{
 		Use:   "list-file repo-name commit-id path/to/dir",
 		Short: "Return the files in a directory.",
-		Long:  "Return the files in a directory.",
+		Long: `Return the files in a directory.
+
+If path/to/dir is a glob the files and directories which match it are returned
+instead, see get-file for the glob syntax.
+
+Files are listed in order of path. Use --limit to list a page of them, the next
+page is listed by passing the last path printed as --page-token.`,
 		Run: cmd.RunBoundedArgs(2, 3, func(args []string) error {
 			client, err := client.NewFromAddress(address)
 			if err != nil {
@@ -360,21 +669,39 @@ Files can be read from finished commits with get-file.`,
 			if len(args) == 3 {
 				path = args[2]
 			}
-			fileInfos, err := client.ListFile(args[0], args[1], path, "", shard(), true)
-			if err != nil {
-				return err
-			}
 			writer := tabwriter.NewWriter(os.Stdout, 20, 1, 3, ' ', 0)
 			pretty.PrintFileInfoHeader(writer)
-			for _, fileInfo := range fileInfos {
+			if err := client.ListFileStream(args[0], args[1], path, "", shard(), true, pageToken, limit, func(fileInfo *pfsclient.FileInfo) error {
 				pretty.PrintFileInfo(writer, fileInfo)
+				return nil
+			}); err != nil {
+				return err
 			}
 			return writer.Flush()
 		}),
 	}
 	addShardFlags(listFile)
+	listFile.Flags().StringVar(&pageToken, "page-token", "", "only list the files after this path")
+	listFile.Flags().Uint64Var(&limit, "limit", 0, "list at most this many files, 0 means no limit")
 	listFile.Flags().BoolVar(&unsafe, "unsafe", false, "use this flag if you need to list files written in the current commit; this operation will race with concurrent writes")
 
+	var copyOverwrite bool
+	copyFile := &cobra.Command{
+		Use:   "copy-file src-repo src-commit src-path dst-repo dst-commit dst-path",
+		Short: "Copy a file or directory between commits.",
+		Long: `Copy a file, or a directory and everything under it, between commits. The
+commits may be in different repos and dst-commit must be writeable. No data is
+copied, the destination references the source's blocks.`,
+		Run: cmd.RunFixedArgs(6, func(args []string) error {
+			client, err := client.NewFromAddress(address)
+			if err != nil {
+				return err
+			}
+			return client.CopyFile(args[0], args[1], args[2], args[3], args[4], args[5], copyOverwrite)
+		}),
+	}
+	copyFile.Flags().BoolVarP(&copyOverwrite, "overwrite", "o", false, "overwrite the existing content of the destination rather than appending to it")
+
 	deleteFile := &cobra.Command{
 		Use:   "delete-file repo-name commit-id path/to/file",
 		Short: "Delete a file.",
@@ -388,6 +715,37 @@ Files can be read from finished commits with get-file.`,
 		}),
 	}
 
+	var dryRun bool
+	garbageCollect := &cobra.Command{
+		Use:   "garbage-collect",
+		Short: "Delete blocks which aren't referenced by any commit.",
+		Long: `Delete blocks which aren't referenced by any commit.
+
+Garbage collection is safe to run while commits are being written, blocks
+written since the oldest open commit started are never deleted.
+
+Examples:
+
+	# report how much space would be reclaimed without deleting anything
+	$ pachctl garbage-collect --dry-run
+`,
+		Run: cmd.RunFixedArgs(0, func(args []string) error {
+			client, err := client.NewFromAddress(address)
+			if err != nil {
+				return err
+			}
+			response, err := client.GarbageCollect(dryRun)
+			if err != nil {
+				return err
+			}
+			writer := tabwriter.NewWriter(os.Stdout, 20, 1, 3, ' ', 0)
+			pretty.PrintGarbageCollectHeader(writer, dryRun)
+			pretty.PrintGarbageCollectResponse(writer, response)
+			return writer.Flush()
+		}),
+	}
+	garbageCollect.Flags().BoolVar(&dryRun, "dry-run", false, "report the blocks and bytes which would be reclaimed without deleting anything")
+
 	mount := &cobra.Command{
 		Use:   "mount path/to/mount/point",
 		Short: "Mount pfs locally.",
@@ -414,18 +772,27 @@ Files can be read from finished commits with get-file.`,
 	result = append(result, inspectRepo)
 	result = append(result, listRepo)
 	result = append(result, deleteRepo)
+	result = append(result, setQuota)
 	result = append(result, commit)
 	result = append(result, startCommit)
 	result = append(result, finishCommit)
 	result = append(result, inspectCommit)
 	result = append(result, listCommit)
+	result = append(result, deleteCommit)
 	result = append(result, listBranch)
+	result = append(result, createBranch)
+	result = append(result, setBranch)
+	result = append(result, deleteBranch)
+	result = append(result, renameBranch)
+	result = append(result, diffCommit)
 	result = append(result, file)
 	result = append(result, putFile)
 	result = append(result, getFile)
 	result = append(result, inspectFile)
 	result = append(result, listFile)
+	result = append(result, copyFile)
 	result = append(result, deleteFile)
+	result = append(result, garbageCollect)
 	result = append(result, mount)
 	return result
 }
@@ -447,3 +814,34 @@ func parseCommitMounts(args []string) []*fuse.CommitMount {
 	}
 	return result
 }
+
+func parseMergePolicies(args []string) ([]*pfsclient.MergePolicy, error) {
+	var result []*pfsclient.MergePolicy
+	for _, arg := range args {
+		split := strings.SplitN(arg, "=", 2)
+		if len(split) != 2 {
+			return nil, fmt.Errorf("merge policy %s should be path=strategy", arg)
+		}
+		strategy, ok := pfsclient.MergeStrategy_value["MERGE_STRATEGY_"+strings.ToUpper(split[1])]
+		if !ok {
+			return nil, fmt.Errorf("unknown merge strategy %s, should be one of fail, ours, theirs or concatenate", split[1])
+		}
+		result = append(result, &pfsclient.MergePolicy{
+			Path:     split[0],
+			Strategy: pfsclient.MergeStrategy(strategy),
+		})
+	}
+	return result, nil
+}
+
+func parseLabels(args []string) (map[string]string, error) {
+	result := make(map[string]string)
+	for _, arg := range args {
+		split := strings.SplitN(arg, "=", 2)
+		if len(split) != 2 || split[0] == "" {
+			return nil, fmt.Errorf("label %s should be key=value", arg)
+		}
+		result[split[0]] = split[1]
+	}
+	return result, nil
+}
@@ -0,0 +1,70 @@
+package cmds
+
+import (
+	"archive/tar"
+	"io"
+	"path"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	pfsclient "github.com/pachyderm/pachyderm/src/client/pfs"
+	"go.pedge.io/proto/time"
+)
+
+// getFileTar writes the files matching pattern to writer as a tar stream,
+// directories which match are written with everything under them.
+func getFileTar(c *client.APIClient, repoName string, commitID string, pattern string, fromCommitID string, shard *pfsclient.Shard, unsafe bool, writer io.Writer) error {
+	globFile := c.GlobFile
+	getFile := c.GetFile
+	if unsafe {
+		globFile = c.GlobFileUnsafe
+		getFile = c.GetFileUnsafe
+	}
+	fileInfos, err := globFile(repoName, commitID, pattern, fromCommitID, shard)
+	if err != nil {
+		return err
+	}
+	tarWriter := tar.NewWriter(writer)
+	written := make(map[string]bool)
+	var writeFile func(fileInfo *pfsclient.FileInfo) error
+	writeFile = func(fileInfo *pfsclient.FileInfo) error {
+		if written[fileInfo.File.Path] {
+			return nil
+		}
+		written[fileInfo.File.Path] = true
+		header := &tar.Header{
+			Name:    fileInfo.File.Path,
+			Mode:    0644,
+			ModTime: prototime.TimestampToTime(fileInfo.Modified),
+		}
+		if fileInfo.FileType == pfsclient.FileType_FILE_TYPE_DIR {
+			header.Name += "/"
+			header.Mode = 0755
+			header.Typeflag = tar.TypeDir
+			if err := tarWriter.WriteHeader(header); err != nil {
+				return err
+			}
+			children, err := globFile(repoName, commitID, path.Join(fileInfo.File.Path, "**"), fromCommitID, shard)
+			if err != nil {
+				return err
+			}
+			for _, child := range children {
+				if err := writeFile(child); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		header.Typeflag = tar.TypeReg
+		header.Size = int64(fileInfo.SizeBytes)
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return err
+		}
+		return getFile(repoName, commitID, fileInfo.File.Path, 0, 0, fromCommitID, shard, tarWriter)
+	}
+	for _, fileInfo := range fileInfos {
+		if err := writeFile(fileInfo); err != nil {
+			return err
+		}
+	}
+	return tarWriter.Close()
+}
@@ -0,0 +1,180 @@
+package cmds
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/docker/go-units"
+	"github.com/pachyderm/pachyderm/src/client"
+)
+
+// putFileTask is a local file that's uploaded to path in a commit.
+type putFileTask struct {
+	localPath string
+	path      string
+}
+
+// putFileFailure is a file which failed to upload.
+type putFileFailure struct {
+	task putFileTask
+	err  error
+}
+
+// walkPutFileTasks returns a task for each file under localPath, which may be
+// a file or a directory, they're uploaded under dest at their path relative
+// to localPath.
+func walkPutFileTasks(localPath string, dest string) ([]putFileTask, error) {
+	var tasks []putFileTask
+	if err := filepath.Walk(localPath, func(walkPath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(localPath, walkPath)
+		if err != nil {
+			return err
+		}
+		tasks = append(tasks, putFileTask{
+			localPath: walkPath,
+			path:      pfsPath(dest, filepath.ToSlash(rel)),
+		})
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// manifestPutFileTasks returns the tasks for the paths listed in manifest,
+// one per line. Each is uploaded under dest at the path it's listed as,
+// directories are uploaded recursively. Paths containing ".." are rejected
+// since they'd be uploaded outside of dest.
+func manifestPutFileTasks(manifest string, dest string) ([]putFileTask, error) {
+	file, err := os.Open(manifest)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	var tasks []putFileTask
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		localPath := strings.TrimSpace(scanner.Text())
+		if localPath == "" {
+			continue
+		}
+		for _, elem := range strings.Split(filepath.ToSlash(localPath), "/") {
+			if elem == ".." {
+				return nil, fmt.Errorf("%s: path %s contains \"..\"", manifest, localPath)
+			}
+		}
+		pathTasks, err := walkPutFileTasks(localPath, pfsPath(dest, filepath.ToSlash(localPath)))
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, pathTasks...)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// pfsPath joins elem onto dest, the result never has a leading slash since
+// PFS forbids them.
+func pfsPath(dest string, elem string) string {
+	return strings.TrimPrefix(path.Join("/", dest, elem), "/")
+}
+
+// putFiles uploads tasks with put, which returns the number of bytes it
+// uploaded, making up to parallelism calls at once and reporting progress to
+// progress. It returns the tasks which failed.
+func putFiles(tasks []putFileTask, parallelism int, progress io.Writer, put func(task putFileTask) (int64, error)) []putFileFailure {
+	if parallelism < 1 {
+		parallelism = 1
+	}
+	var done int64
+	var bytesWritten int64
+	var failures []putFileFailure
+	var lock sync.Mutex
+	report := func() {
+		fmt.Fprintf(progress, "\r%d/%d files, %s", atomic.LoadInt64(&done), len(tasks),
+			units.BytesSize(float64(atomic.LoadInt64(&bytesWritten))))
+	}
+	stop := make(chan struct{})
+	defer func() {
+		close(stop)
+		report()
+		fmt.Fprintln(progress)
+	}()
+	go func() {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				report()
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	taskChan := make(chan putFileTask)
+	var wg sync.WaitGroup
+	for i := 0; i < parallelism; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for task := range taskChan {
+				written, err := put(task)
+				atomic.AddInt64(&bytesWritten, written)
+				atomic.AddInt64(&done, 1)
+				if err != nil {
+					lock.Lock()
+					failures = append(failures, putFileFailure{task, err})
+					lock.Unlock()
+				}
+			}
+		}()
+	}
+	for _, task := range tasks {
+		taskChan <- task
+	}
+	close(taskChan)
+	wg.Wait()
+	return failures
+}
+
+// putLocalFile uploads task to a commit with a PutFileWriter.
+func putLocalFile(c *client.APIClient, repoName string, commitID string, task putFileTask, overwrite bool) (_ int64, retErr error) {
+	file, err := os.Open(task.localPath)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	var writer io.WriteCloser
+	if overwrite {
+		writer, err = c.PutFileWriterOverwrite(repoName, commitID, task.path, "")
+	} else {
+		writer, err = c.PutFileWriter(repoName, commitID, task.path, "")
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err := writer.Close(); err != nil && retErr == nil {
+			retErr = err
+		}
+	}()
+	return io.Copy(writer, file)
+}
@@ -0,0 +1,106 @@
+package cmds
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+)
+
+func TestPFSPath(t *testing.T) {
+	require.Equal(t, "dest/a", pfsPath("dest", "a"))
+	require.Equal(t, "dest/a", pfsPath("/dest/", "/a"))
+	require.Equal(t, "a/b", pfsPath("", "a/b"))
+	require.Equal(t, "dest", pfsPath("dest", "."))
+	require.Equal(t, "", pfsPath("", ""))
+}
+
+// newTestDir returns a directory containing a and sub/b.
+func newTestDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "pachyderm-test-put-file")
+	require.NoError(t, err)
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "sub"), 0777))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "a"), []byte("a"), 0666))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "sub", "b"), []byte("b"), 0666))
+	return dir
+}
+
+func TestWalkPutFileTasks(t *testing.T) {
+	dir := newTestDir(t)
+	defer os.RemoveAll(dir)
+	tasks, err := walkPutFileTasks(dir, "dest")
+	require.NoError(t, err)
+	require.Equal(t, []putFileTask{
+		{localPath: filepath.Join(dir, "a"), path: "dest/a"},
+		{localPath: filepath.Join(dir, "sub", "b"), path: "dest/sub/b"},
+	}, tasks)
+	// a file is uploaded to dest itself
+	tasks, err = walkPutFileTasks(filepath.Join(dir, "a"), "dest")
+	require.NoError(t, err)
+	require.Equal(t, []putFileTask{{localPath: filepath.Join(dir, "a"), path: "dest"}}, tasks)
+	_, err = walkPutFileTasks(filepath.Join(dir, "missing"), "dest")
+	require.YesError(t, err)
+}
+
+func TestManifestPutFileTasks(t *testing.T) {
+	dir := newTestDir(t)
+	defer os.RemoveAll(dir)
+	writeManifest := func(lines ...string) string {
+		manifest := filepath.Join(dir, "manifest")
+		require.NoError(t, ioutil.WriteFile(manifest, []byte(strings.Join(lines, "\n")), 0666))
+		return manifest
+	}
+	// paths are uploaded under dest at the path they're listed as
+	tasks, err := manifestPutFileTasks(writeManifest(filepath.Join(dir, "a"), "", "  "+filepath.Join(dir, "sub")+"  "), "dest")
+	require.NoError(t, err)
+	destDir := pfsPath("dest", filepath.ToSlash(dir))
+	require.Equal(t, []putFileTask{
+		{localPath: filepath.Join(dir, "a"), path: destDir + "/a"},
+		{localPath: filepath.Join(dir, "sub", "b"), path: destDir + "/sub/b"},
+	}, tasks)
+	// paths which would be uploaded outside of dest are rejected
+	for _, line := range []string{"..", "../a", filepath.Join(dir, "sub") + "/../a", "sub/../../a"} {
+		_, err = manifestPutFileTasks(writeManifest(filepath.Join(dir, "a"), line), "dest")
+		require.YesError(t, err, line)
+	}
+	_, err = manifestPutFileTasks(writeManifest(filepath.Join(dir, "missing")), "dest")
+	require.YesError(t, err)
+	_, err = manifestPutFileTasks(filepath.Join(dir, "missing"), "dest")
+	require.YesError(t, err)
+}
+
+func TestPutFiles(t *testing.T) {
+	var tasks []putFileTask
+	for i := 0; i < 10; i++ {
+		tasks = append(tasks, putFileTask{localPath: fmt.Sprintf("local%d", i), path: fmt.Sprintf("file%d", i)})
+	}
+	var lock sync.Mutex
+	var put []string
+	var progress bytes.Buffer
+	failures := putFiles(tasks, 3, &progress, func(task putFileTask) (int64, error) {
+		lock.Lock()
+		put = append(put, task.path)
+		lock.Unlock()
+		if task.path == "file4" {
+			return 0, fmt.Errorf("failed")
+		}
+		return 1024, nil
+	})
+	// every task is put once and the failures are returned
+	sort.Strings(put)
+	var paths []string
+	for _, task := range tasks {
+		paths = append(paths, task.path)
+	}
+	require.Equal(t, paths, put)
+	require.Equal(t, 1, len(failures))
+	require.Equal(t, tasks[4], failures[0].task)
+	require.True(t, strings.Contains(progress.String(), "10/10 files, 9 KiB"), progress.String())
+}
@@ -0,0 +1,242 @@
+package drive
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pfs"
+	pfsserver "github.com/pachyderm/pachyderm/src/server/pfs"
+	"golang.org/x/net/context"
+)
+
+func (d *driver) ListBranch(repo *pfs.Repo, shards map[uint64]bool) ([]*pfs.BranchInfo, error) {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	if _, ok := d.diffs[repo.Name]; !ok {
+		return nil, pfsserver.ErrRepoNotFound
+	}
+	return d.branchInfos(repo.Name), nil
+}
+
+func (d *driver) CreateBranch(commit *pfs.Commit, branch string, shards map[uint64]bool) error {
+	return d.updateBranches(commit.Repo.Name, shards, func() error {
+		if _, ok := d.branches[commit.Repo.Name][branch]; ok {
+			return fmt.Errorf("branch %s already exists in repo %s", branch, commit.Repo.Name)
+		}
+		return d.setBranch(commit, branch, shards)
+	})
+}
+
+func (d *driver) SetBranch(commit *pfs.Commit, branch string, shards map[uint64]bool) error {
+	return d.updateBranches(commit.Repo.Name, shards, func() error {
+		if _, ok := d.branches[commit.Repo.Name][branch]; !ok {
+			return fmt.Errorf("branch %s not found in repo %s", branch, commit.Repo.Name)
+		}
+		return d.setBranch(commit, branch, shards)
+	})
+}
+
+func (d *driver) DeleteBranch(repo *pfs.Repo, branch string, shards map[uint64]bool) error {
+	return d.updateBranches(repo.Name, shards, func() error {
+		if _, ok := d.branches[repo.Name][branch]; !ok {
+			return fmt.Errorf("branch %s not found in repo %s", branch, repo.Name)
+		}
+		delete(d.branches[repo.Name], branch)
+		return nil
+	})
+}
+
+func (d *driver) RenameBranch(repo *pfs.Repo, branch string, newBranch string, shards map[uint64]bool) error {
+	return d.updateBranches(repo.Name, shards, func() error {
+		head, ok := d.branches[repo.Name][branch]
+		if !ok {
+			return fmt.Errorf("branch %s not found in repo %s", branch, repo.Name)
+		}
+		if _, ok := d.branches[repo.Name][newBranch]; ok {
+			return fmt.Errorf("branch %s already exists in repo %s", newBranch, repo.Name)
+		}
+		if err := d.setBranch(client.NewCommit(repo.Name, head), newBranch, shards); err != nil {
+			return err
+		}
+		delete(d.branches[repo.Name], branch)
+		return nil
+	})
+}
+
+// updateBranches calls update, which changes repoName's branches, with
+// d.lock held and then persists the branches if they changed. update may be
+// nil, callers which changed the branches themselves use that to persist them.
+func (d *driver) updateBranches(repoName string, shards map[uint64]bool, update func() error) error {
+	d.branchLock.Lock()
+	defer d.branchLock.Unlock()
+	var rootDiffInfos []*pfs.DiffInfo
+	if err := func() error {
+		d.lock.Lock()
+		defer d.lock.Unlock()
+		if _, ok := d.diffs[repoName]; !ok {
+			return pfsserver.ErrRepoNotFound
+		}
+		if update != nil {
+			if err := update(); err != nil {
+				return err
+			}
+		}
+		var err error
+		rootDiffInfos, err = d.branchDiffInfos(repoName, shards)
+		return err
+	}(); err != nil {
+		return err
+	}
+	return d.persistDiffInfos(rootDiffInfos)
+}
+
+// setBranch points branch at commit, which may itself be a branch.
+func (d *driver) setBranch(commit *pfs.Commit, branch string, shards map[uint64]bool) error {
+	if branch == "" {
+		return fmt.Errorf("branch name cannot be empty")
+	}
+	canonicalCommit, err := d.canonicalCommit(commit)
+	if err != nil {
+		return err
+	}
+	for shard := range shards {
+		if _, ok := d.diffs.get(client.NewDiff(commit.Repo.Name, branch, shard)); ok {
+			return fmt.Errorf("branch %s conflicts with commit of the same name", branch)
+		}
+		if _, ok := d.diffs.get(client.NewDiff(canonicalCommit.Repo.Name, canonicalCommit.ID, shard)); !ok {
+			return fmt.Errorf("commit %s/%s not found", canonicalCommit.Repo.Name, canonicalCommit.ID)
+		}
+	}
+	d.branches[commit.Repo.Name][branch] = canonicalCommit.ID
+	return nil
+}
+
+// branchInfos returns repoName's branches sorted by name.
+func (d *driver) branchInfos(repoName string) []*pfs.BranchInfo {
+	var result []*pfs.BranchInfo
+	for branch, commitID := range d.branches[repoName] {
+		result = append(result, &pfs.BranchInfo{
+			Name: branch,
+			Head: client.NewCommit(repoName, commitID),
+		})
+	}
+	return pfsserver.ReduceBranchInfos(result)
+}
+
+// persistedBranchInfos returns repoName's branches as they're persisted in
+// shard. Open commits aren't persisted, so a branch whose head is open is
+// persisted as its last finished commit, if it has one.
+func (d *driver) persistedBranchInfos(repoName string, shard uint64) []*pfs.BranchInfo {
+	var result []*pfs.BranchInfo
+	for _, branchInfo := range d.branchInfos(repoName) {
+		commit := branchInfo.Head
+		for commit != nil {
+			diffInfo, ok := d.diffs.get(client.NewDiff(repoName, commit.ID, shard))
+			if !ok {
+				commit = nil
+				break
+			}
+			if diffInfo.Finished != nil {
+				break
+			}
+			commit = diffInfo.ParentCommit
+		}
+		if commit != nil {
+			result = append(result, &pfs.BranchInfo{
+				Name: branchInfo.Name,
+				Head: commit,
+			})
+		}
+	}
+	return result
+}
+
+// branchDiffInfos replaces the diffs of repoName's root commit in shards
+// whose persisted branches are out of date with copies holding the current
+// ones and returns them. Finished diffs are read by snapshots without d.lock
+// held, which is why they're copied rather than modified.
+// It must be called with d.lock held and the copies must be persisted before
+// branchLock is released so that they're persisted in order.
+func (d *driver) branchDiffInfos(repoName string, shards map[uint64]bool) ([]*pfs.DiffInfo, error) {
+	var result []*pfs.DiffInfo
+	for shard := range shards {
+		diffInfo, ok := d.diffs.get(client.NewDiff(repoName, "", shard))
+		if !ok {
+			return nil, fmt.Errorf("diff %s//%d not found; this is likely a bug", repoName, shard)
+		}
+		branchInfos := d.persistedBranchInfos(repoName, shard)
+		if diffInfo.Branches != nil && branchInfosEqual(diffInfo.Branches.BranchInfo, branchInfos) {
+			continue
+		}
+		rootDiffInfo := *diffInfo
+		rootDiffInfo.Branches = &pfs.BranchInfos{BranchInfo: branchInfos}
+		d.diffs[repoName][shard][""] = &rootDiffInfo
+		result = append(result, &rootDiffInfo)
+	}
+	return result, nil
+}
+
+func branchInfosEqual(a []*pfs.BranchInfo, b []*pfs.BranchInfo) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Name != b[i].Name || a[i].Head.ID != b[i].Head.ID {
+			return false
+		}
+	}
+	return true
+}
+
+// restoreBranches sets repoName's branches to those persisted in
+// rootDiffInfo, if there are any. It must be called with d.lock held once the
+// shard's diffs have been inserted. A head which can't be found falls back
+// to the last commit started on the branch, which is how the branches of
+// repos that were never persisted are found.
+func (d *driver) restoreBranches(repoName string, rootDiffInfo *pfs.DiffInfo) {
+	if rootDiffInfo.Branches == nil {
+		return
+	}
+	branches := make(map[string]string)
+	for _, branchInfo := range rootDiffInfo.Branches.BranchInfo {
+		if _, ok := d.diffs.get(client.NewDiff(repoName, branchInfo.Head.ID, rootDiffInfo.Diff.Shard)); ok {
+			branches[branchInfo.Name] = branchInfo.Head.ID
+		} else if commitID, ok := d.branches[repoName][branchInfo.Name]; ok {
+			branches[branchInfo.Name] = commitID
+		}
+	}
+	d.branches[repoName] = branches
+}
+
+// persistDiffInfos writes diffInfos to the block store.
+func (d *driver) persistDiffInfos(diffInfos []*pfs.DiffInfo) error {
+	blockClient, err := d.getBlockClient()
+	if err != nil {
+		return err
+	}
+	var wg sync.WaitGroup
+	errCh := make(chan error, 1)
+	for _, diffInfo := range diffInfos {
+		diffInfo := diffInfo
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := blockClient.CreateDiff(context.Background(), diffInfo); err != nil {
+				select {
+				case errCh <- err:
+					// error reported
+				default:
+					// not the first error
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	select {
+	case err := <-errCh:
+		return err
+	default:
+	}
+	return nil
+}
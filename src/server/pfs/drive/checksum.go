@@ -0,0 +1,101 @@
+package drive
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math"
+	"sync"
+
+	"github.com/pachyderm/pachyderm/src/client/pfs"
+)
+
+// sha256CacheSize is how many file SHA-256s are cached.
+const sha256CacheSize = 10000
+
+// fileDigest returns a digest of blockRefs, the blocks of a regular file in
+// order. Blocks are content addressed so files with the same digest have the
+// same content.
+func fileDigest(blockRefs []*pfs.BlockRef) string {
+	hash := sha256.New()
+	for _, blockRef := range blockRefs {
+		fmt.Fprintf(hash, "%s %d %d\n", blockRef.Block.Hash, blockRef.Range.Lower, blockRef.Range.Upper)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// fileSHA256 returns the SHA-256 of the content of the file made of
+// blockRefs, whose digest is digest. It reads the file unless it's cached,
+// partial reads of a file have no digest and are never cached.
+func (d *driver) fileSHA256(digest string, blockRefs []*pfs.BlockRef) (string, error) {
+	if digest != "" {
+		if sum, ok := d.sha256Cache.get(digest); ok {
+			return sum, nil
+		}
+	}
+	blockClient, err := d.getBlockClient()
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.New()
+	if _, err := io.Copy(hash, newFileReader(blockClient, blockRefs, 0, math.MaxInt64)); err != nil {
+		return "", err
+	}
+	sum := hex.EncodeToString(hash.Sum(nil))
+	if digest != "" {
+		d.sha256Cache.add(digest, sum)
+	}
+	return sum, nil
+}
+
+// sha256Cache maps file digests to the SHA-256 of their content, a digest
+// always refers to the same content so entries never need to be invalidated.
+type sha256Cache struct {
+	lock    sync.Mutex
+	size    int
+	entries map[string]*list.Element
+	// lru is ordered from most to least recently used, its values are
+	// *sha256CacheEntry
+	lru *list.List
+}
+
+type sha256CacheEntry struct {
+	digest string
+	sum    string
+}
+
+func newSHA256Cache(size int) *sha256Cache {
+	return &sha256Cache{
+		size:    size,
+		entries: make(map[string]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+func (c *sha256Cache) get(digest string) (string, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	element, ok := c.entries[digest]
+	if !ok {
+		return "", false
+	}
+	c.lru.MoveToFront(element)
+	return element.Value.(*sha256CacheEntry).sum, true
+}
+
+func (c *sha256Cache) add(digest string, sum string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if element, ok := c.entries[digest]; ok {
+		c.lru.MoveToFront(element)
+		return
+	}
+	c.entries[digest] = c.lru.PushFront(&sha256CacheEntry{digest, sum})
+	for c.lru.Len() > c.size {
+		element := c.lru.Back()
+		delete(c.entries, element.Value.(*sha256CacheEntry).digest)
+		c.lru.Remove(element)
+	}
+}
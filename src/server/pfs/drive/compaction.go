@@ -0,0 +1,137 @@
+package drive
+
+import (
+	"fmt"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pfs"
+	pfsserver "github.com/pachyderm/pachyderm/src/server/pfs"
+	"go.pedge.io/lion/proto"
+	"golang.org/x/net/context"
+)
+
+const (
+	// defaultCheckpointInterval is the number of appends a read of a
+	// compacted file follows before it reaches a checkpoint by default.
+	defaultCheckpointInterval = 100
+)
+
+// appendKey identifies the append to a file in a commit.
+type appendKey struct {
+	repoName string
+	commitID string
+	path     string
+}
+
+func (d *driver) CompactShard(shard uint64) error {
+	if d.options.CheckpointInterval == 0 {
+		return nil
+	}
+	// Like SnapshotShard we rely on finished diffs only being modified with
+	// snapshotLock held, the checkpoints are added with d.lock held because
+	// reads use them.
+	d.snapshotLock.Lock()
+	defer d.snapshotLock.Unlock()
+	var diffInfos []*pfs.DiffInfo
+	d.lock.RLock()
+	if !d.shards[shard] {
+		d.lock.RUnlock()
+		return fmt.Errorf("shard %d hasn't been added", shard)
+	}
+	for repoName, shardMap := range d.diffs {
+		// parents come before their children so that the checkpoints we
+		// write are used to compute the later ones
+		for _, commitID := range d.dags[repoName].Sorted() {
+			if diffInfo, ok := shardMap[shard][commitID]; ok && diffInfo.Finished != nil {
+				diffInfos = append(diffInfos, diffInfo)
+			}
+		}
+	}
+	d.lock.RUnlock()
+	blockClient, err := d.getBlockClient()
+	if err != nil {
+		return err
+	}
+	// depths is the number of appends a read starting at each append follows
+	depths := make(map[appendKey]uint64)
+	for _, diffInfo := range diffInfos {
+		commit := diffInfo.Diff.Commit
+		var checkpointPaths []string
+		for filePath, _append := range diffInfo.Appends {
+			depth := uint64(1)
+			if _, ok := diffInfo.Checkpoints[filePath]; !ok && !_append.Delete && _append.LastRef != nil {
+				depth += depths[appendKey{commit.Repo.Name, _append.LastRef.ID, filePath}]
+			}
+			if depth > d.options.CheckpointInterval {
+				checkpointPaths = append(checkpointPaths, filePath)
+				depth = 1
+			}
+			depths[appendKey{commit.Repo.Name, commit.ID, filePath}] = depth
+		}
+		if len(checkpointPaths) == 0 {
+			continue
+		}
+		checkpoints := make(map[string]*pfs.Checkpoint)
+		if err := func() error {
+			d.lock.RLock()
+			defer d.lock.RUnlock()
+			for _, filePath := range checkpointPaths {
+				fileInfo, blockRefs, err := d.inspectFile(client.NewFile(commit.Repo.Name, commit.ID, filePath), nil, shard, nil, false, true)
+				if err != nil && err != pfsserver.ErrFileNotFound {
+					return err
+				}
+				if err == pfsserver.ErrFileNotFound {
+					continue
+				}
+				checkpoint := &pfs.Checkpoint{
+					FileType:  fileInfo.FileType,
+					BlockRefs: blockRefs,
+				}
+				if fileInfo.FileType == pfs.FileType_FILE_TYPE_DIR {
+					checkpoint.Children = make(map[string]*pfs.Commit)
+					for _, child := range fileInfo.Children {
+						checkpoint.Children[child.Path] = child.Commit
+					}
+				}
+				checkpoints[filePath] = checkpoint
+			}
+			return nil
+		}(); err != nil {
+			return err
+		}
+		if err := func() error {
+			d.lock.Lock()
+			defer d.lock.Unlock()
+			if !d.shards[shard] {
+				return fmt.Errorf("shard %d was deleted while it was being compacted", shard)
+			}
+			if diffInfo.Checkpoints == nil {
+				diffInfo.Checkpoints = make(map[string]*pfs.Checkpoint)
+			}
+			for filePath, checkpoint := range checkpoints {
+				diffInfo.Checkpoints[filePath] = checkpoint
+			}
+			return nil
+		}(); err != nil {
+			return err
+		}
+		// Each diff is persisted as soon as it's been compacted so an
+		// interrupted compaction carries on from there the next time the
+		// shard is compacted.
+		if _, err := blockClient.CreateDiff(context.Background(), diffInfo); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compactShards compacts shards in the background, FinishCommit calls it
+// once enough commits have been finished and AddShard calls it to resume
+// compactions which were interrupted.
+func (d *driver) compactShards(shards []uint64) {
+	for _, shard := range shards {
+		if err := d.CompactShard(shard); err != nil {
+			protolion.Errorf("error compacting shard %d: %s", shard, err.Error())
+		}
+	}
+}
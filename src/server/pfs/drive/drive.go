@@ -9,31 +9,103 @@ import (
 	"go.pedge.io/pb/go/google/protobuf"
 
 	"github.com/pachyderm/pachyderm/src/client/pfs"
+	pfsserver "github.com/pachyderm/pachyderm/src/server/pfs"
 )
 
 // Driver represents a low-level pfs storage driver.
 type Driver interface {
-	CreateRepo(repo *pfs.Repo, created *google_protobuf.Timestamp, shards map[uint64]bool) error
+	CreateRepo(repo *pfs.Repo, created *google_protobuf.Timestamp, quota *pfs.Quota, shards map[uint64]bool) error
 	InspectRepo(repo *pfs.Repo, shards map[uint64]bool) (*pfs.RepoInfo, error)
 	ListRepo(shards map[uint64]bool) ([]*pfs.RepoInfo, error)
 	DeleteRepo(repo *pfs.Repo, shards map[uint64]bool) error
-	StartCommit(repo *pfs.Repo, commitID string, parentID string, branch string, started *google_protobuf.Timestamp, shards map[uint64]bool) error
-	FinishCommit(commit *pfs.Commit, finished *google_protobuf.Timestamp, cancel bool, shards map[uint64]bool) error
+	// SetQuota replaces repo's quota, PutFile fails with ErrQuotaExceeded if
+	// it's exceeded in a shard.
+	SetQuota(repo *pfs.Repo, quota *pfs.Quota, shards map[uint64]bool) error
+	// StartCommit starts a commit, if mergeParentID is set the commit merges
+	// it into its parent, conflicting changes are resolved by mergePolicies.
+	// provenance is the commits the commit is derived from, their own
+	// provenance is added to it.
+	StartCommit(repo *pfs.Repo, commitID string, parentID string, branch string, mergeParentID string, mergePolicies []*pfs.MergePolicy, provenance []*pfs.Commit, message string, labels map[string]string, started *google_protobuf.Timestamp, shards map[uint64]bool) error
+	// FinishCommit finishes a commit, message replaces the commit's message
+	// if it's set and labels are added to its labels, empty labels remove
+	// them.
+	FinishCommit(commit *pfs.Commit, finished *google_protobuf.Timestamp, cancel bool, message string, labels map[string]string, shards map[uint64]bool) error
 	InspectCommit(commit *pfs.Commit, shards map[uint64]bool) (*pfs.CommitInfo, error)
-	ListCommit(repo []*pfs.Repo, fromCommit []*pfs.Commit, all bool, shards map[uint64]bool) ([]*pfs.CommitInfo, error)
-	ListBranch(repo *pfs.Repo, shards map[uint64]bool) ([]*pfs.CommitInfo, error)
-	DeleteCommit(commit *pfs.Commit, shards map[uint64]bool) error
-	PutFile(file *pfs.File, handle string, shard uint64, reader io.Reader) error
+	// ListCommit lists the commits in repo, if provenance is set only commits
+	// derived from all of it are listed, from every repo if repo is empty.
+	// If labels is set only commits with all of them are listed.
+	ListCommit(repo []*pfs.Repo, fromCommit []*pfs.Commit, provenance []*pfs.Commit, labels map[string]string, all bool, shards map[uint64]bool) ([]*pfs.CommitInfo, error)
+	ListBranch(repo *pfs.Repo, shards map[uint64]bool) ([]*pfs.BranchInfo, error)
+	// CreateBranch creates branch with commit, which may be a branch, as its
+	// head.
+	CreateBranch(commit *pfs.Commit, branch string, shards map[uint64]bool) error
+	SetBranch(commit *pfs.Commit, branch string, shards map[uint64]bool) error
+	DeleteBranch(repo *pfs.Repo, branch string, shards map[uint64]bool) error
+	RenameBranch(repo *pfs.Repo, branch string, newBranch string, shards map[uint64]bool) error
+	DeleteCommit(commit *pfs.Commit, force bool, shards map[uint64]bool) error
+	// PutFile appends the content of reader to file, if overwrite is set the
+	// file is truncated first.
+	PutFile(file *pfs.File, handle string, overwrite bool, shard uint64, reader io.Reader) error
+	// PutFileBlockRefs appends blocks which are already in the block store
+	// to file, if overwrite is set the file is truncated first.
+	PutFileBlockRefs(file *pfs.File, blockRefs []*pfs.BlockRef, overwrite bool, shard uint64) error
 	MakeDirectory(file *pfs.File, shard uint64) error
 	GetFile(file *pfs.File, filterShard *pfs.Shard, offset int64, size int64, from *pfs.Commit, shard uint64, unsafe bool) (io.ReadCloser, error)
-	InspectFile(file *pfs.File, filterShard *pfs.Shard, from *pfs.Commit, shard uint64, unsafe bool) (*pfs.FileInfo, error)
-	ListFile(file *pfs.File, filterShard *pfs.Shard, from *pfs.Commit, shard uint64, recurse bool, unsafe bool) ([]*pfs.FileInfo, error)
+	// GetFileBlockRefs returns the blocks which make up the regular file
+	// file, in order.
+	GetFileBlockRefs(file *pfs.File, from *pfs.Commit, shard uint64, unsafe bool) ([]*pfs.BlockRef, error)
+	// InspectFile returns info about file, if sha256 is set the SHA-256 of
+	// a regular file's content is computed.
+	InspectFile(file *pfs.File, filterShard *pfs.Shard, from *pfs.Commit, shard uint64, unsafe bool, sha256 bool) (*pfs.FileInfo, error)
+	// ListFile lists the children of file, in order of path. Only the files
+	// after the path pageToken are listed, up to limit of them, unless
+	// they're "" and 0.
+	ListFile(file *pfs.File, filterShard *pfs.Shard, from *pfs.Commit, shard uint64, recurse bool, unsafe bool, pageToken string, limit uint64) ([]*pfs.FileInfo, error)
+	// ListFileStream is like ListFile, but the children are listed once
+	// when it's called and each of them is inspected as it's received.
+	ListFileStream(file *pfs.File, filterShard *pfs.Shard, from *pfs.Commit, shard uint64, recurse bool, unsafe bool, pageToken string) (pfsserver.FileInfoStream, error)
+	// GlobFile returns the files and directories in shard which match the
+	// glob file.Path, if recurse is set directories include the sizes of
+	// their children.
+	GlobFile(file *pfs.File, filterShard *pfs.Shard, from *pfs.Commit, shard uint64, recurse bool, unsafe bool) ([]*pfs.FileInfo, error)
 	DeleteFile(file *pfs.File, shard uint64) error
+	// DiffCommit returns the regular files in shard which were added,
+	// modified or deleted after from up to to, from must be an ancestor of to
+	// or nil.
+	DiffCommit(from *pfs.Commit, to *pfs.Commit, shard uint64) ([]*pfs.FileChange, error)
 	AddShard(shard uint64) error
+	// SnapshotShard writes the shard's metadata to the block store so that
+	// AddShard only has to replay the diffs written after it.
+	SnapshotShard(shard uint64) error
+	// CompactShard writes checkpoints of the shard's files so that reading
+	// them doesn't follow their appends all the way back.
+	CompactShard(shard uint64) error
 	DeleteShard(shard uint64) error
 	Dump()
 }
 
-func NewDriver(blockAddress string) (Driver, error) {
-	return newDriver(blockAddress)
+// Options configures a Driver.
+type Options struct {
+	// SnapshotInterval is the number of commits finished on a shard between
+	// snapshots of its metadata, AddShard loads the latest snapshot and only
+	// replays the diffs written after it. 0 disables snapshots.
+	SnapshotInterval uint64
+	// CheckpointInterval is how many appends to a file can be read before
+	// compaction writes a checkpoint of it, shards are compacted in the
+	// background every time that many commits have been finished on them.
+	// 0 disables compaction.
+	CheckpointInterval uint64
+}
+
+// DefaultOptions returns the options drivers use unless they're configured
+// otherwise.
+func DefaultOptions() Options {
+	return Options{
+		SnapshotInterval:   defaultSnapshotInterval,
+		CheckpointInterval: defaultCheckpointInterval,
+	}
+}
+
+func NewDriver(blockAddress string, options Options) (Driver, error) {
+	return newDriver(blockAddress, options)
 }
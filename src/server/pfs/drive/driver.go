@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"io"
 	"path"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/pachyderm/pachyderm/src/client"
 	"github.com/pachyderm/pachyderm/src/client/pfs"
@@ -12,12 +14,14 @@ import (
 	"github.com/pachyderm/pachyderm/src/server/pkg/dag"
 	"github.com/pachyderm/pachyderm/src/server/pkg/metrics"
 	"go.pedge.io/pb/go/google/protobuf"
+	"go.pedge.io/proto/time"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
 )
 
 type driver struct {
 	blockAddress    string
+	options         Options
 	blockClient     pfs.BlockAPIClient
 	blockClientOnce sync.Once
 	diffs           diffMap
@@ -26,18 +30,40 @@ type driver struct {
 	lock            sync.RWMutex
 	// used for signaling the completion (i.e. finishing) of a commit
 	commitConds map[string]*sync.Cond
+	// shards are the shards which have been added, it's protected by lock
+	shards map[uint64]bool
+	// finishedSinceSnapshot counts the commits finished on each shard since
+	// it was last snapshotted, it's protected by lock
+	finishedSinceSnapshot map[uint64]uint64
+	// finishedSinceCompaction counts the commits finished on each shard
+	// since it was last compacted, it's protected by lock
+	finishedSinceCompaction map[uint64]uint64
+	// snapshotLock is held while shards are snapshotted and while persisted
+	// diffs are deleted or rewritten, see SnapshotShard
+	snapshotLock sync.Mutex
+	// branchLock is held while branches and quotas are persisted so that
+	// they're persisted in the order they change, it's taken after
+	// snapshotLock and before lock
+	branchLock sync.Mutex
+	// sha256Cache caches the SHA-256s of files by their digests
+	sha256Cache *sha256Cache
 }
 
-func newDriver(blockAddress string) (Driver, error) {
+func newDriver(blockAddress string, options Options) (Driver, error) {
 	return &driver{
-		blockAddress:    blockAddress,
-		blockClient:     nil,
-		blockClientOnce: sync.Once{},
-		diffs:           make(diffMap),
-		dags:            make(map[string]*dag.DAG),
-		branches:        make(map[string]map[string]string),
-		lock:            sync.RWMutex{},
-		commitConds:     make(map[string]*sync.Cond),
+		blockAddress:            blockAddress,
+		options:                 options,
+		blockClient:             nil,
+		blockClientOnce:         sync.Once{},
+		diffs:                   make(diffMap),
+		dags:                    make(map[string]*dag.DAG),
+		branches:                make(map[string]map[string]string),
+		lock:                    sync.RWMutex{},
+		commitConds:             make(map[string]*sync.Cond),
+		shards:                  make(map[uint64]bool),
+		finishedSinceSnapshot:   make(map[uint64]uint64),
+		finishedSinceCompaction: make(map[uint64]uint64),
+		sha256Cache:             newSHA256Cache(sha256CacheSize),
 	}, nil
 }
 
@@ -58,7 +84,7 @@ func (d *driver) getBlockClient() (pfs.BlockAPIClient, error) {
 	return d.blockClient, nil
 }
 
-func (d *driver) CreateRepo(repo *pfs.Repo, created *google_protobuf.Timestamp, shards map[uint64]bool) error {
+func (d *driver) CreateRepo(repo *pfs.Repo, created *google_protobuf.Timestamp, quota *pfs.Quota, shards map[uint64]bool) error {
 	d.lock.Lock()
 	defer d.lock.Unlock()
 	if _, ok := d.diffs[repo.Name]; ok {
@@ -77,6 +103,8 @@ func (d *driver) CreateRepo(repo *pfs.Repo, created *google_protobuf.Timestamp,
 		diffInfo := &pfs.DiffInfo{
 			Diff:     client.NewDiff(repo.Name, "", shard),
 			Finished: created,
+			Branches: &pfs.BranchInfos{},
+			Quota:    quota,
 		}
 		if err := d.diffs.insert(diffInfo); err != nil {
 			return err
@@ -145,6 +173,11 @@ func (d *driver) ListRepo(shards map[uint64]bool) ([]*pfs.RepoInfo, error) {
 }
 
 func (d *driver) DeleteRepo(repo *pfs.Repo, shards map[uint64]bool) error {
+	d.snapshotLock.Lock()
+	defer d.snapshotLock.Unlock()
+	// the repo's branches mustn't be persisted after its diffs are deleted
+	d.branchLock.Lock()
+	defer d.branchLock.Unlock()
 	var diffInfos []*pfs.DiffInfo
 	d.lock.Lock()
 	if _, ok := d.diffs[repo.Name]; !ok {
@@ -162,6 +195,9 @@ func (d *driver) DeleteRepo(repo *pfs.Repo, shards map[uint64]bool) error {
 	if err != nil {
 		return err
 	}
+	if err := d.deleteShardSnapshots(blockClient, shards); err != nil {
+		return err
+	}
 	errCh := make(chan error, 1)
 	var wg sync.WaitGroup
 	for _, diffInfo := range diffInfos {
@@ -191,16 +227,53 @@ func (d *driver) DeleteRepo(repo *pfs.Repo, shards map[uint64]bool) error {
 	return nil
 }
 
-func (d *driver) StartCommit(repo *pfs.Repo, commitID string, parentID string, branch string,
+func (d *driver) StartCommit(repo *pfs.Repo, commitID string, parentID string, branch string, mergeParentID string,
+	mergePolicies []*pfs.MergePolicy, provenance []*pfs.Commit, message string, labels map[string]string,
+	started *google_protobuf.Timestamp, shards map[uint64]bool) error {
+	if err := d.startCommit(repo, commitID, parentID, branch, mergeParentID, mergePolicies, provenance, message, labels,
+		started, shards); err != nil {
+		return err
+	}
+	if branch != "" {
+		// the commit may have created the branch
+		return d.updateBranches(repo.Name, shards, nil)
+	}
+	return nil
+}
+
+func (d *driver) startCommit(repo *pfs.Repo, commitID string, parentID string, branch string, mergeParentID string,
+	mergePolicies []*pfs.MergePolicy, provenance []*pfs.Commit, message string, labels map[string]string,
 	started *google_protobuf.Timestamp, shards map[uint64]bool) error {
 	d.lock.Lock()
 	defer d.lock.Unlock()
+	fullProvenance, err := d.fullProvenance(provenance, shards)
+	if err != nil {
+		return err
+	}
+	var mergeParent *pfs.Commit
+	if mergeParentID != "" {
+		var err error
+		mergeParent, err = d.canonicalCommit(client.NewCommit(repo.Name, mergeParentID))
+		if err != nil {
+			return err
+		}
+	}
+	// the diffs are only inserted once every shard has been merged, so a
+	// conflict doesn't leave the commit started on some of them
+	var diffInfos []*pfs.DiffInfo
 	for shard := range shards {
 		diffInfo := &pfs.DiffInfo{
-			Diff:    client.NewDiff(repo.Name, commitID, shard),
-			Started: started,
-			Appends: make(map[string]*pfs.Append),
-			Branch:  branch,
+			Diff:       client.NewDiff(repo.Name, commitID, shard),
+			Started:    started,
+			Appends:    make(map[string]*pfs.Append),
+			Branch:     branch,
+			Provenance: fullProvenance,
+			Message:    message,
+			Labels:     make(map[string]string),
+		}
+		// each shard gets its own copy since FinishCommit modifies them
+		for key, value := range labels {
+			diffInfo.Labels[key] = value
 		}
 		if branch != "" {
 			parentCommit, err := d.branchParent(client.NewCommit(repo.Name, commitID), branch)
@@ -214,8 +287,27 @@ func (d *driver) StartCommit(repo *pfs.Repo, commitID string, parentID string, b
 			diffInfo.ParentCommit = parentCommit
 		}
 		if diffInfo.ParentCommit == nil && parentID != "" {
-			diffInfo.ParentCommit = client.NewCommit(repo.Name, parentID)
+			parentCommit, err := d.canonicalCommit(client.NewCommit(repo.Name, parentID))
+			if err != nil {
+				return err
+			}
+			diffInfo.ParentCommit = parentCommit
 		}
+		if mergeParent != nil {
+			if diffInfo.ParentCommit == nil {
+				return fmt.Errorf("commit %s/%s has no parent to merge %s into", repo.Name, commitID, mergeParent.ID)
+			}
+			if diffInfo.ParentCommit.ID == mergeParent.ID {
+				return fmt.Errorf("cannot merge commit %s/%s with itself", repo.Name, mergeParent.ID)
+			}
+			diffInfo.MergeParent = mergeParent
+			if err := d.merge(diffInfo, mergePolicies, shard); err != nil {
+				return err
+			}
+		}
+		diffInfos = append(diffInfos, diffInfo)
+	}
+	for _, diffInfo := range diffInfos {
 		if err := d.insertDiffInfo(diffInfo); err != nil {
 			return err
 		}
@@ -225,7 +317,8 @@ func (d *driver) StartCommit(repo *pfs.Repo, commitID string, parentID string, b
 }
 
 // FinishCommit blocks until its parent has been finished/cancelled
-func (d *driver) FinishCommit(commit *pfs.Commit, finished *google_protobuf.Timestamp, cancel bool, shards map[uint64]bool) error {
+func (d *driver) FinishCommit(commit *pfs.Commit, finished *google_protobuf.Timestamp, cancel bool, message string,
+	labels map[string]string, shards map[uint64]bool) error {
 	canonicalCommit, err := d.canonicalCommit(commit)
 	if err != nil {
 		return err
@@ -240,23 +333,41 @@ func (d *driver) FinishCommit(commit *pfs.Commit, finished *google_protobuf.Time
 			if !ok {
 				return fmt.Errorf("commit %s/%s not found", canonicalCommit.Repo.Name, canonicalCommit.ID)
 			}
-			if diffInfo.ParentCommit != nil {
+			// Wait for parent to finish, we look the parent up again after
+			// each wait because it may have been deleted in the meantime, in
+			// which case this commit has been reparented
+			for diffInfo.ParentCommit != nil {
 				parentDiffInfo, ok := d.diffs.get(client.NewDiff(canonicalCommit.Repo.Name, diffInfo.ParentCommit.ID, shard))
 				if !ok {
 					return fmt.Errorf("parent commit %s/%s not found", canonicalCommit.Repo.Name, diffInfo.ParentCommit.ID)
 				}
-				// Wait for parent to finish
-				for parentDiffInfo.Finished == nil {
-					cond, ok := d.commitConds[diffInfo.ParentCommit.ID]
-					if !ok {
-						return fmt.Errorf("parent commit %s/%s was not finished but a corresponding conditional variable could not be found; this is likely a bug", canonicalCommit.Repo.Name, diffInfo.ParentCommit.ID)
-					}
-					cond.Wait()
+				if parentDiffInfo.Finished != nil {
+					diffInfo.Cancelled = parentDiffInfo.Cancelled
+					break
+				}
+				cond, ok := d.commitConds[diffInfo.ParentCommit.ID]
+				if !ok {
+					return fmt.Errorf("parent commit %s/%s was not finished but a corresponding conditional variable could not be found; this is likely a bug", canonicalCommit.Repo.Name, diffInfo.ParentCommit.ID)
+				}
+				cond.Wait()
+				if _, ok := d.diffs.get(diffInfo.Diff); !ok {
+					return fmt.Errorf("commit %s/%s was deleted", canonicalCommit.Repo.Name, canonicalCommit.ID)
 				}
-
-				diffInfo.Cancelled = parentDiffInfo.Cancelled
 			}
 			diffInfo.Finished = finished
+			if message != "" {
+				diffInfo.Message = message
+			}
+			for key, value := range labels {
+				if diffInfo.Labels == nil {
+					diffInfo.Labels = make(map[string]string)
+				}
+				if value == "" {
+					delete(diffInfo.Labels, key)
+				} else {
+					diffInfo.Labels[key] = value
+				}
+			}
 			for _, _append := range diffInfo.Appends {
 				coalesceHandles(_append)
 			}
@@ -295,15 +406,52 @@ func (d *driver) FinishCommit(commit *pfs.Commit, finished *google_protobuf.Time
 	default:
 	}
 
+	if err := d.signalFinished(canonicalCommit, shards); err != nil {
+		return err
+	}
+	// the branches persisted with the commit's parent as their head move to
+	// the commit
+	return d.updateBranches(canonicalCommit.Repo.Name, shards, nil)
+}
+
+// signalFinished wakes up what's waiting on commit to be finished and starts
+// the snapshots and compactions that are due.
+func (d *driver) signalFinished(commit *pfs.Commit, shards map[uint64]bool) error {
 	d.lock.Lock()
 	defer d.lock.Unlock()
-	cond, ok := d.commitConds[canonicalCommit.ID]
+	cond, ok := d.commitConds[commit.ID]
 	if !ok {
 		return fmt.Errorf("could not found a conditional variable to signal commit completion; this is likely a bug")
 	}
 	cond.Broadcast()
-	delete(d.commitConds, canonicalCommit.ID)
+	delete(d.commitConds, commit.ID)
 
+	if d.options.SnapshotInterval > 0 {
+		var snapshotShards []uint64
+		for shard := range shards {
+			d.finishedSinceSnapshot[shard]++
+			if d.finishedSinceSnapshot[shard] >= d.options.SnapshotInterval {
+				d.finishedSinceSnapshot[shard] = 0
+				snapshotShards = append(snapshotShards, shard)
+			}
+		}
+		if len(snapshotShards) > 0 {
+			go d.snapshotShards(snapshotShards)
+		}
+	}
+	if d.options.CheckpointInterval > 0 {
+		var compactShards []uint64
+		for shard := range shards {
+			d.finishedSinceCompaction[shard]++
+			if d.finishedSinceCompaction[shard] >= d.options.CheckpointInterval {
+				d.finishedSinceCompaction[shard] = 0
+				compactShards = append(compactShards, shard)
+			}
+		}
+		if len(compactShards) > 0 {
+			go d.compactShards(compactShards)
+		}
+	}
 	return nil
 }
 
@@ -313,7 +461,23 @@ func (d *driver) InspectCommit(commit *pfs.Commit, shards map[uint64]bool) (*pfs
 	return d.inspectCommit(commit, shards)
 }
 
-func (d *driver) ListCommit(repos []*pfs.Repo, fromCommit []*pfs.Commit, all bool, shards map[uint64]bool) ([]*pfs.CommitInfo, error) {
+func (d *driver) ListCommit(repos []*pfs.Repo, fromCommit []*pfs.Commit, provenance []*pfs.Commit, labels map[string]string,
+	all bool, shards map[uint64]bool) ([]*pfs.CommitInfo, error) {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	var provenanceCommits []*pfs.Commit
+	for _, commit := range provenance {
+		canonicalCommit, err := d.canonicalCommit(commit)
+		if err != nil {
+			return nil, err
+		}
+		provenanceCommits = append(provenanceCommits, canonicalCommit)
+	}
+	if len(repos) == 0 && len(provenanceCommits) > 0 {
+		for repoName := range d.diffs {
+			repos = append(repos, client.NewRepo(repoName))
+		}
+	}
 	repoSet := make(map[string]bool)
 	for _, repo := range repos {
 		repoSet[repo.Name] = true
@@ -323,10 +487,12 @@ func (d *driver) ListCommit(repos []*pfs.Repo, fromCommit []*pfs.Commit, all boo
 		if !repoSet[commit.Repo.Name] {
 			return nil, fmt.Errorf("Commit %s/%s is from a repo that isn't being listed.", commit.Repo.Name, commit.ID)
 		}
-		breakCommitIDs[commit.ID] = true
+		canonicalCommit, err := d.canonicalCommit(commit)
+		if err != nil {
+			return nil, err
+		}
+		breakCommitIDs[canonicalCommit.ID] = true
 	}
-	d.lock.RLock()
-	defer d.lock.RUnlock()
 	var result []*pfs.CommitInfo
 	for _, repo := range repos {
 		_, ok := d.diffs[repo.Name]
@@ -338,40 +504,191 @@ func (d *driver) ListCommit(repos []*pfs.Repo, fromCommit []*pfs.Commit, all boo
 				Repo: repo,
 				ID:   commitID,
 			}
-			for commit != nil && !breakCommitIDs[commit.ID] {
+			commits := []*pfs.Commit{commit}
+			for len(commits) > 0 {
+				commit := commits[len(commits)-1]
+				commits = commits[:len(commits)-1]
+				if breakCommitIDs[commit.ID] {
+					continue
+				}
 				// we add this commit to breakCommitIDs so we won't see it twice
 				breakCommitIDs[commit.ID] = true
 				commitInfo, err := d.inspectCommit(commit, shards)
 				if err != nil {
 					return nil, err
 				}
-				if !commitInfo.Cancelled || all {
+				if (!commitInfo.Cancelled || all) && pfsserver.HasProvenance(commitInfo, provenanceCommits) &&
+					pfsserver.HasLabels(commitInfo, labels) {
 					result = append(result, commitInfo)
 				}
-				commit = commitInfo.ParentCommit
+				// merge commits have history on both parents, the first
+				// parent's is listed first
+				for _, parent := range []*pfs.Commit{commitInfo.MergeParent, commitInfo.ParentCommit} {
+					if parent != nil {
+						commits = append(commits, parent)
+					}
+				}
 			}
 		}
 	}
 	return result, nil
 }
 
-func (d *driver) ListBranch(repo *pfs.Repo, shards map[uint64]bool) ([]*pfs.CommitInfo, error) {
-	var result []*pfs.CommitInfo
-	for commitID := range d.branches[repo.Name] {
-		commitInfo, err := d.inspectCommit(client.NewCommit(repo.Name, commitID), shards)
+func (d *driver) DeleteCommit(commit *pfs.Commit, force bool, shards map[uint64]bool) error {
+	d.snapshotLock.Lock()
+	defer d.snapshotLock.Unlock()
+	// deleted are the persisted diffs of the commit, reparented are the
+	// persisted diffs of its descendants that referenced it or had
+	// checkpoints
+	var deleted []*pfs.DiffInfo
+	var reparented []*pfs.DiffInfo
+	repoName := commit.Repo.Name
+	if err := func() error {
+		d.lock.Lock()
+		defer d.lock.Unlock()
+		canonicalCommit, err := d.canonicalCommit(commit)
 		if err != nil {
-			return nil, err
+			return err
+		}
+		if canonicalCommit.ID == "" {
+			return fmt.Errorf("cannot delete the root commit of repo %s", repoName)
 		}
-		result = append(result, commitInfo)
+		var parentCommit *pfs.Commit
+		found := false
+		for _, commitToDiffInfo := range d.diffs[repoName] {
+			if diffInfo, ok := commitToDiffInfo[canonicalCommit.ID]; ok {
+				parentCommit = diffInfo.ParentCommit
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("commit %s/%s not found", repoName, canonicalCommit.ID)
+		}
+		if children := d.dags[repoName].Children(canonicalCommit.ID); len(children) > 0 && !force {
+			return fmt.Errorf("commit %s/%s has children %v, use force to delete it anyway", repoName, canonicalCommit.ID, children)
+		}
+		// the first descendant is the commit itself
+		descendants := d.dags[repoName].Descendants(canonicalCommit.ID, nil)[1:]
+		for shard := range shards {
+			diffInfo := d.diffs.pop(client.NewDiff(repoName, canonicalCommit.ID, shard))
+			if diffInfo == nil {
+				continue
+			}
+			if diffInfo.Finished == nil {
+				// the commit is open, we cancel it so that anything waiting
+				// on it sees it as cancelled, its diff was never persisted
+				diffInfo.Finished = prototime.TimeToTimestamp(time.Now())
+				diffInfo.Cancelled = true
+			} else {
+				deleted = append(deleted, diffInfo)
+			}
+			for _, descendantID := range descendants {
+				descendantDiffInfo, ok := d.diffs.get(client.NewDiff(repoName, descendantID, shard))
+				if !ok {
+					continue
+				}
+				modified := reparent(descendantDiffInfo, diffInfo)
+				// the descendant's checkpoints may include the commit, they
+				// get rewritten by the next compaction
+				if len(descendantDiffInfo.Checkpoints) > 0 {
+					descendantDiffInfo.Checkpoints = nil
+					modified = true
+				}
+				if modified && descendantDiffInfo.Finished != nil {
+					reparented = append(reparented, descendantDiffInfo)
+				}
+			}
+		}
+		d.dags[repoName].RemoveNode(canonicalCommit.ID)
+		for branch, commitID := range d.branches[repoName] {
+			if commitID != canonicalCommit.ID {
+				continue
+			}
+			if parentCommit != nil {
+				d.branches[repoName][branch] = parentCommit.ID
+			} else {
+				delete(d.branches[repoName], branch)
+			}
+		}
+		if cond, ok := d.commitConds[canonicalCommit.ID]; ok {
+			cond.Broadcast()
+			delete(d.commitConds, canonicalCommit.ID)
+		}
+		return nil
+	}(); err != nil {
+		return err
 	}
-	return result, nil
-}
-
-func (d *driver) DeleteCommit(commit *pfs.Commit, shards map[uint64]bool) error {
-	return fmt.Errorf("DeleteCommit is not implemented")
+	blockClient, err := d.getBlockClient()
+	if err != nil {
+		return err
+	}
+	// snapshots of the shards whose persisted diffs change are deleted first
+	// so that they can't bring back the commit
+	changedShards := make(map[uint64]bool)
+	for _, diffInfo := range append(deleted, reparented...) {
+		changedShards[diffInfo.Diff.Shard] = true
+	}
+	if err := d.deleteShardSnapshots(blockClient, changedShards); err != nil {
+		return err
+	}
+	// We rewrite the descendants before deleting the commit's diffs so that
+	// a failure in between never leaves diffs with a missing parent.
+	var wg sync.WaitGroup
+	errCh := make(chan error, 1)
+	for _, diffInfo := range reparented {
+		diffInfo := diffInfo
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := blockClient.CreateDiff(context.Background(), diffInfo); err != nil {
+				select {
+				case errCh <- err:
+					// error reported
+				default:
+					// not the first error
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	select {
+	case err := <-errCh:
+		return err
+	default:
+	}
+	// branches which pointed at the commit point at its parent now
+	if err := d.updateBranches(repoName, shards, nil); err != nil {
+		return err
+	}
+	for _, diffInfo := range deleted {
+		diffInfo := diffInfo
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := blockClient.DeleteDiff(
+				context.Background(),
+				&pfs.DeleteDiffRequest{Diff: diffInfo.Diff},
+			); err != nil {
+				select {
+				case errCh <- err:
+					// error reported
+				default:
+					// not the first error
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	select {
+	case err := <-errCh:
+		return err
+	default:
+	}
+	return nil
 }
 
-func (d *driver) PutFile(file *pfs.File, handle string, shard uint64, reader io.Reader) (retErr error) {
+func (d *driver) PutFile(file *pfs.File, handle string, overwrite bool, shard uint64, reader io.Reader) (retErr error) {
 	blockClient, err := d.getBlockClient()
 	if err != nil {
 		return err
@@ -389,6 +706,20 @@ func (d *driver) PutFile(file *pfs.File, handle string, shard uint64, reader io.
 			}
 		}
 	}()
+	return d.putBlockRefs(file, handle, overwrite, shard, blockRefs.BlockRef)
+}
+
+func (d *driver) PutFileBlockRefs(file *pfs.File, blockRefs []*pfs.BlockRef, overwrite bool, shard uint64) (retErr error) {
+	defer func() {
+		if retErr == nil {
+			metrics.AddFiles(1)
+		}
+	}()
+	return d.putBlockRefs(file, "", overwrite, shard, blockRefs)
+}
+
+// putBlockRefs appends blockRefs to file under handle.
+func (d *driver) putBlockRefs(file *pfs.File, handle string, overwrite bool, shard uint64, blockRefs []*pfs.BlockRef) error {
 	d.lock.Lock()
 	defer d.lock.Unlock()
 
@@ -414,7 +745,14 @@ func (d *driver) PutFile(file *pfs.File, handle string, shard uint64, reader io.
 	if diffInfo.Finished != nil {
 		return fmt.Errorf("commit %s/%s has already been finished", canonicalCommit.Repo.Name, canonicalCommit.ID)
 	}
-	addDirs(diffInfo, file)
+	var size uint64
+	for _, blockRef := range blockRefs {
+		size += pfsserver.ByteRangeSize(blockRef.Range)
+	}
+	if err := d.checkPutFileQuota(file, diffInfo, size, overwrite, shard); err != nil {
+		return err
+	}
+	d.addDirs(diffInfo, file, shard)
 	_append, ok := diffInfo.Appends[path.Clean(file.Path)]
 	if !ok {
 		_append = &pfs.Append{Handles: make(map[string]*pfs.BlockRefs)}
@@ -426,19 +764,26 @@ func (d *driver) PutFile(file *pfs.File, handle string, shard uint64, reader io.
 		}
 		diffInfo.Appends[path.Clean(file.Path)] = _append
 	}
+	if overwrite {
+		// Delete stops reads at this commit so the parent's content is
+		// dropped along with what's been written in this commit, which is
+		// done under lock so concurrent writers see the file truncated once
+		diffInfo.SizeBytes = subtractSize(diffInfo.SizeBytes, appendSize(_append))
+		_append.BlockRefs = nil
+		_append.Handles = make(map[string]*pfs.BlockRefs)
+		_append.Delete = true
+	}
 	if handle == "" {
-		_append.BlockRefs = append(_append.BlockRefs, blockRefs.BlockRef...)
+		_append.BlockRefs = append(_append.BlockRefs, blockRefs...)
 	} else {
 		handleBlockRefs, ok := _append.Handles[handle]
 		if !ok {
 			handleBlockRefs = &pfs.BlockRefs{}
 			_append.Handles[handle] = handleBlockRefs
 		}
-		handleBlockRefs.BlockRef = append(handleBlockRefs.BlockRef, blockRefs.BlockRef...)
-	}
-	for _, blockRef := range blockRefs.BlockRef {
-		diffInfo.SizeBytes += blockRef.Range.Upper - blockRef.Range.Lower
+		handleBlockRefs.BlockRef = append(handleBlockRefs.BlockRef, blockRefs...)
 	}
+	diffInfo.SizeBytes += size
 	return nil
 }
 
@@ -473,7 +818,7 @@ func (d *driver) MakeDirectory(file *pfs.File, shard uint64) (retErr error) {
 	if diffInfo.Finished != nil {
 		return fmt.Errorf("commit %s/%s has already been finished", canonicalCommit.Repo.Name, canonicalCommit.ID)
 	}
-	addDirs(diffInfo, file)
+	d.addDirs(diffInfo, file, shard)
 	_append, ok := diffInfo.Appends[path.Clean(file.Path)]
 	if !ok {
 		_append = &pfs.Append{}
@@ -512,14 +857,55 @@ func (d *driver) GetFile(file *pfs.File, filterShard *pfs.Shard, offset int64, s
 	return newFileReader(blockClient, blockRefs, offset, size), nil
 }
 
-func (d *driver) InspectFile(file *pfs.File, filterShard *pfs.Shard, from *pfs.Commit, shard uint64, unsafe bool) (*pfs.FileInfo, error) {
+func (d *driver) GetFileBlockRefs(file *pfs.File, from *pfs.Commit, shard uint64, unsafe bool) ([]*pfs.BlockRef, error) {
 	d.lock.RLock()
 	defer d.lock.RUnlock()
-	fileInfo, _, err := d.inspectFile(file, filterShard, shard, from, false, unsafe)
-	return fileInfo, err
+	fileInfo, blockRefs, err := d.inspectFile(file, nil, shard, from, false, unsafe)
+	if err != nil {
+		return nil, err
+	}
+	if fileInfo.FileType == pfs.FileType_FILE_TYPE_DIR {
+		return nil, fmt.Errorf("file %s/%s/%s is directory", file.Commit.Repo.Name, file.Commit.ID, file.Path)
+	}
+	return blockRefs, nil
+}
+
+func (d *driver) InspectFile(file *pfs.File, filterShard *pfs.Shard, from *pfs.Commit, shard uint64, unsafe bool, sha256 bool) (*pfs.FileInfo, error) {
+	d.lock.RLock()
+	fileInfo, blockRefs, err := d.inspectFile(file, filterShard, shard, from, false, unsafe)
+	d.lock.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+	if sha256 && fileInfo.FileType == pfs.FileType_FILE_TYPE_REGULAR {
+		// the file is read without the lock held, as in GetFile
+		if fileInfo.Sha256, err = d.fileSHA256(fileInfo.Digest, blockRefs); err != nil {
+			return nil, err
+		}
+	}
+	return fileInfo, nil
+}
+
+func (d *driver) ListFile(file *pfs.File, filterShard *pfs.Shard, from *pfs.Commit, shard uint64, recurse bool, unsafe bool, pageToken string, limit uint64) ([]*pfs.FileInfo, error) {
+	stream, err := d.ListFileStream(file, filterShard, from, shard, recurse, unsafe, pageToken)
+	if err != nil {
+		return nil, err
+	}
+	var result []*pfs.FileInfo
+	for limit == 0 || uint64(len(result)) < limit {
+		fileInfo, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, fileInfo)
+	}
+	return result, nil
 }
 
-func (d *driver) ListFile(file *pfs.File, filterShard *pfs.Shard, from *pfs.Commit, shard uint64, recurse bool, unsafe bool) ([]*pfs.FileInfo, error) {
+func (d *driver) ListFileStream(file *pfs.File, filterShard *pfs.Shard, from *pfs.Commit, shard uint64, recurse bool, unsafe bool, pageToken string) (pfsserver.FileInfoStream, error) {
 	d.lock.RLock()
 	defer d.lock.RUnlock()
 	fileInfo, _, err := d.inspectFile(file, filterShard, shard, from, false, unsafe)
@@ -527,20 +913,215 @@ func (d *driver) ListFile(file *pfs.File, filterShard *pfs.Shard, from *pfs.Comm
 		return nil, err
 	}
 	if fileInfo.FileType == pfs.FileType_FILE_TYPE_REGULAR {
-		return []*pfs.FileInfo{fileInfo}, nil
+		return pfsserver.NewFileInfoStream(pfsserver.PageFileInfos([]*pfs.FileInfo{fileInfo}, pageToken, 0)), nil
+	}
+	// the children are paged before they're inspected since inspecting is
+	// the expensive part
+	children := append([]*pfs.File(nil), fileInfo.Children...)
+	sort.Sort(sortFiles(children))
+	start := sort.Search(len(children), func(i int) bool {
+		return children[i].Path > pageToken
+	})
+	return &childFileInfoStream{
+		driver:      d,
+		children:    children[start:],
+		filterShard: filterShard,
+		from:        from,
+		shard:       shard,
+		recurse:     recurse,
+		unsafe:      unsafe,
+	}, nil
+}
+
+// childFileInfoStream inspects children, which are sorted by path, as
+// they're received.
+type childFileInfoStream struct {
+	driver      *driver
+	children    []*pfs.File
+	filterShard *pfs.Shard
+	from        *pfs.Commit
+	shard       uint64
+	recurse     bool
+	unsafe      bool
+}
+
+func (s *childFileInfoStream) Recv() (*pfs.FileInfo, error) {
+	s.driver.lock.RLock()
+	defer s.driver.lock.RUnlock()
+	for len(s.children) > 0 {
+		child := s.children[0]
+		s.children = s.children[1:]
+		fileInfo, _, err := s.driver.inspectFile(child, s.filterShard, s.shard, s.from, s.recurse, s.unsafe)
+		if err == pfsserver.ErrFileNotFound {
+			// how can a listed child return not found?
+			// regular files without any blocks in this shard count as not found
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return fileInfo, nil
 	}
+	return nil, io.EOF
+}
+
+func (d *driver) GlobFile(file *pfs.File, filterShard *pfs.Shard, from *pfs.Commit, shard uint64, recurse bool, unsafe bool) ([]*pfs.FileInfo, error) {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	return d.globFile(file, filterShard, from, shard, recurse, unsafe)
+}
+
+// globFile returns the files and directories in shard which match the glob
+// file.Path. It walks down from the pattern's prefix, skipping directories
+// which can't contain matches.
+func (d *driver) globFile(file *pfs.File, filterShard *pfs.Shard, from *pfs.Commit, shard uint64, recurse bool, unsafe bool) ([]*pfs.FileInfo, error) {
 	var result []*pfs.FileInfo
-	for _, child := range fileInfo.Children {
-		fileInfo, _, err := d.inspectFile(child, filterShard, shard, from, recurse, unsafe)
+	var walk func(filePath string) error
+	walk = func(filePath string) error {
+		fileInfo, _, err := d.inspectFile(&pfs.File{
+			Commit: file.Commit,
+			Path:   filePath,
+		}, filterShard, shard, from, false, unsafe)
+		if err == pfsserver.ErrFileNotFound {
+			// regular files without any blocks in this shard count as not found
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		// the root directory never matches
+		match := false
+		if filePath != "" {
+			match, err = pfsserver.GlobMatch(file.Path, filePath)
+			if err != nil {
+				return err
+			}
+		}
+		if match {
+			if recurse && fileInfo.FileType == pfs.FileType_FILE_TYPE_DIR {
+				// inspect it again to get the sizes of its children
+				if fileInfo, _, err = d.inspectFile(fileInfo.File, filterShard, shard, from, true, unsafe); err != nil {
+					return err
+				}
+			}
+			result = append(result, fileInfo)
+		}
+		if fileInfo.FileType != pfs.FileType_FILE_TYPE_DIR {
+			return nil
+		}
+		under, err := pfsserver.GlobMatchUnder(file.Path, filePath)
+		if err != nil || !under {
+			return err
+		}
+		for _, child := range fileInfo.Children {
+			if err := walk(child.Path); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(pfsserver.GlobPrefix(file.Path)); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (d *driver) DiffCommit(from *pfs.Commit, to *pfs.Commit, shard uint64) ([]*pfs.FileChange, error) {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	canonicalTo, err := d.canonicalCommit(to)
+	if err != nil {
+		return nil, err
+	}
+	var canonicalFrom *pfs.Commit
+	if from != nil {
+		if from.Repo.Name != to.Repo.Name {
+			return nil, fmt.Errorf("cannot diff commits from different repos %s and %s", from.Repo.Name, to.Repo.Name)
+		}
+		canonicalFrom, err = d.canonicalCommit(from)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := d.diffs.get(client.NewDiff(canonicalFrom.Repo.Name, canonicalFrom.ID, shard)); !ok {
+			return nil, fmt.Errorf("commit %s/%s not found", canonicalFrom.Repo.Name, canonicalFrom.ID)
+		}
+	}
+	// paths are the files appended to, or deleted, in the ancestors of to
+	// which aren't ancestors of from, merge commits have history on both of
+	// their parents
+	dag := d.dags[canonicalTo.Repo.Name]
+	var fromAncestors []string
+	if canonicalFrom != nil {
+		fromAncestors = dag.Ancestors(canonicalFrom.ID, nil)
+		isAncestor := false
+		for _, commitID := range dag.Ancestors(canonicalTo.ID, nil) {
+			if commitID == canonicalFrom.ID {
+				isAncestor = true
+				break
+			}
+		}
+		if !isAncestor {
+			return nil, fmt.Errorf("commit %s/%s is not an ancestor of %s/%s", canonicalFrom.Repo.Name, canonicalFrom.ID, canonicalTo.Repo.Name, canonicalTo.ID)
+		}
+	}
+	paths := make(map[string]bool)
+	for _, commitID := range dag.Ancestors(canonicalTo.ID, fromAncestors) {
+		diffInfo, ok := d.diffs.get(client.NewDiff(canonicalTo.Repo.Name, commitID, shard))
+		if !ok {
+			return nil, fmt.Errorf("commit %s/%s not found", canonicalTo.Repo.Name, commitID)
+		}
+		if diffInfo.Finished == nil {
+			return nil, fmt.Errorf("commit %s/%s has not been finished", canonicalTo.Repo.Name, commitID)
+		}
+		for filePath := range diffInfo.Appends {
+			paths[filePath] = true
+		}
+	}
+	// A directory has appends in every shard with files in it so no shard
+	// can tell whether it was added or deleted, we only report regular files.
+	regularFile := func(filePath string, commit *pfs.Commit) (*pfs.FileInfo, []*pfs.BlockRef, error) {
+		if commit == nil {
+			return nil, nil, nil
+		}
+		fileInfo, blockRefs, err := d.inspectFile(client.NewFile(commit.Repo.Name, commit.ID, filePath), nil, shard, nil, false, false)
 		if err != nil && err != pfsserver.ErrFileNotFound {
+			return nil, nil, err
+		}
+		if err == pfsserver.ErrFileNotFound || fileInfo.FileType != pfs.FileType_FILE_TYPE_REGULAR {
+			return nil, nil, nil
+		}
+		return fileInfo, blockRefs, nil
+	}
+	var result []*pfs.FileChange
+	for filePath := range paths {
+		fromFileInfo, fromBlockRefs, err := regularFile(filePath, canonicalFrom)
+		if err != nil {
 			return nil, err
 		}
-		if err == pfsserver.ErrFileNotFound {
-			// how can a listed child return not found?
-			// regular files without any blocks in this shard count as not found
+		toFileInfo, toBlockRefs, err := regularFile(filePath, canonicalTo)
+		if err != nil {
+			return nil, err
+		}
+		// merges rewrite files without changing them
+		if fromFileInfo != nil && toFileInfo != nil &&
+			len(fromBlockRefs) == len(toBlockRefs) && hasBlockRefPrefix(toBlockRefs, fromBlockRefs) {
 			continue
 		}
-		result = append(result, fileInfo)
+		fileChange := &pfs.FileChange{File: client.NewFile(to.Repo.Name, to.ID, filePath)}
+		switch {
+		case fromFileInfo == nil && toFileInfo != nil:
+			fileChange.ChangeType = pfs.ChangeType_CHANGE_TYPE_ADDED
+			fileChange.SizeDeltaBytes = int64(toFileInfo.SizeBytes)
+		case fromFileInfo != nil && toFileInfo == nil:
+			fileChange.ChangeType = pfs.ChangeType_CHANGE_TYPE_DELETED
+			fileChange.SizeDeltaBytes = -int64(fromFileInfo.SizeBytes)
+		case fromFileInfo != nil && toFileInfo != nil:
+			fileChange.ChangeType = pfs.ChangeType_CHANGE_TYPE_MODIFIED
+			fileChange.SizeDeltaBytes = int64(toFileInfo.SizeBytes) - int64(fromFileInfo.SizeBytes)
+		default:
+			continue
+		}
+		result = append(result, fileChange)
 	}
 	return result, nil
 }
@@ -557,7 +1138,7 @@ func (d *driver) DeleteFile(file *pfs.File, shard uint64) error {
 	d.lock.RUnlock()
 
 	if fileInfo.FileType == pfs.FileType_FILE_TYPE_DIR {
-		fileInfos, err := d.ListFile(file, nil, nil, shard, false, false)
+		fileInfos, err := d.ListFile(file, nil, nil, shard, false, false, "", 0)
 		if err != nil {
 			return err
 		}
@@ -597,7 +1178,7 @@ func (d *driver) deleteFile(file *pfs.File, shard uint64) error {
 		diffInfo.Appends[cleanPath] = &pfs.Append{Handles: make(map[string]*pfs.BlockRefs)}
 	}
 	diffInfo.Appends[cleanPath].Delete = true
-	deleteFromDir(diffInfo, file)
+	d.deleteFromDir(diffInfo, file, shard)
 
 	return nil
 }
@@ -607,12 +1188,39 @@ func (d *driver) AddShard(shard uint64) error {
 	if err != nil {
 		return err
 	}
-	listDiffClient, err := blockClient.ListDiff(context.Background(), &pfs.ListDiffRequest{Shard: shard})
+	diffInfos := make(diffMap)
+	addDiffInfo := func(diffInfo *pfs.DiffInfo) error {
+		if diffInfo.Diff == nil || diffInfo.Diff.Commit == nil || diffInfo.Diff.Commit.Repo == nil {
+			return fmt.Errorf("broken diff info: %v; this is likely a bug", diffInfo)
+		}
+		repoName := diffInfo.Diff.Commit.Repo.Name
+		if _, ok := diffInfos[repoName]; !ok {
+			diffInfos[repoName] = make(map[uint64]map[string]*pfs.DiffInfo)
+		}
+		for _, _append := range diffInfo.Appends {
+			// empty Children, which mark directories, don't survive being
+			// persisted; an append with nothing else in it is a directory
+			if len(_append.BlockRefs) == 0 && len(_append.Handles) == 0 && _append.Children == nil && !_append.Delete {
+				_append.Children = make(map[string]bool)
+			}
+		}
+		// diffs listed after the snapshot replace its copies of them
+		diffInfos.pop(diffInfo.Diff)
+		return diffInfos.insert(diffInfo)
+	}
+	// we start from the latest snapshot, if there is one, and only list the
+	// diffs written after it
+	modifiedAfter, err := d.readShardSnapshot(blockClient, shard, addDiffInfo)
+	if err != nil {
+		return err
+	}
+	listDiffClient, err := blockClient.ListDiff(context.Background(), &pfs.ListDiffRequest{
+		Shard:         shard,
+		ModifiedAfter: modifiedAfter,
+	})
 	if err != nil {
 		return err
 	}
-	diffInfos := make(diffMap)
-	dags := make(map[string]*dag.DAG)
 	for {
 		diffInfo, err := listDiffClient.Recv()
 		if err != nil && err != io.EOF {
@@ -621,19 +1229,17 @@ func (d *driver) AddShard(shard uint64) error {
 		if err == io.EOF {
 			break
 		}
-		if diffInfo.Diff == nil || diffInfo.Diff.Commit == nil || diffInfo.Diff.Commit.Repo == nil {
-			return fmt.Errorf("broken diff info: %v; this is likely a bug", diffInfo)
-		}
-		repoName := diffInfo.Diff.Commit.Repo.Name
-		if _, ok := diffInfos[repoName]; !ok {
-			diffInfos[repoName] = make(map[uint64]map[string]*pfs.DiffInfo)
-			dags[repoName] = dag.NewDAG(nil)
-		}
-		updateDAG(diffInfo, dags[repoName])
-		if err := diffInfos.insert(diffInfo); err != nil {
+		if err := addDiffInfo(diffInfo); err != nil {
 			return err
 		}
 	}
+	dags := make(map[string]*dag.DAG)
+	for repoName, shardMap := range diffInfos {
+		dags[repoName] = dag.NewDAG(nil)
+		for _, diffInfo := range shardMap[shard] {
+			updateDAG(diffInfo, dags[repoName])
+		}
+	}
 	for repoName, dag := range dags {
 		if ghosts := dag.Ghosts(); len(ghosts) != 0 {
 			return fmt.Errorf("error adding shard %d, repo %s has ghost commits: %+v", shard, repoName, ghosts)
@@ -641,6 +1247,7 @@ func (d *driver) AddShard(shard uint64) error {
 	}
 	d.lock.Lock()
 	defer d.lock.Unlock()
+	d.shards[shard] = true
 	for repoName, dag := range dags {
 		for _, commitID := range dag.Sorted() {
 			d.createRepoState(client.NewRepo(repoName))
@@ -655,6 +1262,13 @@ func (d *driver) AddShard(shard uint64) error {
 				return fmt.Errorf("diff %s/%s/%d not found; this is likely a bug", repoName, commitID, shard)
 			}
 		}
+		if rootDiffInfo, ok := diffInfos.get(client.NewDiff(repoName, "", shard)); ok {
+			d.restoreBranches(repoName, rootDiffInfo)
+		}
+	}
+	if d.options.CheckpointInterval > 0 {
+		// resumes compactions of the shard interrupted by a crash
+		go d.compactShards([]uint64{shard})
 	}
 	return nil
 }
@@ -665,6 +1279,9 @@ func (d *driver) DeleteShard(shard uint64) error {
 	for _, shardMap := range d.diffs {
 		delete(shardMap, shard)
 	}
+	delete(d.shards, shard)
+	delete(d.finishedSinceSnapshot, shard)
+	delete(d.finishedSinceCompaction, shard)
 	return nil
 }
 
@@ -703,6 +1320,7 @@ func (d *driver) inspectRepo(repo *pfs.Repo, shards map[uint64]bool) (*pfs.RepoI
 			diffInfo := diffInfo
 			if diffInfo.Diff.Commit.ID == "" {
 				result.Created = diffInfo.Finished
+				result.Quota = diffInfo.Quota
 			}
 			result.SizeBytes += diffInfo.SizeBytes
 		}
@@ -731,6 +1349,11 @@ func (d *driver) inspectCommit(commit *pfs.Commit, shards map[uint64]bool) (*pfs
 		}
 		commitInfo.Branch = diffInfo.Branch
 		commitInfo.ParentCommit = diffInfo.ParentCommit
+		commitInfo.MergeParent = diffInfo.MergeParent
+		commitInfo.Provenance = diffInfo.Provenance
+		commitInfo.Message = diffInfo.Message
+		commitInfo.Labels = diffInfo.Labels
+		commitInfo.FileCount = fileCount(diffInfo)
 		commitInfo.Started = diffInfo.Started
 		commitInfo.Finished = diffInfo.Finished
 		commitInfo.SizeBytes = diffInfo.SizeBytes
@@ -769,10 +1392,12 @@ func (d *driver) getFileType(file *pfs.File, shard uint64) (pfs.FileType, error)
 			return pfs.FileType_FILE_TYPE_NONE, fmt.Errorf("diff %s/%s/%d not found", commit.Repo.Name, commit.ID, shard)
 		}
 		if _append, ok := diffInfo.Appends[path.Clean(file.Path)]; ok {
-			if _append.Delete {
-				break
-			} else if len(_append.BlockRefs) > 0 || len(_append.Handles) > 0 {
+			// an append which deletes the file can also replace it, merges
+			// write those
+			if len(_append.BlockRefs) > 0 || len(_append.Handles) > 0 {
 				return pfs.FileType_FILE_TYPE_REGULAR, nil
+			} else if _append.Delete {
+				break
 			} else {
 				return pfs.FileType_FILE_TYPE_DIR, nil
 			}
@@ -791,6 +1416,54 @@ func (d *driver) inspectFile(file *pfs.File, filterShard *pfs.Shard, shard uint6
 	var blockRefs []*pfs.BlockRef
 	children := make(map[string]bool)
 	deletedChildren := make(map[string]bool)
+	addBlockRefs := func(appendBlockRefs []*pfs.BlockRef) error {
+		if fileInfo.FileType == pfs.FileType_FILE_TYPE_DIR {
+			return fmt.Errorf("mixed dir and regular file %s/%s/%s, (this is likely a bug)", file.Commit.Repo.Name, file.Commit.ID, file.Path)
+		}
+		if fileInfo.FileType == pfs.FileType_FILE_TYPE_NONE {
+			// the first time we find out it's a regular file we check
+			// the file shard, dirs get returned regardless of sharding,
+			// since they might have children from any shard
+			if !pfsserver.FileInShard(filterShard, file) {
+				return pfsserver.ErrFileNotFound
+			}
+		}
+		fileInfo.FileType = pfs.FileType_FILE_TYPE_REGULAR
+		filtered := filterBlockRefs(filterShard, appendBlockRefs)
+		blockRefs = append(filtered, blockRefs...)
+		for _, blockRef := range filtered {
+			fileInfo.SizeBytes += (blockRef.Range.Upper - blockRef.Range.Lower)
+		}
+		return nil
+	}
+	// addChild adds a child which was added to the directory in commit
+	addChild := func(child string, commit *pfs.Commit) error {
+		if !children[child] && !deletedChildren[child] {
+			fileInfo.Children = append(
+				fileInfo.Children,
+				client.NewFile(commit.Repo.Name, commit.ID, child),
+			)
+			if recurse {
+				childFileInfo, _, err := d.inspectFile(&pfs.File{
+					Commit: file.Commit,
+					Path:   child,
+				}, filterShard, shard, from, recurse, unsafe)
+				if err != nil {
+					return err
+				}
+				fileInfo.SizeBytes += childFileInfo.SizeBytes
+			}
+		}
+		children[child] = true
+		return nil
+	}
+	setDir := func() error {
+		if fileInfo.FileType == pfs.FileType_FILE_TYPE_REGULAR {
+			return fmt.Errorf("mixed dir and regular file %s/%s/%s, (this is likely a bug)", file.Commit.Repo.Name, file.Commit.ID, file.Path)
+		}
+		fileInfo.FileType = pfs.FileType_FILE_TYPE_DIR
+		return nil
+	}
 	commit, err := d.canonicalCommit(file.Commit)
 	if err != nil {
 		return nil, nil, err
@@ -809,71 +1482,66 @@ func (d *driver) inspectFile(file *pfs.File, filterShard *pfs.Shard, shard uint6
 				return nil, nil, fmt.Errorf("the append for %s does not correspond to a file or a directory, and does not signify deletion; this is likely a bug", path.Clean(file.Path))
 			}
 
-			if len(_append.BlockRefs) > 0 || len(_append.Handles) > 0 {
-				if fileInfo.FileType == pfs.FileType_FILE_TYPE_DIR {
-					return nil, nil,
-						fmt.Errorf("mixed dir and regular file %s/%s/%s, (this is likely a bug)", file.Commit.Repo.Name, file.Commit.ID, file.Path)
-				}
-				if fileInfo.FileType == pfs.FileType_FILE_TYPE_NONE {
-					// the first time we find out it's a regular file we check
-					// the file shard, dirs get returned regardless of sharding,
-					// since they might have children from any shard
-					if !pfsserver.FileInShard(filterShard, file) {
-						return nil, nil, pfsserver.ErrFileNotFound
+			// A checkpoint replaces this append and all the ones before it,
+			// they can't be used when reading from a commit since we'd need
+			// to know whether from is among the commits it covers.
+			if checkpoint, ok := diffInfo.Checkpoints[path.Clean(file.Path)]; ok && from == nil {
+				switch checkpoint.FileType {
+				case pfs.FileType_FILE_TYPE_REGULAR:
+					if err := addBlockRefs(checkpoint.BlockRefs); err != nil {
+						return nil, nil, err
 					}
+				case pfs.FileType_FILE_TYPE_DIR:
+					if err := setDir(); err != nil {
+						return nil, nil, err
+					}
+					for child, childCommit := range checkpoint.Children {
+						if err := addChild(child, childCommit); err != nil {
+							return nil, nil, err
+						}
+					}
+				default:
+					return nil, nil, fmt.Errorf("checkpoint for %s has file type %s; this is likely a bug", path.Clean(file.Path), checkpoint.FileType)
 				}
-				fileInfo.FileType = pfs.FileType_FILE_TYPE_REGULAR
-				filtered := filterBlockRefs(filterShard, _append.BlockRefs)
-				for _, handleBlockRefs := range _append.Handles {
-					filtered = append(filtered, filterBlockRefs(filterShard, handleBlockRefs.BlockRef)...)
+				if fileInfo.CommitModified == nil {
+					fileInfo.CommitModified = commit
+					fileInfo.Modified = diffInfo.Finished
 				}
-				blockRefs = append(filtered, blockRefs...)
-				for _, blockRef := range filtered {
-					fileInfo.SizeBytes += (blockRef.Range.Upper - blockRef.Range.Lower)
+				break
+			}
+
+			if len(_append.BlockRefs) > 0 || len(_append.Handles) > 0 {
+				appendBlockRefs := append([]*pfs.BlockRef(nil), _append.BlockRefs...)
+				appendBlockRefs = append(appendBlockRefs, handleBlockRefs(_append)...)
+				if err := addBlockRefs(appendBlockRefs); err != nil {
+					return nil, nil, err
 				}
 			} else if _append.Children != nil {
 				// With non-nil Children, this Append is for a directory, even if
 				// Children is empty.  This is because we sometimes
 				// have an Append with an empty children just to signify that
 				// this is a directory.
-				if fileInfo.FileType == pfs.FileType_FILE_TYPE_REGULAR {
-					return nil, nil,
-						fmt.Errorf("mixed dir and regular file %s/%s/%s, (this is likely a bug)", file.Commit.Repo.Name, file.Commit.ID, file.Path)
+				if err := setDir(); err != nil {
+					return nil, nil, err
 				}
-				fileInfo.FileType = pfs.FileType_FILE_TYPE_DIR
 				for child, add := range _append.Children {
 					if !add {
 						deletedChildren[child] = true
 						continue
 					}
-
-					if !children[child] && !deletedChildren[child] {
-						fileInfo.Children = append(
-							fileInfo.Children,
-							client.NewFile(commit.Repo.Name, commit.ID, child),
-						)
-						if recurse {
-							childFileInfo, _, err := d.inspectFile(&pfs.File{
-								Commit: file.Commit,
-								Path:   child,
-							}, filterShard, shard, from, recurse, unsafe)
-							if err != nil {
-								return nil, nil, err
-							}
-							fileInfo.SizeBytes += childFileInfo.SizeBytes
-						}
+					if err := addChild(child, commit); err != nil {
+						return nil, nil, err
 					}
-					children[child] = true
 				}
 			}
-			// If Delete is true, then everything before this commit is irrelevant
-			if _append.Delete {
-				break
-			}
 			if fileInfo.CommitModified == nil {
 				fileInfo.CommitModified = commit
 				fileInfo.Modified = diffInfo.Finished
 			}
+			// If Delete is true, then everything before this commit is irrelevant
+			if _append.Delete {
+				break
+			}
 			commit = _append.LastRef
 			continue
 		}
@@ -882,6 +1550,11 @@ func (d *driver) inspectFile(file *pfs.File, filterShard *pfs.Shard, shard uint6
 	if fileInfo.FileType == pfs.FileType_FILE_TYPE_NONE {
 		return nil, nil, pfsserver.ErrFileNotFound
 	}
+	// reads from a commit or of a block shard only see part of the file so
+	// they don't get a digest
+	if fileInfo.FileType == pfs.FileType_FILE_TYPE_REGULAR && from == nil && (filterShard == nil || filterShard.BlockModulus <= 1) {
+		fileInfo.Digest = fileDigest(blockRefs)
+	}
 	return fileInfo, blockRefs, nil
 }
 
@@ -970,25 +1643,54 @@ func (d *driver) insertDiffInfo(diffInfo *pfs.DiffInfo) error {
 }
 
 func updateDAG(diffInfo *pfs.DiffInfo, dag *dag.DAG) {
-	if diffInfo.ParentCommit != nil {
-		dag.NewNode(diffInfo.Diff.Commit.ID, []string{diffInfo.ParentCommit.ID})
-	} else {
-		dag.NewNode(diffInfo.Diff.Commit.ID, nil)
+	var parents []string
+	for _, parent := range []*pfs.Commit{diffInfo.ParentCommit, diffInfo.MergeParent} {
+		if parent != nil {
+			parents = append(parents, parent.ID)
+		}
+	}
+	dag.NewNode(diffInfo.Diff.Commit.ID, parents)
+}
+
+// reparent removes the references diffInfo has to the deleted commit, parent
+// and last refs are replaced with the deleted commit's own. It returns true
+// if diffInfo was modified.
+func reparent(diffInfo *pfs.DiffInfo, deleted *pfs.DiffInfo) bool {
+	modified := false
+	deletedID := deleted.Diff.Commit.ID
+	if diffInfo.ParentCommit != nil && diffInfo.ParentCommit.ID == deletedID {
+		diffInfo.ParentCommit = deleted.ParentCommit
+		if diffInfo.MergeParent == nil {
+			// the deleted commit's merge parent stays in the history
+			diffInfo.MergeParent = deleted.MergeParent
+		}
+		modified = true
+	}
+	if diffInfo.MergeParent != nil && diffInfo.MergeParent.ID == deletedID {
+		diffInfo.MergeParent = deleted.ParentCommit
+		modified = true
+	}
+	if diffInfo.MergeParent != nil && (diffInfo.ParentCommit == nil || diffInfo.MergeParent.ID == diffInfo.ParentCommit.ID) {
+		diffInfo.MergeParent = nil
+	}
+	for filePath, _append := range diffInfo.Appends {
+		if _append.LastRef == nil || _append.LastRef.ID != deletedID {
+			continue
+		}
+		_append.LastRef = nil
+		if deletedAppend, ok := deleted.Appends[filePath]; ok {
+			_append.LastRef = deletedAppend.LastRef
+		}
+		modified = true
 	}
+	return modified
 }
 
-func addDirs(diffInfo *pfs.DiffInfo, child *pfs.File) {
+func (d *driver) addDirs(diffInfo *pfs.DiffInfo, child *pfs.File, shard uint64) {
 	childPath := child.Path
 	dirPath := path.Dir(childPath)
 	for {
-		_append, ok := diffInfo.Appends[dirPath]
-		if !ok {
-			_append = &pfs.Append{}
-			diffInfo.Appends[dirPath] = _append
-		}
-		if _append.Children == nil {
-			_append.Children = make(map[string]bool)
-		}
+		_append := d.dirAppend(diffInfo, dirPath, shard)
 		_append.Children[childPath] = true
 		if dirPath == "." {
 			break
@@ -998,25 +1700,39 @@ func addDirs(diffInfo *pfs.DiffInfo, child *pfs.File) {
 	}
 }
 
-func deleteFromDir(diffInfo *pfs.DiffInfo, child *pfs.File) {
+func (d *driver) deleteFromDir(diffInfo *pfs.DiffInfo, child *pfs.File, shard uint64) {
 	childPath := child.Path
 	dirPath := path.Dir(childPath)
 
+	_append := d.dirAppend(diffInfo, dirPath, shard)
+	// Basically, we only set the entry to false if it's not been
+	// set to true.  If it's been set to true, that means that there
+	// is a PutFile operation in this commit for this very same file,
+	// so we don't want to remove the file from the directory.
+	if !_append.Children[childPath] {
+		_append.Children[childPath] = false
+	}
+}
+
+// dirAppend returns diffInfo's append for the directory dirPath, creating it
+// if needed. New appends reference the directory's last append in the parent
+// commit so that the children added before this commit are still listed.
+func (d *driver) dirAppend(diffInfo *pfs.DiffInfo, dirPath string, shard uint64) *pfs.Append {
 	_append, ok := diffInfo.Appends[dirPath]
 	if !ok {
 		_append = &pfs.Append{}
+		if diffInfo.ParentCommit != nil {
+			_append.LastRef = d.lastRef(
+				client.NewFile(diffInfo.ParentCommit.Repo.Name, diffInfo.ParentCommit.ID, dirPath),
+				shard,
+			)
+		}
 		diffInfo.Appends[dirPath] = _append
 	}
 	if _append.Children == nil {
 		_append.Children = make(map[string]bool)
 	}
-	// Basically, we only set the entry to false if it's not been
-	// set to true.  If it's been set to true, that means that there
-	// is a PutFile operation in this commit for this very same file,
-	// so we don't want to remove the file from the directory.
-	if !_append.Children[childPath] {
-		_append.Children[childPath] = false
-	}
+	return _append
 }
 
 type fileReader struct {
@@ -1131,8 +1847,35 @@ func (d diffMap) pop(diff *pfs.Diff) *pfs.DiffInfo {
 }
 
 func coalesceHandles(_append *pfs.Append) {
-	for _, blockRefs := range _append.Handles {
-		_append.BlockRefs = append(_append.BlockRefs, blockRefs.BlockRef...)
-	}
+	_append.BlockRefs = append(_append.BlockRefs, handleBlockRefs(_append)...)
 	_append.Handles = nil
 }
+
+// handleBlockRefs returns the blocks written to _append's handles, in order
+// of handle so that reads of open commits are repeatable.
+func handleBlockRefs(_append *pfs.Append) []*pfs.BlockRef {
+	var handles []string
+	for handle := range _append.Handles {
+		handles = append(handles, handle)
+	}
+	sort.Strings(handles)
+	var result []*pfs.BlockRef
+	for _, handle := range handles {
+		result = append(result, _append.Handles[handle].BlockRef...)
+	}
+	return result
+}
+
+type sortFiles []*pfs.File
+
+func (a sortFiles) Len() int {
+	return len(a)
+}
+
+func (a sortFiles) Less(i, j int) bool {
+	return a[i].Path < a[j].Path
+}
+
+func (a sortFiles) Swap(i, j int) {
+	a[i], a[j] = a[j], a[i]
+}
@@ -0,0 +1,267 @@
+package drive
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pfs"
+	pfsserver "github.com/pachyderm/pachyderm/src/server/pfs"
+	"github.com/pachyderm/pachyderm/src/server/pkg/dag"
+)
+
+// fileState is the content of a file in a commit, as far as merging is
+// concerned.
+type fileState struct {
+	fileType  pfs.FileType
+	blockRefs []*pfs.BlockRef
+	children  map[string]bool
+}
+
+func (s *fileState) equal(other *fileState) bool {
+	if s.fileType != other.fileType {
+		return false
+	}
+	switch s.fileType {
+	case pfs.FileType_FILE_TYPE_REGULAR:
+		return len(s.blockRefs) == len(other.blockRefs) && hasBlockRefPrefix(s.blockRefs, other.blockRefs)
+	case pfs.FileType_FILE_TYPE_DIR:
+		if len(s.children) != len(other.children) {
+			return false
+		}
+		for child := range s.children {
+			if !other.children[child] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// hasBlockRefPrefix returns true if blockRefs starts with prefix.
+func hasBlockRefPrefix(blockRefs []*pfs.BlockRef, prefix []*pfs.BlockRef) bool {
+	if len(prefix) > len(blockRefs) {
+		return false
+	}
+	for i, blockRef := range prefix {
+		if blockRef.Block.Hash != blockRefs[i].Block.Hash ||
+			blockRef.Range.Lower != blockRefs[i].Range.Lower ||
+			blockRef.Range.Upper != blockRefs[i].Range.Upper {
+			return false
+		}
+	}
+	return true
+}
+
+// mergeBase returns the common ancestor of a and b which isn't an ancestor of
+// another common ancestor, or "" if they have none. Criss-cross merges can
+// leave several such ancestors, the smallest ID is used so that every shard
+// picks the same one.
+func mergeBase(dag *dag.DAG, a string, b string) string {
+	aAncestors := make(map[string]bool)
+	for _, commitID := range dag.Ancestors(a, nil) {
+		aAncestors[commitID] = true
+	}
+	common := make(map[string]bool)
+	for _, commitID := range dag.Ancestors(b, nil) {
+		if aAncestors[commitID] {
+			common[commitID] = true
+		}
+	}
+	// children are visited before their parents, so common ancestors of the
+	// commits we pick are covered by the time we reach them
+	sorted := dag.Sorted()
+	covered := make(map[string]bool)
+	result := ""
+	for i := len(sorted) - 1; i >= 0; i-- {
+		commitID := sorted[i]
+		if !common[commitID] || covered[commitID] {
+			continue
+		}
+		if result == "" || commitID < result {
+			result = commitID
+		}
+		for _, ancestor := range dag.Ancestors(commitID, nil) {
+			covered[ancestor] = true
+		}
+	}
+	return result
+}
+
+// mergeStrategy returns the strategy of the policy with the longest path
+// matching filePath, conflicts fail if no policy matches.
+func mergeStrategy(policies []*pfs.MergePolicy, filePath string) pfs.MergeStrategy {
+	filePath = path.Clean("/" + filePath)
+	strategy := pfs.MergeStrategy_MERGE_STRATEGY_FAIL
+	longest := -1
+	for _, policy := range policies {
+		policyPath := path.Clean("/" + policy.Path)
+		if policyPath != "/" && policyPath != filePath && !strings.HasPrefix(filePath, policyPath+"/") {
+			continue
+		}
+		if len(policyPath) > longest {
+			strategy = policy.Strategy
+			longest = len(policyPath)
+		}
+	}
+	return strategy
+}
+
+// fileState returns the state of filePath in commit on shard, commit may be
+// nil in which case the file doesn't exist.
+func (d *driver) fileState(commit *pfs.Commit, filePath string, shard uint64) (*fileState, error) {
+	if commit == nil {
+		return &fileState{}, nil
+	}
+	fileInfo, blockRefs, err := d.inspectFile(client.NewFile(commit.Repo.Name, commit.ID, filePath), nil, shard, nil, false, false)
+	if err != nil && err != pfsserver.ErrFileNotFound {
+		return nil, err
+	}
+	if err == pfsserver.ErrFileNotFound {
+		return &fileState{}, nil
+	}
+	result := &fileState{
+		fileType:  fileInfo.FileType,
+		blockRefs: blockRefs,
+	}
+	if fileInfo.FileType == pfs.FileType_FILE_TYPE_DIR {
+		result.children = make(map[string]bool)
+		for _, child := range fileInfo.Children {
+			result.children[child.Path] = true
+		}
+	}
+	return result, nil
+}
+
+// merge writes the changes diffInfo.MergeParent made since its merge base
+// with diffInfo.ParentCommit to diffInfo's appends. Merged files get an
+// append which deletes what came before it, so reads only follow the first
+// parent. Directories are never in conflict, their children are merged as
+// the files in them are.
+func (d *driver) merge(diffInfo *pfs.DiffInfo, policies []*pfs.MergePolicy, shard uint64) error {
+	repoName := diffInfo.Diff.Commit.Repo.Name
+	ours := diffInfo.ParentCommit
+	theirs := diffInfo.MergeParent
+	for _, commit := range []*pfs.Commit{ours, theirs} {
+		parentDiffInfo, ok := d.diffs.get(client.NewDiff(repoName, commit.ID, shard))
+		if !ok {
+			return fmt.Errorf("commit %s/%s not found", repoName, commit.ID)
+		}
+		if parentDiffInfo.Finished == nil {
+			return fmt.Errorf("commit %s/%s must be finished before it can be merged", repoName, commit.ID)
+		}
+	}
+	var base *pfs.Commit
+	baseAncestors := make(map[string]bool)
+	if baseID := mergeBase(d.dags[repoName], ours.ID, theirs.ID); baseID != "" {
+		base = client.NewCommit(repoName, baseID)
+		for _, commitID := range d.dags[repoName].Ancestors(baseID, nil) {
+			baseAncestors[commitID] = true
+		}
+	}
+	pathSet := make(map[string]bool)
+	for _, commitID := range d.dags[repoName].Ancestors(theirs.ID, nil) {
+		if baseAncestors[commitID] {
+			continue
+		}
+		theirsDiffInfo, ok := d.diffs.get(client.NewDiff(repoName, commitID, shard))
+		if !ok {
+			return fmt.Errorf("diff %s/%s/%d not found", repoName, commitID, shard)
+		}
+		for filePath := range theirsDiffInfo.Appends {
+			pathSet[filePath] = true
+		}
+	}
+	// directories sort before the files in them, so a directory's append
+	// exists before its children are added to it
+	var paths []string
+	for filePath := range pathSet {
+		paths = append(paths, filePath)
+	}
+	sort.Strings(paths)
+	for _, filePath := range paths {
+		theirsState, err := d.fileState(theirs, filePath, shard)
+		if err != nil {
+			return err
+		}
+		baseState, err := d.fileState(base, filePath, shard)
+		if err != nil {
+			return err
+		}
+		if theirsState.equal(baseState) {
+			continue
+		}
+		oursState, err := d.fileState(ours, filePath, shard)
+		if err != nil {
+			return err
+		}
+		if oursState.equal(theirsState) ||
+			(oursState.fileType == pfs.FileType_FILE_TYPE_DIR && theirsState.fileType == pfs.FileType_FILE_TYPE_DIR) {
+			continue
+		}
+		result := theirsState
+		if !oursState.equal(baseState) {
+			// both sides changed the file
+			switch mergeStrategy(policies, filePath) {
+			case pfs.MergeStrategy_MERGE_STRATEGY_OURS:
+				continue
+			case pfs.MergeStrategy_MERGE_STRATEGY_THEIRS:
+			case pfs.MergeStrategy_MERGE_STRATEGY_CONCATENATE:
+				if oursState.fileType == pfs.FileType_FILE_TYPE_DIR || theirsState.fileType == pfs.FileType_FILE_TYPE_DIR {
+					return fmt.Errorf("merge conflict on %s: cannot concatenate a directory", filePath)
+				}
+				result = concatenate(baseState, oursState, theirsState)
+			default:
+				return fmt.Errorf("merge conflict on %s: changed in both %s/%s and %s/%s", filePath, repoName, ours.ID, repoName, theirs.ID)
+			}
+		}
+		file := client.NewFile(repoName, diffInfo.Diff.Commit.ID, filePath)
+		switch result.fileType {
+		case pfs.FileType_FILE_TYPE_REGULAR:
+			diffInfo.Appends[filePath] = &pfs.Append{
+				BlockRefs: result.blockRefs,
+				Handles:   make(map[string]*pfs.BlockRefs),
+				Delete:    true,
+			}
+			// the merged content counts as written by this commit, as it
+			// would if it had been copied in with CopyFile
+			for _, blockRef := range result.blockRefs {
+				diffInfo.SizeBytes += pfsserver.ByteRangeSize(blockRef.Range)
+			}
+			d.addDirs(diffInfo, file, shard)
+		case pfs.FileType_FILE_TYPE_DIR:
+			d.dirAppend(diffInfo, filePath, shard).Delete = true
+			if filePath != "." {
+				d.addDirs(diffInfo, file, shard)
+			}
+		default:
+			diffInfo.Appends[filePath] = &pfs.Append{
+				Handles: make(map[string]*pfs.BlockRefs),
+				Delete:  true,
+			}
+			d.deleteFromDir(diffInfo, file, shard)
+		}
+	}
+	return nil
+}
+
+// concatenate returns ours followed by what theirs added to base, a file
+// deleted on one side is replaced by the other side's version of it.
+func concatenate(base *fileState, ours *fileState, theirs *fileState) *fileState {
+	if ours.fileType == pfs.FileType_FILE_TYPE_NONE {
+		return theirs
+	}
+	if theirs.fileType == pfs.FileType_FILE_TYPE_NONE {
+		return ours
+	}
+	added := theirs.blockRefs
+	if base.fileType == pfs.FileType_FILE_TYPE_REGULAR && hasBlockRefPrefix(theirs.blockRefs, base.blockRefs) {
+		added = theirs.blockRefs[len(base.blockRefs):]
+	}
+	return &fileState{
+		fileType:  pfs.FileType_FILE_TYPE_REGULAR,
+		blockRefs: append(append([]*pfs.BlockRef(nil), ours.blockRefs...), added...),
+	}
+}
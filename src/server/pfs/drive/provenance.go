@@ -0,0 +1,65 @@
+package drive
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pfs"
+)
+
+// fullProvenance returns provenance along with the commits each of its
+// commits was derived from, without duplicates and sorted by repo and ID.
+// It must be called with d.lock held.
+func (d *driver) fullProvenance(provenance []*pfs.Commit, shards map[uint64]bool) ([]*pfs.Commit, error) {
+	commits := make(map[string]map[string]bool)
+	add := func(commit *pfs.Commit) {
+		if commits[commit.Repo.Name] == nil {
+			commits[commit.Repo.Name] = make(map[string]bool)
+		}
+		commits[commit.Repo.Name][commit.ID] = true
+	}
+	for _, commit := range provenance {
+		canonicalCommit, err := d.canonicalCommit(commit)
+		if err != nil {
+			return nil, err
+		}
+		add(canonicalCommit)
+		for shard := range shards {
+			diffInfo, ok := d.diffs.get(client.NewDiff(canonicalCommit.Repo.Name, canonicalCommit.ID, shard))
+			if !ok {
+				return nil, fmt.Errorf("commit %s/%s not found", canonicalCommit.Repo.Name, canonicalCommit.ID)
+			}
+			for _, provenanceCommit := range diffInfo.Provenance {
+				add(provenanceCommit)
+			}
+		}
+	}
+	var result []*pfs.Commit
+	for repoName, commitIDs := range commits {
+		for commitID := range commitIDs {
+			result = append(result, client.NewCommit(repoName, commitID))
+		}
+	}
+	sort.Sort(sortCommits(result))
+	return result, nil
+}
+
+type sortCommits []*pfs.Commit
+
+func (a sortCommits) Len() int {
+	return len(a)
+}
+
+func (a sortCommits) Less(i, j int) bool {
+	if a[i].Repo.Name != a[j].Repo.Name {
+		return a[i].Repo.Name < a[j].Repo.Name
+	}
+	return a[i].ID < a[j].ID
+}
+
+func (a sortCommits) Swap(i, j int) {
+	tmp := a[i]
+	a[i] = a[j]
+	a[j] = tmp
+}
@@ -0,0 +1,145 @@
+package drive
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pfs"
+	pfsserver "github.com/pachyderm/pachyderm/src/server/pfs"
+)
+
+func (d *driver) SetQuota(repo *pfs.Repo, quota *pfs.Quota, shards map[uint64]bool) error {
+	// root diffs are written under branchLock so that they're persisted in
+	// the order they change
+	d.branchLock.Lock()
+	defer d.branchLock.Unlock()
+	var rootDiffInfos []*pfs.DiffInfo
+	if err := func() error {
+		d.lock.Lock()
+		defer d.lock.Unlock()
+		if _, ok := d.diffs[repo.Name]; !ok {
+			return pfsserver.ErrRepoNotFound
+		}
+		for shard := range shards {
+			diffInfo, ok := d.diffs.get(client.NewDiff(repo.Name, "", shard))
+			if !ok {
+				return fmt.Errorf("diff %s//%d not found; this is likely a bug", repo.Name, shard)
+			}
+			// finished diffs are copied rather than modified, see
+			// branchDiffInfos
+			rootDiffInfo := *diffInfo
+			rootDiffInfo.Quota = quota
+			d.diffs[repo.Name][shard][""] = &rootDiffInfo
+			rootDiffInfos = append(rootDiffInfos, &rootDiffInfo)
+		}
+		return nil
+	}(); err != nil {
+		return err
+	}
+	return d.persistDiffInfos(rootDiffInfos)
+}
+
+// quota returns repoName's quota, which is nil if it has none.
+// It must be called with d.lock held.
+func (d *driver) quota(repoName string, shard uint64) *pfs.Quota {
+	diffInfo, ok := d.diffs.get(client.NewDiff(repoName, "", shard))
+	if !ok {
+		return nil
+	}
+	return diffInfo.Quota
+}
+
+// fileCount returns the number of files diffInfo writes to.
+func fileCount(diffInfo *pfs.DiffInfo) uint64 {
+	var result uint64
+	for _, _append := range diffInfo.Appends {
+		if len(_append.BlockRefs) > 0 || len(_append.Handles) > 0 {
+			result++
+		}
+	}
+	return result
+}
+
+// subtractSize returns size - delta, or 0 rather than wrapping around if
+// delta is larger.
+func subtractSize(size uint64, delta uint64) uint64 {
+	if delta > size {
+		return 0
+	}
+	return size - delta
+}
+
+// appendSize returns the number of bytes _append writes in its own commit.
+func appendSize(_append *pfs.Append) uint64 {
+	var result uint64
+	for _, blockRef := range _append.BlockRefs {
+		result += pfsserver.ByteRangeSize(blockRef.Range)
+	}
+	for _, blockRefs := range _append.Handles {
+		for _, blockRef := range blockRefs.BlockRef {
+			result += pfsserver.ByteRangeSize(blockRef.Range)
+		}
+	}
+	return result
+}
+
+// checkPutFileQuota checks that writing size bytes to file in diffInfo,
+// after truncating it if overwrite is set, doesn't exceed the quota of file's
+// repo. The repo's size and the number of
+// files written to by the commit can only be checked in shard here, the rest
+// of the shards are checked when the commit is finished.
+// It must be called with d.lock held.
+func (d *driver) checkPutFileQuota(file *pfs.File, diffInfo *pfs.DiffInfo, size uint64, overwrite bool, shard uint64) error {
+	repoName := diffInfo.Diff.Commit.Repo.Name
+	quota := d.quota(repoName, shard)
+	if quota == nil {
+		return nil
+	}
+	if quota.MaxFileSizeBytes != 0 {
+		var fileSize uint64
+		if !overwrite {
+			fileInfo, _, err := d.inspectFile(file, nil, shard, nil, false, true)
+			if err != nil && err != pfsserver.ErrFileNotFound {
+				return err
+			}
+			if err == nil {
+				fileSize = fileInfo.SizeBytes
+			}
+		}
+		if fileSize+size > quota.MaxFileSizeBytes {
+			return pfsserver.ErrQuotaExceeded{
+				Repo:   repoName,
+				Reason: fmt.Sprintf("file %s would be %d bytes, the limit is %d", file.Path, fileSize+size, quota.MaxFileSizeBytes),
+			}
+		}
+	}
+	if quota.MaxFilesPerCommit != 0 {
+		_append, ok := diffInfo.Appends[path.Clean(file.Path)]
+		if !ok || (len(_append.BlockRefs) == 0 && len(_append.Handles) == 0) {
+			if fileCount(diffInfo)+1 > quota.MaxFilesPerCommit {
+				return pfsserver.ErrQuotaExceeded{
+					Repo:   repoName,
+					Reason: fmt.Sprintf("commit %s would write more than %d files", diffInfo.Diff.Commit.ID, quota.MaxFilesPerCommit),
+				}
+			}
+		}
+	}
+	if quota.MaxBytes != 0 && size > 0 {
+		var repoSize uint64
+		for _, diffInfo := range d.diffs[repoName][shard] {
+			repoSize += diffInfo.SizeBytes
+		}
+		if _append, ok := diffInfo.Appends[path.Clean(file.Path)]; ok && overwrite {
+			// what this commit has written to the file is dropped
+			repoSize = subtractSize(repoSize, appendSize(_append))
+		}
+		if repoSize+size > quota.MaxBytes {
+			return pfsserver.ErrQuotaExceeded{
+				Repo:   repoName,
+				Reason: fmt.Sprintf("repo would be larger than %d bytes", quota.MaxBytes),
+			}
+		}
+	}
+	return nil
+}
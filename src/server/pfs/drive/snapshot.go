@@ -0,0 +1,141 @@
+package drive
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/pachyderm/pachyderm/src/client/pfs"
+	"go.pedge.io/lion/proto"
+	"go.pedge.io/pb/go/google/protobuf"
+	"go.pedge.io/proto/time"
+	"golang.org/x/net/context"
+)
+
+const (
+	// defaultSnapshotInterval is the number of commits finished on a shard
+	// between snapshots of it by default.
+	defaultSnapshotInterval = 1000
+	// snapshotReplayWindow is how long before a snapshot was written AddShard
+	// starts replaying diffs on top of it. It covers the time between reading
+	// the shard's state and the block server writing the snapshot, object
+	// stores which only keep modification times to the second and clock skew
+	// between pachds.
+	snapshotReplayWindow = 10 * time.Minute
+)
+
+func (d *driver) SnapshotShard(shard uint64) error {
+	d.snapshotLock.Lock()
+	defer d.snapshotLock.Unlock()
+	start := time.Now()
+	// Finished diffs are only modified by DeleteCommit, which holds
+	// snapshotLock, so they can be sent without holding d.lock.
+	var diffInfos []*pfs.DiffInfo
+	d.lock.RLock()
+	if !d.shards[shard] {
+		d.lock.RUnlock()
+		// a snapshot of a shard we don't have would hide its diffs
+		return fmt.Errorf("shard %d hasn't been added", shard)
+	}
+	for _, shardMap := range d.diffs {
+		for _, diffInfo := range shardMap[shard] {
+			if diffInfo.Finished != nil {
+				diffInfos = append(diffInfos, diffInfo)
+			}
+		}
+	}
+	d.lock.RUnlock()
+	blockClient, err := d.getBlockClient()
+	if err != nil {
+		return err
+	}
+	createShardSnapshotClient, err := blockClient.CreateShardSnapshot(context.Background())
+	if err != nil {
+		return err
+	}
+	if err := createShardSnapshotClient.Send(&pfs.ShardSnapshot{Shard: shard}); err != nil {
+		return err
+	}
+	for _, diffInfo := range diffInfos {
+		if err := createShardSnapshotClient.Send(&pfs.ShardSnapshot{
+			Shard:     shard,
+			DiffInfos: []*pfs.DiffInfo{diffInfo},
+		}); err != nil {
+			return err
+		}
+	}
+	if _, err := createShardSnapshotClient.CloseAndRecv(); err != nil {
+		return err
+	}
+	if elapsed := time.Since(start); elapsed > snapshotReplayWindow/2 {
+		// diffs written while the snapshot was being written might not be
+		// replayed on top of it
+		if _, err := blockClient.DeleteShardSnapshot(
+			context.Background(),
+			&pfs.DeleteShardSnapshotRequest{Shard: shard},
+		); err != nil {
+			return err
+		}
+		return fmt.Errorf("snapshot of shard %d took %s so it was discarded", shard, elapsed)
+	}
+	return nil
+}
+
+// snapshotShards snapshots shards in the background, FinishCommit calls it
+// once enough commits have been finished.
+func (d *driver) snapshotShards(shards []uint64) {
+	for _, shard := range shards {
+		if err := d.SnapshotShard(shard); err != nil {
+			protolion.Errorf("error snapshotting shard %d: %s", shard, err.Error())
+		}
+	}
+}
+
+// readShardSnapshot calls f on each diff in shard's latest snapshot, it
+// returns the time from which diffs must be replayed on top of the snapshot
+// or nil if the shard doesn't have one.
+func (d *driver) readShardSnapshot(blockClient pfs.BlockAPIClient, shard uint64, f func(*pfs.DiffInfo) error) (*google_protobuf.Timestamp, error) {
+	getShardSnapshotClient, err := blockClient.GetShardSnapshot(context.Background(), &pfs.GetShardSnapshotRequest{Shard: shard})
+	if err != nil {
+		return nil, err
+	}
+	shardSnapshot, err := getShardSnapshotClient.Recv()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if shardSnapshot.Created == nil {
+		return nil, fmt.Errorf("snapshot of shard %d has no creation time; this is likely a bug", shard)
+	}
+	modifiedAfter := prototime.TimestampToTime(shardSnapshot.Created).Add(-snapshotReplayWindow)
+	for {
+		for _, diffInfo := range shardSnapshot.DiffInfos {
+			if err := f(diffInfo); err != nil {
+				return nil, err
+			}
+		}
+		shardSnapshot, err = getShardSnapshotClient.Recv()
+		if err == io.EOF {
+			return prototime.TimeToTimestamp(modifiedAfter), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// deleteShardSnapshots deletes the snapshots of shards, it's called with
+// snapshotLock held before diffs in them are deleted or rewritten.
+func (d *driver) deleteShardSnapshots(blockClient pfs.BlockAPIClient, shards map[uint64]bool) error {
+	for shard := range shards {
+		if _, err := blockClient.DeleteShardSnapshot(
+			context.Background(),
+			&pfs.DeleteShardSnapshotRequest{Shard: shard},
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -137,7 +137,7 @@ func (d *directory) Create(ctx context.Context, request *fuse.CreateRequest, res
 		local:     true,
 	}
 	response.Flags |= fuse.OpenDirectIO | fuse.OpenNonSeekable
-	handle := localResult.newHandle()
+	handle := localResult.newHandle(request.Flags)
 	return localResult, handle, nil
 }
 
@@ -204,7 +204,7 @@ func (f *file) Open(ctx context.Context, request *fuse.OpenRequest, response *fu
 		protolion.Debug(&FileOpen{&f.Node, errorToString(retErr)})
 	}()
 	response.Flags |= fuse.OpenDirectIO | fuse.OpenNonSeekable
-	return f.newHandle(), nil
+	return f.newHandle(request.Flags), nil
 }
 
 func (f *file) Fsync(ctx context.Context, req *fuse.FsyncRequest) error {
@@ -237,9 +237,10 @@ func (f *filesystem) inode(file *pfsclient.File) uint64 {
 	return newInode
 }
 
-func (f *file) newHandle() *handle {
+func (f *file) newHandle(flags fuse.OpenFlags) *handle {
 	h := &handle{
-		f: f,
+		f:        f,
+		truncate: flags&fuse.OpenTruncate != 0,
 	}
 
 	f.handles = append(f.handles, h)
@@ -251,6 +252,9 @@ type handle struct {
 	f       *file
 	w       io.WriteCloser
 	written int
+	// truncate is set for handles opened with O_TRUNC, the file is
+	// overwritten by the handle's first write or when it's flushed
+	truncate bool
 }
 
 func (h *handle) Read(ctx context.Context, request *fuse.ReadRequest, response *fuse.ReadResponse) (retErr error) {
@@ -289,12 +293,9 @@ func (h *handle) Write(ctx context.Context, request *fuse.WriteRequest, response
 	}()
 	protolion.Printf("WriteRequest: %s@%d\n", string(request.Data), request.Offset)
 	if h.w == nil {
-		w, err := h.f.fs.apiClient.PutFileWriter(
-			h.f.File.Commit.Repo.Name, h.f.File.Commit.ID, h.f.File.Path, h.f.fs.handleID)
-		if err != nil {
+		if err := h.openWriter(); err != nil {
 			return err
 		}
-		h.w = w
 	}
 	// repeated is how many bytes in this write have already been sent in
 	// previous call to Write. Why does the OS send us the same data twice in
@@ -317,6 +318,12 @@ func (h *handle) Write(ctx context.Context, request *fuse.WriteRequest, response
 }
 
 func (h *handle) Flush(ctx context.Context, req *fuse.FlushRequest) error {
+	if h.w == nil && h.truncate {
+		// nothing was written but the file was still truncated
+		if err := h.openWriter(); err != nil {
+			return err
+		}
+	}
 	if h.w != nil {
 		w := h.w
 		h.w = nil
@@ -327,6 +334,26 @@ func (h *handle) Flush(ctx context.Context, req *fuse.FlushRequest) error {
 	return nil
 }
 
+// openWriter opens h.w, the first writer opened by a handle with truncate set
+// overwrites the file.
+func (h *handle) openWriter() error {
+	var w io.WriteCloser
+	var err error
+	if h.truncate {
+		w, err = h.f.fs.apiClient.PutFileWriterOverwrite(
+			h.f.File.Commit.Repo.Name, h.f.File.Commit.ID, h.f.File.Path, h.f.fs.handleID)
+	} else {
+		w, err = h.f.fs.apiClient.PutFileWriter(
+			h.f.File.Commit.Repo.Name, h.f.File.Commit.ID, h.f.File.Path, h.f.fs.handleID)
+	}
+	if err != nil {
+		return err
+	}
+	h.w = w
+	h.truncate = false
+	return nil
+}
+
 func (h *handle) Release(ctx context.Context, req *fuse.ReleaseRequest) error {
 	return nil
 }
@@ -511,7 +538,8 @@ func (d *directory) readCommits(ctx context.Context) ([]fuse.Dirent, error) {
 }
 
 func (d *directory) readFiles(ctx context.Context) ([]fuse.Dirent, error) {
-	fileInfos, err := d.fs.apiClient.ListFile(
+	var result []fuse.Dirent
+	if err := d.fs.apiClient.ListFileStream(
 		d.File.Commit.Repo.Name,
 		d.File.Commit.ID,
 		d.File.Path,
@@ -520,25 +548,24 @@ func (d *directory) readFiles(ctx context.Context) ([]fuse.Dirent, error) {
 		// setting recurse to false for performance reasons
 		// it does however means that we won't know the correct sizes of directories
 		false,
-	)
-	if err != nil {
+		"",
+		0,
+		func(fileInfo *pfsclient.FileInfo) error {
+			shortPath := strings.TrimPrefix(fileInfo.File.Path, d.File.Path)
+			if shortPath[0] == '/' {
+				shortPath = shortPath[1:]
+			}
+			switch fileInfo.FileType {
+			case pfsclient.FileType_FILE_TYPE_REGULAR:
+				result = append(result, fuse.Dirent{Name: shortPath, Type: fuse.DT_File})
+			case pfsclient.FileType_FILE_TYPE_DIR:
+				result = append(result, fuse.Dirent{Name: shortPath, Type: fuse.DT_Dir})
+			}
+			return nil
+		},
+	); err != nil {
 		return nil, err
 	}
-	var result []fuse.Dirent
-	for _, fileInfo := range fileInfos {
-		shortPath := strings.TrimPrefix(fileInfo.File.Path, d.File.Path)
-		if shortPath[0] == '/' {
-			shortPath = shortPath[1:]
-		}
-		switch fileInfo.FileType {
-		case pfsclient.FileType_FILE_TYPE_REGULAR:
-			result = append(result, fuse.Dirent{Name: shortPath, Type: fuse.DT_File})
-		case pfsclient.FileType_FILE_TYPE_DIR:
-			result = append(result, fuse.Dirent{Name: shortPath, Type: fuse.DT_Dir})
-		default:
-			continue
-		}
-	}
 	return result, nil
 }
 
@@ -235,6 +235,31 @@ func TestWriteAndRead(t *testing.T) {
 	})
 }
 
+func TestOverwrite(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipped because of short mode")
+	}
+
+	testFuse(t, func(c client.APIClient, mountpoint string) {
+		repo := "test"
+		require.NoError(t, c.CreateRepo(repo))
+		commit1, err := c.StartCommit(repo, "", "")
+		require.NoError(t, err)
+		_, err = c.PutFile(repo, commit1.ID, "file", strings.NewReader("foo\n"))
+		require.NoError(t, err)
+		require.NoError(t, c.FinishCommit(repo, commit1.ID))
+		commit2, err := c.StartCommit(repo, commit1.ID, "")
+		require.NoError(t, err)
+		// WriteFile opens the file with O_TRUNC
+		path := filepath.Join(mountpoint, repo, commit2.ID, "file")
+		require.NoError(t, ioutil.WriteFile(path, []byte("bar\n"), 0644))
+		require.NoError(t, c.FinishCommit(repo, commit2.ID))
+		data, err := ioutil.ReadFile(path)
+		require.NoError(t, err)
+		require.Equal(t, []byte("bar\n"), data)
+	})
+}
+
 func TestBigWrite(t *testing.T) {
 	if testing.Short() {
 		t.Skip("Skipped because of short mode")
@@ -427,11 +452,11 @@ func testFuse(
 	)
 
 	blockDir := filepath.Join(tmp, "blocks")
-	blockServer, err := server.NewLocalBlockAPIServer(blockDir)
+	blockServer, err := server.NewLocalBlockAPIServer(blockDir, server.DefaultBlockAPIServerOptions())
 	require.NoError(t, err)
 	pfsclient.RegisterBlockAPIServer(srv, blockServer)
 
-	driver, err := drive.NewDriver(localAddress)
+	driver, err := drive.NewDriver(localAddress, drive.DefaultOptions())
 	require.NoError(t, err)
 
 	apiServer := server.NewAPIServer(
@@ -444,6 +469,7 @@ func testFuse(
 		hasher,
 		router,
 		driver,
+		server.URLAccess{},
 	)
 	pfsclient.RegisterInternalAPIServer(srv, internalAPIServer)
 
@@ -0,0 +1,96 @@
+package pfs
+
+import (
+	"path"
+	"strings"
+)
+
+// IsGlob returns true if pattern contains any glob metacharacters.
+func IsGlob(pattern string) bool {
+	return strings.ContainsAny(pattern, `*?[\`)
+}
+
+// GlobEscape returns a pattern which only matches name, by escaping its
+// metacharacters with backslashes.
+func GlobEscape(name string) string {
+	var escaped []byte
+	for i := 0; i < len(name); i++ {
+		if strings.IndexByte(`*?[\`, name[i]) >= 0 {
+			escaped = append(escaped, '\\')
+		}
+		escaped = append(escaped, name[i])
+	}
+	return string(escaped)
+}
+
+// GlobMatch reports whether name matches pattern. Patterns use the syntax of
+// path.Match for each path component, in addition a component of ** matches
+// any number of components, including none.
+func GlobMatch(pattern string, name string) (bool, error) {
+	return globMatch(globComponents(pattern), globComponents(name))
+}
+
+// GlobMatchUnder reports whether anything under the directory dir could match
+// pattern, it's used to prune directories when walking a tree.
+func GlobMatchUnder(pattern string, dir string) (bool, error) {
+	return globMatchUnder(globComponents(pattern), globComponents(dir))
+}
+
+// GlobPrefix returns the deepest directory which contains every match of
+// pattern, it's the leading components of pattern without metacharacters.
+func GlobPrefix(pattern string) string {
+	var prefix []string
+	components := globComponents(pattern)
+	for i, component := range components {
+		if IsGlob(component) || i == len(components)-1 {
+			break
+		}
+		prefix = append(prefix, component)
+	}
+	return path.Join(prefix...)
+}
+
+func globComponents(name string) []string {
+	name = strings.Trim(path.Clean("/"+name), "/")
+	if name == "" {
+		return nil
+	}
+	return strings.Split(name, "/")
+}
+
+func globMatch(pattern []string, name []string) (bool, error) {
+	if len(pattern) == 0 {
+		return len(name) == 0, nil
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(name); i++ {
+			match, err := globMatch(pattern[1:], name[i:])
+			if err != nil || match {
+				return match, err
+			}
+		}
+		return false, nil
+	}
+	if len(name) == 0 {
+		return false, nil
+	}
+	match, err := path.Match(pattern[0], name[0])
+	if err != nil || !match {
+		return false, err
+	}
+	return globMatch(pattern[1:], name[1:])
+}
+
+func globMatchUnder(pattern []string, dir []string) (bool, error) {
+	if len(pattern) == 0 {
+		return false, nil
+	}
+	if pattern[0] == "**" || len(dir) == 0 {
+		return true, nil
+	}
+	match, err := path.Match(pattern[0], dir[0])
+	if err != nil || !match {
+		return false, err
+	}
+	return globMatchUnder(pattern[1:], dir[1:])
+}
@@ -2,6 +2,7 @@ package pfs
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/pachyderm/pachyderm/src/client/pfs"
 )
@@ -13,3 +14,66 @@ var ErrRepoNotFound = errors.New("repo not found")
 func ByteRangeSize(byteRange *pfs.ByteRange) uint64 {
 	return byteRange.Upper - byteRange.Lower
 }
+
+// HasProvenance returns true if commitInfo was derived from every commit in
+// provenance.
+func HasProvenance(commitInfo *pfs.CommitInfo, provenance []*pfs.Commit) bool {
+	for _, commit := range provenance {
+		found := false
+		for _, provenanceCommit := range commitInfo.Provenance {
+			if provenanceCommit.Repo.Name == commit.Repo.Name && provenanceCommit.ID == commit.ID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// HasLabels returns true if commitInfo has every label in labels.
+func HasLabels(commitInfo *pfs.CommitInfo, labels map[string]string) bool {
+	for key, value := range labels {
+		if commitInfo.Labels[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// ErrQuotaExceeded is returned by writes which would take a repo over its
+// quota.
+type ErrQuotaExceeded struct {
+	Repo   string
+	Reason string
+}
+
+func (e ErrQuotaExceeded) Error() string {
+	return fmt.Sprintf("quota exceeded for repo %s: %s", e.Repo, e.Reason)
+}
+
+// CheckQuota returns an error if repoInfo's usage or commitInfo's exceeds
+// repoInfo's quota. repoInfo and commitInfo must be the totals from every
+// shard. A repo over its MaxBytes only fails commits which add data, so that
+// data can still be deleted after a quota is lowered.
+func CheckQuota(repoInfo *pfs.RepoInfo, commitInfo *pfs.CommitInfo) error {
+	quota := repoInfo.Quota
+	if quota == nil {
+		return nil
+	}
+	if quota.MaxBytes != 0 && commitInfo.SizeBytes > 0 && repoInfo.SizeBytes > quota.MaxBytes {
+		return ErrQuotaExceeded{
+			Repo:   repoInfo.Repo.Name,
+			Reason: fmt.Sprintf("repo is %d bytes, the limit is %d", repoInfo.SizeBytes, quota.MaxBytes),
+		}
+	}
+	if quota.MaxFilesPerCommit != 0 && commitInfo.FileCount > quota.MaxFilesPerCommit {
+		return ErrQuotaExceeded{
+			Repo:   repoInfo.Repo.Name,
+			Reason: fmt.Sprintf("commit %s writes %d files, the limit is %d", commitInfo.Commit.ID, commitInfo.FileCount, quota.MaxFilesPerCommit),
+		}
+	}
+	return nil
+}
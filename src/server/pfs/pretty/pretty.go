@@ -3,6 +3,8 @@ package pretty
 import (
 	"fmt"
 	"io"
+	"sort"
+	"strings"
 	"time"
 
 	"go.pedge.io/proto/time"
@@ -12,7 +14,7 @@ import (
 )
 
 func PrintRepoHeader(w io.Writer) {
-	fmt.Fprint(w, "NAME\tCREATED\tSIZE\t\n")
+	fmt.Fprint(w, "NAME\tCREATED\tSIZE\tQUOTA\t\n")
 }
 
 func PrintRepoInfo(w io.Writer, repoInfo *pfs.RepoInfo) {
@@ -27,17 +29,36 @@ func PrintRepoInfo(w io.Writer, repoInfo *pfs.RepoInfo) {
 			),
 		),
 	)
-	fmt.Fprintf(w, "%s\t\n", units.BytesSize(float64(repoInfo.SizeBytes)))
+	fmt.Fprintf(w, "%s\t", units.BytesSize(float64(repoInfo.SizeBytes)))
+	var limits []string
+	if repoInfo.Quota != nil {
+		if repoInfo.Quota.MaxBytes != 0 {
+			limits = append(limits, units.BytesSize(float64(repoInfo.Quota.MaxBytes)))
+		}
+		if repoInfo.Quota.MaxFilesPerCommit != 0 {
+			limits = append(limits, fmt.Sprintf("%d files/commit", repoInfo.Quota.MaxFilesPerCommit))
+		}
+		if repoInfo.Quota.MaxFileSizeBytes != 0 {
+			limits = append(limits, fmt.Sprintf("%s/file", units.BytesSize(float64(repoInfo.Quota.MaxFileSizeBytes))))
+		}
+	}
+	if len(limits) == 0 {
+		fmt.Fprint(w, "none\t\n")
+	} else {
+		fmt.Fprintf(w, "%s\t\n", strings.Join(limits, ", "))
+	}
 }
 
 func PrintCommitInfoHeader(w io.Writer) {
-	fmt.Fprint(w, "BRANCH\tID\tPARENT\tSTARTED\tFINISHED\tSIZE\t\n")
+	fmt.Fprint(w, "BRANCH\tID\tPARENT\tSTARTED\tFINISHED\tSIZE\tMESSAGE\tLABELS\t\n")
 }
 
 func PrintCommitInfo(w io.Writer, commitInfo *pfs.CommitInfo) {
 	fmt.Fprintf(w, "%s\t", commitInfo.Branch)
 	fmt.Fprintf(w, "%s\t", commitInfo.Commit.ID)
-	if commitInfo.ParentCommit != nil {
+	if commitInfo.ParentCommit != nil && commitInfo.MergeParent != nil {
+		fmt.Fprintf(w, "%s, %s\t", commitInfo.ParentCommit.ID, commitInfo.MergeParent.ID)
+	} else if commitInfo.ParentCommit != nil {
 		fmt.Fprintf(w, "%s\t", commitInfo.ParentCommit.ID)
 	} else {
 		fmt.Fprint(w, "<none>\t")
@@ -63,7 +84,24 @@ func PrintCommitInfo(w io.Writer, commitInfo *pfs.CommitInfo) {
 		))
 	}
 	fmt.Fprintf(w, finished)
-	fmt.Fprintf(w, "%s\t\n", units.BytesSize(float64(commitInfo.SizeBytes)))
+	fmt.Fprintf(w, "%s\t", units.BytesSize(float64(commitInfo.SizeBytes)))
+	// only the first line of the message fits in the table
+	fmt.Fprintf(w, "%s\t", strings.SplitN(commitInfo.Message, "\n", 2)[0])
+	var labels []string
+	for key, value := range commitInfo.Labels {
+		labels = append(labels, fmt.Sprintf("%s=%s", key, value))
+	}
+	sort.Strings(labels)
+	fmt.Fprintf(w, "%s\t\n", strings.Join(labels, ","))
+}
+
+func PrintBranchInfoHeader(w io.Writer) {
+	fmt.Fprint(w, "BRANCH\tHEAD\t\n")
+}
+
+func PrintBranchInfo(w io.Writer, branchInfo *pfs.BranchInfo) {
+	fmt.Fprintf(w, "%s\t", branchInfo.Name)
+	fmt.Fprintf(w, "%s\t\n", branchInfo.Head.ID)
 }
 
 func PrintFileInfoHeader(w io.Writer) {
@@ -71,6 +109,28 @@ func PrintFileInfoHeader(w io.Writer) {
 }
 
 func PrintFileInfo(w io.Writer, fileInfo *pfs.FileInfo) {
+	printFileInfo(w, fileInfo)
+	fmt.Fprint(w, "\n")
+}
+
+// PrintDetailedFileInfoHeader is like PrintFileInfoHeader but with the
+// file's checksums.
+func PrintDetailedFileInfoHeader(w io.Writer) {
+	fmt.Fprint(w, "NAME\tTYPE\tMODIFIED\tLAST_COMMIT_MODIFIED\tSIZE\tDIGEST\tSHA256\t\n")
+}
+
+func PrintDetailedFileInfo(w io.Writer, fileInfo *pfs.FileInfo) {
+	printFileInfo(w, fileInfo)
+	for _, checksum := range []string{fileInfo.Digest, fileInfo.Sha256} {
+		if checksum == "" {
+			checksum = "-"
+		}
+		fmt.Fprintf(w, "%s\t", checksum)
+	}
+	fmt.Fprint(w, "\n")
+}
+
+func printFileInfo(w io.Writer, fileInfo *pfs.FileInfo) {
 	fmt.Fprintf(w, "%s\t", fileInfo.File.Path)
 	if fileInfo.FileType == pfs.FileType_FILE_TYPE_REGULAR {
 		fmt.Fprint(w, "file\t")
@@ -88,11 +148,25 @@ func PrintFileInfo(w io.Writer, fileInfo *pfs.FileInfo) {
 		),
 	)
 	fmt.Fprint(w, "-\t")
-	fmt.Fprintf(w, "%s\t\n", units.BytesSize(float64(fileInfo.SizeBytes)))
+	fmt.Fprintf(w, "%s\t", units.BytesSize(float64(fileInfo.SizeBytes)))
+}
+
+func PrintFileChangeHeader(w io.Writer) {
+	fmt.Fprint(w, "NAME\tCHANGE\tSIZE_DELTA\t\n")
+}
+
+func PrintFileChange(w io.Writer, fileChange *pfs.FileChange) {
+	fmt.Fprintf(w, "%s\t", fileChange.File.Path)
+	fmt.Fprintf(w, "%s\t", strings.ToLower(strings.TrimPrefix(fileChange.ChangeType.String(), "CHANGE_TYPE_")))
+	if fileChange.SizeDeltaBytes < 0 {
+		fmt.Fprintf(w, "-%s\t\n", units.BytesSize(float64(-fileChange.SizeDeltaBytes)))
+	} else {
+		fmt.Fprintf(w, "+%s\t\n", units.BytesSize(float64(fileChange.SizeDeltaBytes)))
+	}
 }
 
 func PrintBlockInfoHeader(w io.Writer) {
-	fmt.Fprintf(w, "HASH\tCREATED\tSIZE\t\n")
+	fmt.Fprintf(w, "HASH\tCREATED\tSIZE\tSTORED_SIZE\tCOMPRESSION\t\n")
 }
 
 func PrintBlockInfo(w io.Writer, blockInfo *pfs.BlockInfo) {
@@ -107,7 +181,23 @@ func PrintBlockInfo(w io.Writer, blockInfo *pfs.BlockInfo) {
 			),
 		),
 	)
-	fmt.Fprintf(w, "%s\t\n", units.BytesSize(float64(blockInfo.SizeBytes)))
+	fmt.Fprintf(w, "%s\t", units.BytesSize(float64(blockInfo.SizeBytes)))
+	fmt.Fprintf(w, "%s\t", units.BytesSize(float64(blockInfo.StoredSizeBytes)))
+	fmt.Fprintf(w, "%s\t\n", strings.ToLower(strings.TrimPrefix(blockInfo.Compression.String(), "COMPRESSION_")))
+}
+
+func PrintGarbageCollectHeader(w io.Writer, dryRun bool) {
+	if dryRun {
+		fmt.Fprint(w, "LIVE_BLOCKS\tRECLAIMABLE_BLOCKS\tRECLAIMABLE_SIZE\t\n")
+	} else {
+		fmt.Fprint(w, "LIVE_BLOCKS\tRECLAIMED_BLOCKS\tRECLAIMED_SIZE\t\n")
+	}
+}
+
+func PrintGarbageCollectResponse(w io.Writer, response *pfs.GarbageCollectResponse) {
+	fmt.Fprintf(w, "%d\t", response.LiveBlocks)
+	fmt.Fprintf(w, "%d\t", response.CollectedBlocks)
+	fmt.Fprintf(w, "%s\t\n", units.BytesSize(float64(response.CollectedBytes)))
 }
 
 type uint64Slice []uint64
@@ -1,6 +1,8 @@
 package pfs
 
 import (
+	"container/heap"
+	"io"
 	"sort"
 
 	"github.com/pachyderm/pachyderm/src/client/pfs"
@@ -37,6 +39,7 @@ func ReduceCommitInfos(commitInfos []*pfs.CommitInfo) []*pfs.CommitInfo {
 			reducedCommitInfo.CommitType = pfs.CommitType_COMMIT_TYPE_WRITE
 		}
 		reducedCommitInfo.SizeBytes += commitInfo.SizeBytes
+		reducedCommitInfo.FileCount += commitInfo.FileCount
 	}
 	var result []*pfs.CommitInfo
 	for _, commitInfo := range reducedCommitInfos {
@@ -46,6 +49,25 @@ func ReduceCommitInfos(commitInfos []*pfs.CommitInfo) []*pfs.CommitInfo {
 	return result
 }
 
+// ReduceBranchInfos removes the duplicate branches reported by different
+// servers and sorts them by name.
+func ReduceBranchInfos(branchInfos []*pfs.BranchInfo) []*pfs.BranchInfo {
+	reducedBranchInfos := make(map[string]*pfs.BranchInfo)
+	for _, branchInfo := range branchInfos {
+		if _, ok := reducedBranchInfos[branchInfo.Name]; !ok {
+			reducedBranchInfos[branchInfo.Name] = branchInfo
+		}
+	}
+	var result []*pfs.BranchInfo
+	for _, branchInfo := range reducedBranchInfos {
+		result = append(result, branchInfo)
+	}
+	sort.Sort(sortBranchInfos(result))
+	return result
+}
+
+// ReduceFileInfos merges the infos for the same path, which directories have
+// in every shard with files under them, and sorts the result by path.
 func ReduceFileInfos(fileInfos []*pfs.FileInfo) []*pfs.FileInfo {
 	reducedFileInfos := make(map[string]*pfs.FileInfo)
 	for _, fileInfo := range fileInfos {
@@ -54,20 +76,148 @@ func ReduceFileInfos(fileInfos []*pfs.FileInfo) []*pfs.FileInfo {
 			reducedFileInfos[fileInfo.File.Path] = fileInfo
 			continue
 		}
-		if prototime.TimestampToTime(fileInfo.Modified).
-			After(prototime.TimestampToTime(reducedFileInfo.Modified)) {
-			reducedFileInfo.Modified = fileInfo.Modified
-			reducedFileInfo.CommitModified = fileInfo.CommitModified
-		}
-		reducedFileInfo.Children = append(reducedFileInfo.Children, fileInfo.Children...)
+		reduceFileInfo(reducedFileInfo, fileInfo)
 	}
 	var result []*pfs.FileInfo
 	for _, reducedFileInfo := range reducedFileInfos {
 		result = append(result, reducedFileInfo)
 	}
+	sort.Sort(sortFileInfos(result))
 	return result
 }
 
+// reduceFileInfo merges fileInfo into reducedFileInfo, they're for the same
+// path.
+func reduceFileInfo(reducedFileInfo *pfs.FileInfo, fileInfo *pfs.FileInfo) {
+	if prototime.TimestampToTime(fileInfo.Modified).
+		After(prototime.TimestampToTime(reducedFileInfo.Modified)) {
+		reducedFileInfo.Modified = fileInfo.Modified
+		reducedFileInfo.CommitModified = fileInfo.CommitModified
+	}
+	reducedFileInfo.Children = append(reducedFileInfo.Children, fileInfo.Children...)
+}
+
+// PageFileInfos sorts fileInfos by path and returns those after pageToken, a
+// path, up to limit of them. An empty pageToken and a limit of 0 are ignored.
+func PageFileInfos(fileInfos []*pfs.FileInfo, pageToken string, limit uint64) []*pfs.FileInfo {
+	sort.Sort(sortFileInfos(fileInfos))
+	start := sort.Search(len(fileInfos), func(i int) bool {
+		return fileInfos[i].File.Path > pageToken
+	})
+	fileInfos = fileInfos[start:]
+	if limit != 0 && uint64(len(fileInfos)) > limit {
+		fileInfos = fileInfos[:limit]
+	}
+	return fileInfos
+}
+
+// FileInfoStream is a stream of FileInfos sorted by path, Recv returns
+// io.EOF at the end of the stream. ListFileStream clients implement it.
+type FileInfoStream interface {
+	Recv() (*pfs.FileInfo, error)
+}
+
+// NewFileInfoStream returns a FileInfoStream of fileInfos, which must be
+// sorted by path.
+func NewFileInfoStream(fileInfos []*pfs.FileInfo) FileInfoStream {
+	return &sliceFileInfoStream{fileInfos}
+}
+
+type sliceFileInfoStream struct {
+	fileInfos []*pfs.FileInfo
+}
+
+func (s *sliceFileInfoStream) Recv() (*pfs.FileInfo, error) {
+	if len(s.fileInfos) == 0 {
+		return nil, io.EOF
+	}
+	fileInfo := s.fileInfos[0]
+	s.fileInfos = s.fileInfos[1:]
+	return fileInfo, nil
+}
+
+// MergeFileInfos calls f with the FileInfos from streams in order of path,
+// it only buffers one FileInfo from each stream. The FileInfos for the same
+// path in different streams are merged as in ReduceFileInfos. f is called at
+// most limit times unless limit is 0.
+func MergeFileInfos(streams []FileInfoStream, limit uint64, f func(*pfs.FileInfo) error) error {
+	var heads fileInfoHeap
+	next := func(stream FileInfoStream) error {
+		fileInfo, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		heap.Push(&heads, fileInfoHead{fileInfo, stream})
+		return nil
+	}
+	for _, stream := range streams {
+		if err := next(stream); err != nil {
+			return err
+		}
+	}
+	var sent uint64
+	for heads.Len() > 0 && (limit == 0 || sent < limit) {
+		head := heap.Pop(&heads).(fileInfoHead)
+		if err := next(head.stream); err != nil {
+			return err
+		}
+		for heads.Len() > 0 && heads[0].fileInfo.File.Path == head.fileInfo.File.Path {
+			other := heap.Pop(&heads).(fileInfoHead)
+			reduceFileInfo(head.fileInfo, other.fileInfo)
+			if err := next(other.stream); err != nil {
+				return err
+			}
+		}
+		if err := f(head.fileInfo); err != nil {
+			return err
+		}
+		sent++
+	}
+	return nil
+}
+
+type fileInfoHead struct {
+	fileInfo *pfs.FileInfo
+	stream   FileInfoStream
+}
+
+// fileInfoHeap is a min heap of the next FileInfo of each stream being
+// merged, by path.
+type fileInfoHeap []fileInfoHead
+
+func (h fileInfoHeap) Len() int {
+	return len(h)
+}
+
+func (h fileInfoHeap) Less(i, j int) bool {
+	return h[i].fileInfo.File.Path < h[j].fileInfo.File.Path
+}
+
+func (h fileInfoHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+}
+
+func (h *fileInfoHeap) Push(x interface{}) {
+	*h = append(*h, x.(fileInfoHead))
+}
+
+func (h *fileInfoHeap) Pop() interface{} {
+	old := *h
+	head := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return head
+}
+
+// ReduceFileChanges sorts fileChanges by path, files are only ever in one
+// shard so there's nothing to merge.
+func ReduceFileChanges(fileChanges []*pfs.FileChange) []*pfs.FileChange {
+	sort.Sort(sortFileChanges(fileChanges))
+	return fileChanges
+}
+
 type sortRepoInfos []*pfs.RepoInfo
 
 func (a sortRepoInfos) Len() int {
@@ -104,3 +254,49 @@ func (a sortCommitInfos) Swap(i, j int) {
 	a[i] = a[j]
 	a[j] = tmp
 }
+
+type sortBranchInfos []*pfs.BranchInfo
+
+func (a sortBranchInfos) Len() int {
+	return len(a)
+}
+
+func (a sortBranchInfos) Less(i, j int) bool {
+	return a[i].Name < a[j].Name
+}
+
+func (a sortBranchInfos) Swap(i, j int) {
+	tmp := a[i]
+	a[i] = a[j]
+	a[j] = tmp
+}
+
+type sortFileChanges []*pfs.FileChange
+
+func (a sortFileChanges) Len() int {
+	return len(a)
+}
+
+func (a sortFileChanges) Less(i, j int) bool {
+	return a[i].File.Path < a[j].File.Path
+}
+
+func (a sortFileChanges) Swap(i, j int) {
+	tmp := a[i]
+	a[i] = a[j]
+	a[j] = tmp
+}
+
+type sortFileInfos []*pfs.FileInfo
+
+func (a sortFileInfos) Len() int {
+	return len(a)
+}
+
+func (a sortFileInfos) Less(i, j int) bool {
+	return a[i].File.Path < a[j].File.Path
+}
+
+func (a sortFileInfos) Swap(i, j int) {
+	a[i], a[j] = a[j], a[i]
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"math/rand"
+	"path"
 	"path/filepath"
 	"strings"
 	"sync"
@@ -15,15 +16,21 @@ import (
 	"github.com/pachyderm/pachyderm/src/client/pkg/uuid"
 	pfsserver "github.com/pachyderm/pachyderm/src/server/pfs"
 	"github.com/pachyderm/pachyderm/src/server/pkg/metrics"
+	"go.pedge.io/lion/proto"
 	"go.pedge.io/pb/go/google/protobuf"
 	"go.pedge.io/proto/rpclog"
 	"go.pedge.io/proto/stream"
 	"go.pedge.io/proto/time"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 )
 
+// copyFileParallelism is how many files and directories CopyFile copies at
+// once.
+const copyFileParallelism = 16
+
 type apiServer struct {
 	protorpclog.Logger
 	hasher  *pfsserver.Hasher
@@ -183,6 +190,23 @@ func (a *apiServer) DeleteRepo(ctx context.Context, request *pfs.DeleteRepoReque
 
 }
 
+func (a *apiServer) SetQuota(ctx context.Context, request *pfs.SetQuotaRequest) (response *google_protobuf.Empty, retErr error) {
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+	a.versionLock.RLock()
+	defer a.versionLock.RUnlock()
+	ctx = versionToContext(a.version, ctx)
+	clientConns, err := a.router.GetAllClientConns(a.version)
+	if err != nil {
+		return nil, err
+	}
+	for _, clientConn := range clientConns {
+		if _, err := pfs.NewInternalAPIClient(clientConn).SetQuota(ctx, request); err != nil {
+			return nil, err
+		}
+	}
+	return google_protobuf.EmptyInstance, nil
+}
+
 func (a *apiServer) StartCommit(ctx context.Context, request *pfs.StartCommitRequest) (response *pfs.Commit, retErr error) {
 	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
 	a.versionLock.RLock()
@@ -202,8 +226,20 @@ func (a *apiServer) StartCommit(ctx context.Context, request *pfs.StartCommitReq
 	}
 	request.ID = uuid.NewWithoutDashes()
 	request.Started = prototime.TimeToTimestamp(time.Now())
-	for _, clientConn := range clientConns {
+	for i, clientConn := range clientConns {
 		if _, err := pfs.NewInternalAPIClient(clientConn).StartCommit(ctx, request); err != nil {
+			// the commit may have started on the other servers, a merge
+			// conflict for example is only found by the server with the
+			// conflicting file. The original error is returned so that its
+			// code is kept, rollback failures can only be logged.
+			for _, clientConn := range clientConns[:i] {
+				if _, rollbackErr := pfs.NewInternalAPIClient(clientConn).DeleteCommit(ctx, &pfs.DeleteCommitRequest{
+					Commit: client.NewCommit(request.Repo.Name, request.ID),
+					Force:  true,
+				}); rollbackErr != nil {
+					protolion.Errorf("error rolling back commit %s/%s after %s: %s", request.Repo.Name, request.ID, err.Error(), rollbackErr.Error())
+				}
+			}
 			return nil, err
 		}
 	}
@@ -219,6 +255,11 @@ func (a *apiServer) FinishCommit(ctx context.Context, request *pfs.FinishCommitR
 	if err != nil {
 		return nil, err
 	}
+	if !request.Cancel {
+		if err := checkQuota(ctx, clientConns, request.Commit); err != nil {
+			return nil, err
+		}
+	}
 	request.Finished = prototime.TimeToTimestamp(time.Now())
 	for _, clientConn := range clientConns {
 		if _, err := pfs.NewInternalAPIClient(clientConn).FinishCommit(ctx, request); err != nil {
@@ -228,6 +269,34 @@ func (a *apiServer) FinishCommit(ctx context.Context, request *pfs.FinishCommitR
 	return google_protobuf.EmptyInstance, nil
 }
 
+// checkQuota checks the quota of commit's repo against the usage in every
+// shard, the internal servers can only check the shards they have.
+func checkQuota(ctx context.Context, clientConns []*grpc.ClientConn, commit *pfs.Commit) error {
+	var repoInfos []*pfs.RepoInfo
+	var commitInfos []*pfs.CommitInfo
+	for _, clientConn := range clientConns {
+		repoInfo, err := pfs.NewInternalAPIClient(clientConn).InspectRepo(ctx, &pfs.InspectRepoRequest{Repo: commit.Repo})
+		if err != nil {
+			return err
+		}
+		repoInfos = append(repoInfos, repoInfo)
+		commitInfo, err := pfs.NewInternalAPIClient(clientConn).InspectCommit(ctx, &pfs.InspectCommitRequest{Commit: commit})
+		if err != nil {
+			return err
+		}
+		commitInfos = append(commitInfos, commitInfo)
+	}
+	repoInfos = pfsserver.ReduceRepoInfos(repoInfos)
+	commitInfos = pfsserver.ReduceCommitInfos(commitInfos)
+	if len(repoInfos) != 1 || len(commitInfos) != 1 {
+		return fmt.Errorf("incorrect repo or commit returned (this is likely a bug)")
+	}
+	if err := pfsserver.CheckQuota(repoInfos[0], commitInfos[0]); err != nil {
+		return grpcErrorf(codes.ResourceExhausted, "%v", err)
+	}
+	return nil
+}
+
 func (a *apiServer) InspectCommit(ctx context.Context, request *pfs.InspectCommitRequest) (response *pfs.CommitInfo, retErr error) {
 	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
 	a.versionLock.RLock()
@@ -271,7 +340,9 @@ func (a *apiServer) InspectCommit(ctx context.Context, request *pfs.InspectCommi
 
 	commitInfos = pfsserver.ReduceCommitInfos(commitInfos)
 
-	if len(commitInfos) != 1 || commitInfos[0].Commit.ID != request.Commit.ID {
+	// request.Commit may be a branch, in which case the returned commit is
+	// its head, so all we can check is that the shards agree.
+	if len(commitInfos) != 1 {
 		return nil, fmt.Errorf("incorrect commit returned (this is likely a bug)")
 	}
 
@@ -319,7 +390,25 @@ func (a *apiServer) ListCommit(ctx context.Context, request *pfs.ListCommitReque
 	return &pfs.CommitInfos{CommitInfo: pfsserver.ReduceCommitInfos(commitInfos)}, nil
 }
 
+// ListBranch is deprecated, it's ListBranchInfo without the branches' names.
 func (a *apiServer) ListBranch(ctx context.Context, request *pfs.ListBranchRequest) (response *pfs.CommitInfos, retErr error) {
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+	branchInfos, err := a.ListBranchInfo(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	response = &pfs.CommitInfos{}
+	for _, branchInfo := range branchInfos.BranchInfo {
+		commitInfo, err := a.InspectCommit(ctx, &pfs.InspectCommitRequest{Commit: branchInfo.Head})
+		if err != nil {
+			return nil, err
+		}
+		response.CommitInfo = append(response.CommitInfo, commitInfo)
+	}
+	return response, nil
+}
+
+func (a *apiServer) ListBranchInfo(ctx context.Context, request *pfs.ListBranchRequest) (response *pfs.BranchInfos, retErr error) {
 	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
 	a.versionLock.RLock()
 	defer a.versionLock.RUnlock()
@@ -330,13 +419,13 @@ func (a *apiServer) ListBranch(ctx context.Context, request *pfs.ListBranchReque
 	}
 	var wg sync.WaitGroup
 	var lock sync.Mutex
-	var commitInfos []*pfs.CommitInfo
+	var branchInfos []*pfs.BranchInfo
 	errCh := make(chan error, 1)
 	for _, clientConn := range clientConns {
 		wg.Add(1)
 		go func(clientConn *grpc.ClientConn) {
 			defer wg.Done()
-			subCommitInfos, err := pfs.NewInternalAPIClient(clientConn).ListBranch(ctx, request)
+			subBranchInfos, err := pfs.NewInternalAPIClient(clientConn).ListBranchInfo(ctx, request)
 			if err != nil {
 				select {
 				case errCh <- err:
@@ -348,7 +437,7 @@ func (a *apiServer) ListBranch(ctx context.Context, request *pfs.ListBranchReque
 			}
 			lock.Lock()
 			defer lock.Unlock()
-			commitInfos = append(commitInfos, subCommitInfos.CommitInfo...)
+			branchInfos = append(branchInfos, subBranchInfos.BranchInfo...)
 		}(clientConn)
 	}
 	wg.Wait()
@@ -357,7 +446,75 @@ func (a *apiServer) ListBranch(ctx context.Context, request *pfs.ListBranchReque
 		return nil, err
 	default:
 	}
-	return &pfs.CommitInfos{CommitInfo: pfsserver.ReduceCommitInfos(commitInfos)}, nil
+	return &pfs.BranchInfos{BranchInfo: pfsserver.ReduceBranchInfos(branchInfos)}, nil
+}
+
+func (a *apiServer) CreateBranch(ctx context.Context, request *pfs.CreateBranchRequest) (response *google_protobuf.Empty, retErr error) {
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+	a.versionLock.RLock()
+	defer a.versionLock.RUnlock()
+	ctx = versionToContext(a.version, ctx)
+	clientConns, err := a.router.GetAllClientConns(a.version)
+	if err != nil {
+		return nil, err
+	}
+	for _, clientConn := range clientConns {
+		if _, err := pfs.NewInternalAPIClient(clientConn).CreateBranch(ctx, request); err != nil {
+			return nil, err
+		}
+	}
+	return google_protobuf.EmptyInstance, nil
+}
+
+func (a *apiServer) SetBranch(ctx context.Context, request *pfs.SetBranchRequest) (response *google_protobuf.Empty, retErr error) {
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+	a.versionLock.RLock()
+	defer a.versionLock.RUnlock()
+	ctx = versionToContext(a.version, ctx)
+	clientConns, err := a.router.GetAllClientConns(a.version)
+	if err != nil {
+		return nil, err
+	}
+	for _, clientConn := range clientConns {
+		if _, err := pfs.NewInternalAPIClient(clientConn).SetBranch(ctx, request); err != nil {
+			return nil, err
+		}
+	}
+	return google_protobuf.EmptyInstance, nil
+}
+
+func (a *apiServer) DeleteBranch(ctx context.Context, request *pfs.DeleteBranchRequest) (response *google_protobuf.Empty, retErr error) {
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+	a.versionLock.RLock()
+	defer a.versionLock.RUnlock()
+	ctx = versionToContext(a.version, ctx)
+	clientConns, err := a.router.GetAllClientConns(a.version)
+	if err != nil {
+		return nil, err
+	}
+	for _, clientConn := range clientConns {
+		if _, err := pfs.NewInternalAPIClient(clientConn).DeleteBranch(ctx, request); err != nil {
+			return nil, err
+		}
+	}
+	return google_protobuf.EmptyInstance, nil
+}
+
+func (a *apiServer) RenameBranch(ctx context.Context, request *pfs.RenameBranchRequest) (response *google_protobuf.Empty, retErr error) {
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+	a.versionLock.RLock()
+	defer a.versionLock.RUnlock()
+	ctx = versionToContext(a.version, ctx)
+	clientConns, err := a.router.GetAllClientConns(a.version)
+	if err != nil {
+		return nil, err
+	}
+	for _, clientConn := range clientConns {
+		if _, err := pfs.NewInternalAPIClient(clientConn).RenameBranch(ctx, request); err != nil {
+			return nil, err
+		}
+	}
+	return google_protobuf.EmptyInstance, nil
 }
 
 func (a *apiServer) DeleteCommit(ctx context.Context, request *pfs.DeleteCommitRequest) (response *google_protobuf.Empty, retErr error) {
@@ -532,6 +689,29 @@ func (a *apiServer) InspectFile(ctx context.Context, request *pfs.InspectFileReq
 	a.versionLock.RLock()
 	defer a.versionLock.RUnlock()
 	ctx = versionToContext(a.version, ctx)
+	listFileRequest, err := a.literalListFileRequest(ctx, &pfs.ListFileRequest{
+		File:       request.File,
+		Shard:      request.Shard,
+		FromCommit: request.FromCommit,
+		Unsafe:     request.Unsafe,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if pfsserver.IsGlob(request.File.Path) && !listFileRequest.Literal {
+		fileInfo, err := a.inspectGlob(ctx, request)
+		if err != nil || !request.Sha256 || fileInfo.FileType != pfs.FileType_FILE_TYPE_REGULAR {
+			return fileInfo, err
+		}
+		// inspect the match itself to get its SHA-256
+		request = &pfs.InspectFileRequest{
+			File:       fileInfo.File,
+			Shard:      request.Shard,
+			FromCommit: request.FromCommit,
+			Unsafe:     request.Unsafe,
+			Sha256:     true,
+		}
+	}
 	clientConn, err := a.getClientConnForFile(request.File, a.version)
 	if err != nil {
 		return nil, err
@@ -554,11 +734,87 @@ func (a *apiServer) InspectFile(ctx context.Context, request *pfs.InspectFileReq
 	return fileInfo, nil
 }
 
+// fileExists returns true if request.File.Path exists as a path, paths with
+// glob metacharacters are only globs if they don't.
+func (a *apiServer) fileExists(ctx context.Context, request *pfs.InspectFileRequest) bool {
+	clientConn, err := a.getClientConnForFile(request.File, a.version)
+	if err != nil {
+		return false
+	}
+	_, err = pfs.NewInternalAPIClient(clientConn).InspectFile(ctx, &pfs.InspectFileRequest{
+		File:       request.File,
+		Shard:      request.Shard,
+		FromCommit: request.FromCommit,
+		Unsafe:     request.Unsafe,
+	})
+	return err == nil
+}
+
+// literalListFileRequest returns request with Literal set if its path has
+// glob metacharacters but exists as a path.
+func (a *apiServer) literalListFileRequest(ctx context.Context, request *pfs.ListFileRequest) (*pfs.ListFileRequest, error) {
+	if !pfsserver.IsGlob(request.File.Path) || request.Literal {
+		return request, nil
+	}
+	literalRequest := *request
+	literalRequest.Literal = true
+	if !a.fileExists(ctx, &pfs.InspectFileRequest{
+		File:       request.File,
+		Shard:      request.Shard,
+		FromCommit: request.FromCommit,
+		Unsafe:     request.Unsafe,
+	}) {
+		// a directory's children may be in any shard, listing it is the
+		// only way to be sure it doesn't exist
+		probeRequest := literalRequest
+		probeRequest.PageToken = ""
+		probeRequest.Limit = 1
+		fileInfos, err := a.ListFile(ctx, &probeRequest)
+		if err != nil {
+			return nil, err
+		}
+		if len(fileInfos.FileInfo) == 0 {
+			return request, nil
+		}
+	}
+	return &literalRequest, nil
+}
+
+// inspectGlob returns the only file matching the glob request.File.Path, it
+// errors if there isn't exactly one match.
+func (a *apiServer) inspectGlob(ctx context.Context, request *pfs.InspectFileRequest) (*pfs.FileInfo, error) {
+	fileInfos, err := a.ListFile(ctx, &pfs.ListFileRequest{
+		File:       request.File,
+		Shard:      request.Shard,
+		FromCommit: request.FromCommit,
+		Unsafe:     request.Unsafe,
+	})
+	if err != nil {
+		return nil, err
+	}
+	switch len(fileInfos.FileInfo) {
+	case 0:
+		return nil, grpcErrorf(codes.NotFound, "no files match %s", request.File.Path)
+	case 1:
+		fileInfo := fileInfos.FileInfo[0]
+		if fileInfo.FileType == pfs.FileType_FILE_TYPE_DIR {
+			fileInfo.Children = nil
+		}
+		return fileInfo, nil
+	default:
+		return nil, fmt.Errorf("%d files match %s, InspectFile needs exactly one", len(fileInfos.FileInfo), request.File.Path)
+	}
+}
+
 func (a *apiServer) ListFile(ctx context.Context, request *pfs.ListFileRequest) (response *pfs.FileInfos, retErr error) {
 	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
 	a.versionLock.RLock()
 	defer a.versionLock.RUnlock()
 	ctx = versionToContext(a.version, ctx)
+	request, err := a.literalListFileRequest(ctx, request)
+	if err != nil {
+		return nil, err
+	}
 	clientConns, err := a.router.GetAllClientConns(a.version)
 	if err != nil {
 		return nil, err
@@ -601,11 +857,253 @@ func (a *apiServer) ListFile(ctx context.Context, request *pfs.ListFileRequest)
 		return nil, err
 	default:
 	}
+	fileInfos = pfsserver.ReduceFileInfos(fileInfos)
+	if request.Limit != 0 && uint64(len(fileInfos)) > request.Limit {
+		fileInfos = fileInfos[:request.Limit]
+	}
 	return &pfs.FileInfos{
-		FileInfo: pfsserver.ReduceFileInfos(fileInfos),
+		FileInfo: fileInfos,
 	}, nil
 }
 
+func (a *apiServer) ListFileStream(request *pfs.ListFileRequest, listFileServer pfs.API_ListFileStreamServer) (retErr error) {
+	defer func(start time.Time) { a.Log(request, nil, retErr, time.Since(start)) }(time.Now())
+	a.versionLock.RLock()
+	defer a.versionLock.RUnlock()
+	ctx, cancel := context.WithCancel(versionToContext(a.version, listFileServer.Context()))
+	// cancel the remaining streams if we stop early because of the limit
+	defer cancel()
+	request, err := a.literalListFileRequest(ctx, request)
+	if err != nil {
+		return err
+	}
+	clientConns, err := a.router.GetAllClientConns(a.version)
+	if err != nil {
+		return err
+	}
+	var streams []pfsserver.FileInfoStream
+	for _, clientConn := range clientConns {
+		stream, err := pfs.NewInternalAPIClient(clientConn).ListFileStream(ctx, request)
+		if err != nil {
+			return err
+		}
+		streams = append(streams, stream)
+	}
+	return pfsserver.MergeFileInfos(streams, request.Limit, listFileServer.Send)
+}
+
+func (a *apiServer) GlobFile(request *pfs.GlobFileRequest, globFileServer pfs.API_GlobFileServer) (retErr error) {
+	defer func(start time.Time) { a.Log(request, nil, retErr, time.Since(start)) }(time.Now())
+	a.versionLock.RLock()
+	defer a.versionLock.RUnlock()
+	ctx := versionToContext(a.version, globFileServer.Context())
+	return a.globFile(ctx, request, globFileServer.Send)
+}
+
+// globFile calls f with each file and directory matching request, f isn't
+// called concurrently. Directories are returned without their children,
+// after the files.
+func (a *apiServer) globFile(ctx context.Context, request *pfs.GlobFileRequest, f func(*pfs.FileInfo) error) error {
+	clientConns, err := a.router.GetAllClientConns(a.version)
+	if err != nil {
+		return err
+	}
+	var lock sync.Mutex
+	// directories are in every shard with files under them, they're merged
+	// once every shard has replied
+	var dirInfos []*pfs.FileInfo
+	send := func(fileInfo *pfs.FileInfo) error {
+		lock.Lock()
+		defer lock.Unlock()
+		if fileInfo.FileType == pfs.FileType_FILE_TYPE_DIR {
+			dirInfos = append(dirInfos, fileInfo)
+			return nil
+		}
+		return f(fileInfo)
+	}
+	var wg sync.WaitGroup
+	errCh := make(chan error, 1)
+	for _, clientConn := range clientConns {
+		wg.Add(1)
+		go func(clientConn *grpc.ClientConn) {
+			defer wg.Done()
+			if err := func() error {
+				globFileClient, err := pfs.NewInternalAPIClient(clientConn).GlobFile(ctx, request)
+				if err != nil {
+					return err
+				}
+				for {
+					fileInfo, err := globFileClient.Recv()
+					if err == io.EOF {
+						return nil
+					}
+					if err != nil {
+						return err
+					}
+					if err := send(fileInfo); err != nil {
+						return err
+					}
+				}
+			}(); err != nil {
+				select {
+				case errCh <- err:
+					// error reported
+				default:
+					// not the first error
+				}
+			}
+		}(clientConn)
+	}
+	wg.Wait()
+	select {
+	case err := <-errCh:
+		return err
+	default:
+	}
+	for _, dirInfo := range pfsserver.ReduceFileInfos(dirInfos) {
+		// as in InspectFile we'd rather return no children than a partial
+		// list of them
+		dirInfo.Children = nil
+		if err := f(dirInfo); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *apiServer) CopyFile(ctx context.Context, request *pfs.CopyFileRequest) (response *google_protobuf.Empty, retErr error) {
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+	a.versionLock.RLock()
+	defer a.versionLock.RUnlock()
+	ctx = versionToContext(a.version, ctx)
+	if strings.HasPrefix(request.Dst.Path, "/") {
+		// see PutFile
+		return nil, fmt.Errorf("pachyderm: leading slash in path: %s", request.Dst.Path)
+	}
+	srcInfo, err := a.InspectFile(ctx, &pfs.InspectFileRequest{File: request.Src})
+	if err != nil {
+		return nil, err
+	}
+	// files maps the paths of the regular files to copy to their
+	// destinations, dstDirs are the directories which need to exist for them
+	files := make(map[string]string)
+	dstDirs := make(map[string]bool)
+	addDirs := func(dstPath string) {
+		for _, dir := range dirs(dstPath) {
+			dstDirs[dir] = true
+		}
+	}
+	if srcInfo.FileType == pfs.FileType_FILE_TYPE_DIR {
+		srcDir := path.Clean(srcInfo.File.Path)
+		if err := a.globFile(ctx, &pfs.GlobFileRequest{
+			File: &pfs.File{
+				Commit: request.Src.Commit,
+				Path:   path.Join(pfsserver.GlobEscape(srcDir), "**"),
+			},
+		}, func(fileInfo *pfs.FileInfo) error {
+			rel, err := filepath.Rel(srcDir, path.Clean(fileInfo.File.Path))
+			if err != nil {
+				return err
+			}
+			if rel == ".." || strings.HasPrefix(rel, "../") {
+				return fmt.Errorf("pachyderm: %s isn't under %s", fileInfo.File.Path, srcDir)
+			}
+			dstPath := path.Join(request.Dst.Path, rel)
+			if fileInfo.FileType == pfs.FileType_FILE_TYPE_DIR {
+				dstDirs[dstPath] = true
+			} else {
+				files[fileInfo.File.Path] = dstPath
+			}
+			addDirs(dstPath)
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	} else {
+		files[srcInfo.File.Path] = request.Dst.Path
+		addDirs(request.Dst.Path)
+	}
+	delete(dstDirs, ".")
+
+	var wg sync.WaitGroup
+	errCh := make(chan error, 1)
+	limiter := make(chan struct{}, copyFileParallelism)
+	do := func(f func() error) {
+		wg.Add(1)
+		limiter <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-limiter }()
+			if err := f(); err != nil {
+				select {
+				case errCh <- err:
+					// error reported
+				default:
+					// not the first error
+				}
+			}
+		}()
+	}
+	for srcPath, dstPath := range files {
+		src := &pfs.File{Commit: request.Src.Commit, Path: srcPath}
+		dst := &pfs.File{Commit: request.Dst.Commit, Path: dstPath}
+		do(func() error {
+			return a.copyBlockRefs(ctx, src, dst, request.Overwrite)
+		})
+	}
+	for dir := range dstDirs {
+		dst := &pfs.File{Commit: request.Dst.Commit, Path: dir}
+		do(func() error {
+			clientConn, err := a.getClientConnForFile(dst, a.version)
+			if err != nil {
+				return err
+			}
+			putFileClient, err := pfs.NewInternalAPIClient(clientConn).PutFile(ctx)
+			if err != nil {
+				return err
+			}
+			if err := putFileClient.Send(&pfs.PutFileRequest{
+				File:     dst,
+				FileType: pfs.FileType_FILE_TYPE_DIR,
+			}); err != nil {
+				return err
+			}
+			_, err = putFileClient.CloseAndRecv()
+			return err
+		})
+	}
+	wg.Wait()
+	select {
+	case err := <-errCh:
+		return nil, err
+	default:
+	}
+	return google_protobuf.EmptyInstance, nil
+}
+
+// copyBlockRefs appends the blocks of the regular file src to dst, the files
+// may be in different shards.
+func (a *apiServer) copyBlockRefs(ctx context.Context, src *pfs.File, dst *pfs.File, overwrite bool) error {
+	srcClientConn, err := a.getClientConnForFile(src, a.version)
+	if err != nil {
+		return err
+	}
+	blockRefs, err := pfs.NewInternalAPIClient(srcClientConn).GetFileBlockRefs(ctx, &pfs.InspectFileRequest{File: src})
+	if err != nil {
+		return err
+	}
+	dstClientConn, err := a.getClientConnForFile(dst, a.version)
+	if err != nil {
+		return err
+	}
+	_, err = pfs.NewInternalAPIClient(dstClientConn).PutFileBlockRefs(ctx, &pfs.PutFileBlockRefsRequest{
+		File:      dst,
+		BlockRef:  blockRefs.BlockRef,
+		Overwrite: overwrite,
+	})
+	return err
+}
+
 func (a *apiServer) DeleteFile(ctx context.Context, request *pfs.DeleteFileRequest) (response *google_protobuf.Empty, retErr error) {
 	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
 	a.versionLock.RLock()
@@ -660,6 +1158,89 @@ func (a *apiServer) DeleteFile(ctx context.Context, request *pfs.DeleteFileReque
 	return google_protobuf.EmptyInstance, nil
 }
 
+func (a *apiServer) DiffCommit(ctx context.Context, request *pfs.DiffCommitRequest) (response *pfs.FileChanges, retErr error) {
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+	a.versionLock.RLock()
+	defer a.versionLock.RUnlock()
+	ctx = versionToContext(a.version, ctx)
+	clientConns, err := a.router.GetAllClientConns(a.version)
+	if err != nil {
+		return nil, err
+	}
+	var wg sync.WaitGroup
+	var lock sync.Mutex
+	var fileChanges []*pfs.FileChange
+	errCh := make(chan error, 1)
+	for _, clientConn := range clientConns {
+		wg.Add(1)
+		go func(clientConn *grpc.ClientConn) {
+			defer wg.Done()
+			subFileChanges, err := pfs.NewInternalAPIClient(clientConn).DiffCommit(ctx, request)
+			if err != nil {
+				select {
+				case errCh <- err:
+					// error reported
+				default:
+					// not the first error
+				}
+				return
+			}
+			lock.Lock()
+			defer lock.Unlock()
+			fileChanges = append(fileChanges, subFileChanges.FileChange...)
+		}(clientConn)
+	}
+	wg.Wait()
+	select {
+	case err := <-errCh:
+		return nil, err
+	default:
+	}
+	return &pfs.FileChanges{
+		FileChange: pfsserver.ReduceFileChanges(fileChanges),
+	}, nil
+}
+
+func (a *apiServer) GarbageCollect(ctx context.Context, request *pfs.GarbageCollectRequest) (response *pfs.GarbageCollectResponse, retErr error) {
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+	a.versionLock.RLock()
+	defer a.versionLock.RUnlock()
+	ctx = versionToContext(a.version, ctx)
+	// Open commits haven't written their diffs yet so the block store can't
+	// see which blocks they reference, we protect every block written since
+	// the oldest of them started.
+	repoInfos, err := a.ListRepo(ctx, &pfs.ListRepoRequest{})
+	if err != nil {
+		return nil, err
+	}
+	if len(repoInfos.RepoInfo) > 0 {
+		var repos []*pfs.Repo
+		for _, repoInfo := range repoInfos.RepoInfo {
+			repos = append(repos, repoInfo.Repo)
+		}
+		commitInfos, err := a.ListCommit(ctx, &pfs.ListCommitRequest{
+			Repo:       repos,
+			CommitType: pfs.CommitType_COMMIT_TYPE_WRITE,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if started, ok := oldestStarted(commitInfos.CommitInfo); ok &&
+			(request.Before == nil || started.Before(prototime.TimestampToTime(request.Before))) {
+			request = &pfs.GarbageCollectRequest{
+				Before: prototime.TimeToTimestamp(started),
+				DryRun: request.DryRun,
+			}
+		}
+	}
+	// every pachd serves the block API in front of the same store
+	clientConn, err := a.getClientConn(a.version)
+	if err != nil {
+		return nil, err
+	}
+	return pfs.NewBlockAPIClient(clientConn).GarbageCollect(ctx, request)
+}
+
 func (a *apiServer) Version(version int64) error {
 	a.versionLock.Lock()
 	defer a.versionLock.Unlock()
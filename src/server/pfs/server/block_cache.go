@@ -0,0 +1,221 @@
+package server
+
+import (
+	"container/list"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.pedge.io/lion/proto"
+)
+
+const (
+	// defaultBlockCacheSize is the number of bytes of blocks which object
+	// storage block servers cache on local disk by default.
+	defaultBlockCacheSize uint64 = 1024 * 1024 * 1024 // 1 Gigabyte
+)
+
+// blockCache is an on disk LRU cache of blocks stored in object storage.
+// Blocks are content addressed so cached blocks never go stale.
+type blockCache struct {
+	// dir holds the cached blocks, one file per block named by its hash
+	dir string
+	// tmpDir holds blocks while they're being downloaded
+	tmpDir   string
+	capacity uint64
+
+	lock sync.Mutex
+	size uint64
+	// lru is ordered from most to least recently used, its values are
+	// *blockCacheEntry
+	lru     *list.List
+	entries map[string]*list.Element
+
+	hits      uint64
+	misses    uint64
+	evictions uint64
+}
+
+type blockCacheEntry struct {
+	hash string
+	size uint64
+}
+
+// newBlockCache returns a cache in dir, blocks already in dir (from a previous
+// run) are added to the cache in order of modification.
+func newBlockCache(dir string, tmpDir string, capacity uint64) (*blockCache, error) {
+	c := &blockCache{
+		dir:      dir,
+		tmpDir:   tmpDir,
+		capacity: capacity,
+		lru:      list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+	stats, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	sort.Sort(byModTime(stats))
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for _, stat := range stats {
+		c.entries[stat.Name()] = c.lru.PushFront(&blockCacheEntry{stat.Name(), uint64(stat.Size())})
+		c.size += uint64(stat.Size())
+	}
+	if err := c.evict(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// open returns the block with hash from the cache, if it isn't cached it's
+// read from fetch and added to the cache. The returned file may have been
+// evicted by the time it's read, that's fine since it's already open.
+func (c *blockCache) open(hash string, fetch func() (io.ReadCloser, error)) (*os.File, error) {
+	file, err := c.openCached(hash)
+	if err != nil {
+		return nil, err
+	}
+	if file != nil {
+		atomic.AddUint64(&c.hits, 1)
+		return file, nil
+	}
+	atomic.AddUint64(&c.misses, 1)
+	return c.download(hash, fetch)
+}
+
+func (c *blockCache) openCached(hash string) (*os.File, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	element, ok := c.entries[hash]
+	if !ok {
+		return nil, nil
+	}
+	file, err := os.Open(filepath.Join(c.dir, hash))
+	if err != nil {
+		if os.IsNotExist(err) {
+			// someone removed it from under us, forget about it
+			c.remove(element)
+			return nil, nil
+		}
+		return nil, err
+	}
+	c.lru.MoveToFront(element)
+	return file, nil
+}
+
+func (c *blockCache) download(hash string, fetch func() (io.ReadCloser, error)) (_ *os.File, retErr error) {
+	reader, err := fetch()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := reader.Close(); err != nil && retErr == nil {
+			retErr = err
+		}
+	}()
+	file, err := ioutil.TempFile(c.tmpDir, hash)
+	if err != nil {
+		return nil, err
+	}
+	size, err := io.Copy(file, reader)
+	if err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return nil, err
+	}
+	if _, err := file.Seek(0, 0); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return nil, err
+	}
+	if uint64(size) > c.capacity {
+		// too big to cache, the open file can still be read once it's removed
+		return file, os.Remove(file.Name())
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if err := os.Rename(file.Name(), filepath.Join(c.dir, hash)); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return nil, err
+	}
+	if element, ok := c.entries[hash]; ok {
+		// another download of the same block beat us to it
+		c.lru.MoveToFront(element)
+		return file, nil
+	}
+	c.entries[hash] = c.lru.PushFront(&blockCacheEntry{hash, uint64(size)})
+	c.size += uint64(size)
+	if err := c.evict(); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return file, nil
+}
+
+// delete removes the block with hash from the cache if it's there.
+func (c *blockCache) delete(hash string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	element, ok := c.entries[hash]
+	if !ok {
+		return nil
+	}
+	c.remove(element)
+	if err := os.Remove(filepath.Join(c.dir, hash)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// evict removes the least recently used blocks until the cache fits in its
+// capacity, c.lock must be held.
+func (c *blockCache) evict() error {
+	for c.size > c.capacity {
+		element := c.lru.Back()
+		entry := element.Value.(*blockCacheEntry)
+		c.remove(element)
+		atomic.AddUint64(&c.evictions, 1)
+		if err := os.Remove(filepath.Join(c.dir, entry.hash)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// remove removes element from the index, c.lock must be held.
+func (c *blockCache) remove(element *list.Element) {
+	entry := element.Value.(*blockCacheEntry)
+	c.lru.Remove(element)
+	delete(c.entries, entry.hash)
+	c.size -= entry.size
+}
+
+// logStats logs the cache's counters every interval if they've changed.
+func (c *blockCache) logStats(interval time.Duration) {
+	var lastHits, lastMisses uint64
+	for range time.Tick(interval) {
+		hits := atomic.LoadUint64(&c.hits)
+		misses := atomic.LoadUint64(&c.misses)
+		if hits == lastHits && misses == lastMisses {
+			continue
+		}
+		lastHits, lastMisses = hits, misses
+		c.lock.Lock()
+		size := c.size
+		c.lock.Unlock()
+		protolion.Infof("block cache: %d hits, %d misses, %d evictions, %d/%d bytes used", hits, misses, atomic.LoadUint64(&c.evictions), size, c.capacity)
+	}
+}
+
+type byModTime []os.FileInfo
+
+func (s byModTime) Len() int           { return len(s) }
+func (s byModTime) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s byModTime) Less(i, j int) bool { return s[i].ModTime().Before(s[j].ModTime()) }
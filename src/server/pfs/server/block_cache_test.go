@@ -0,0 +1,135 @@
+package server
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	pclient "github.com/pachyderm/pachyderm/src/client"
+	pfsclient "github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/server/pkg/obj"
+	"go.pedge.io/pb/go/google/protobuf"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+func newTestBlockCache(t *testing.T, capacity uint64) (*blockCache, string) {
+	dir := uniqueString("/tmp/pach_test/cache")
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "block"), 0777))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "tmp"), 0777))
+	cache, err := newBlockCache(filepath.Join(dir, "block"), filepath.Join(dir, "tmp"), capacity)
+	require.NoError(t, err)
+	return cache, dir
+}
+
+func readCached(t *testing.T, cache *blockCache, hash string, data string) string {
+	file, err := cache.open(hash, func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewBufferString(data)), nil
+	})
+	require.NoError(t, err)
+	result, err := ioutil.ReadAll(file)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+	return string(result)
+}
+
+func cached(cache *blockCache, hash string) bool {
+	_, err := os.Stat(filepath.Join(cache.dir, hash))
+	return err == nil
+}
+
+func TestBlockCache(t *testing.T) {
+	t.Parallel()
+	cache, dir := newTestBlockCache(t, 8)
+	require.Equal(t, "foo\n", readCached(t, cache, "a", "foo\n"))
+	require.Equal(t, "bar\n", readCached(t, cache, "b", "bar\n"))
+	// hits don't call fetch
+	require.Equal(t, "foo\n", readCached(t, cache, "a", "wrong"))
+	require.Equal(t, uint64(1), cache.hits)
+	require.Equal(t, uint64(2), cache.misses)
+	// b is now the least recently used
+	require.Equal(t, "buzz", readCached(t, cache, "c", "buzz"))
+	require.True(t, cached(cache, "a"))
+	require.False(t, cached(cache, "b"))
+	require.True(t, cached(cache, "c"))
+	require.Equal(t, uint64(1), cache.evictions)
+	require.Equal(t, uint64(8), cache.size)
+
+	// blocks bigger than the cache are read but not cached
+	require.Equal(t, "too big!\n", readCached(t, cache, "d", "too big!\n"))
+	require.False(t, cached(cache, "d"))
+	require.True(t, cached(cache, "a"))
+
+	require.NoError(t, cache.delete("a"))
+	require.False(t, cached(cache, "a"))
+	require.Equal(t, uint64(4), cache.size)
+
+	// a new cache picks up the blocks already on disk
+	cache, err := newBlockCache(filepath.Join(dir, "block"), filepath.Join(dir, "tmp"), 8)
+	require.NoError(t, err)
+	require.Equal(t, uint64(4), cache.size)
+	require.Equal(t, "buzz", readCached(t, cache, "c", "wrong"))
+}
+
+func TestObjGetBlockCached(t *testing.T) {
+	t.Parallel()
+	server, objClient, put := newTestObjBlockAPIServer(t)
+	require.NotNil(t, server.cache)
+	data := "foo\nbar\n"
+	block := pclient.NewBlock("block")
+	put(server.localServer.blockPath(block), []byte(data), time.Now())
+	getBlock := func(offset uint64, size uint64) string {
+		var buffer bytes.Buffer
+		require.NoError(t, server.GetBlock(&pfsclient.GetBlockRequest{Block: block, OffsetBytes: offset, SizeBytes: size}, &testGetBlockServer{buffer: &buffer}))
+		return buffer.String()
+	}
+	require.Equal(t, data, getBlock(0, 0))
+	// the block is read from the cache once it's been read
+	require.NoError(t, objClient.Delete(server.localServer.blockPath(block)))
+	require.Equal(t, "bar\n", getBlock(4, 4))
+	require.Equal(t, uint64(1), server.cache.hits)
+	_, err := server.DeleteBlock(context.Background(), &pfsclient.DeleteBlockRequest{Block: block})
+	require.NoError(t, err)
+	require.YesError(t, server.GetBlock(&pfsclient.GetBlockRequest{Block: block}, &testGetBlockServer{buffer: &bytes.Buffer{}}))
+}
+
+func TestObjBlockCacheDisabledByEncryption(t *testing.T) {
+	t.Parallel()
+	dir := uniqueString("/tmp/pach_test/run")
+	objClient, err := obj.NewLocalClient(filepath.Join(dir, "obj"))
+	require.NoError(t, err)
+	objClient, err = obj.NewEncryptedClient(objClient, map[string][]byte{"1": bytes.Repeat([]byte{1}, 32)}, "1", false)
+	require.NoError(t, err)
+	server, err := newObjBlockAPIServer(dir, objClient, DefaultBlockAPIServerOptions())
+	require.NoError(t, err)
+	// the cache would hold decrypted blocks
+	require.Nil(t, server.cache)
+}
+
+func TestObjBlockCacheDisabled(t *testing.T) {
+	t.Parallel()
+	dir := uniqueString("/tmp/pach_test/run")
+	objClient, err := obj.NewLocalClient(filepath.Join(dir, "obj"))
+	require.NoError(t, err)
+	options := DefaultBlockAPIServerOptions()
+	options.CacheSize = 0
+	server, err := newObjBlockAPIServer(dir, objClient, options)
+	require.NoError(t, err)
+	require.Nil(t, server.cache)
+}
+
+// testGetBlockServer collects the data sent by GetBlock.
+type testGetBlockServer struct {
+	grpc.ServerStream
+	buffer *bytes.Buffer
+}
+
+func (s *testGetBlockServer) Send(value *google_protobuf.BytesValue) error {
+	_, err := s.buffer.Write(value.Value)
+	return err
+}
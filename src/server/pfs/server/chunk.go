@@ -0,0 +1,154 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+)
+
+const (
+	// chunkWindow is the number of bytes the rolling hash is computed over.
+	chunkWindow = 64
+	// chunkNewlineSearch is how far past a boundary we look for a newline to
+	// cut at instead, the search is bounded so that binary data without
+	// newlines is still cut at content defined boundaries.
+	chunkNewlineSearch = 4 * 1024
+)
+
+var (
+	// buzTable maps each byte to a pseudo random value, it is generated
+	// deterministically so that chunk boundaries are stable across processes.
+	buzTable = newBuzTable()
+)
+
+// ChunkSizes controls how PutBlock splits data into blocks. A boundary is
+// placed where the rolling hash of the last 64 bytes matches a pattern that
+// occurs on average once every Avg bytes, blocks are never smaller than Min
+// (except for the last one) and never larger than Max.
+type ChunkSizes struct {
+	Min int
+	Avg int
+	Max int
+}
+
+func defaultChunkSizes() ChunkSizes {
+	return ChunkSizes{
+		Min: 2 * 1024 * 1024,  // 2 Megabytes
+		Avg: blockSize,        // 8 Megabytes
+		Max: 32 * 1024 * 1024, // 32 Megabytes
+	}
+}
+
+func (c ChunkSizes) validate() error {
+	if c.Min <= 0 || c.Min > c.Avg || c.Avg > c.Max {
+		return fmt.Errorf("invalid chunk sizes min: %d, avg: %d, max: %d, need 0 < min <= avg <= max", c.Min, c.Avg, c.Max)
+	}
+	return nil
+}
+
+// mask returns a mask with roughly log2(Avg - Min) bits set, a boundary
+// occurs when all of the masked bits of the hash are set. We start looking
+// for boundaries after Min bytes so the expected block size is close to Avg.
+func (c ChunkSizes) mask() uint32 {
+	var mask uint32
+	for size := c.Avg - c.Min; size > 1 && mask < 1<<31; size >>= 1 {
+		mask = mask<<1 | 1
+	}
+	return mask
+}
+
+// chunker splits a stream into content defined chunks using a buzhash. Since
+// boundaries depend only on the bytes around them, inserting data into a file
+// only changes the blocks near the insertion and later blocks dedup.
+type chunker struct {
+	reader *bufio.Reader
+	sizes  ChunkSizes
+	mask   uint32
+}
+
+func newChunker(reader *bufio.Reader, sizes ChunkSizes) *chunker {
+	return &chunker{
+		// make sure we can peek far enough to search for a newline
+		reader: bufio.NewReaderSize(reader, chunkNewlineSearch),
+		sizes:  sizes,
+		mask:   sizes.mask(),
+	}
+}
+
+// next returns the next chunk in the stream, eof is true when the stream has
+// been exhausted in which case data contains the last (possibly empty) chunk.
+// If there's a newline shortly after a content defined boundary the chunk is
+// extended to it so that text data stays line aligned, jobs which shard input
+// by block rely on this.
+func (c *chunker) next() (data []byte, eof bool, retErr error) {
+	var buffer bytes.Buffer
+	var hash uint32
+	for buffer.Len() < c.sizes.Max {
+		b, err := c.reader.ReadByte()
+		if err != nil {
+			if err == io.EOF {
+				return buffer.Bytes(), true, nil
+			}
+			return nil, false, err
+		}
+		buffer.WriteByte(b)
+		hash = hash<<1 | hash>>31
+		hash ^= buzTable[b]
+		if buffer.Len() > chunkWindow {
+			out := buffer.Bytes()[buffer.Len()-chunkWindow-1]
+			// chunkWindow is a multiple of 32 so the outgoing byte's
+			// contribution has been rotated back to its original position
+			hash ^= buzTable[out]
+		}
+		if buffer.Len() >= c.sizes.Min && hash&c.mask == c.mask {
+			if b != '\n' {
+				if err := c.extendToNewline(&buffer); err != nil {
+					return nil, false, err
+				}
+			}
+			break
+		}
+	}
+	// check if we're at the end of the stream so callers don't need to send
+	// an empty trailing block
+	if _, err := c.reader.Peek(1); err == io.EOF {
+		return buffer.Bytes(), true, nil
+	}
+	return buffer.Bytes(), false, nil
+}
+
+// extendToNewline moves the bytes up to and including the next newline from
+// the reader to buffer, if there's one within chunkNewlineSearch bytes and
+// the chunk doesn't exceed Max. Otherwise nothing is read.
+func (c *chunker) extendToNewline(buffer *bytes.Buffer) error {
+	search := chunkNewlineSearch
+	if c.sizes.Max-buffer.Len() < search {
+		search = c.sizes.Max - buffer.Len()
+	}
+	// Peek returns what it can along with an error if there are fewer than
+	// search bytes left, we only care about the bytes
+	peek, _ := c.reader.Peek(search)
+	i := bytes.IndexByte(peek, '\n')
+	if i < 0 {
+		return nil
+	}
+	_, err := io.CopyN(buffer, c.reader, int64(i+1))
+	return err
+}
+
+func newBuzTable() [256]uint32 {
+	// splitmix64 with a fixed seed, changing this would change every block
+	// boundary and thus break dedup with previously written data
+	var result [256]uint32
+	state := uint64(0x5041434859444552) // "PACHYDER"
+	for i := range result {
+		state += 0x9e3779b97f4a7c15
+		z := state
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		z = z ^ (z >> 31)
+		result[i] = uint32(z)
+	}
+	return result
+}
@@ -0,0 +1,127 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+)
+
+var testChunkSizes = ChunkSizes{
+	Min: 1024,
+	Avg: 4 * 1024,
+	Max: 16 * 1024,
+}
+
+func chunks(t *testing.T, data []byte) [][]byte {
+	chunker := newChunker(bufio.NewReader(bytes.NewReader(data)), testChunkSizes)
+	var result [][]byte
+	for {
+		chunk, eof, err := chunker.next()
+		require.NoError(t, err)
+		result = append(result, chunk)
+		if eof {
+			return result
+		}
+	}
+}
+
+func randomBytes(seed int64, n int) []byte {
+	result := make([]byte, n)
+	rand.New(rand.NewSource(seed)).Read(result)
+	return result
+}
+
+func TestChunkSizes(t *testing.T) {
+	data := randomBytes(1, 1024*1024)
+	result := chunks(t, data)
+	require.Equal(t, data, bytes.Join(result, nil))
+	for i, chunk := range result {
+		require.True(t, len(chunk) <= testChunkSizes.Max)
+		if i != len(result)-1 {
+			require.True(t, len(chunk) >= testChunkSizes.Min)
+		}
+	}
+}
+
+func TestChunkNoNewlines(t *testing.T) {
+	data := bytes.Repeat([]byte{'a'}, 100*1024)
+	result := chunks(t, data)
+	require.Equal(t, data, bytes.Join(result, nil))
+	require.True(t, len(result) > 1)
+}
+
+func TestChunkLineAligned(t *testing.T) {
+	var buffer bytes.Buffer
+	for _, b := range randomBytes(2, 256*1024) {
+		buffer.WriteByte('a' + b%26)
+		if b%32 == 0 {
+			buffer.WriteByte('\n')
+		}
+	}
+	result := chunks(t, buffer.Bytes())
+	for _, chunk := range result[:len(result)-1] {
+		require.Equal(t, byte('\n'), chunk[len(chunk)-1])
+	}
+}
+
+func TestChunkInsertDedup(t *testing.T) {
+	data := randomBytes(3, 1024*1024)
+	inserted := append([]byte("a new line at the top\n"), data...)
+	hashes := make(map[string]bool)
+	for _, chunk := range chunks(t, data) {
+		hashes[string(chunk)] = true
+	}
+	result := chunks(t, inserted)
+	shared := 0
+	for _, chunk := range result {
+		if hashes[string(chunk)] {
+			shared++
+		}
+	}
+	require.True(t, shared >= len(result)-2)
+}
+
+func TestChunkBinaryInsertDedup(t *testing.T) {
+	// binary data without newlines must still be cut at content defined
+	// boundaries rather than at Max
+	data := bytes.Replace(randomBytes(4, 1024*1024), []byte{'\n'}, []byte{0}, -1)
+	inserted := append([]byte{1, 2, 3}, data...)
+	hashes := make(map[string]bool)
+	maxSized := 0
+	original := chunks(t, data)
+	for _, chunk := range original {
+		if len(chunk) == testChunkSizes.Max {
+			maxSized++
+		}
+		hashes[string(chunk)] = true
+	}
+	require.True(t, maxSized < len(original)/10)
+	result := chunks(t, inserted)
+	shared := 0
+	for _, chunk := range result {
+		if hashes[string(chunk)] {
+			shared++
+		}
+	}
+	require.True(t, shared >= len(result)-2)
+}
+
+func TestChunkEmpty(t *testing.T) {
+	result := chunks(t, nil)
+	require.Equal(t, 1, len(result))
+	require.Equal(t, 0, len(result[0]))
+}
+
+func TestChunkSizesValidate(t *testing.T) {
+	require.NoError(t, defaultChunkSizes().validate())
+	require.YesError(t, ChunkSizes{Min: 0, Avg: 1, Max: 2}.validate())
+	require.YesError(t, ChunkSizes{Min: 4, Avg: 2, Max: 8}.validate())
+	require.YesError(t, ChunkSizes{Min: 1, Avg: 8, Max: 4}.validate())
+	options := DefaultBlockAPIServerOptions()
+	options.ChunkSizes.Min = 0
+	_, err := newLocalBlockAPIServer(uniqueString("/tmp/pach_test/run"), options)
+	require.YesError(t, err)
+}
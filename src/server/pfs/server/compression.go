@@ -0,0 +1,188 @@
+package server
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	pfsclient "github.com/pachyderm/pachyderm/src/client/pfs"
+)
+
+const (
+	// blockMagic starts every block which is stored with a header, blocks
+	// without it are stored raw (this is how all blocks used to be stored).
+	blockMagic = "\x00pachblk"
+	// blockHeaderSize is the size of the magic, a byte for the compression
+	// and 8 bytes for the logical size of the block.
+	blockHeaderSize = len(blockMagic) + 1 + 8
+)
+
+// ParseBlockCompression returns the compression named by compression, which
+// should be "none" or "gzip" ("" means "none").
+func ParseBlockCompression(compression string) (pfsclient.Compression, error) {
+	if compression == "" {
+		compression = "none"
+	}
+	value, ok := pfsclient.Compression_value["COMPRESSION_"+strings.ToUpper(compression)]
+	if !ok {
+		return pfsclient.Compression_COMPRESSION_NONE, fmt.Errorf("unknown block compression %s", compression)
+	}
+	return pfsclient.Compression(value), nil
+}
+
+type blockHeader struct {
+	compression pfsclient.Compression
+	sizeBytes   uint64
+}
+
+// parseBlockHeader parses the header at the beginning of a stored block, ok
+// is false if the block is stored raw.
+func parseBlockHeader(data []byte) (_ *blockHeader, ok bool) {
+	if len(data) < blockHeaderSize || !bytes.HasPrefix(data, []byte(blockMagic)) {
+		return nil, false
+	}
+	return &blockHeader{
+		compression: pfsclient.Compression(data[len(blockMagic)]),
+		sizeBytes:   binary.BigEndian.Uint64(data[len(blockMagic)+1:]),
+	}, true
+}
+
+// readBlockHeader reads the header of a stored block from reader.
+func readBlockHeader(reader io.Reader) (_ *blockHeader, ok bool, retErr error) {
+	data := make([]byte, blockHeaderSize)
+	n, err := io.ReadFull(reader, data)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, false, err
+	}
+	header, ok := parseBlockHeader(data[:n])
+	return header, ok, nil
+}
+
+// encodeBlock returns the form in which data should be stored. Uncompressed
+// blocks are stored raw unless they begin with blockMagic, so that they can't
+// be mistaken for a header. If compression doesn't make the block smaller
+// it's stored uncompressed.
+func encodeBlock(data []byte, compression pfsclient.Compression) ([]byte, error) {
+	if compression != pfsclient.Compression_COMPRESSION_NONE {
+		encoded, err := encodeBlockWithHeader(data, compression)
+		if err != nil {
+			return nil, err
+		}
+		if len(encoded) < len(data) {
+			return encoded, nil
+		}
+	}
+	if !bytes.HasPrefix(data, []byte(blockMagic)) {
+		return data, nil
+	}
+	return encodeBlockWithHeader(data, pfsclient.Compression_COMPRESSION_NONE)
+}
+
+func encodeBlockWithHeader(data []byte, compression pfsclient.Compression) ([]byte, error) {
+	var buffer bytes.Buffer
+	buffer.WriteString(blockMagic)
+	buffer.WriteByte(byte(compression))
+	if err := binary.Write(&buffer, binary.BigEndian, uint64(len(data))); err != nil {
+		return nil, err
+	}
+	switch compression {
+	case pfsclient.Compression_COMPRESSION_NONE:
+		buffer.Write(data)
+	case pfsclient.Compression_COMPRESSION_GZIP:
+		writer := gzip.NewWriter(&buffer)
+		if _, err := writer.Write(data); err != nil {
+			return nil, err
+		}
+		if err := writer.Close(); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown block compression %v", compression)
+	}
+	return buffer.Bytes(), nil
+}
+
+// blockReader returns a reader for size bytes of the logical content of a
+// stored block starting at offset, size 0 means read to the end of the block.
+// read returns a reader for a range of the stored block with the same
+// semantics. The header and the content are read with a single call to read,
+// since each one is a request to object storage, so the read starts at the
+// beginning of the block and the content before offset is skipped. It's read
+// to the end of the block since the stored size of a compressed range isn't
+// known, the read stops early when the reader is closed.
+func blockReader(read func(offset uint64, size uint64) (io.ReadCloser, error), offset uint64, size uint64) (io.ReadCloser, error) {
+	stored, err := read(0, 0)
+	if err != nil {
+		return nil, err
+	}
+	data := make([]byte, blockHeaderSize)
+	n, err := io.ReadFull(stored, data)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		stored.Close()
+		return nil, err
+	}
+	var reader io.ReadCloser = stored
+	header, ok := parseBlockHeader(data[:n])
+	switch {
+	case !ok:
+		// the block is stored raw so what we took for a header is content
+		reader = &readCloser{io.MultiReader(bytes.NewReader(data[:n]), stored), stored}
+	case header.compression != pfsclient.Compression_COMPRESSION_NONE:
+		if reader, err = newDecompressor(stored, header.compression); err != nil {
+			stored.Close()
+			return nil, err
+		}
+	}
+	if _, err := io.CopyN(ioutil.Discard, reader, int64(offset)); err != nil {
+		reader.Close()
+		return nil, err
+	}
+	if size == 0 {
+		return reader, nil
+	}
+	return &readCloser{io.LimitReader(reader, int64(size)), reader}, nil
+}
+
+// newDecompressor returns a reader for the decompressed content of reader,
+// closing it also closes reader.
+func newDecompressor(reader io.ReadCloser, compression pfsclient.Compression) (io.ReadCloser, error) {
+	switch compression {
+	case pfsclient.Compression_COMPRESSION_GZIP:
+		gzipReader, err := gzip.NewReader(reader)
+		if err != nil {
+			return nil, err
+		}
+		return &decompressReadCloser{gzipReader, func() error {
+			if err := gzipReader.Close(); err != nil {
+				reader.Close()
+				return err
+			}
+			return reader.Close()
+		}}, nil
+	default:
+		return nil, fmt.Errorf("unknown block compression %v", compression)
+	}
+}
+
+type decompressReadCloser struct {
+	io.Reader
+	close func() error
+}
+
+func (r *decompressReadCloser) Close() error {
+	return r.close()
+}
+
+// readCloser reads from Reader, which reads from closer.
+type readCloser struct {
+	io.Reader
+	closer io.Closer
+}
+
+func (r *readCloser) Close() error {
+	return r.closer.Close()
+}
@@ -0,0 +1,91 @@
+package server
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	pfsclient "github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+)
+
+var compressions = []pfsclient.Compression{
+	pfsclient.Compression_COMPRESSION_NONE,
+	pfsclient.Compression_COMPRESSION_GZIP,
+}
+
+func readStoredBlock(t *testing.T, stored []byte, offset uint64, size uint64) string {
+	reads := 0
+	reader, err := blockReader(func(offset uint64, size uint64) (io.ReadCloser, error) {
+		// each read is a request to object storage
+		reads++
+		require.Equal(t, 1, reads)
+		if size == 0 {
+			size = uint64(len(stored))
+		}
+		return ioutil.NopCloser(io.NewSectionReader(bytes.NewReader(stored), int64(offset), int64(size))), nil
+	}, offset, size)
+	require.NoError(t, err)
+	data, err := ioutil.ReadAll(reader)
+	require.NoError(t, err)
+	require.NoError(t, reader.Close())
+	return string(data)
+}
+
+func TestBlockCompression(t *testing.T) {
+	data := strings.Repeat("foo bar buzz\n", 1000)
+	for _, compression := range compressions {
+		stored, err := encodeBlock([]byte(data), compression)
+		require.NoError(t, err)
+		if compression != pfsclient.Compression_COMPRESSION_NONE {
+			require.True(t, len(stored) < len(data))
+			header, ok := parseBlockHeader(stored)
+			require.True(t, ok)
+			require.Equal(t, compression, header.compression)
+			require.Equal(t, uint64(len(data)), header.sizeBytes)
+		}
+		require.Equal(t, data, readStoredBlock(t, stored, 0, 0))
+		require.Equal(t, data[13:26], readStoredBlock(t, stored, 13, 13))
+		require.Equal(t, data[100:], readStoredBlock(t, stored, 100, 0))
+	}
+}
+
+func TestBlockCompressionLegacy(t *testing.T) {
+	// blocks written before compression have no header
+	data := "foo\nbar\n"
+	require.Equal(t, data, readStoredBlock(t, []byte(data), 0, 0))
+	require.Equal(t, "bar\n", readStoredBlock(t, []byte(data), 4, 4))
+}
+
+func TestBlockCompressionMagic(t *testing.T) {
+	// uncompressed data which looks like a header must not be stored raw
+	data := blockMagic + "\x01\x00\x00\x00\x00\x00\x00\x00\x05fake"
+	stored, err := encodeBlock([]byte(data), pfsclient.Compression_COMPRESSION_NONE)
+	require.NoError(t, err)
+	require.Equal(t, blockHeaderSize+len(data), len(stored))
+	require.Equal(t, data, readStoredBlock(t, stored, 0, 0))
+}
+
+func TestBlockCompressionIncompressible(t *testing.T) {
+	data := randomBytes(1, 1024)
+	stored, err := encodeBlock(data, pfsclient.Compression_COMPRESSION_GZIP)
+	require.NoError(t, err)
+	require.Equal(t, data, stored)
+}
+
+func TestParseBlockCompression(t *testing.T) {
+	_, err := ParseBlockCompression("lzma")
+	require.YesError(t, err)
+	compression, err := ParseBlockCompression("")
+	require.NoError(t, err)
+	require.Equal(t, pfsclient.Compression_COMPRESSION_NONE, compression)
+	compression, err = ParseBlockCompression("gzip")
+	require.NoError(t, err)
+	require.Equal(t, pfsclient.Compression_COMPRESSION_GZIP, compression)
+	options := DefaultBlockAPIServerOptions()
+	options.Compression = pfsclient.Compression(100)
+	_, err = newLocalBlockAPIServer(uniqueString("/tmp/pach_test/run"), options)
+	require.YesError(t, err)
+}
@@ -0,0 +1,129 @@
+package server
+
+import (
+	"sync"
+	"time"
+
+	pfsclient "github.com/pachyderm/pachyderm/src/client/pfs"
+	"go.pedge.io/proto/time"
+	"golang.org/x/net/context"
+)
+
+const (
+	// gcGracePeriod protects blocks written shortly before a collection
+	// started, it covers commits started after the open commits were listed
+	// and clock skew between pachds.
+	gcGracePeriod = 10 * time.Minute
+)
+
+// garbageCollect deletes the blocks which aren't referenced by any diff and
+// were written before cutoff. walkDiffs calls f on every diff in the store
+// regardless of shard and walkBlocks calls f on every block in the store, it
+// only has to list them since only unreferenced blocks are inspected. inspect
+// returns a block's info for dry runs, only its Created and StoredSizeBytes
+// are used. sweep deletes a block unless it was written after cutoff and
+// returns its info, or nil if it wasn't deleted, it has to make sure that a
+// concurrent PutBlock can't dedup against a block it's deleting.
+func garbageCollect(ctx context.Context, inspect func(ctx context.Context, block *pfsclient.Block) (*pfsclient.BlockInfo, error), walkDiffs func(f func(*pfsclient.DiffInfo) error) error,
+	walkBlocks func(f func(*pfsclient.Block) error) error, sweep func(ctx context.Context, block *pfsclient.Block, cutoff time.Time) (*pfsclient.BlockInfo, error),
+	request *pfsclient.GarbageCollectRequest, cutoff time.Time) (*pfsclient.GarbageCollectResponse, error) {
+	// mark
+	live := make(map[string]bool)
+	if err := walkDiffs(func(diffInfo *pfsclient.DiffInfo) error {
+		for _, _append := range diffInfo.Appends {
+			for _, blockRef := range _append.BlockRefs {
+				live[blockRef.Block.Hash] = true
+			}
+			for _, blockRefs := range _append.Handles {
+				for _, blockRef := range blockRefs.BlockRef {
+					live[blockRef.Block.Hash] = true
+				}
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	// sweep
+	response := &pfsclient.GarbageCollectResponse{}
+	if err := walkBlocks(func(block *pfsclient.Block) error {
+		if live[block.Hash] {
+			response.LiveBlocks++
+			return nil
+		}
+		var blockInfo *pfsclient.BlockInfo
+		var err error
+		if request.DryRun {
+			blockInfo, err = inspect(ctx, block)
+			if err == nil && !collectable(blockInfo, cutoff) {
+				blockInfo = nil
+			}
+		} else {
+			blockInfo, err = sweep(ctx, block, cutoff)
+		}
+		if err != nil {
+			return err
+		}
+		if blockInfo == nil {
+			response.LiveBlocks++
+			return nil
+		}
+		response.CollectedBlocks++
+		response.CollectedBytes += blockInfo.StoredSizeBytes
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// gcCutoff returns the time before which blocks have to have been written to
+// be collected, the grace period or request.Before, whichever is earlier.
+// Blocks referenced by open commits are protected by the pfs API setting
+// request.Before, see apiServer.GarbageCollect.
+func gcCutoff(request *pfsclient.GarbageCollectRequest) time.Time {
+	cutoff := time.Now().Add(-gcGracePeriod)
+	if request.Before != nil {
+		if before := prototime.TimestampToTime(request.Before); before.Before(cutoff) {
+			cutoff = before
+		}
+	}
+	return cutoff
+}
+
+// oldestStarted returns when the oldest of commitInfos started, ok is false
+// if there aren't any.
+func oldestStarted(commitInfos []*pfsclient.CommitInfo) (_ time.Time, ok bool) {
+	var oldest time.Time
+	for _, commitInfo := range commitInfos {
+		if started := prototime.TimestampToTime(commitInfo.Started); !ok || started.Before(oldest) {
+			oldest, ok = started, true
+		}
+	}
+	return oldest, ok
+}
+
+func collectable(blockInfo *pfsclient.BlockInfo, cutoff time.Time) bool {
+	return blockInfo.Created != nil && prototime.TimestampToTime(blockInfo.Created).Before(cutoff)
+}
+
+// sweepBlock deletes block unless it was written after cutoff, which
+// includes being put again since it was listed. It returns the info of the
+// deleted block or nil if it wasn't deleted. lock must be held for reading by
+// PutBlock while it writes (or dedups against) a block, it's held for writing
+// while the block is swept so it can't be deleted out from under a put.
+func sweepBlock(ctx context.Context, server pfsclient.BlockAPIServer, lock *sync.RWMutex, block *pfsclient.Block, cutoff time.Time) (*pfsclient.BlockInfo, error) {
+	lock.Lock()
+	defer lock.Unlock()
+	blockInfo, err := server.InspectBlock(ctx, &pfsclient.InspectBlockRequest{Block: block})
+	if err != nil {
+		return nil, err
+	}
+	if !collectable(blockInfo, cutoff) {
+		return nil, nil
+	}
+	if _, err := server.DeleteBlock(ctx, &pfsclient.DeleteBlockRequest{Block: block}); err != nil {
+		return nil, err
+	}
+	return blockInfo, nil
+}
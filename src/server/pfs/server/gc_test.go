@@ -0,0 +1,100 @@
+package server
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	pclient "github.com/pachyderm/pachyderm/src/client"
+	pfsclient "github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"go.pedge.io/proto/time"
+	"golang.org/x/net/context"
+)
+
+func TestGarbageCollect(t *testing.T) {
+	t.Parallel()
+	server, err := newLocalBlockAPIServer(uniqueString("/tmp/pach_test/run"), DefaultBlockAPIServerOptions())
+	require.NoError(t, err)
+	old := time.Now().Add(-2 * gcGracePeriod)
+	writeBlock := func(hash string, data string, modified time.Time) *pfsclient.Block {
+		block := pclient.NewBlock(hash)
+		require.NoError(t, ioutil.WriteFile(server.blockPath(block), []byte(data), 0666))
+		require.NoError(t, os.Chtimes(server.blockPath(block), modified, modified))
+		return block
+	}
+	live := writeBlock("live", "foo\n", old)
+	dead := writeBlock("dead", "bar\n", old)
+	recent := writeBlock("recent", "buzz\n", time.Now())
+	_, err = server.CreateDiff(context.Background(), &pfsclient.DiffInfo{
+		Diff: &pfsclient.Diff{
+			Commit: pclient.NewCommit("repo", "commit"),
+		},
+		Appends: map[string]*pfsclient.Append{
+			"file": {BlockRefs: []*pfsclient.BlockRef{{Block: live}}},
+		},
+	})
+	require.NoError(t, err)
+
+	response, err := server.GarbageCollect(context.Background(), &pfsclient.GarbageCollectRequest{DryRun: true})
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), response.LiveBlocks)
+	require.Equal(t, uint64(1), response.CollectedBlocks)
+	require.Equal(t, uint64(4), response.CollectedBytes)
+	_, err = os.Stat(server.blockPath(dead))
+	require.NoError(t, err)
+
+	response, err = server.GarbageCollect(context.Background(), &pfsclient.GarbageCollectRequest{})
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), response.CollectedBlocks)
+	_, err = os.Stat(server.blockPath(dead))
+	require.True(t, os.IsNotExist(err))
+	for _, block := range []*pfsclient.Block{live, recent} {
+		_, err = os.Stat(server.blockPath(block))
+		require.NoError(t, err)
+	}
+}
+
+func TestOldestStarted(t *testing.T) {
+	_, ok := oldestStarted(nil)
+	require.False(t, ok)
+	now := time.Now()
+	started, ok := oldestStarted([]*pfsclient.CommitInfo{
+		{Started: prototime.TimeToTimestamp(now)},
+		{Started: prototime.TimeToTimestamp(now.Add(-time.Hour))},
+		{Started: prototime.TimeToTimestamp(now.Add(-time.Minute))},
+	})
+	require.True(t, ok)
+	require.Equal(t, now.Add(-time.Hour).UnixNano(), started.UnixNano())
+}
+
+func TestGarbageCollectAPI(t *testing.T) {
+	t.Parallel()
+	root := uniqueString("/tmp/pach_test/run")
+	client, _ := getClientAndServerInDir(t, root)
+	require.NoError(t, client.CreateRepo("repo"))
+	commit, err := client.StartCommit("repo", "", "")
+	require.NoError(t, err)
+	_, err = client.PutFile("repo", commit.ID, "file", strings.NewReader("foo\n"))
+	require.NoError(t, err)
+	// an old block which no commit references
+	server, err := newLocalBlockAPIServer(root, DefaultBlockAPIServerOptions())
+	require.NoError(t, err)
+	dead := pclient.NewBlock("dead")
+	require.NoError(t, ioutil.WriteFile(server.blockPath(dead), []byte("bar\n"), 0666))
+	old := time.Now().Add(-2 * gcGracePeriod)
+	require.NoError(t, os.Chtimes(server.blockPath(dead), old, old))
+
+	response, err := client.GarbageCollect(false)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), response.CollectedBlocks)
+	_, err = os.Stat(server.blockPath(dead))
+	require.True(t, os.IsNotExist(err))
+	require.NoError(t, client.FinishCommit("repo", commit.ID))
+	var buffer bytes.Buffer
+	require.NoError(t, client.GetFile("repo", commit.ID, "file", 0, 0, "", nil, &buffer))
+	require.Equal(t, "foo\n", buffer.String())
+}
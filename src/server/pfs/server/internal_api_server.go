@@ -3,6 +3,7 @@ package server
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"strconv"
 	"strings"
 	"sync"
@@ -32,6 +33,7 @@ type internalAPIServer struct {
 	hasher            *pfsserver.Hasher
 	router            shard.Router
 	driver            drive.Driver
+	urlAccess         URLAccess
 	commitWaiters     []*commitWait
 	commitWaitersLock sync.Mutex
 }
@@ -40,12 +42,14 @@ func newInternalAPIServer(
 	hasher *pfsserver.Hasher,
 	router shard.Router,
 	driver drive.Driver,
+	urlAccess URLAccess,
 ) *internalAPIServer {
 	return &internalAPIServer{
 		Logger:            protorpclog.NewLogger("pachyderm.pfsserver.InternalAPI"),
 		hasher:            hasher,
 		router:            router,
 		driver:            driver,
+		urlAccess:         urlAccess,
 		commitWaiters:     nil,
 		commitWaitersLock: sync.Mutex{},
 	}
@@ -61,7 +65,7 @@ func (a *internalAPIServer) CreateRepo(ctx context.Context, request *pfs.CreateR
 	if err != nil {
 		return nil, err
 	}
-	if err := a.driver.CreateRepo(request.Repo, request.Created, shards); err != nil {
+	if err := a.driver.CreateRepo(request.Repo, request.Created, request.Quota, shards); err != nil {
 		return nil, err
 	}
 	return google_protobuf.EmptyInstance, nil
@@ -110,6 +114,22 @@ func (a *internalAPIServer) DeleteRepo(ctx context.Context, request *pfs.DeleteR
 	return google_protobuf.EmptyInstance, nil
 }
 
+func (a *internalAPIServer) SetQuota(ctx context.Context, request *pfs.SetQuotaRequest) (response *google_protobuf.Empty, retErr error) {
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+	version, err := a.getVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+	shards, err := a.router.GetShards(version)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.driver.SetQuota(request.Repo, request.Quota, shards); err != nil {
+		return nil, err
+	}
+	return google_protobuf.EmptyInstance, nil
+}
+
 func (a *internalAPIServer) StartCommit(ctx context.Context, request *pfs.StartCommitRequest) (response *google_protobuf.Empty, retErr error) {
 	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
 	version, err := a.getVersion(ctx)
@@ -121,7 +141,8 @@ func (a *internalAPIServer) StartCommit(ctx context.Context, request *pfs.StartC
 		return nil, err
 	}
 	if err := a.driver.StartCommit(request.Repo, request.ID, request.ParentID,
-		request.Branch, request.Started, shards); err != nil {
+		request.Branch, request.MergeParentID, request.MergePolicies, request.Provenance, request.Message, request.Labels,
+		request.Started, shards); err != nil {
 		return nil, err
 	}
 	if err := a.pulseCommitWaiters(client.NewCommit(request.Repo.Name, request.ID), pfs.CommitType_COMMIT_TYPE_WRITE, shards); err != nil {
@@ -140,7 +161,7 @@ func (a *internalAPIServer) FinishCommit(ctx context.Context, request *pfs.Finis
 	if err != nil {
 		return nil, err
 	}
-	if err := a.driver.FinishCommit(request.Commit, request.Finished, request.Cancel, shards); err != nil {
+	if err := a.driver.FinishCommit(request.Commit, request.Finished, request.Cancel, request.Message, request.Labels, shards); err != nil {
 		return nil, err
 	}
 	if err := a.pulseCommitWaiters(request.Commit, pfs.CommitType_COMMIT_TYPE_READ, shards); err != nil {
@@ -172,7 +193,7 @@ func (a *internalAPIServer) ListCommit(ctx context.Context, request *pfs.ListCom
 	if err != nil {
 		return nil, err
 	}
-	commitInfos, err := a.filteredListCommits(request.Repo, request.FromCommit, request.CommitType, request.All, shards)
+	commitInfos, err := a.filteredListCommits(request, shards)
 	if err != nil {
 		return nil, err
 	}
@@ -190,6 +211,7 @@ func (a *internalAPIServer) ListCommit(ctx context.Context, request *pfs.ListCom
 	}, nil
 }
 
+// ListBranch is deprecated, it's ListBranchInfo without the branches' names.
 func (a *internalAPIServer) ListBranch(ctx context.Context, request *pfs.ListBranchRequest) (response *pfs.CommitInfos, retErr error) {
 	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
 	version, err := a.getVersion(ctx)
@@ -200,15 +222,104 @@ func (a *internalAPIServer) ListBranch(ctx context.Context, request *pfs.ListBra
 	if err != nil {
 		return nil, err
 	}
-	commitInfos, err := a.driver.ListBranch(request.Repo, shards)
+	branchInfos, err := a.driver.ListBranch(request.Repo, shards)
 	if err != nil {
 		return nil, err
 	}
-	return &pfs.CommitInfos{
-		CommitInfo: commitInfos,
+	response = &pfs.CommitInfos{}
+	for _, branchInfo := range branchInfos {
+		commitInfo, err := a.driver.InspectCommit(branchInfo.Head, shards)
+		if err != nil {
+			return nil, err
+		}
+		response.CommitInfo = append(response.CommitInfo, commitInfo)
+	}
+	return response, nil
+}
+
+func (a *internalAPIServer) ListBranchInfo(ctx context.Context, request *pfs.ListBranchRequest) (response *pfs.BranchInfos, retErr error) {
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+	version, err := a.getVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+	shards, err := a.router.GetShards(version)
+	if err != nil {
+		return nil, err
+	}
+	branchInfos, err := a.driver.ListBranch(request.Repo, shards)
+	if err != nil {
+		return nil, err
+	}
+	return &pfs.BranchInfos{
+		BranchInfo: branchInfos,
 	}, nil
 }
 
+func (a *internalAPIServer) CreateBranch(ctx context.Context, request *pfs.CreateBranchRequest) (response *google_protobuf.Empty, retErr error) {
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+	version, err := a.getVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+	shards, err := a.router.GetShards(version)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.driver.CreateBranch(request.Commit, request.Branch, shards); err != nil {
+		return nil, err
+	}
+	return google_protobuf.EmptyInstance, nil
+}
+
+func (a *internalAPIServer) SetBranch(ctx context.Context, request *pfs.SetBranchRequest) (response *google_protobuf.Empty, retErr error) {
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+	version, err := a.getVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+	shards, err := a.router.GetShards(version)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.driver.SetBranch(request.Commit, request.Branch, shards); err != nil {
+		return nil, err
+	}
+	return google_protobuf.EmptyInstance, nil
+}
+
+func (a *internalAPIServer) DeleteBranch(ctx context.Context, request *pfs.DeleteBranchRequest) (response *google_protobuf.Empty, retErr error) {
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+	version, err := a.getVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+	shards, err := a.router.GetShards(version)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.driver.DeleteBranch(request.Repo, request.Branch, shards); err != nil {
+		return nil, err
+	}
+	return google_protobuf.EmptyInstance, nil
+}
+
+func (a *internalAPIServer) RenameBranch(ctx context.Context, request *pfs.RenameBranchRequest) (response *google_protobuf.Empty, retErr error) {
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+	version, err := a.getVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+	shards, err := a.router.GetShards(version)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.driver.RenameBranch(request.Repo, request.Branch, request.NewBranch, shards); err != nil {
+		return nil, err
+	}
+	return google_protobuf.EmptyInstance, nil
+}
+
 func (a *internalAPIServer) DeleteCommit(ctx context.Context, request *pfs.DeleteCommitRequest) (response *google_protobuf.Empty, retErr error) {
 	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
 	version, err := a.getVersion(ctx)
@@ -219,7 +330,7 @@ func (a *internalAPIServer) DeleteCommit(ctx context.Context, request *pfs.Delet
 	if err != nil {
 		return nil, err
 	}
-	if err := a.driver.DeleteCommit(request.Commit, shards); err != nil {
+	if err := a.driver.DeleteCommit(request.Commit, request.Force, shards); err != nil {
 		return nil, err
 	}
 	// TODO push delete to replicas
@@ -267,14 +378,31 @@ func (a *internalAPIServer) PutFile(putFileServer pfs.InternalAPI_PutFileServer)
 			return err
 		}
 	} else {
-		reader := putFileReader{
-			server: putFileServer,
-		}
-		_, err = reader.buffer.Write(request.Value)
-		if err != nil {
-			return err
+		var reader io.Reader
+		if request.Url != "" {
+			if len(request.Value) > 0 {
+				return fmt.Errorf("PutFileRequest shouldn't have a URL and a value")
+			}
+			urlReader, err := a.urlAccess.reader(request.Url)
+			if err != nil {
+				return err
+			}
+			defer urlReader.Close()
+			reader = urlReader
+		} else {
+			putFileReader := &putFileReader{
+				server: putFileServer,
+			}
+			_, err = putFileReader.buffer.Write(request.Value)
+			if err != nil {
+				return err
+			}
+			reader = putFileReader
 		}
-		if err := a.driver.PutFile(request.File, request.Handle, shard, &reader); err != nil {
+		if err := a.driver.PutFile(request.File, request.Handle, request.Overwrite, shard, reader); err != nil {
+			if _, ok := err.(pfsserver.ErrQuotaExceeded); ok {
+				return grpcErrorf(codes.ResourceExhausted, "%v", err)
+			}
 			return err
 		}
 	}
@@ -307,6 +435,45 @@ func (a *internalAPIServer) GetFile(request *pfs.GetFileRequest, apiGetFileServe
 	return protostream.WriteToStreamingBytesServer(file, apiGetFileServer)
 }
 
+func (a *internalAPIServer) GetFileBlockRefs(ctx context.Context, request *pfs.InspectFileRequest) (response *pfs.BlockRefs, retErr error) {
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+	version, err := a.getVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+	shard, err := a.getShardForFile(request.File, version)
+	if err != nil {
+		return nil, err
+	}
+	blockRefs, err := a.driver.GetFileBlockRefs(request.File, request.FromCommit, shard, request.Unsafe)
+	if err != nil {
+		if err == pfsserver.ErrFileNotFound {
+			return nil, grpcErrorf(codes.NotFound, "%v", err)
+		}
+		return nil, err
+	}
+	return &pfs.BlockRefs{BlockRef: blockRefs}, nil
+}
+
+func (a *internalAPIServer) PutFileBlockRefs(ctx context.Context, request *pfs.PutFileBlockRefsRequest) (response *google_protobuf.Empty, retErr error) {
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+	version, err := a.getVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+	shard, err := a.getMasterShardForFile(request.File, version)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.driver.PutFileBlockRefs(request.File, request.BlockRef, request.Overwrite, shard); err != nil {
+		if _, ok := err.(pfsserver.ErrQuotaExceeded); ok {
+			return nil, grpcErrorf(codes.ResourceExhausted, "%v", err)
+		}
+		return nil, err
+	}
+	return google_protobuf.EmptyInstance, nil
+}
+
 func (a *internalAPIServer) InspectFile(ctx context.Context, request *pfs.InspectFileRequest) (response *pfs.FileInfo, retErr error) {
 	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
 	version, err := a.getVersion(ctx)
@@ -317,7 +484,7 @@ func (a *internalAPIServer) InspectFile(ctx context.Context, request *pfs.Inspec
 	if err != nil {
 		return nil, err
 	}
-	return a.driver.InspectFile(request.File, request.Shard, request.FromCommit, shard, request.Unsafe)
+	return a.driver.InspectFile(request.File, request.Shard, request.FromCommit, shard, request.Unsafe, request.Sha256)
 }
 
 func (a *internalAPIServer) ListFile(ctx context.Context, request *pfs.ListFileRequest) (response *pfs.FileInfos, retErr error) {
@@ -339,7 +506,7 @@ func (a *internalAPIServer) ListFile(ctx context.Context, request *pfs.ListFileR
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			subFileInfos, err := a.driver.ListFile(request.File, request.Shard, request.FromCommit, shard, request.Recurse, request.Unsafe)
+			subFileInfos, err := a.listFile(request, shard)
 			if err != nil && err != pfsserver.ErrFileNotFound {
 				select {
 				case errCh <- err:
@@ -365,6 +532,132 @@ func (a *internalAPIServer) ListFile(ctx context.Context, request *pfs.ListFileR
 	}, nil
 }
 
+func (a *internalAPIServer) ListFileStream(request *pfs.ListFileRequest, listFileServer pfs.InternalAPI_ListFileStreamServer) (retErr error) {
+	defer func(start time.Time) { a.Log(request, nil, retErr, time.Since(start)) }(time.Now())
+	version, err := a.getVersion(listFileServer.Context())
+	if err != nil {
+		return err
+	}
+	shards, err := a.router.GetShards(version)
+	if err != nil {
+		return err
+	}
+	var wg sync.WaitGroup
+	var lock sync.Mutex
+	var streams []pfsserver.FileInfoStream
+	errCh := make(chan error, 1)
+	for shard := range shards {
+		shard := shard
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// the directory is listed here so that the shards are read in
+			// parallel and errors are returned before anything is sent
+			stream, err := a.listFileStream(request, shard)
+			if err != nil {
+				select {
+				case errCh <- err:
+					// error reported
+				default:
+					// not the first error
+				}
+				return
+			}
+			lock.Lock()
+			defer lock.Unlock()
+			streams = append(streams, stream)
+		}()
+	}
+	wg.Wait()
+	select {
+	case err := <-errCh:
+		return err
+	default:
+	}
+	return pfsserver.MergeFileInfos(streams, request.Limit, listFileServer.Send)
+}
+
+// listFile lists request in shard, file.Path is listed as a glob if it has
+// glob metacharacters, unless request.Literal is set.
+func (a *internalAPIServer) listFile(request *pfs.ListFileRequest, shard uint64) ([]*pfs.FileInfo, error) {
+	if pfsserver.IsGlob(request.File.Path) && !request.Literal {
+		fileInfos, err := a.driver.GlobFile(request.File, request.Shard, request.FromCommit, shard, request.Recurse, request.Unsafe)
+		if err != nil {
+			return nil, err
+		}
+		return pfsserver.PageFileInfos(fileInfos, request.PageToken, request.Limit), nil
+	}
+	return a.driver.ListFile(request.File, request.Shard, request.FromCommit, shard, request.Recurse, request.Unsafe, request.PageToken, request.Limit)
+}
+
+// listFileStream returns a stream of the FileInfos listFile returns for
+// request in shard. A directory's children are inspected as they're
+// received, but glob matches are all found up front since the whole tree
+// under the pattern's prefix has to be walked to find any of them.
+func (a *internalAPIServer) listFileStream(request *pfs.ListFileRequest, shard uint64) (pfsserver.FileInfoStream, error) {
+	var stream pfsserver.FileInfoStream
+	var err error
+	if pfsserver.IsGlob(request.File.Path) && !request.Literal {
+		var fileInfos []*pfs.FileInfo
+		fileInfos, err = a.driver.GlobFile(request.File, request.Shard, request.FromCommit, shard, request.Recurse, request.Unsafe)
+		stream = pfsserver.NewFileInfoStream(pfsserver.PageFileInfos(fileInfos, request.PageToken, request.Limit))
+	} else {
+		stream, err = a.driver.ListFileStream(request.File, request.Shard, request.FromCommit, shard, request.Recurse, request.Unsafe, request.PageToken)
+	}
+	if err == pfsserver.ErrFileNotFound {
+		// the file isn't in this shard
+		return pfsserver.NewFileInfoStream(nil), nil
+	}
+	return stream, err
+}
+
+func (a *internalAPIServer) GlobFile(request *pfs.GlobFileRequest, globFileServer pfs.InternalAPI_GlobFileServer) (retErr error) {
+	defer func(start time.Time) { a.Log(request, nil, retErr, time.Since(start)) }(time.Now())
+	version, err := a.getVersion(globFileServer.Context())
+	if err != nil {
+		return err
+	}
+	shards, err := a.router.GetShards(version)
+	if err != nil {
+		return err
+	}
+	var wg sync.WaitGroup
+	var lock sync.Mutex
+	errCh := make(chan error, 1)
+	for shard := range shards {
+		shard := shard
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fileInfos, err := a.driver.GlobFile(request.File, request.Shard, request.FromCommit, shard, false, request.Unsafe)
+			if err == nil {
+				lock.Lock()
+				defer lock.Unlock()
+				for _, fileInfo := range fileInfos {
+					if err = globFileServer.Send(fileInfo); err != nil {
+						break
+					}
+				}
+			}
+			if err != nil {
+				select {
+				case errCh <- err:
+					// error reported
+				default:
+					// not the first error
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	select {
+	case err := <-errCh:
+		return err
+	default:
+	}
+	return nil
+}
+
 func (a *internalAPIServer) DeleteFile(ctx context.Context, request *pfs.DeleteFileRequest) (response *google_protobuf.Empty, retErr error) {
 	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
 	version, err := a.getVersion(ctx)
@@ -408,6 +701,51 @@ func (a *internalAPIServer) DeleteFile(ctx context.Context, request *pfs.DeleteF
 	return google_protobuf.EmptyInstance, nil
 }
 
+func (a *internalAPIServer) DiffCommit(ctx context.Context, request *pfs.DiffCommitRequest) (response *pfs.FileChanges, retErr error) {
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+	version, err := a.getVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+	shards, err := a.router.GetShards(version)
+	if err != nil {
+		return nil, err
+	}
+	var wg sync.WaitGroup
+	var lock sync.Mutex
+	var fileChanges []*pfs.FileChange
+	errCh := make(chan error, 1)
+	for shard := range shards {
+		shard := shard
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			subFileChanges, err := a.driver.DiffCommit(request.FromCommit, request.ToCommit, shard)
+			if err != nil {
+				select {
+				case errCh <- err:
+					// error reported
+				default:
+					// not the first error
+				}
+				return
+			}
+			lock.Lock()
+			defer lock.Unlock()
+			fileChanges = append(fileChanges, subFileChanges...)
+		}()
+	}
+	wg.Wait()
+	select {
+	case err := <-errCh:
+		return nil, err
+	default:
+	}
+	return &pfs.FileChanges{
+		FileChange: fileChanges,
+	}, nil
+}
+
 func (a *internalAPIServer) AddShard(shard uint64) error {
 	return a.driver.AddShard(shard)
 }
@@ -480,14 +818,18 @@ func (a *internalAPIServer) getVersion(ctx context.Context) (int64, error) {
 type commitWait struct {
 	//TODO don't use repo here, it's technically fine but using protobufs as map keys is fraught with peril
 	repos          []*pfs.Repo
+	provenance     []*pfs.Commit
+	labels         map[string]string
 	commitType     pfs.CommitType
 	all            bool
 	commitInfoChan chan *pfs.CommitInfo
 }
 
-func newCommitWait(repos []*pfs.Repo, commitType pfs.CommitType, all bool, commitInfoChan chan *pfs.CommitInfo) *commitWait {
+func newCommitWait(repos []*pfs.Repo, provenance []*pfs.Commit, labels map[string]string, commitType pfs.CommitType, all bool, commitInfoChan chan *pfs.CommitInfo) *commitWait {
 	return &commitWait{
 		repos:          repos,
+		provenance:     provenance,
+		labels:         labels,
 		commitType:     commitType,
 		all:            all,
 		commitInfoChan: commitInfoChan,
@@ -501,7 +843,7 @@ func (a *internalAPIServer) registerCommitWaiter(request *pfs.ListCommitRequest,
 	defer a.commitWaitersLock.Unlock()
 	// We need to redo the call to ListCommit because commits may have been
 	// created between then and now.
-	commitInfos, err := a.filteredListCommits(request.Repo, request.FromCommit, request.CommitType, request.All, shards)
+	commitInfos, err := a.filteredListCommits(request, shards)
 	if err != nil {
 		return err
 	}
@@ -513,7 +855,7 @@ func (a *internalAPIServer) registerCommitWaiter(request *pfs.ListCommitRequest,
 			close(outChan)
 		}()
 	}
-	a.commitWaiters = append(a.commitWaiters, newCommitWait(request.Repo, request.CommitType, request.All, outChan))
+	a.commitWaiters = append(a.commitWaiters, newCommitWait(request.Repo, request.Provenance, request.Labels, request.CommitType, request.All, outChan))
 	return nil
 }
 
@@ -527,7 +869,14 @@ func (a *internalAPIServer) pulseCommitWaiters(commit *pfs.Commit, commitType pf
 	var unpulsedWaiters []*commitWait
 WaitersLoop:
 	for _, commitWaiter := range a.commitWaiters {
-		if (commitWaiter.commitType == pfs.CommitType_COMMIT_TYPE_NONE || commitType == commitWaiter.commitType) && (commitWaiter.all || !commitInfo.Cancelled) {
+		if (commitWaiter.commitType == pfs.CommitType_COMMIT_TYPE_NONE || commitType == commitWaiter.commitType) &&
+			(commitWaiter.all || !commitInfo.Cancelled) && pfsserver.HasProvenance(commitInfo, commitWaiter.provenance) && pfsserver.HasLabels(commitInfo, commitWaiter.labels) {
+			if len(commitWaiter.repos) == 0 && len(commitWaiter.provenance) > 0 {
+				// waiting for derived commits in every repo
+				commitWaiter.commitInfoChan <- commitInfo
+				close(commitWaiter.commitInfoChan)
+				continue WaitersLoop
+			}
 			for _, repo := range commitWaiter.repos {
 				if repo.Name == commit.Repo.Name {
 					commitWaiter.commitInfoChan <- commitInfo
@@ -542,14 +891,14 @@ WaitersLoop:
 	return nil
 }
 
-func (a *internalAPIServer) filteredListCommits(repos []*pfs.Repo, fromCommit []*pfs.Commit, commitType pfs.CommitType, all bool, shards map[uint64]bool) ([]*pfs.CommitInfo, error) {
-	commitInfos, err := a.driver.ListCommit(repos, fromCommit, all, shards)
+func (a *internalAPIServer) filteredListCommits(request *pfs.ListCommitRequest, shards map[uint64]bool) ([]*pfs.CommitInfo, error) {
+	commitInfos, err := a.driver.ListCommit(request.Repo, request.FromCommit, request.Provenance, request.Labels, request.All, shards)
 	if err != nil && err != pfsserver.ErrRepoNotFound {
 		return nil, err
 	}
 	var filtered []*pfs.CommitInfo
 	for _, commitInfo := range commitInfos {
-		if commitType != pfs.CommitType_COMMIT_TYPE_NONE && commitInfo.CommitType != commitType {
+		if request.CommitType != pfs.CommitType_COMMIT_TYPE_NONE && commitInfo.CommitType != request.CommitType {
 			continue
 		}
 		filtered = append(filtered, commitInfo)
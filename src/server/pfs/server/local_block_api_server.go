@@ -2,15 +2,16 @@ package server
 
 import (
 	"bufio"
-	"bytes"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"math"
 	"os"
 	"path"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/golang/protobuf/proto"
@@ -24,13 +25,24 @@ import (
 
 type localBlockAPIServer struct {
 	protorpclog.Logger
-	dir string
+	dir     string
+	options BlockAPIServerOptions
+	// gcLock is held for reading while blocks are put and for writing while
+	// they're garbage collected
+	gcLock sync.RWMutex
 }
 
-func newLocalBlockAPIServer(dir string) (*localBlockAPIServer, error) {
+func newLocalBlockAPIServer(dir string, options BlockAPIServerOptions) (*localBlockAPIServer, error) {
+	if err := options.ChunkSizes.validate(); err != nil {
+		return nil, err
+	}
+	if _, ok := pfsclient.Compression_name[int32(options.Compression)]; !ok {
+		return nil, fmt.Errorf("unknown block compression %d", options.Compression)
+	}
 	server := &localBlockAPIServer{
-		Logger: protorpclog.NewLogger("pachyderm.pfsclient.localBlockAPIServer"),
-		dir:    dir,
+		Logger:  protorpclog.NewLogger("pachyderm.pfsclient.localBlockAPIServer"),
+		dir:     dir,
+		options: options,
 	}
 	if err := os.MkdirAll(server.tmpDir(), 0777); err != nil {
 		return nil, err
@@ -41,6 +53,9 @@ func newLocalBlockAPIServer(dir string) (*localBlockAPIServer, error) {
 	if err := os.MkdirAll(server.blockDir(), 0777); err != nil {
 		return nil, err
 	}
+	if err := os.MkdirAll(server.snapshotDir(), 0777); err != nil {
+		return nil, err
+	}
 	return server, nil
 }
 
@@ -48,14 +63,14 @@ func (s *localBlockAPIServer) PutBlock(putBlockServer pfsclient.BlockAPI_PutBloc
 	result := &pfsclient.BlockRefs{}
 	defer func(start time.Time) { s.Log(nil, result, retErr, time.Since(start)) }(time.Now())
 	defer drainBlockServer(putBlockServer)
-	reader := bufio.NewReader(protostream.NewStreamingBytesReader(putBlockServer))
+	chunker := newChunker(bufio.NewReader(protostream.NewStreamingBytesReader(putBlockServer)), s.options.ChunkSizes)
 	for {
-		blockRef, err := s.putOneBlock(reader)
+		blockRef, eof, err := s.putOneBlock(chunker)
 		if err != nil {
 			return err
 		}
 		result.BlockRef = append(result.BlockRef, blockRef)
-		if (blockRef.Range.Upper - blockRef.Range.Lower) < uint64(blockSize) {
+		if eof {
 			break
 		}
 	}
@@ -77,13 +92,25 @@ func (s *localBlockAPIServer) GetBlock(request *pfsclient.GetBlockRequest, getBl
 			retErr = err
 		}
 	}()
-	reader := io.NewSectionReader(file, int64(request.OffsetBytes), int64(request.SizeBytes))
+	reader, err := blockReader(sectionReader(file), request.OffsetBytes, request.SizeBytes)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := reader.Close(); err != nil && retErr == nil {
+			retErr = err
+		}
+	}()
 	return protostream.WriteToStreamingBytesServer(reader, getBlockServer)
 }
 
 func (s *localBlockAPIServer) DeleteBlock(ctx context.Context, request *pfsclient.DeleteBlockRequest) (response *google_protobuf.Empty, retErr error) {
 	defer func(start time.Time) { s.Log(request, response, retErr, time.Since(start)) }(time.Now())
-	return google_protobuf.EmptyInstance, s.deleteBlock(request.Block)
+	// deleting a block which is already gone isn't an error
+	if err := s.deleteBlock(request.Block); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return google_protobuf.EmptyInstance, nil
 }
 
 func (s *localBlockAPIServer) InspectBlock(ctx context.Context, request *pfsclient.InspectBlockRequest) (response *pfsclient.BlockInfo, retErr error) {
@@ -92,18 +119,36 @@ func (s *localBlockAPIServer) InspectBlock(ctx context.Context, request *pfsclie
 	if err != nil {
 		return nil, err
 	}
-	return &pfsclient.BlockInfo{
-		Block: request.Block,
-		Created: prototime.TimeToTimestamp(
-			stat.ModTime(),
-		),
-		SizeBytes: uint64(stat.Size()),
-	}, nil
+	return s.blockInfo(request.Block, stat)
 }
 
+// ListBlock is deprecated, it's ListBlockStream in a single message.
 func (s *localBlockAPIServer) ListBlock(ctx context.Context, request *pfsclient.ListBlockRequest) (response *pfsclient.BlockInfos, retErr error) {
+	defer func(start time.Time) { s.Log(request, nil, retErr, time.Since(start)) }(time.Now())
+	response = &pfsclient.BlockInfos{}
+	if err := s.walkBlocks(func(blockInfo *pfsclient.BlockInfo) error {
+		response.BlockInfo = append(response.BlockInfo, blockInfo)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (s *localBlockAPIServer) ListBlockStream(request *pfsclient.ListBlockRequest, listBlockServer pfsclient.BlockAPI_ListBlockStreamServer) (retErr error) {
+	defer func(start time.Time) { s.Log(request, nil, retErr, time.Since(start)) }(time.Now())
+	return s.walkBlocks(listBlockServer.Send)
+}
+
+func (s *localBlockAPIServer) GarbageCollect(ctx context.Context, request *pfsclient.GarbageCollectRequest) (response *pfsclient.GarbageCollectResponse, retErr error) {
 	defer func(start time.Time) { s.Log(request, response, retErr, time.Since(start)) }(time.Now())
-	return nil, fmt.Errorf("not implemented")
+	cutoff := gcCutoff(request)
+	inspect := func(ctx context.Context, block *pfsclient.Block) (*pfsclient.BlockInfo, error) {
+		return s.InspectBlock(ctx, &pfsclient.InspectBlockRequest{Block: block})
+	}
+	return garbageCollect(ctx, inspect, s.walkDiffs, s.listBlocks, func(ctx context.Context, block *pfsclient.Block, cutoff time.Time) (*pfsclient.BlockInfo, error) {
+		return sweepBlock(ctx, s, &s.gcLock, block, cutoff)
+	}, request, cutoff)
 }
 
 func (s *localBlockAPIServer) CreateDiff(ctx context.Context, request *pfsclient.DiffInfo) (response *google_protobuf.Empty, retErr error) {
@@ -115,7 +160,23 @@ func (s *localBlockAPIServer) CreateDiff(ctx context.Context, request *pfsclient
 	if err := os.MkdirAll(path.Dir(s.diffPath(request.Diff)), 0777); err != nil {
 		return nil, err
 	}
-	if err := ioutil.WriteFile(s.diffPath(request.Diff), data, 0666); err != nil {
+	// diffs are rewritten by the driver so we replace them atomically, a
+	// reader never sees a partial diff
+	file, err := ioutil.TempFile(s.tmpDir(), "diff")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return nil, err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(file.Name())
+		return nil, err
+	}
+	if err := os.Rename(file.Name(), s.diffPath(request.Diff)); err != nil {
+		os.Remove(file.Name())
 		return nil, err
 	}
 	return google_protobuf.EmptyInstance, nil
@@ -128,31 +189,165 @@ func (s *localBlockAPIServer) InspectDiff(ctx context.Context, request *pfsclien
 
 func (s *localBlockAPIServer) ListDiff(request *pfsclient.ListDiffRequest, listDiffServer pfsclient.BlockAPI_ListDiffServer) (retErr error) {
 	defer func(start time.Time) { s.Log(request, nil, retErr, time.Since(start)) }(time.Now())
-	if err := filepath.Walk(s.diffDir(), func(path string, info os.FileInfo, err error) error {
+	shard := strconv.FormatUint(request.Shard, 10)
+	return filepath.Walk(s.diffDir(), func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			// diffs are stored in diff/repo/shard/commit
+			if filepath.Dir(filepath.Dir(path)) == s.diffDir() && info.Name() != shard {
+				return filepath.SkipDir
+			}
+			return nil
+		}
 		diff := s.pathToDiff(path)
-		if diff == nil {
-			// likely a directory
+		if diff == nil || diff.Shard != request.Shard || !diffModifiedAfter(request, info.ModTime()) {
 			return nil
 		}
-		if diff.Shard == request.Shard {
-			diffInfo, err := s.readDiff(diff)
-			if err != nil {
-				return err
-			}
-			if err := listDiffServer.Send(diffInfo); err != nil {
-				return err
-			}
+		diffInfo, err := s.readDiff(diff)
+		if err != nil {
+			return err
 		}
-		return nil
+		return listDiffServer.Send(diffInfo)
+	})
+}
+
+func (s *localBlockAPIServer) DeleteDiff(ctx context.Context, request *pfsclient.DeleteDiffRequest) (response *google_protobuf.Empty, retErr error) {
+	defer func(start time.Time) { s.Log(request, response, retErr, time.Since(start)) }(time.Now())
+	return google_protobuf.EmptyInstance, os.Remove(s.diffPath(request.Diff))
+}
+
+func (s *localBlockAPIServer) CreateShardSnapshot(createShardSnapshotServer pfsclient.BlockAPI_CreateShardSnapshotServer) (retErr error) {
+	defer func(start time.Time) { s.Log(nil, nil, retErr, time.Since(start)) }(time.Now())
+	if err := receiveShardSnapshot(createShardSnapshotServer, func(shard uint64, reader io.Reader) error {
+		file, err := ioutil.TempFile(s.tmpDir(), "snapshot")
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(file, reader); err != nil {
+			file.Close()
+			os.Remove(file.Name())
+			return err
+		}
+		if err := file.Close(); err != nil {
+			os.Remove(file.Name())
+			return err
+		}
+		return os.Rename(file.Name(), s.snapshotPath(shard))
 	}); err != nil {
 		return err
 	}
-	return nil
+	return createShardSnapshotServer.SendAndClose(google_protobuf.EmptyInstance)
 }
 
-func (s *localBlockAPIServer) DeleteDiff(ctx context.Context, request *pfsclient.DeleteDiffRequest) (response *google_protobuf.Empty, retErr error) {
+func (s *localBlockAPIServer) GetShardSnapshot(request *pfsclient.GetShardSnapshotRequest, getShardSnapshotServer pfsclient.BlockAPI_GetShardSnapshotServer) (retErr error) {
+	defer func(start time.Time) { s.Log(request, nil, retErr, time.Since(start)) }(time.Now())
+	file, err := os.Open(s.snapshotPath(request.Shard))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer func() {
+		if err := file.Close(); err != nil && retErr == nil {
+			retErr = err
+		}
+	}()
+	stat, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	return sendShardSnapshot(getShardSnapshotServer, request.Shard, stat.ModTime(), file)
+}
+
+func (s *localBlockAPIServer) DeleteShardSnapshot(ctx context.Context, request *pfsclient.DeleteShardSnapshotRequest) (response *google_protobuf.Empty, retErr error) {
 	defer func(start time.Time) { s.Log(request, response, retErr, time.Since(start)) }(time.Now())
-	return google_protobuf.EmptyInstance, os.Remove(s.diffPath(request.Diff))
+	if err := os.Remove(s.snapshotPath(request.Shard)); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return google_protobuf.EmptyInstance, nil
+}
+
+func (s *localBlockAPIServer) blockInfo(block *pfsclient.Block, stat os.FileInfo) (_ *pfsclient.BlockInfo, retErr error) {
+	result := &pfsclient.BlockInfo{
+		Block: block,
+		Created: prototime.TimeToTimestamp(
+			stat.ModTime(),
+		),
+		SizeBytes:       uint64(stat.Size()),
+		StoredSizeBytes: uint64(stat.Size()),
+	}
+	file, err := s.blockFile(block)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := file.Close(); err != nil && retErr == nil {
+			retErr = err
+		}
+	}()
+	header, ok, err := readBlockHeader(file)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		result.SizeBytes = header.sizeBytes
+		result.Compression = header.compression
+	}
+	return result, nil
+}
+
+func (s *localBlockAPIServer) walkBlocks(f func(*pfsclient.BlockInfo) error) error {
+	stats, err := ioutil.ReadDir(s.blockDir())
+	if err != nil {
+		return err
+	}
+	for _, stat := range stats {
+		blockInfo, err := s.blockInfo(&pfsclient.Block{Hash: stat.Name()}, stat)
+		if err != nil {
+			return err
+		}
+		if err := f(blockInfo); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// listBlocks calls f on each block, unlike walkBlocks it doesn't stat them.
+func (s *localBlockAPIServer) listBlocks(f func(*pfsclient.Block) error) error {
+	dir, err := os.Open(s.blockDir())
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+	names, err := dir.Readdirnames(-1)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if err := f(&pfsclient.Block{Hash: name}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *localBlockAPIServer) walkDiffs(f func(*pfsclient.DiffInfo) error) error {
+	return filepath.Walk(s.diffDir(), func(path string, info os.FileInfo, err error) error {
+		diff := s.pathToDiff(path)
+		if diff == nil {
+			// likely a directory
+			return nil
+		}
+		diffInfo, err := s.readDiff(diff)
+		if err != nil {
+			return err
+		}
+		return f(diffInfo)
+	})
 }
 
 func (s *localBlockAPIServer) tmpDir() string {
@@ -181,6 +376,14 @@ func (s *localBlockAPIServer) diffPath(diff *pfsclient.Diff) string {
 	return filepath.Join(s.diffDir(), diff.Commit.Repo.Name, strconv.FormatUint(diff.Shard, 10), commitID)
 }
 
+func (s *localBlockAPIServer) snapshotDir() string {
+	return filepath.Join(s.dir, "snapshot")
+}
+
+func (s *localBlockAPIServer) snapshotPath(shard uint64) string {
+	return filepath.Join(s.snapshotDir(), strconv.FormatUint(shard, 10))
+}
+
 // pathToDiff parses a path as a diff, it returns nil when parse fails
 func (s *localBlockAPIServer) pathToDiff(path string) *pfsclient.Diff {
 	repoCommitShard := strings.Split(strings.TrimPrefix(path, s.diffDir()+"/"), "/")
@@ -216,51 +419,61 @@ func (s *localBlockAPIServer) readDiff(diff *pfsclient.Diff) (*pfsclient.DiffInf
 	return result, nil
 }
 
-func readBlock(reader *bufio.Reader) (*pfsclient.BlockRef, []byte, error) {
-	var buffer bytes.Buffer
-	var bytesWritten int
-	hash := newHash()
-	EOF := false
-	for !EOF {
-		bytes, err := reader.ReadBytes('\n')
-		if err != nil {
-			if err == io.EOF {
-				EOF = true
-			} else {
-				return nil, nil, err
-			}
-		}
-		buffer.Write(bytes)
-		hash.Write(bytes)
-		bytesWritten += len(bytes)
-		if bytesWritten > blockSize {
-			break
-		}
+func readBlock(chunker *chunker) (*pfsclient.BlockRef, []byte, bool, error) {
+	data, eof, err := chunker.next()
+	if err != nil {
+		return nil, nil, false, err
 	}
+	hash := newHash()
+	hash.Write(data)
 	return &pfsclient.BlockRef{
 		Block: getBlock(hash),
 		Range: &pfsclient.ByteRange{
 			Lower: 0,
-			Upper: uint64(buffer.Len()),
+			Upper: uint64(len(data)),
 		},
-	}, buffer.Bytes(), nil
+	}, data, eof, nil
 }
 
-func (s *localBlockAPIServer) putOneBlock(reader *bufio.Reader) (*pfsclient.BlockRef, error) {
-	blockRef, data, err := readBlock(reader)
+func (s *localBlockAPIServer) putOneBlock(chunker *chunker) (*pfsclient.BlockRef, bool, error) {
+	blockRef, data, eof, err := readBlock(chunker)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
+	data, err = encodeBlock(data, s.options.Compression)
+	if err != nil {
+		return nil, false, err
+	}
+	s.gcLock.RLock()
+	defer s.gcLock.RUnlock()
 	if _, err := os.Stat(s.blockPath(blockRef.Block)); os.IsNotExist(err) {
 		ioutil.WriteFile(s.blockPath(blockRef.Block), data, 0666)
+	} else {
+		// touch the block so garbage collection knows it's been put
+		// recently, the commit referencing it may not have a diff yet
+		now := time.Now()
+		if err := os.Chtimes(s.blockPath(blockRef.Block), now, now); err != nil {
+			return nil, false, err
+		}
 	}
-	return blockRef, nil
+	return blockRef, eof, nil
 }
 
 func (s *localBlockAPIServer) deleteBlock(block *pfsclient.Block) error {
 	return os.Remove(s.blockPath(block))
 }
 
+// sectionReader returns a function which reads ranges of file, size 0 means
+// read to the end of the file.
+func sectionReader(file *os.File) func(offset uint64, size uint64) (io.ReadCloser, error) {
+	return func(offset uint64, size uint64) (io.ReadCloser, error) {
+		if size == 0 {
+			size = math.MaxInt64
+		}
+		return ioutil.NopCloser(io.NewSectionReader(file, int64(offset), int64(size))), nil
+	}
+}
+
 func drainBlockServer(putBlockServer pfsclient.BlockAPI_PutBlockServer) {
 	for {
 		if _, err := putBlockServer.Recv(); err != nil {
@@ -2,42 +2,82 @@ package server
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
+	"go.pedge.io/lion/proto"
 	"go.pedge.io/pb/go/google/protobuf"
 	"go.pedge.io/proto/rpclog"
 	"go.pedge.io/proto/stream"
+	"go.pedge.io/proto/time"
 	"golang.org/x/net/context"
 
 	"github.com/gogo/protobuf/proto"
 	pfsclient "github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/client/pkg/uuid"
 	"github.com/pachyderm/pachyderm/src/server/pkg/obj"
 )
 
+const (
+	// rewriteSuffix is appended to the name of the copy of an object that's
+	// kept while it's rewritten, see rewriteObject.
+	rewriteSuffix = ".rewrite"
+	// touchInterval is how often a block which keeps being put is touched,
+	// see touchBlock.
+	touchInterval = time.Minute
+	// sweepTimeout is how old a sweep marker has to be before it's assumed to
+	// have been left by a sweep which died, see sweepBlock.
+	sweepTimeout = time.Hour
+	// listBlockParallelism is how many blocks ListBlock inspects at once.
+	listBlockParallelism = 16
+)
+
+// errWalkStopped stops a Walk whose results are no longer needed.
+var errWalkStopped = errors.New("walk stopped")
+
 type objBlockAPIServer struct {
 	protorpclog.Logger
 	dir         string
 	localServer *localBlockAPIServer
 	objClient   obj.Client
+	// cache is nil if caching is disabled
+	cache *blockCache
 }
 
-func newObjBlockAPIServer(dir string, objClient obj.Client) (*objBlockAPIServer, error) {
-	localServer, err := newLocalBlockAPIServer(dir)
+func newObjBlockAPIServer(dir string, objClient obj.Client, options BlockAPIServerOptions) (*objBlockAPIServer, error) {
+	localServer, err := newLocalBlockAPIServer(dir, options)
 	if err != nil {
 		return nil, err
 	}
+	var cache *blockCache
+	if options.CacheSize > 0 && obj.IsEncrypted(objClient) {
+		// we'd leave plaintext copies of the blocks on local disk
+		protolion.Infof("block cache disabled because object storage is encrypted")
+	} else if options.CacheSize > 0 {
+		cache, err = newBlockCache(localServer.blockDir(), localServer.tmpDir(), options.CacheSize)
+		if err != nil {
+			return nil, err
+		}
+		go cache.logStats(time.Minute)
+	}
 	return &objBlockAPIServer{
 		Logger:      protorpclog.NewLogger("pachyderm.pfsclient.objBlockAPIServer"),
 		dir:         dir,
 		localServer: localServer,
 		objClient:   objClient,
+		cache:       cache,
 	}, nil
 }
 
-func newAmazonBlockAPIServer(dir string) (*objBlockAPIServer, error) {
+func newAmazonBlockAPIServer(dir string, options BlockAPIServerOptions) (*objBlockAPIServer, error) {
 	bucket, err := ioutil.ReadFile("/amazon-secret/bucket")
 	if err != nil {
 		return nil, err
@@ -62,10 +102,14 @@ func newAmazonBlockAPIServer(dir string) (*objBlockAPIServer, error) {
 	if err != nil {
 		return nil, err
 	}
-	return newObjBlockAPIServer(dir, objClient)
+	objClient, err = encryptObjClient(objClient)
+	if err != nil {
+		return nil, err
+	}
+	return newObjBlockAPIServer(dir, objClient, options)
 }
 
-func newGoogleBlockAPIServer(dir string) (*objBlockAPIServer, error) {
+func newGoogleBlockAPIServer(dir string, options BlockAPIServerOptions) (*objBlockAPIServer, error) {
 	bucket, err := ioutil.ReadFile("/google-secret/bucket")
 	if err != nil {
 		return nil, err
@@ -74,18 +118,149 @@ func newGoogleBlockAPIServer(dir string) (*objBlockAPIServer, error) {
 	if err != nil {
 		return nil, err
 	}
-	return newObjBlockAPIServer(dir, objClient)
+	objClient, err = encryptObjClient(objClient)
+	if err != nil {
+		return nil, err
+	}
+	return newObjBlockAPIServer(dir, objClient, options)
+}
+
+// newLocalObjBlockAPIServer creates a block server backed by a directory under
+// dir, it exercises the object storage code paths without a cloud account.
+func newLocalObjBlockAPIServer(dir string, options BlockAPIServerOptions) (*objBlockAPIServer, error) {
+	objClient, err := obj.NewLocalClient(filepath.Join(dir, "obj"))
+	if err != nil {
+		return nil, err
+	}
+	objClient, err = encryptObjClient(objClient)
+	if err != nil {
+		return nil, err
+	}
+	return newObjBlockAPIServer(dir, objClient, options)
+}
+
+// newS3BlockAPIServer creates a block server backed by an S3-compatible store
+// such as MinIO, its "secure" and "path-style" options are "true" or "false".
+func newS3BlockAPIServer(dir string, options BlockAPIServerOptions) (*objBlockAPIServer, error) {
+	bucket, err := ioutil.ReadFile("/s3-secret/bucket")
+	if err != nil {
+		return nil, err
+	}
+	id, err := ioutil.ReadFile("/s3-secret/id")
+	if err != nil {
+		return nil, err
+	}
+	secret, err := ioutil.ReadFile("/s3-secret/secret")
+	if err != nil {
+		return nil, err
+	}
+	endpoint, err := ioutil.ReadFile("/s3-secret/endpoint")
+	if err != nil {
+		return nil, err
+	}
+	region, err := ioutil.ReadFile("/s3-secret/region")
+	if err != nil {
+		return nil, err
+	}
+	secure, err := ioutil.ReadFile("/s3-secret/secure")
+	if err != nil {
+		return nil, err
+	}
+	pathStyle, err := ioutil.ReadFile("/s3-secret/path-style")
+	if err != nil {
+		return nil, err
+	}
+	objClient, err := obj.NewS3Client(string(bucket), string(id), string(secret), string(endpoint), string(region),
+		string(secure) == "true", string(pathStyle) == "true")
+	if err != nil {
+		return nil, err
+	}
+	objClient, err = encryptObjClient(objClient)
+	if err != nil {
+		return nil, err
+	}
+	return newObjBlockAPIServer(dir, objClient, options)
+}
+
+// newAzureBlockAPIServer creates a block server backed by an Azure Blob
+// Storage container, "endpoint" is empty unless the container isn't in the
+// public Azure cloud (or it's the Azurite emulator).
+func newAzureBlockAPIServer(dir string, options BlockAPIServerOptions) (*objBlockAPIServer, error) {
+	container, err := ioutil.ReadFile("/azure-secret/container")
+	if err != nil {
+		return nil, err
+	}
+	account, err := ioutil.ReadFile("/azure-secret/account")
+	if err != nil {
+		return nil, err
+	}
+	key, err := ioutil.ReadFile("/azure-secret/key")
+	if err != nil {
+		return nil, err
+	}
+	endpoint, err := ioutil.ReadFile("/azure-secret/endpoint")
+	if err != nil {
+		return nil, err
+	}
+	objClient, err := obj.NewAzureClient(string(container), string(account), string(key), string(endpoint))
+	if err != nil {
+		return nil, err
+	}
+	objClient, err = encryptObjClient(objClient)
+	if err != nil {
+		return nil, err
+	}
+	return newObjBlockAPIServer(dir, objClient, options)
+}
+
+// encryptObjClient wraps objClient with encryption if the encryption secret
+// is mounted. The secret contains a file "key-id" with the ID of the key used
+// for new objects and a file per key named by its ID, old keys have to be
+// kept in the secret for as long as there are objects encrypted with them.
+// Objects which aren't encrypted can't be read unless the secret contains a
+// file "allow-plaintext", it's for turning on encryption for an existing
+// store.
+func encryptObjClient(objClient obj.Client) (obj.Client, error) {
+	fileInfos, err := ioutil.ReadDir("/encryption-secret")
+	if err != nil {
+		if os.IsNotExist(err) {
+			return objClient, nil
+		}
+		return nil, err
+	}
+	keyID, err := ioutil.ReadFile("/encryption-secret/key-id")
+	if err != nil {
+		return nil, err
+	}
+	keys := make(map[string][]byte)
+	for _, fileInfo := range fileInfos {
+		// kubernetes puts its own hidden files and directories in secret
+		// volumes
+		if fileInfo.Name() == "key-id" || fileInfo.Name() == "allow-plaintext" || strings.HasPrefix(fileInfo.Name(), ".") || fileInfo.IsDir() {
+			continue
+		}
+		key, err := ioutil.ReadFile(filepath.Join("/encryption-secret", fileInfo.Name()))
+		if err != nil {
+			return nil, err
+		}
+		keys[fileInfo.Name()] = key
+	}
+	_, err = os.Stat("/encryption-secret/allow-plaintext")
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return obj.NewEncryptedClient(objClient, keys, strings.TrimSpace(string(keyID)), err == nil)
 }
 
 func (s *objBlockAPIServer) PutBlock(putBlockServer pfsclient.BlockAPI_PutBlockServer) (retErr error) {
 	result := &pfsclient.BlockRefs{}
 	defer func(start time.Time) { s.Log(nil, result, retErr, time.Since(start)) }(time.Now())
 	defer drainBlockServer(putBlockServer)
-	reader := bufio.NewReader(protostream.NewStreamingBytesReader(putBlockServer))
+	chunker := newChunker(bufio.NewReader(protostream.NewStreamingBytesReader(putBlockServer)), s.localServer.options.ChunkSizes)
 	var wg sync.WaitGroup
 	errCh := make(chan error, 1)
 	for {
-		blockRef, data, err := readBlock(reader)
+		blockRef, data, eof, err := readBlock(chunker)
 		if err != nil {
 			return err
 		}
@@ -93,31 +268,14 @@ func (s *objBlockAPIServer) PutBlock(putBlockServer pfsclient.BlockAPI_PutBlockS
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			writer, err := s.objClient.Writer(s.localServer.blockPath(blockRef.Block))
-			if err != nil {
-				select {
-				case errCh <- err:
-				default:
-				}
-				return
-			}
-			defer func() {
-				if err := writer.Close(); err != nil {
-					select {
-					case errCh <- err:
-					default:
-					}
-					return
-				}
-			}()
-			if _, err := writer.Write(data); err != nil {
+			if err := s.putBlock(blockRef.Block, data); err != nil {
 				select {
 				case errCh <- err:
 				default:
 				}
 			}
 		}()
-		if (blockRef.Range.Upper - blockRef.Range.Lower) < uint64(blockSize) {
+		if eof {
 			break
 		}
 	}
@@ -130,9 +288,55 @@ func (s *objBlockAPIServer) PutBlock(putBlockServer pfsclient.BlockAPI_PutBlockS
 	return putBlockServer.SendAndClose(result)
 }
 
+// putBlock stores data as block unless it's already stored.
+func (s *objBlockAPIServer) putBlock(block *pfsclient.Block, data []byte) error {
+	if _, err := s.objClient.Inspect(s.localServer.blockPath(block)); err == nil {
+		stored, err := s.dedupBlock(block)
+		if err != nil {
+			return err
+		}
+		if stored {
+			return nil
+		}
+	}
+	data, err := encodeBlock(data, s.localServer.options.Compression)
+	if err != nil {
+		return err
+	}
+	return s.putObject(s.localServer.blockPath(block), data)
+}
+
+// putObject writes an object which is named by its content, it isn't an
+// error if the object has already been written by someone else.
+func (s *objBlockAPIServer) putObject(name string, data []byte) error {
+	if err := s.writeObject(name, data); err != nil {
+		if _, inspectErr := s.objClient.Inspect(name); inspectErr != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (s *objBlockAPIServer) GetBlock(request *pfsclient.GetBlockRequest, getBlockServer pfsclient.BlockAPI_GetBlockServer) (retErr error) {
 	defer func(start time.Time) { s.Log(request, nil, retErr, time.Since(start)) }(time.Now())
-	reader, err := s.objClient.Reader(s.localServer.blockPath(request.Block), request.OffsetBytes, request.SizeBytes)
+	read := func(offset uint64, size uint64) (io.ReadCloser, error) {
+		return s.objClient.Reader(s.localServer.blockPath(request.Block), offset, size)
+	}
+	if s.cache != nil {
+		file, err := s.cache.open(request.Block.Hash, func() (io.ReadCloser, error) {
+			return read(0, 0)
+		})
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := file.Close(); err != nil && retErr == nil {
+				retErr = err
+			}
+		}()
+		read = sectionReader(file)
+	}
+	reader, err := blockReader(read, request.OffsetBytes, request.SizeBytes)
 	if err != nil {
 		return err
 	}
@@ -146,37 +350,92 @@ func (s *objBlockAPIServer) GetBlock(request *pfsclient.GetBlockRequest, getBloc
 
 func (s *objBlockAPIServer) DeleteBlock(ctx context.Context, request *pfsclient.DeleteBlockRequest) (response *google_protobuf.Empty, retErr error) {
 	defer func(start time.Time) { s.Log(request, response, retErr, time.Since(start)) }(time.Now())
-	return google_protobuf.EmptyInstance, s.objClient.Delete(s.localServer.blockPath(request.Block))
+	if s.cache != nil {
+		if err := s.cache.delete(request.Block.Hash); err != nil {
+			return nil, err
+		}
+	}
+	// deleting a block which is already gone isn't an error
+	if err := s.objClient.Delete(s.localServer.blockPath(request.Block)); err != nil && !s.objClient.IsNotExist(err) {
+		return nil, err
+	}
+	return google_protobuf.EmptyInstance, nil
 }
 
 func (s *objBlockAPIServer) InspectBlock(ctx context.Context, request *pfsclient.InspectBlockRequest) (response *pfsclient.BlockInfo, retErr error) {
-	return nil, fmt.Errorf("not implemented")
+	defer func(start time.Time) { s.Log(request, response, retErr, time.Since(start)) }(time.Now())
+	touched, err := s.touchedBlocks(request.Block.Hash + "/")
+	if err != nil {
+		return nil, err
+	}
+	return s.blockInfo(request.Block, touched)
 }
 
+// ListBlock is deprecated, it's ListBlockStream in a single message.
 func (s *objBlockAPIServer) ListBlock(ctx context.Context, request *pfsclient.ListBlockRequest) (response *pfsclient.BlockInfos, retErr error) {
-	defer func(start time.Time) { s.Log(request, response, retErr, time.Since(start)) }(time.Now())
-	return nil, fmt.Errorf("not implemented")
+	defer func(start time.Time) { s.Log(request, nil, retErr, time.Since(start)) }(time.Now())
+	response = &pfsclient.BlockInfos{}
+	if err := s.walkBlocks(func(blockInfo *pfsclient.BlockInfo) error {
+		response.BlockInfo = append(response.BlockInfo, blockInfo)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return response, nil
 }
 
-func (s *objBlockAPIServer) CreateDiff(ctx context.Context, request *pfsclient.DiffInfo) (response *google_protobuf.Empty, retErr error) {
+func (s *objBlockAPIServer) ListBlockStream(request *pfsclient.ListBlockRequest, listBlockServer pfsclient.BlockAPI_ListBlockStreamServer) (retErr error) {
+	defer func(start time.Time) { s.Log(request, nil, retErr, time.Since(start)) }(time.Now())
+	return s.walkBlocks(listBlockServer.Send)
+}
+
+func (s *objBlockAPIServer) GarbageCollect(ctx context.Context, request *pfsclient.GarbageCollectRequest) (response *pfsclient.GarbageCollectResponse, retErr error) {
 	defer func(start time.Time) { s.Log(request, response, retErr, time.Since(start)) }(time.Now())
-	data, err := proto.Marshal(request)
+	cutoff := gcCutoff(request)
+	response, err := garbageCollect(ctx, s.inspectForGC, s.walkDiffs, s.listBlocks, s.sweepBlock, request, cutoff)
 	if err != nil {
 		return nil, err
 	}
-	writer, err := s.objClient.Writer(s.localServer.diffPath(request.Diff))
+	if !request.DryRun {
+		// touches from before the cutoff no longer protect anything
+		if err := s.objClient.Walk(s.touchDir(), func(name string) error {
+			_, touched, ok := s.parseTouch(name)
+			if ok && !touched.Before(cutoff) {
+				return nil
+			}
+			if !ok {
+				protolion.Errorf("couldn't parse touch %s", name)
+			}
+			return s.objClient.Delete(name)
+		}); err != nil {
+			return nil, err
+		}
+		// and markers left by sweeps which died
+		if err := s.objClient.Walk(s.sweepDir(), func(name string) error {
+			nanos, err := strconv.ParseInt(strings.Split(filepath.Base(name), "-")[0], 10, 64)
+			if err == nil && time.Since(time.Unix(0, nanos)) < sweepTimeout {
+				return nil
+			}
+			return s.objClient.Delete(name)
+		}); err != nil {
+			return nil, err
+		}
+	}
+	return response, nil
+}
+
+func (s *objBlockAPIServer) CreateDiff(ctx context.Context, request *pfsclient.DiffInfo) (response *google_protobuf.Empty, retErr error) {
+	defer func(start time.Time) { s.Log(request, response, retErr, time.Since(start)) }(time.Now())
+	data, err := proto.Marshal(request)
 	if err != nil {
 		return nil, err
 	}
-	defer func() {
-		if err := writer.Close(); err != nil && retErr == nil {
-			retErr = err
-		}
-	}()
-	if _, err := writer.Write(data); err != nil {
-		return nil, err
+	diffPath := s.localServer.diffPath(request.Diff)
+	if _, err := s.objClient.Inspect(diffPath); err == nil {
+		// the diff is being rewritten because an ancestor was deleted
+		return google_protobuf.EmptyInstance, s.rewriteObject(diffPath, data)
 	}
-	return google_protobuf.EmptyInstance, nil
+	return google_protobuf.EmptyInstance, s.writeObject(diffPath, data)
 }
 
 func (s *objBlockAPIServer) InspectDiff(ctx context.Context, request *pfsclient.InspectDiffRequest) (response *pfsclient.DiffInfo, retErr error) {
@@ -186,41 +445,464 @@ func (s *objBlockAPIServer) InspectDiff(ctx context.Context, request *pfsclient.
 
 func (s *objBlockAPIServer) ListDiff(request *pfsclient.ListDiffRequest, listDiffServer pfsclient.BlockAPI_ListDiffServer) (retErr error) {
 	defer func(start time.Time) { s.Log(request, nil, retErr, time.Since(start)) }(time.Now())
-	if err := s.objClient.Walk(s.localServer.diffDir(), func(path string) error {
-		diff := s.localServer.pathToDiff(path)
-		if diff == nil {
-			return fmt.Errorf("couldn't parse %s", path)
+	return s.walkDiffPaths(func(diff *pfsclient.Diff, path string) error {
+		if diff.Shard != request.Shard {
+			return nil
 		}
-		if diff.Shard == request.Shard {
-			diffInfo, err := s.readDiff(diff)
+		if request.ModifiedAfter != nil {
+			objectInfo, err := s.objClient.Inspect(path)
 			if err != nil {
 				return err
 			}
-			if err := listDiffServer.Send(diffInfo); err != nil {
-				return err
+			if !diffModifiedAfter(request, objectInfo.Modified) {
+				return nil
 			}
 		}
+		diffInfo, err := s.readDiff(diff)
+		if err != nil {
+			return err
+		}
+		return listDiffServer.Send(diffInfo)
+	})
+}
+
+func (s *objBlockAPIServer) DeleteDiff(ctx context.Context, request *pfsclient.DeleteDiffRequest) (response *google_protobuf.Empty, retErr error) {
+	defer func(start time.Time) { s.Log(request, response, retErr, time.Since(start)) }(time.Now())
+	diffPath := s.localServer.diffPath(request.Diff)
+	if err := s.objClient.Delete(diffPath); err != nil {
+		return nil, err
+	}
+	// there's only a rewrite copy if a rewrite failed part way
+	if err := s.objClient.Delete(diffPath + rewriteSuffix); err != nil && !s.objClient.IsNotExist(err) {
+		return nil, err
+	}
+	return google_protobuf.EmptyInstance, nil
+}
+
+func (s *objBlockAPIServer) CreateShardSnapshot(createShardSnapshotServer pfsclient.BlockAPI_CreateShardSnapshotServer) (retErr error) {
+	defer func(start time.Time) { s.Log(nil, nil, retErr, time.Since(start)) }(time.Now())
+	if err := receiveShardSnapshot(createShardSnapshotServer, func(shard uint64, reader io.Reader) error {
+		// objects can't be overwritten so each snapshot gets a new name and
+		// the old ones are deleted once it's written
+		created := time.Now().UnixNano()
+		name := filepath.Join(s.localServer.snapshotPath(shard), strconv.FormatInt(created, 10))
+		writer, err := s.objClient.Writer(name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(writer, reader); err != nil {
+			writer.Close()
+			s.objClient.Delete(name)
+			return err
+		}
+		if err := writer.Close(); err != nil {
+			return err
+		}
+		return s.walkShardSnapshots(shard, func(name string, snapshotCreated int64) error {
+			if snapshotCreated < created {
+				return s.objClient.Delete(name)
+			}
+			return nil
+		})
+	}); err != nil {
+		return err
+	}
+	return createShardSnapshotServer.SendAndClose(google_protobuf.EmptyInstance)
+}
+
+func (s *objBlockAPIServer) GetShardSnapshot(request *pfsclient.GetShardSnapshotRequest, getShardSnapshotServer pfsclient.BlockAPI_GetShardSnapshotServer) (retErr error) {
+	defer func(start time.Time) { s.Log(request, nil, retErr, time.Since(start)) }(time.Now())
+	var latest string
+	var latestCreated int64
+	if err := s.walkShardSnapshots(request.Shard, func(name string, created int64) error {
+		if created > latestCreated {
+			latest, latestCreated = name, created
+		}
 		return nil
 	}); err != nil {
 		return err
 	}
-	return nil
+	if latest == "" {
+		return nil
+	}
+	// the object's modification time is by the same clock as the diffs'
+	objectInfo, err := s.objClient.Inspect(latest)
+	if err != nil {
+		return err
+	}
+	reader, err := s.objClient.Reader(latest, 0, 0)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := reader.Close(); err != nil && retErr == nil {
+			retErr = err
+		}
+	}()
+	return sendShardSnapshot(getShardSnapshotServer, request.Shard, objectInfo.Modified, reader)
 }
 
-func (s *objBlockAPIServer) DeleteDiff(ctx context.Context, request *pfsclient.DeleteDiffRequest) (response *google_protobuf.Empty, retErr error) {
+func (s *objBlockAPIServer) DeleteShardSnapshot(ctx context.Context, request *pfsclient.DeleteShardSnapshotRequest) (response *google_protobuf.Empty, retErr error) {
 	defer func(start time.Time) { s.Log(request, response, retErr, time.Since(start)) }(time.Now())
-	return google_protobuf.EmptyInstance, s.objClient.Delete(s.localServer.diffPath(request.Diff))
+	if err := s.walkShardSnapshots(request.Shard, func(name string, created int64) error {
+		return s.objClient.Delete(name)
+	}); err != nil {
+		return nil, err
+	}
+	return google_protobuf.EmptyInstance, nil
 }
 
-func (s *objBlockAPIServer) readDiff(diff *pfsclient.Diff) (*pfsclient.DiffInfo, error) {
-	reader, err := s.objClient.Reader(s.localServer.diffPath(diff), 0, 0)
+// walkShardSnapshots calls f with the name of each of shard's snapshots and
+// the time it was created in nanoseconds, there's more than one if an old one
+// hasn't been deleted yet.
+func (s *objBlockAPIServer) walkShardSnapshots(shard uint64, f func(name string, created int64) error) error {
+	return s.objClient.Walk(s.localServer.snapshotPath(shard)+"/", func(name string) error {
+		created, err := strconv.ParseInt(filepath.Base(name), 10, 64)
+		if err != nil {
+			return fmt.Errorf("couldn't parse snapshot %s", name)
+		}
+		return f(name, created)
+	})
+}
+
+// walkBlocks calls f on each block in object storage. Each block costs a
+// stat and a read of its header so listBlockParallelism of them are
+// inspected at once, f isn't called concurrently or in any particular order.
+// Garbage collection avoids this by only listing blocks and stating those it
+// might collect, see inspectForGC.
+func (s *objBlockAPIServer) walkBlocks(f func(*pfsclient.BlockInfo) error) error {
+	touched, err := s.touchedBlocks("")
+	if err != nil {
+		return err
+	}
+	blocks := make(chan *pfsclient.Block)
+	blockInfos := make(chan *pfsclient.BlockInfo)
+	errCh := make(chan error, 1)
+	done := make(chan struct{})
+	var doneOnce sync.Once
+	stop := func(err error) {
+		select {
+		case errCh <- err:
+		default:
+		}
+		doneOnce.Do(func() { close(done) })
+	}
+	go func() {
+		defer close(blocks)
+		if err := s.listBlocks(func(block *pfsclient.Block) error {
+			select {
+			case blocks <- block:
+				return nil
+			case <-done:
+				return errWalkStopped
+			}
+		}); err != nil && err != errWalkStopped {
+			stop(err)
+		}
+	}()
+	var wg sync.WaitGroup
+	for i := 0; i < listBlockParallelism; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for block := range blocks {
+				blockInfo, err := s.blockInfo(block, touched)
+				if err != nil {
+					stop(err)
+					return
+				}
+				select {
+				case blockInfos <- blockInfo:
+				case <-done:
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(blockInfos)
+	}()
+	for blockInfo := range blockInfos {
+		if err := f(blockInfo); err != nil {
+			stop(err)
+			break
+		}
+	}
+	select {
+	case err := <-errCh:
+		return err
+	default:
+	}
+	return nil
+}
+
+// listBlocks calls f on each block in object storage, unlike walkBlocks it
+// only lists them.
+func (s *objBlockAPIServer) listBlocks(f func(*pfsclient.Block) error) error {
+	return s.objClient.Walk(s.localServer.blockDir(), func(name string) error {
+		return f(&pfsclient.Block{Hash: filepath.Base(name)})
+	})
+}
+
+// blockInfo returns info about block, its Created is the last time it was put
+// according to touched (see touchBlock).
+func (s *objBlockAPIServer) blockInfo(block *pfsclient.Block, touched map[string]time.Time) (_ *pfsclient.BlockInfo, retErr error) {
+	result, err := s.statBlock(block, touched)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := s.objClient.Reader(s.localServer.blockPath(block), 0, uint64(blockHeaderSize))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := reader.Close(); err != nil && retErr == nil {
+			retErr = err
+		}
+	}()
+	header, ok, err := readBlockHeader(reader)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		result.SizeBytes = header.sizeBytes
+		result.Compression = header.compression
+	}
+	return result, nil
+}
+
+// statBlock is blockInfo without reading the block's header, the block is
+// taken to be stored raw.
+func (s *objBlockAPIServer) statBlock(block *pfsclient.Block, touched map[string]time.Time) (*pfsclient.BlockInfo, error) {
+	objectInfo, err := s.objClient.Inspect(s.localServer.blockPath(block))
+	if err != nil {
+		return nil, err
+	}
+	created := objectInfo.Modified
+	if touched[block.Hash].After(created) {
+		created = touched[block.Hash]
+	}
+	return &pfsclient.BlockInfo{
+		Block:           block,
+		Created:         prototime.TimeToTimestamp(created),
+		SizeBytes:       objectInfo.SizeBytes,
+		StoredSizeBytes: objectInfo.SizeBytes,
+	}, nil
+}
+
+// inspectForGC returns what garbage collection needs to know about block,
+// its Created and StoredSizeBytes, without reading from it.
+func (s *objBlockAPIServer) inspectForGC(ctx context.Context, block *pfsclient.Block) (*pfsclient.BlockInfo, error) {
+	touched, err := s.touchedBlocks(block.Hash + "/")
+	if err != nil {
+		return nil, err
+	}
+	return s.statBlock(block, touched)
+}
+
+// touchBlock records that block, which is already stored, has been put again.
+// Objects can't be overwritten, so rather than updating the block's
+// modification time (which is what protects new blocks from garbage
+// collection) we write an empty object named by the current touchInterval, a
+// block has at most one touch per interval.
+func (s *objBlockAPIServer) touchBlock(block *pfsclient.Block) error {
+	name := filepath.Join(s.touchDir(), block.Hash, fmt.Sprint(time.Now().Truncate(touchInterval).UnixNano()))
+	if _, err := s.objClient.Inspect(name); err == nil {
+		return nil
+	}
+	return s.putObject(name, nil)
+}
+
+// touchedBlocks returns the last time each block whose hash starts with
+// prefix was touched.
+func (s *objBlockAPIServer) touchedBlocks(prefix string) (map[string]time.Time, error) {
+	result := make(map[string]time.Time)
+	if err := s.objClient.Walk(filepath.Join(s.touchDir(), prefix), func(name string) error {
+		hash, touched, ok := s.parseTouch(name)
+		if ok && touched.After(result[hash]) {
+			result[hash] = touched
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// parseTouch returns the block a touch is for and the end of the interval it
+// was written in, which is when the block counts as touched.
+func (s *objBlockAPIServer) parseTouch(name string) (string, time.Time, bool) {
+	hashTouch := strings.Split(strings.TrimPrefix(name, s.touchDir()+"/"), "/")
+	if len(hashTouch) != 2 {
+		return "", time.Time{}, false
+	}
+	nanos, err := strconv.ParseInt(strings.Split(hashTouch[1], "-")[0], 10, 64)
+	if err != nil {
+		return hashTouch[0], time.Time{}, false
+	}
+	return hashTouch[0], time.Unix(0, nanos).Add(touchInterval), true
+}
+
+func (s *objBlockAPIServer) touchDir() string {
+	return filepath.Join(s.dir, "touch")
+}
+
+// dedupBlock protects block, which is already stored, from garbage collection
+// by touching it. It returns false if a sweep is deleting the block, in which
+// case it has to be written again.
+//
+// Puts and sweeps can be on different pachds sharing the store so they never
+// wait for each other. A put touches the block and then looks for a sweep
+// marker, a sweep writes its marker and then looks for touches (see
+// sweepBlock). Object storage is strongly consistent so either the put sees
+// the marker and writes the block itself, or the sweep sees the touch and
+// leaves the block alone.
+func (s *objBlockAPIServer) dedupBlock(block *pfsclient.Block) (bool, error) {
+	if err := s.touchBlock(block); err != nil {
+		return false, err
+	}
+	sweeping := false
+	if err := s.objClient.Walk(filepath.Join(s.sweepDir(), block.Hash)+"/", func(name string) error {
+		sweeping = true
+		return errWalkStopped
+	}); err != nil && err != errWalkStopped {
+		return false, err
+	}
+	return !sweeping, nil
+}
+
+// sweepBlock deletes block unless it was written or touched after cutoff. A
+// put which touches the block after it's inspected writes the block again
+// when it sees the marker, but that write can land before the delete, so the
+// block is restored if it's been touched by the time it's deleted.
+func (s *objBlockAPIServer) sweepBlock(ctx context.Context, block *pfsclient.Block, cutoff time.Time) (_ *pfsclient.BlockInfo, retErr error) {
+	marker := filepath.Join(s.sweepDir(), block.Hash, fmt.Sprintf("%d-%s", time.Now().UnixNano(), uuid.NewWithoutDashes()))
+	if err := s.putObject(marker, nil); err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := s.objClient.Delete(marker); err != nil && retErr == nil {
+			retErr = err
+		}
+	}()
+	blockInfo, err := s.inspectForGC(ctx, block)
+	if err != nil {
+		return nil, err
+	}
+	if !collectable(blockInfo, cutoff) {
+		return nil, nil
+	}
+	reader, err := s.objClient.Reader(s.localServer.blockPath(block), 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadAll(reader)
+	if err := reader.Close(); err != nil {
+		return nil, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.DeleteBlock(ctx, &pfsclient.DeleteBlockRequest{Block: block}); err != nil {
+		return nil, err
+	}
+	touched, err := s.touchedBlocks(block.Hash + "/")
 	if err != nil {
 		return nil, err
 	}
+	if !touched[block.Hash].Before(cutoff) {
+		return nil, s.putObject(s.localServer.blockPath(block), data)
+	}
+	return blockInfo, nil
+}
+
+// sweepDir holds a marker for each block which is being swept.
+func (s *objBlockAPIServer) sweepDir() string {
+	return filepath.Join(s.dir, "sweep")
+}
+
+func (s *objBlockAPIServer) walkDiffs(f func(*pfsclient.DiffInfo) error) error {
+	return s.walkDiffPaths(func(diff *pfsclient.Diff, path string) error {
+		diffInfo, err := s.readDiff(diff)
+		if err != nil {
+			return err
+		}
+		return f(diffInfo)
+	})
+}
+
+// walkDiffPaths calls f on every diff in the store along with the name of
+// the object it's stored in.
+func (s *objBlockAPIServer) walkDiffPaths(f func(diff *pfsclient.Diff, path string) error) error {
+	return s.objClient.Walk(s.localServer.diffDir(), func(path string) error {
+		diffPath := path
+		if strings.HasSuffix(path, rewriteSuffix) {
+			// a rewrite copy is only read if a failed rewrite deleted the
+			// diff itself
+			diffPath = strings.TrimSuffix(path, rewriteSuffix)
+			if _, err := s.objClient.Inspect(diffPath); err == nil {
+				return nil
+			}
+		}
+		diff := s.localServer.pathToDiff(diffPath)
+		if diff == nil {
+			return fmt.Errorf("couldn't parse %s", path)
+		}
+		return f(diff, path)
+	})
+}
+
+func (s *objBlockAPIServer) readDiff(diff *pfsclient.Diff) (*pfsclient.DiffInfo, error) {
+	diffPath := s.localServer.diffPath(diff)
+	reader, err := s.objClient.Reader(diffPath, 0, 0)
+	if err != nil {
+		var rewriteErr error
+		if reader, rewriteErr = s.objClient.Reader(diffPath+rewriteSuffix, 0, 0); rewriteErr != nil {
+			return nil, err
+		}
+	}
+	defer reader.Close()
 	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
 	result := &pfsclient.DiffInfo{}
 	if err := proto.Unmarshal(data, result); err != nil {
 		return nil, err
 	}
 	return result, nil
 }
+
+func (s *objBlockAPIServer) writeObject(name string, data []byte) (retErr error) {
+	writer, err := s.objClient.Writer(name)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := writer.Close(); err != nil && retErr == nil {
+			retErr = err
+		}
+	}()
+	_, err = writer.Write(data)
+	return err
+}
+
+// rewriteObject replaces the object name with data. Objects can't be
+// overwritten so it's deleted and written again, a copy of data is written
+// first so that the object isn't lost if we fail in between. Readers of
+// objects which are rewritten must fall back to name+rewriteSuffix.
+func (s *objBlockAPIServer) rewriteObject(name string, data []byte) error {
+	// a copy may be left over from a rewrite which failed
+	s.objClient.Delete(name + rewriteSuffix)
+	if err := s.writeObject(name+rewriteSuffix, data); err != nil {
+		return err
+	}
+	if err := s.objClient.Delete(name); err != nil {
+		return err
+	}
+	if err := s.writeObject(name, data); err != nil {
+		return err
+	}
+	return s.objClient.Delete(name + rewriteSuffix)
+}
@@ -0,0 +1,242 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	pclient "github.com/pachyderm/pachyderm/src/client"
+	pfsclient "github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/server/pkg/obj"
+	"go.pedge.io/proto/time"
+	"golang.org/x/net/context"
+)
+
+// newTestObjBlockAPIServer returns a server backed by a local obj.Client and
+// a function which writes an object with a given modification time.
+func newTestObjBlockAPIServer(t *testing.T) (*objBlockAPIServer, obj.Client, func(name string, data []byte, modified time.Time)) {
+	dir := uniqueString("/tmp/pach_test/run")
+	objClient, err := obj.NewLocalClient(filepath.Join(dir, "obj"))
+	require.NoError(t, err)
+	server, err := newObjBlockAPIServer(dir, objClient, DefaultBlockAPIServerOptions())
+	require.NoError(t, err)
+	return server, objClient, func(name string, data []byte, modified time.Time) {
+		writer, err := objClient.Writer(name)
+		require.NoError(t, err)
+		_, err = writer.Write(data)
+		require.NoError(t, err)
+		require.NoError(t, writer.Close())
+		require.NoError(t, os.Chtimes(filepath.Join(dir, "obj", name), modified, modified))
+	}
+}
+
+func TestObjInspectAndListBlock(t *testing.T) {
+	t.Parallel()
+	server, objClient, put := newTestObjBlockAPIServer(t)
+	old := time.Now().Add(-2 * gcGracePeriod)
+	data := strings.Repeat("foo\n", 100)
+	compressed, err := encodeBlock([]byte(data), pfsclient.Compression_COMPRESSION_GZIP)
+	require.NoError(t, err)
+	put(server.localServer.blockPath(pclient.NewBlock("compressed")), compressed, old)
+	put(server.localServer.blockPath(pclient.NewBlock("raw")), []byte(data), old)
+
+	blockInfo, err := server.InspectBlock(context.Background(), &pfsclient.InspectBlockRequest{Block: pclient.NewBlock("compressed")})
+	require.NoError(t, err)
+	require.Equal(t, uint64(len(data)), blockInfo.SizeBytes)
+	require.Equal(t, uint64(len(compressed)), blockInfo.StoredSizeBytes)
+	require.Equal(t, pfsclient.Compression_COMPRESSION_GZIP, blockInfo.Compression)
+	require.Equal(t, old.Unix(), prototime.TimestampToTime(blockInfo.Created).Unix())
+
+	var hashes []string
+	require.NoError(t, server.walkBlocks(func(blockInfo *pfsclient.BlockInfo) error {
+		require.Equal(t, uint64(len(data)), blockInfo.SizeBytes)
+		hashes = append(hashes, blockInfo.Block.Hash)
+		return nil
+	}))
+	sort.Strings(hashes)
+	require.Equal(t, []string{"compressed", "raw"}, hashes)
+
+	response, err := server.GarbageCollect(context.Background(), &pfsclient.GarbageCollectRequest{})
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), response.CollectedBlocks)
+	require.NoError(t, objClient.Walk(server.localServer.blockDir(), func(name string) error {
+		return fmt.Errorf("%s wasn't collected", name)
+	}))
+}
+
+func TestObjGarbageCollectTouched(t *testing.T) {
+	t.Parallel()
+	server, objClient, put := newTestObjBlockAPIServer(t)
+	old := time.Now().Add(-2 * gcGracePeriod)
+	block := pclient.NewBlock("block")
+	put(server.localServer.blockPath(block), []byte("foo\n"), old)
+	put(filepath.Join(server.touchDir(), "block", fmt.Sprintf("%d-old", old.UnixNano())), nil, old)
+	// putting the block again touches it rather than rewriting it
+	require.NoError(t, server.touchBlock(block))
+	blockInfo, err := server.InspectBlock(context.Background(), &pfsclient.InspectBlockRequest{Block: block})
+	require.NoError(t, err)
+	require.True(t, prototime.TimestampToTime(blockInfo.Created).After(old))
+
+	response, err := server.GarbageCollect(context.Background(), &pfsclient.GarbageCollectRequest{})
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), response.CollectedBlocks)
+	var touches []string
+	require.NoError(t, objClient.Walk(server.touchDir(), func(name string) error {
+		touches = append(touches, name)
+		return nil
+	}))
+	require.Equal(t, 1, len(touches))
+	require.False(t, strings.HasSuffix(touches[0], "-old"))
+}
+
+// readCountingClient counts the objects read from it.
+type readCountingClient struct {
+	obj.Client
+	reads int32
+}
+
+func (c *readCountingClient) Reader(name string, offset uint64, size uint64) (io.ReadCloser, error) {
+	atomic.AddInt32(&c.reads, 1)
+	return c.Client.Reader(name, offset, size)
+}
+
+func TestObjGarbageCollectDryRunDoesntRead(t *testing.T) {
+	t.Parallel()
+	dir := uniqueString("/tmp/pach_test/run")
+	localClient, err := obj.NewLocalClient(filepath.Join(dir, "obj"))
+	require.NoError(t, err)
+	objClient := &readCountingClient{Client: localClient}
+	server, err := newObjBlockAPIServer(dir, objClient, DefaultBlockAPIServerOptions())
+	require.NoError(t, err)
+	old := time.Now().Add(-2 * gcGracePeriod)
+	path := server.localServer.blockPath(pclient.NewBlock("block"))
+	writer, err := objClient.Writer(path)
+	require.NoError(t, err)
+	_, err = writer.Write([]byte("foo\n"))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	require.NoError(t, os.Chtimes(filepath.Join(dir, "obj", path), old, old))
+
+	// collecting only needs to know when blocks were written and how big
+	// they are, not what's in them
+	response, err := server.GarbageCollect(context.Background(), &pfsclient.GarbageCollectRequest{DryRun: true})
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), response.CollectedBlocks)
+	require.Equal(t, uint64(4), response.CollectedBytes)
+	require.Equal(t, int32(0), atomic.LoadInt32(&objClient.reads))
+}
+
+func TestObjDedupDuringSweep(t *testing.T) {
+	t.Parallel()
+	server, objClient, put := newTestObjBlockAPIServer(t)
+	old := time.Now().Add(-2 * gcGracePeriod)
+	block := pclient.NewBlock("block")
+	put(server.localServer.blockPath(block), []byte("foo\n"), old)
+	stored, err := server.dedupBlock(block)
+	require.NoError(t, err)
+	require.True(t, stored)
+	// a block is touched at most once per interval
+	require.NoError(t, server.touchBlock(block))
+	var touches []string
+	require.NoError(t, objClient.Walk(server.touchDir(), func(name string) error {
+		touches = append(touches, name)
+		return nil
+	}))
+	require.Equal(t, 1, len(touches))
+
+	// another pachd sharing the store is sweeping the block, puts don't wait
+	// for it, they write the block themselves
+	marker := filepath.Join(server.sweepDir(), "block", fmt.Sprintf("%d-other", time.Now().UnixNano()))
+	put(marker, nil, time.Now())
+	stored, err = server.dedupBlock(block)
+	require.NoError(t, err)
+	require.False(t, stored)
+	require.NoError(t, server.putBlock(block, []byte("foo\n")))
+	// and the sweep leaves the block alone
+	blockInfo, err := server.sweepBlock(context.Background(), block, time.Now().Add(-gcGracePeriod))
+	require.NoError(t, err)
+	require.Nil(t, blockInfo)
+	_, err = objClient.Inspect(server.localServer.blockPath(block))
+	require.NoError(t, err)
+
+	// markers left by sweeps which died are cleaned up
+	require.NoError(t, objClient.Delete(marker))
+	put(filepath.Join(server.sweepDir(), "block", fmt.Sprintf("%d-dead", old.Add(-sweepTimeout).UnixNano())), nil, old)
+	dead := pclient.NewBlock("dead")
+	put(server.localServer.blockPath(dead), []byte("bar\n"), old)
+	response, err := server.GarbageCollect(context.Background(), &pfsclient.GarbageCollectRequest{})
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), response.CollectedBlocks)
+	require.NoError(t, objClient.Walk(server.sweepDir(), func(name string) error {
+		return fmt.Errorf("%s wasn't cleaned up", name)
+	}))
+}
+
+func TestObjPutBlockTwice(t *testing.T) {
+	t.Parallel()
+	server, _, _ := newTestObjBlockAPIServer(t)
+	client := pclient.APIClient{BlockAPIClient: serveBlockAPIServer(t, server)}
+	data := strings.Repeat("foo\n", 1000)
+	blockRefs, err := client.PutBlock(strings.NewReader(data))
+	require.NoError(t, err)
+	blockRefs2, err := client.PutBlock(strings.NewReader(data))
+	require.NoError(t, err)
+	require.Equal(t, blockRefs, blockRefs2)
+	blockInfos, err := client.ListBlock()
+	require.NoError(t, err)
+	require.Equal(t, len(blockRefs.BlockRef), len(blockInfos))
+	// the deprecated ListBlock returns them in a single message
+	response, err := client.BlockAPIClient.ListBlock(context.Background(), &pfsclient.ListBlockRequest{})
+	require.NoError(t, err)
+	require.Equal(t, len(blockRefs.BlockRef), len(response.BlockInfo))
+	var buffer bytes.Buffer
+	for _, blockRef := range blockRefs.BlockRef {
+		reader, err := client.GetBlock(blockRef.Block.Hash, 0, 0)
+		require.NoError(t, err)
+		_, err = buffer.ReadFrom(reader)
+		require.NoError(t, err)
+	}
+	require.Equal(t, data, buffer.String())
+}
+
+func TestObjRewriteDiff(t *testing.T) {
+	t.Parallel()
+	server, objClient, put := newTestObjBlockAPIServer(t)
+	diff := pclient.NewDiff("repo", "commit", 0)
+	_, err := server.CreateDiff(context.Background(), &pfsclient.DiffInfo{Diff: diff, Branch: "old"})
+	require.NoError(t, err)
+	// diffs are rewritten when a commit's parent is deleted
+	_, err = server.CreateDiff(context.Background(), &pfsclient.DiffInfo{Diff: diff, Branch: "new"})
+	require.NoError(t, err)
+	diffInfo, err := server.InspectDiff(context.Background(), &pfsclient.InspectDiffRequest{Diff: diff})
+	require.NoError(t, err)
+	require.Equal(t, "new", diffInfo.Branch)
+
+	// a rewrite which failed after deleting the diff leaves only the copy
+	require.NoError(t, objClient.Delete(server.localServer.diffPath(diff)))
+	data, err := proto.Marshal(&pfsclient.DiffInfo{Diff: diff, Branch: "copy"})
+	require.NoError(t, err)
+	put(server.localServer.diffPath(diff)+rewriteSuffix, data, time.Now())
+	var branches []string
+	require.NoError(t, server.walkDiffs(func(diffInfo *pfsclient.DiffInfo) error {
+		branches = append(branches, diffInfo.Branch)
+		return nil
+	}))
+	require.Equal(t, []string{"copy"}, branches)
+	_, err = server.CreateDiff(context.Background(), &pfsclient.DiffInfo{Diff: diff, Branch: "new"})
+	require.NoError(t, err)
+	_, err = server.DeleteDiff(context.Background(), &pfsclient.DeleteDiffRequest{Diff: diff})
+	require.NoError(t, err)
+	require.NoError(t, objClient.Walk(server.localServer.diffDir(), func(name string) error {
+		return fmt.Errorf("%s wasn't deleted", name)
+	}))
+}
@@ -15,6 +15,11 @@ var (
 const (
 	AmazonBackendEnvVar = "AMAZON"
 	GoogleBackendEnvVar = "GOOGLE"
+	S3BackendEnvVar     = "S3"
+	AzureBackendEnvVar  = "AZURE"
+	// LocalObjBackendEnvVar stores blocks on local disk through the object
+	// storage block server, it's for testing.
+	LocalObjBackendEnvVar = "LOCAL_OBJ"
 )
 
 type APIServer interface {
@@ -31,35 +36,80 @@ func NewAPIServer(hasher *pfsserver.Hasher, router shard.Router) APIServer {
 	return newAPIServer(hasher, router)
 }
 
-func NewInternalAPIServer(hasher *pfsserver.Hasher, router shard.Router, driver drive.Driver) InternalAPIServer {
-	return newInternalAPIServer(hasher, router, driver)
+func NewInternalAPIServer(hasher *pfsserver.Hasher, router shard.Router, driver drive.Driver, urlAccess URLAccess) InternalAPIServer {
+	return newInternalAPIServer(hasher, router, driver, urlAccess)
 }
 
-func NewLocalBlockAPIServer(dir string) (pfsclient.BlockAPIServer, error) { // SJ: also bad naming
-	return newLocalBlockAPIServer(dir)
+// BlockAPIServerOptions configures a block server.
+type BlockAPIServerOptions struct {
+	// ChunkSizes controls how PutBlock splits data into blocks. Changing them
+	// doesn't affect the readability of existing blocks since blocks are
+	// addressed by hash.
+	ChunkSizes ChunkSizes
+	// Compression is used for new blocks. The compression of each block is
+	// stored with it so changing this doesn't affect the readability of
+	// existing blocks.
+	Compression pfsclient.Compression
+	// CacheSize is the number of bytes of recently read blocks which object
+	// storage block servers cache on local disk, 0 disables the cache.
+	// Cached blocks are stored decrypted so the cache is always disabled if
+	// object storage is encrypted.
+	CacheSize uint64
 }
 
-func NewObjBlockAPIServer(dir string, objClient obj.Client) (pfsclient.BlockAPIServer, error) { // SJ: Also bad naming
-	return newObjBlockAPIServer(dir, objClient)
+// DefaultBlockAPIServerOptions returns the options block servers use unless
+// they're configured otherwise.
+func DefaultBlockAPIServerOptions() BlockAPIServerOptions {
+	return BlockAPIServerOptions{
+		ChunkSizes:  defaultChunkSizes(),
+		Compression: pfsclient.Compression_COMPRESSION_NONE,
+		CacheSize:   defaultBlockCacheSize,
+	}
+}
+
+func NewLocalBlockAPIServer(dir string, options BlockAPIServerOptions) (pfsclient.BlockAPIServer, error) { // SJ: also bad naming
+	return newLocalBlockAPIServer(dir, options)
+}
+
+func NewObjBlockAPIServer(dir string, objClient obj.Client, options BlockAPIServerOptions) (pfsclient.BlockAPIServer, error) { // SJ: Also bad naming
+	return newObjBlockAPIServer(dir, objClient, options)
 }
 
 // NewBlockAPIServer creates a BlockAPIServer using the credentials it finds in
 // the environment
-func NewBlockAPIServer(dir string, backend string) (pfsclient.BlockAPIServer, error) {
+func NewBlockAPIServer(dir string, backend string, options BlockAPIServerOptions) (pfsclient.BlockAPIServer, error) {
 	switch backend {
 	case AmazonBackendEnvVar:
-		blockAPIServer, err := newAmazonBlockAPIServer(dir)
+		blockAPIServer, err := newAmazonBlockAPIServer(dir, options)
 		if err != nil {
 			return nil, err
 		}
 		return blockAPIServer, nil
 	case GoogleBackendEnvVar:
-		blockAPIServer, err := newGoogleBlockAPIServer(dir)
+		blockAPIServer, err := newGoogleBlockAPIServer(dir, options)
+		if err != nil {
+			return nil, err
+		}
+		return blockAPIServer, nil
+	case S3BackendEnvVar:
+		blockAPIServer, err := newS3BlockAPIServer(dir, options)
+		if err != nil {
+			return nil, err
+		}
+		return blockAPIServer, nil
+	case AzureBackendEnvVar:
+		blockAPIServer, err := newAzureBlockAPIServer(dir, options)
+		if err != nil {
+			return nil, err
+		}
+		return blockAPIServer, nil
+	case LocalObjBackendEnvVar:
+		blockAPIServer, err := newLocalObjBlockAPIServer(dir, options)
 		if err != nil {
 			return nil, err
 		}
 		return blockAPIServer, nil
 	default:
-		return NewLocalBlockAPIServer(dir)
+		return NewLocalBlockAPIServer(dir, options)
 	}
 }
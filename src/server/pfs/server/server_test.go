@@ -2,9 +2,17 @@ package server
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -15,6 +23,7 @@ import (
 
 	"go.pedge.io/proto/server"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 
 	pclient "github.com/pachyderm/pachyderm/src/client"
 	pfsclient "github.com/pachyderm/pachyderm/src/client/pfs"
@@ -136,10 +145,10 @@ func TestBranch(t *testing.T) {
 	var buffer bytes.Buffer
 	require.NoError(t, client.GetFile(repo, "master", "foo", 0, 0, "", nil, &buffer))
 	require.Equal(t, "foo\n", buffer.String())
-	branches, err := client.ListBranch(repo)
+	branches, err := client.ListBranchInfo(repo)
 	require.NoError(t, err)
-	require.Equal(t, commit1, branches[0].Commit)
-	require.Equal(t, "master", branches[0].Branch)
+	require.Equal(t, commit1, branches[0].Head)
+	require.Equal(t, "master", branches[0].Name)
 	commit2, err := client.StartCommit(repo, "", "master")
 	require.NoError(t, err)
 	_, err = client.PutFile(repo, "master", "foo", strings.NewReader("foo\n"))
@@ -152,10 +161,10 @@ func TestBranch(t *testing.T) {
 	buffer = bytes.Buffer{}
 	require.NoError(t, client.GetFile(repo, "master", "foo", 0, 0, "", nil, &buffer))
 	require.Equal(t, "foo\nfoo\n", buffer.String())
-	branches, err = client.ListBranch(repo)
+	branches, err = client.ListBranchInfo(repo)
 	require.NoError(t, err)
-	require.Equal(t, commit2, branches[0].Commit)
-	require.Equal(t, "master", branches[0].Branch)
+	require.Equal(t, commit2, branches[0].Head)
+	require.Equal(t, "master", branches[0].Name)
 
 	// restart the server and make sure data is still there
 	restartServer(server, t)
@@ -165,10 +174,893 @@ func TestBranch(t *testing.T) {
 	buffer = bytes.Buffer{}
 	require.NoError(t, client.GetFile(repo, "master", "foo", 0, 0, "", nil, &buffer))
 	require.Equal(t, "foo\nfoo\n", buffer.String())
-	branches, err = client.ListBranch(repo)
+	branches, err = client.ListBranchInfo(repo)
 	require.NoError(t, err)
-	require.Equal(t, commit2, branches[0].Commit)
-	require.Equal(t, "master", branches[0].Branch)
+	require.Equal(t, commit2, branches[0].Head)
+	require.Equal(t, "master", branches[0].Name)
+	// the deprecated ListBranch still returns the heads
+	heads, err := client.ListBranch(repo)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(heads))
+	require.Equal(t, commit2.ID, heads[0].Commit.ID)
+}
+
+func TestBranchManagement(t *testing.T) {
+	t.Parallel()
+	client, server := getClientAndServer(t)
+	repo := "test"
+	require.NoError(t, client.CreateRepo(repo))
+	commit1, err := client.StartCommit(repo, "", "master")
+	require.NoError(t, err)
+	_, err = client.PutFile(repo, "master", "foo", strings.NewReader("foo\n"))
+	require.NoError(t, err)
+	require.NoError(t, client.FinishCommit(repo, "master"))
+
+	// create a branch from an existing branch
+	require.NoError(t, client.CreateBranch(repo, "master", "dev"))
+	require.YesError(t, client.CreateBranch(repo, commit1.ID, "dev"))
+	require.YesError(t, client.CreateBranch(repo, "bogus", "other"))
+	require.YesError(t, client.CreateBranch(repo, "master", commit1.ID))
+	branches, err := client.ListBranchInfo(repo)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(branches))
+	require.Equal(t, "dev", branches[0].Name)
+	require.Equal(t, commit1.ID, branches[0].Head.ID)
+	require.Equal(t, "master", branches[1].Name)
+	require.Equal(t, commit1.ID, branches[1].Head.ID)
+
+	// commits started on the new branch continue from its head
+	commit2, err := client.StartCommit(repo, "", "dev")
+	require.NoError(t, err)
+	commitInfo, err := client.InspectCommit(repo, commit2.ID)
+	require.NoError(t, err)
+	require.Equal(t, commit1.ID, commitInfo.ParentCommit.ID)
+	_, err = client.PutFile(repo, "dev", "foo", strings.NewReader("bar\n"))
+	require.NoError(t, err)
+	require.NoError(t, client.FinishCommit(repo, "dev"))
+	var buffer bytes.Buffer
+	require.NoError(t, client.GetFile(repo, "dev", "foo", 0, 0, "", nil, &buffer))
+	require.Equal(t, "foo\nbar\n", buffer.String())
+	buffer = bytes.Buffer{}
+	require.NoError(t, client.GetFile(repo, "master", "foo", 0, 0, "", nil, &buffer))
+	require.Equal(t, "foo\n", buffer.String())
+
+	// set, rename and delete
+	require.NoError(t, client.SetBranch(repo, commit2.ID, "master"))
+	require.YesError(t, client.SetBranch(repo, commit2.ID, "bogus"))
+	require.NoError(t, client.RenameBranch(repo, "dev", "feature"))
+	require.YesError(t, client.RenameBranch(repo, "dev", "other"))
+	require.YesError(t, client.RenameBranch(repo, "feature", "master"))
+	require.NoError(t, client.CreateBranch(repo, commit1.ID, "old"))
+	require.NoError(t, client.DeleteBranch(repo, "old"))
+	require.YesError(t, client.DeleteBranch(repo, "old"))
+	_, err = client.InspectCommit(repo, "old")
+	require.YesError(t, err)
+	commitInfo, err = client.InspectCommit(repo, "feature")
+	require.NoError(t, err)
+	require.Equal(t, commit2.ID, commitInfo.Commit.ID)
+
+	// restart the server and make sure the branches are still there
+	restartServer(server, t)
+	branches, err = client.ListBranchInfo(repo)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(branches))
+	require.Equal(t, "feature", branches[0].Name)
+	require.Equal(t, commit2.ID, branches[0].Head.ID)
+	require.Equal(t, "master", branches[1].Name)
+	require.Equal(t, commit2.ID, branches[1].Head.ID)
+	buffer = bytes.Buffer{}
+	require.NoError(t, client.GetFile(repo, "master", "foo", 0, 0, "", nil, &buffer))
+	require.Equal(t, "foo\nbar\n", buffer.String())
+
+	// deleting a head moves its branches back to its parent
+	require.NoError(t, client.DeleteCommit(repo, commit2.ID))
+	branches, err = client.ListBranchInfo(repo)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(branches))
+	require.Equal(t, commit1.ID, branches[0].Head.ID)
+	require.Equal(t, commit1.ID, branches[1].Head.ID)
+	restartServer(server, t)
+	branches, err = client.ListBranchInfo(repo)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(branches))
+	require.Equal(t, commit1.ID, branches[0].Head.ID)
+	require.Equal(t, commit1.ID, branches[1].Head.ID)
+}
+
+func TestProvenance(t *testing.T) {
+	t.Parallel()
+	client, server := getClientAndServer(t)
+	require.NoError(t, client.CreateRepo("A"))
+	require.NoError(t, client.CreateRepo("B"))
+	require.NoError(t, client.CreateRepo("C"))
+	a, err := client.StartCommit("A", "", "master")
+	require.NoError(t, err)
+	require.NoError(t, client.FinishCommit("A", a.ID))
+	b, err := client.StartCommitWithProvenance("B", "", "", []*pfsclient.Commit{a})
+	require.NoError(t, err)
+	require.NoError(t, client.FinishCommit("B", b.ID))
+	c, err := client.StartCommitWithProvenance("C", "", "", []*pfsclient.Commit{b})
+	require.NoError(t, err)
+	require.NoError(t, client.FinishCommit("C", c.ID))
+	_, err = client.StartCommitWithProvenance("C", "", "", []*pfsclient.Commit{pclient.NewCommit("A", "bogus")})
+	require.YesError(t, err)
+
+	checkProvenance := func() {
+		commitInfo, err := client.InspectCommit("C", c.ID)
+		require.NoError(t, err)
+		require.Equal(t, []*pfsclient.Commit{a, b}, commitInfo.Provenance)
+		// provenance can be given as a branch
+		commitInfos, err := client.ListCommitByProvenance(nil, []*pfsclient.Commit{pclient.NewCommit("A", "master")}, pclient.CommitTypeNone, false, false)
+		require.NoError(t, err)
+		require.Equal(t, 2, len(commitInfos))
+		commitInfos, err = client.ListCommitByProvenance([]string{"C"}, []*pfsclient.Commit{a}, pclient.CommitTypeNone, false, false)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(commitInfos))
+		require.Equal(t, c, commitInfos[0].Commit)
+		commitInfos, err = client.ListCommitByProvenance(nil, []*pfsclient.Commit{a, b}, pclient.CommitTypeNone, false, false)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(commitInfos))
+		require.Equal(t, c, commitInfos[0].Commit)
+		commitInfos, err = client.ListCommitByProvenance(nil, []*pfsclient.Commit{c}, pclient.CommitTypeNone, false, false)
+		require.NoError(t, err)
+		require.Equal(t, 0, len(commitInfos))
+	}
+	checkProvenance()
+	restartServer(server, t)
+	checkProvenance()
+
+	// blocking waits for a derived commit
+	commitInfosCh := make(chan []*pfsclient.CommitInfo)
+	go func() {
+		commitInfos, err := client.ListCommitByProvenance(nil, []*pfsclient.Commit{c}, pclient.CommitTypeNone, true, false)
+		require.NoError(t, err)
+		commitInfosCh <- commitInfos
+	}()
+	d, err := client.StartCommitWithProvenance("B", "", "", []*pfsclient.Commit{c})
+	require.NoError(t, err)
+	commitInfos := <-commitInfosCh
+	require.Equal(t, 1, len(commitInfos))
+	require.Equal(t, d, commitInfos[0].Commit)
+}
+
+func TestCommitMetadata(t *testing.T) {
+	t.Parallel()
+	client, server := getClientAndServer(t)
+	repo := "test"
+	require.NoError(t, client.CreateRepo(repo))
+	commit1, err := client.StartCommitWithMetadata(repo, "", "master", "ingest from sensors",
+		map[string]string{"source": "sensors", "author": "alice"})
+	require.NoError(t, err)
+	commitInfo, err := client.InspectCommit(repo, commit1.ID)
+	require.NoError(t, err)
+	require.Equal(t, "ingest from sensors", commitInfo.Message)
+	require.Equal(t, map[string]string{"source": "sensors", "author": "alice"}, commitInfo.Labels)
+	require.NoError(t, client.FinishCommitWithMetadata(repo, commit1.ID, "ingest from sensors 1-4",
+		map[string]string{"author": "", "status": "clean"}))
+	commit2, err := client.StartCommitWithMetadata(repo, "", "master", "ingest from logs",
+		map[string]string{"source": "logs"})
+	require.NoError(t, err)
+	require.NoError(t, client.FinishCommit(repo, commit2.ID))
+
+	checkMetadata := func() {
+		commitInfo, err := client.InspectCommit(repo, commit1.ID)
+		require.NoError(t, err)
+		require.Equal(t, "ingest from sensors 1-4", commitInfo.Message)
+		require.Equal(t, map[string]string{"source": "sensors", "status": "clean"}, commitInfo.Labels)
+		commitInfos, err := client.ListCommitByLabels([]string{repo}, map[string]string{"source": "sensors"}, pclient.CommitTypeNone, false, false)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(commitInfos))
+		require.Equal(t, commit1, commitInfos[0].Commit)
+		commitInfos, err = client.ListCommitByLabels([]string{repo}, map[string]string{"source": "logs"}, pclient.CommitTypeNone, false, false)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(commitInfos))
+		require.Equal(t, commit2, commitInfos[0].Commit)
+		commitInfos, err = client.ListCommitByLabels([]string{repo}, map[string]string{"source": "sensors", "status": "dirty"}, pclient.CommitTypeNone, false, false)
+		require.NoError(t, err)
+		require.Equal(t, 0, len(commitInfos))
+	}
+	checkMetadata()
+	restartServer(server, t)
+	checkMetadata()
+}
+
+func TestQuota(t *testing.T) {
+	t.Parallel()
+	client, server := getClientAndServer(t)
+	repo := "test"
+	require.NoError(t, client.CreateRepoWithQuota(repo, &pfsclient.Quota{MaxFileSizeBytes: 10}))
+	repoInfo, err := client.InspectRepo(repo)
+	require.NoError(t, err)
+	require.Equal(t, uint64(10), repoInfo.Quota.MaxFileSizeBytes)
+
+	commit1, err := client.StartCommit(repo, "", "")
+	require.NoError(t, err)
+	_, err = client.PutFile(repo, commit1.ID, "foo", strings.NewReader("foofoofoofoo\n"))
+	require.Equal(t, codes.ResourceExhausted, grpc.Code(err))
+	_, err = client.PutFile(repo, commit1.ID, "foo", strings.NewReader("foofo\n"))
+	require.NoError(t, err)
+	_, err = client.PutFile(repo, commit1.ID, "foo", strings.NewReader("foofo\n"))
+	require.Equal(t, codes.ResourceExhausted, grpc.Code(err))
+	_, err = client.PutFile(repo, commit1.ID, "foo", strings.NewReader("foo\n"))
+	require.NoError(t, err)
+	require.NoError(t, client.FinishCommit(repo, commit1.ID))
+	// the limit applies to the file's size, not just what's written by a commit
+	commit2, err := client.StartCommit(repo, commit1.ID, "")
+	require.NoError(t, err)
+	_, err = client.PutFile(repo, commit2.ID, "foo", strings.NewReader("foo\n"))
+	require.Equal(t, codes.ResourceExhausted, grpc.Code(err))
+	require.NoError(t, client.FinishCommit(repo, commit2.ID))
+
+	// files per commit are checked when the commit is finished if they're
+	// spread across shards
+	require.NoError(t, client.SetQuota(repo, &pfsclient.Quota{MaxFilesPerCommit: 2}))
+	commit3, err := client.StartCommit(repo, commit2.ID, "")
+	require.NoError(t, err)
+	var quotaErr error
+	for _, file := range []string{"a", "b", "c"} {
+		if _, err := client.PutFile(repo, commit3.ID, file, strings.NewReader("foo\n")); err != nil {
+			require.Equal(t, codes.ResourceExhausted, grpc.Code(err))
+			quotaErr = err
+		}
+	}
+	if quotaErr == nil {
+		err = client.FinishCommit(repo, commit3.ID)
+		require.Equal(t, codes.ResourceExhausted, grpc.Code(err))
+	}
+	// commits over quota can still be cancelled
+	require.NoError(t, client.CancelCommit(repo, commit3.ID))
+
+	require.NoError(t, client.SetQuota(repo, &pfsclient.Quota{MaxBytes: 20}))
+	commit4, err := client.StartCommit(repo, commit2.ID, "")
+	require.NoError(t, err)
+	quotaErr = nil
+	for _, file := range []string{"a", "b", "c"} {
+		if _, err := client.PutFile(repo, commit4.ID, file, strings.NewReader("foofoofoo\n")); err != nil {
+			require.Equal(t, codes.ResourceExhausted, grpc.Code(err))
+			quotaErr = err
+		}
+	}
+	if quotaErr == nil {
+		err = client.FinishCommit(repo, commit4.ID)
+		require.Equal(t, codes.ResourceExhausted, grpc.Code(err))
+	}
+	require.NoError(t, client.CancelCommit(repo, commit4.ID))
+
+	// restart the server and make sure the quota is still there
+	restartServer(server, t)
+	repoInfo, err = client.InspectRepo(repo)
+	require.NoError(t, err)
+	require.Equal(t, &pfsclient.Quota{MaxBytes: 20}, repoInfo.Quota)
+	commit5, err := client.StartCommit(repo, commit2.ID, "")
+	require.NoError(t, err)
+	_, err = client.PutFile(repo, commit5.ID, "bar", strings.NewReader("foofoofoofoofoofoofoo\n"))
+	require.Equal(t, codes.ResourceExhausted, grpc.Code(err))
+	require.NoError(t, client.CancelCommit(repo, commit5.ID))
+
+	// commits which don't add data can still finish once a repo is over
+	// its quota
+	require.NoError(t, client.SetQuota(repo, &pfsclient.Quota{MaxBytes: 1}))
+	commit6, err := client.StartCommit(repo, commit2.ID, "")
+	require.NoError(t, err)
+	require.NoError(t, client.DeleteFile(repo, commit6.ID, "foo"))
+	require.NoError(t, client.FinishCommit(repo, commit6.ID))
+	commit7, err := client.StartCommit(repo, commit6.ID, "")
+	require.NoError(t, err)
+	require.NoError(t, client.FinishCommit(repo, commit7.ID))
+	commit8, err := client.StartCommit(repo, commit7.ID, "")
+	require.NoError(t, err)
+	_, err = client.PutFile(repo, commit8.ID, "foo", strings.NewReader("foo\n"))
+	require.Equal(t, codes.ResourceExhausted, grpc.Code(err))
+}
+
+func TestPutFileOverwrite(t *testing.T) {
+	t.Parallel()
+	client, server := getClientAndServer(t)
+	repo := "test"
+	require.NoError(t, client.CreateRepo(repo))
+	commit1, err := client.StartCommit(repo, "", "")
+	require.NoError(t, err)
+	_, err = client.PutFile(repo, commit1.ID, "foo", strings.NewReader("foo\n"))
+	require.NoError(t, err)
+	_, err = client.PutFile(repo, commit1.ID, "bar", strings.NewReader("bar\n"))
+	require.NoError(t, err)
+	require.NoError(t, client.FinishCommit(repo, commit1.ID))
+
+	commit2, err := client.StartCommit(repo, commit1.ID, "")
+	require.NoError(t, err)
+	// overwriting drops the parent's content
+	_, err = client.PutFileOverwrite(repo, commit2.ID, "foo", strings.NewReader("foo2\n"))
+	require.NoError(t, err)
+	// and the content written in this commit, under any handle
+	writer, err := client.PutFileWriter(repo, commit2.ID, "bar", "handle")
+	require.NoError(t, err)
+	_, err = writer.Write([]byte("bar2\n"))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	_, err = client.PutFileOverwrite(repo, commit2.ID, "bar", strings.NewReader("bar3\n"))
+	require.NoError(t, err)
+	_, err = client.PutFile(repo, commit2.ID, "bar", strings.NewReader("bar4\n"))
+	require.NoError(t, err)
+	require.NoError(t, client.FinishCommit(repo, commit2.ID))
+
+	check := func() {
+		var buffer bytes.Buffer
+		require.NoError(t, client.GetFile(repo, commit2.ID, "foo", 0, 0, "", nil, &buffer))
+		require.Equal(t, "foo2\n", buffer.String())
+		buffer.Reset()
+		require.NoError(t, client.GetFile(repo, commit2.ID, "bar", 0, 0, "", nil, &buffer))
+		require.Equal(t, "bar3\nbar4\n", buffer.String())
+		buffer.Reset()
+		require.NoError(t, client.GetFile(repo, commit1.ID, "foo", 0, 0, "", nil, &buffer))
+		require.Equal(t, "foo\n", buffer.String())
+		fileInfos, err := client.ListFile(repo, commit2.ID, "", "", nil, false)
+		require.NoError(t, err)
+		require.Equal(t, 2, len(fileInfos))
+	}
+	check()
+	restartServer(server, t)
+	check()
+
+	// children see the overwritten file
+	commit3, err := client.StartCommit(repo, commit2.ID, "")
+	require.NoError(t, err)
+	_, err = client.PutFile(repo, commit3.ID, "foo", strings.NewReader("foo3\n"))
+	require.NoError(t, err)
+	require.NoError(t, client.FinishCommit(repo, commit3.ID))
+	var buffer bytes.Buffer
+	require.NoError(t, client.GetFile(repo, commit3.ID, "foo", 0, 0, "", nil, &buffer))
+	require.Equal(t, "foo2\nfoo3\n", buffer.String())
+}
+
+func TestPutFileOverwriteSize(t *testing.T) {
+	t.Parallel()
+	client, _ := getClientAndServer(t)
+	repo := "test"
+	// the quota only fits the file once, overwrites mustn't count the
+	// content they drop
+	require.NoError(t, client.CreateRepoWithQuota(repo, &pfsclient.Quota{MaxBytes: 8}))
+	commit, err := client.StartCommit(repo, "", "")
+	require.NoError(t, err)
+	_, err = client.PutFile(repo, commit.ID, "foo", strings.NewReader("foo1\n"))
+	require.NoError(t, err)
+	_, err = client.PutFileOverwrite(repo, commit.ID, "foo", strings.NewReader("foo2\n"))
+	require.NoError(t, err)
+	_, err = client.PutFileOverwrite(repo, commit.ID, "foo", strings.NewReader("foo3\n"))
+	require.NoError(t, err)
+	require.NoError(t, client.FinishCommit(repo, commit.ID))
+	commitInfo, err := client.InspectCommit(repo, commit.ID)
+	require.NoError(t, err)
+	require.Equal(t, uint64(5), commitInfo.SizeBytes)
+	repoInfo, err := client.InspectRepo(repo)
+	require.NoError(t, err)
+	require.Equal(t, uint64(5), repoInfo.SizeBytes)
+}
+
+func TestMergeOverwriteSize(t *testing.T) {
+	t.Parallel()
+	client, _ := getClientAndServer(t)
+	repo := "test"
+	require.NoError(t, client.CreateRepoWithQuota(repo, &pfsclient.Quota{MaxBytes: 100}))
+	base, err := client.StartCommit(repo, "", "master")
+	require.NoError(t, err)
+	_, err = client.PutFile(repo, base.ID, "a", strings.NewReader("a\n"))
+	require.NoError(t, err)
+	require.NoError(t, client.FinishCommit(repo, base.ID))
+	theirs, err := client.StartCommit(repo, base.ID, "feature")
+	require.NoError(t, err)
+	_, err = client.PutFile(repo, theirs.ID, "a", strings.NewReader("theirs\n"))
+	require.NoError(t, err)
+	require.NoError(t, client.FinishCommit(repo, theirs.ID))
+	// the merged file, a\ntheirs\n, counts towards the merge commit's size
+	// so dropping it with an overwrite doesn't wrap the size around
+	merge, err := client.StartMergeCommit(repo, "", "master", "feature", nil)
+	require.NoError(t, err)
+	commitInfo, err := client.InspectCommit(repo, merge.ID)
+	require.NoError(t, err)
+	require.Equal(t, uint64(9), commitInfo.SizeBytes)
+	_, err = client.PutFileOverwrite(repo, merge.ID, "a", strings.NewReader("x\n"))
+	require.NoError(t, err)
+	_, err = client.PutFile(repo, merge.ID, "b", strings.NewReader("b\n"))
+	require.NoError(t, err)
+	require.NoError(t, client.FinishCommit(repo, merge.ID))
+	commitInfo, err = client.InspectCommit(repo, merge.ID)
+	require.NoError(t, err)
+	require.Equal(t, uint64(4), commitInfo.SizeBytes)
+	repoInfo, err := client.InspectRepo(repo)
+	require.NoError(t, err)
+	require.Equal(t, uint64(13), repoInfo.SizeBytes)
+}
+
+func TestGlobFile(t *testing.T) {
+	t.Parallel()
+	client, server := getClientAndServer(t)
+	repo := "test"
+	require.NoError(t, client.CreateRepo(repo))
+	commit, err := client.StartCommit(repo, "", "")
+	require.NoError(t, err)
+	for _, path := range []string{
+		"2016/01/day=01/a.parquet",
+		"2016/01/day=01/b.csv",
+		"2016/01/day=02/c.parquet",
+		"2016/02/day=01/d.parquet",
+		"2016/02/other/e.parquet",
+		"2017/01/day=01/f.parquet",
+		"g.parquet",
+	} {
+		_, err = client.PutFile(repo, commit.ID, path, strings.NewReader(path))
+		require.NoError(t, err)
+	}
+	require.NoError(t, client.FinishCommit(repo, commit.ID))
+
+	paths := func(fileInfos []*pfsclient.FileInfo) []string {
+		var result []string
+		for _, fileInfo := range fileInfos {
+			result = append(result, fileInfo.File.Path)
+		}
+		sort.Strings(result)
+		return result
+	}
+	check := func() {
+		for pattern, expected := range map[string][]string{
+			"2016/*/day=*/*.parquet":   {"2016/01/day=01/a.parquet", "2016/01/day=02/c.parquet", "2016/02/day=01/d.parquet"},
+			"**/*.parquet":             {"2016/01/day=01/a.parquet", "2016/01/day=02/c.parquet", "2016/02/day=01/d.parquet", "2016/02/other/e.parquet", "2017/01/day=01/f.parquet", "g.parquet"},
+			"2016/**/?.csv":            {"2016/01/day=01/b.csv"},
+			"201[67]/01/day=01":        {"2016/01/day=01", "2017/01/day=01"},
+			"2016/0[^1]/*":             {"2016/02/day=01", "2016/02/other"},
+			"*":                        {"2016", "2017", "g.parquet"},
+			"2016/01/day=01/a.parquet": {"2016/01/day=01/a.parquet"},
+			"nothing/*":                nil,
+		} {
+			fileInfos, err := client.GlobFile(repo, commit.ID, pattern, "", nil)
+			require.NoError(t, err)
+			require.Equal(t, expected, paths(fileInfos), pattern)
+			if pfsserver.IsGlob(pattern) {
+				fileInfos, err = client.ListFile(repo, commit.ID, pattern, "", nil, false)
+				require.NoError(t, err)
+				require.Equal(t, expected, paths(fileInfos), pattern)
+			}
+		}
+		fileInfo, err := client.InspectFile(repo, commit.ID, "2016/*/day=02/*", "", nil)
+		require.NoError(t, err)
+		require.Equal(t, "2016/01/day=02/c.parquet", fileInfo.File.Path)
+		require.Equal(t, uint64(len("2016/01/day=02/c.parquet")), fileInfo.SizeBytes)
+		_, err = client.InspectFile(repo, commit.ID, "2016/*/day=01/*", "", nil)
+		require.YesError(t, err)
+		_, err = client.InspectFile(repo, commit.ID, "2018/*", "", nil)
+		require.YesError(t, err)
+	}
+	check()
+	restartServer(server, t)
+	check()
+}
+
+func TestListFileGlobMetacharacters(t *testing.T) {
+	t.Parallel()
+	client, _ := getClientAndServer(t)
+	repo := "test"
+	require.NoError(t, client.CreateRepo(repo))
+	commit, err := client.StartCommit(repo, "", "")
+	require.NoError(t, err)
+	for _, path := range []string{"a[1]", "a1", `b\c`, "d[1]/0", "d[1]/1", "d[1]/2", "d[1]/3", "d1/0"} {
+		_, err = client.PutFile(repo, commit.ID, path, strings.NewReader(path))
+		require.NoError(t, err)
+	}
+	require.NoError(t, client.FinishCommit(repo, commit.ID))
+
+	paths := func(fileInfos []*pfsclient.FileInfo) []string {
+		var result []string
+		for _, fileInfo := range fileInfos {
+			result = append(result, fileInfo.File.Path)
+		}
+		return result
+	}
+	// paths which exist are looked up exactly
+	fileInfo, err := client.InspectFile(repo, commit.ID, "a[1]", "", nil)
+	require.NoError(t, err)
+	require.Equal(t, "a[1]", fileInfo.File.Path)
+	fileInfo, err = client.InspectFile(repo, commit.ID, `b\c`, "", nil)
+	require.NoError(t, err)
+	require.Equal(t, `b\c`, fileInfo.File.Path)
+	fileInfos, err := client.ListFile(repo, commit.ID, "a[1]", "", nil, false)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a[1]"}, paths(fileInfos))
+	fileInfos, err = client.ListFile(repo, commit.ID, "d[1]", "", nil, false)
+	require.NoError(t, err)
+	require.Equal(t, []string{"d[1]/0", "d[1]/1", "d[1]/2", "d[1]/3"}, paths(fileInfos))
+	// and the rest are globs
+	fileInfos, err = client.ListFile(repo, commit.ID, "a[12]", "", nil, false)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a1"}, paths(fileInfos))
+	fileInfo, err = client.InspectFile(repo, commit.ID, "d[12]", "", nil)
+	require.NoError(t, err)
+	require.Equal(t, "d1", fileInfo.File.Path)
+}
+
+func TestGlobFileDirectoryAcrossShards(t *testing.T) {
+	t.Parallel()
+	client, _ := getClientAndServer(t)
+	repo := "test"
+	require.NoError(t, client.CreateRepo(repo))
+	commit1, err := client.StartCommit(repo, "", "")
+	require.NoError(t, err)
+	for i := 0; i < 20; i++ {
+		_, err = client.PutFile(repo, commit1.ID, fmt.Sprintf("dir/%d", i), strings.NewReader("foo"))
+		require.NoError(t, err)
+	}
+	require.NoError(t, client.FinishCommit(repo, commit1.ID))
+	// only the shard with the new file sees the directory modified
+	commit2, err := client.StartCommit(repo, commit1.ID, "")
+	require.NoError(t, err)
+	_, err = client.PutFile(repo, commit2.ID, "dir/new", strings.NewReader("foo"))
+	require.NoError(t, err)
+	require.NoError(t, client.FinishCommit(repo, commit2.ID))
+
+	fileInfos, err := client.GlobFile(repo, commit2.ID, "di?", "", nil)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(fileInfos))
+	require.Equal(t, commit2.ID, fileInfos[0].CommitModified.ID)
+	require.Equal(t, 0, len(fileInfos[0].Children))
+}
+
+func TestListFileStream(t *testing.T) {
+	t.Parallel()
+	client, server := getClientAndServer(t)
+	repo := "test"
+	require.NoError(t, client.CreateRepo(repo))
+	commit, err := client.StartCommit(repo, "", "")
+	require.NoError(t, err)
+	var expected []string
+	for i := 0; i < 50; i++ {
+		path := fmt.Sprintf("dir/%02d", i)
+		_, err = client.PutFile(repo, commit.ID, path, strings.NewReader(path))
+		require.NoError(t, err)
+		expected = append(expected, path)
+	}
+	// a subdirectory which is in more than one shard
+	for i := 0; i < 10; i++ {
+		_, err = client.PutFile(repo, commit.ID, fmt.Sprintf("dir/sub/%d", i), strings.NewReader("foo"))
+		require.NoError(t, err)
+	}
+	expected = append(expected, "dir/sub")
+	require.NoError(t, client.FinishCommit(repo, commit.ID))
+
+	listFile := func(path string, pageToken string, limit uint64) []string {
+		var result []string
+		require.NoError(t, client.ListFileStream(repo, commit.ID, path, "", nil, false, pageToken, limit, func(fileInfo *pfsclient.FileInfo) error {
+			result = append(result, fileInfo.File.Path)
+			return nil
+		}))
+		return result
+	}
+	check := func() {
+		require.Equal(t, expected, listFile("dir", "", 0))
+		var paged []string
+		pageToken := ""
+		for {
+			page := listFile("dir", pageToken, 7)
+			require.True(t, len(page) <= 7)
+			if len(page) == 0 {
+				break
+			}
+			paged = append(paged, page...)
+			pageToken = page[len(page)-1]
+		}
+		require.Equal(t, expected, paged)
+		require.Equal(t, []string{"dir/10", "dir/11", "dir/12"}, listFile("dir/1*", "dir/09", 3))
+		require.Equal(t, 0, len(listFile("dir", "dir/sub", 0)))
+
+		fileInfos, err := client.ListFile(repo, commit.ID, "dir", "", nil, false)
+		require.NoError(t, err)
+		require.Equal(t, len(expected), len(fileInfos))
+		for i, fileInfo := range fileInfos {
+			require.Equal(t, expected[i], fileInfo.File.Path)
+		}
+	}
+	check()
+	restartServer(server, t)
+	check()
+}
+
+func TestCopyFile(t *testing.T) {
+	t.Parallel()
+	client, server := getClientAndServer(t)
+	require.NoError(t, client.CreateRepo("output"))
+	require.NoError(t, client.CreateRepo("production"))
+	src, err := client.StartCommit("output", "", "")
+	require.NoError(t, err)
+	for _, path := range []string{"model/weights", "model/config/a", "model/config/b", "other"} {
+		_, err = client.PutFile("output", src.ID, path, strings.NewReader(path+"\n"))
+		require.NoError(t, err)
+	}
+	require.NoError(t, client.MakeDirectory("output", src.ID, "model/empty"))
+	require.NoError(t, client.FinishCommit("output", src.ID))
+
+	dst, err := client.StartCommit("production", "", "")
+	require.NoError(t, err)
+	require.NoError(t, client.CopyFile("output", src.ID, "model", "production", dst.ID, "v1/model", false))
+	require.NoError(t, client.CopyFile("output", src.ID, "other", "production", dst.ID, "other", false))
+	// copies append unless they overwrite
+	require.NoError(t, client.CopyFile("output", src.ID, "other", "production", dst.ID, "other", false))
+	_, err = client.PutFile("production", dst.ID, "overwritten", strings.NewReader("foo\n"))
+	require.NoError(t, err)
+	require.NoError(t, client.CopyFile("output", src.ID, "model/weights", "production", dst.ID, "overwritten", true))
+	require.YesError(t, client.CopyFile("output", src.ID, "missing", "production", dst.ID, "missing", false))
+	// the destination must be writeable
+	require.YesError(t, client.CopyFile("output", src.ID, "other", "output", src.ID, "copy", false))
+	require.NoError(t, client.FinishCommit("production", dst.ID))
+
+	check := func() {
+		for path, expected := range map[string]string{
+			"v1/model/weights":  "model/weights\n",
+			"v1/model/config/a": "model/config/a\n",
+			"v1/model/config/b": "model/config/b\n",
+			"other":             "other\nother\n",
+			"overwritten":       "model/weights\n",
+		} {
+			var buffer bytes.Buffer
+			require.NoError(t, client.GetFile("production", dst.ID, path, 0, 0, "", nil, &buffer))
+			require.Equal(t, expected, buffer.String())
+		}
+		fileInfos, err := client.ListFile("production", dst.ID, "v1/model", "", nil, false)
+		require.NoError(t, err)
+		require.Equal(t, 3, len(fileInfos))
+		fileInfo, err := client.InspectFile("production", dst.ID, "v1/model/empty", "", nil)
+		require.NoError(t, err)
+		require.Equal(t, pfsclient.FileType_FILE_TYPE_DIR, fileInfo.FileType)
+		// the source is untouched
+		var buffer bytes.Buffer
+		require.NoError(t, client.GetFile("output", src.ID, "other", 0, 0, "", nil, &buffer))
+		require.Equal(t, "other\n", buffer.String())
+	}
+	check()
+	restartServer(server, t)
+	check()
+}
+
+func TestCopyFileTree(t *testing.T) {
+	t.Parallel()
+	client, _ := getClientAndServer(t)
+	repo := "test"
+	require.NoError(t, client.CreateRepo(repo))
+	src, err := client.StartCommit(repo, "", "")
+	require.NoError(t, err)
+	// more files and directories than are copied at once
+	n := 4 * copyFileParallelism
+	for i := 0; i < n; i++ {
+		path := fmt.Sprintf("src/%d/%d", i%(n/2), i)
+		_, err = client.PutFile(repo, src.ID, path, strings.NewReader(path))
+		require.NoError(t, err)
+	}
+	require.NoError(t, client.FinishCommit(repo, src.ID))
+	dst, err := client.StartCommit(repo, src.ID, "")
+	require.NoError(t, err)
+	require.NoError(t, client.CopyFile(repo, src.ID, "src", repo, dst.ID, "dst", false))
+	require.NoError(t, client.FinishCommit(repo, dst.ID))
+	for i := 0; i < n; i++ {
+		var buffer bytes.Buffer
+		require.NoError(t, client.GetFile(repo, dst.ID, fmt.Sprintf("dst/%d/%d", i%(n/2), i), 0, 0, "", nil, &buffer))
+		require.Equal(t, fmt.Sprintf("src/%d/%d", i%(n/2), i), buffer.String())
+	}
+	fileInfos, err := client.ListFile(repo, dst.ID, "dst", "", nil, false)
+	require.NoError(t, err)
+	require.Equal(t, n/2, len(fileInfos))
+}
+
+func TestCopyFileGlobMetacharacters(t *testing.T) {
+	t.Parallel()
+	client, _ := getClientAndServer(t)
+	repo := "test"
+	require.NoError(t, client.CreateRepo(repo))
+	src, err := client.StartCommit(repo, "", "")
+	require.NoError(t, err)
+	// data1 matches the pattern data[1] but isn't under data[1]
+	for _, path := range []string{"data[1]/a", "data[1]/sub/b", "data1/c", `data\*/d`} {
+		_, err = client.PutFile(repo, src.ID, path, strings.NewReader(path))
+		require.NoError(t, err)
+	}
+	require.NoError(t, client.FinishCommit(repo, src.ID))
+	dst, err := client.StartCommit(repo, "", "")
+	require.NoError(t, err)
+	require.NoError(t, client.CopyFile(repo, src.ID, "data[1]", repo, dst.ID, "out/copy", false))
+	require.NoError(t, client.CopyFile(repo, src.ID, `data\*`, repo, dst.ID, "out/star", false))
+	require.NoError(t, client.FinishCommit(repo, dst.ID))
+	var paths []string
+	for _, dir := range []string{"out", "out/copy", "out/copy/sub", "out/star"} {
+		fileInfos, err := client.ListFile(repo, dst.ID, dir, "", nil, false)
+		require.NoError(t, err)
+		for _, fileInfo := range fileInfos {
+			paths = append(paths, fileInfo.File.Path)
+		}
+	}
+	require.Equal(t, []string{"out/copy", "out/star", "out/copy/a", "out/copy/sub", "out/copy/sub/b", "out/star/d"}, paths)
+	var buffer bytes.Buffer
+	require.NoError(t, client.GetFile(repo, dst.ID, "out/copy/sub/b", 0, 0, "", nil, &buffer))
+	require.Equal(t, "data[1]/sub/b", buffer.String())
+}
+
+func TestFileChecksums(t *testing.T) {
+	t.Parallel()
+	client, server := getClientAndServer(t)
+	repo := "test"
+	require.NoError(t, client.CreateRepo(repo))
+	commit1, err := client.StartCommit(repo, "", "")
+	require.NoError(t, err)
+	_, err = client.PutFile(repo, commit1.ID, "foo", strings.NewReader("foo\n"))
+	require.NoError(t, err)
+	_, err = client.PutFile(repo, commit1.ID, "bar", strings.NewReader("bar\n"))
+	require.NoError(t, err)
+	require.NoError(t, client.MakeDirectory(repo, commit1.ID, "dir"))
+	require.NoError(t, client.FinishCommit(repo, commit1.ID))
+	commit2, err := client.StartCommit(repo, commit1.ID, "")
+	require.NoError(t, err)
+	_, err = client.PutFile(repo, commit2.ID, "bar", strings.NewReader("bar\n"))
+	require.NoError(t, err)
+	require.NoError(t, client.CopyFile(repo, commit1.ID, "foo", repo, commit2.ID, "foo2", false))
+	require.NoError(t, client.FinishCommit(repo, commit2.ID))
+
+	sha256Hex := func(content string) string {
+		sum := sha256.Sum256([]byte(content))
+		return hex.EncodeToString(sum[:])
+	}
+	check := func() {
+		foo1, err := client.InspectFile(repo, commit1.ID, "foo", "", nil)
+		require.NoError(t, err)
+		require.True(t, foo1.Digest != "")
+		require.Equal(t, "", foo1.Sha256)
+		// unchanged files keep their digest, copies have the same digest
+		foo2, err := client.InspectFile(repo, commit2.ID, "foo", "", nil)
+		require.NoError(t, err)
+		require.Equal(t, foo1.Digest, foo2.Digest)
+		copied, err := client.InspectFile(repo, commit2.ID, "foo2", "", nil)
+		require.NoError(t, err)
+		require.Equal(t, foo1.Digest, copied.Digest)
+		// changed files don't
+		bar1, err := client.InspectFile(repo, commit1.ID, "bar", "", nil)
+		require.NoError(t, err)
+		bar2, err := client.InspectFile(repo, commit2.ID, "bar", "", nil)
+		require.NoError(t, err)
+		require.True(t, bar1.Digest != bar2.Digest)
+		require.True(t, foo1.Digest != bar1.Digest)
+		fileInfos, err := client.ListFile(repo, commit1.ID, "", "", nil, false)
+		require.NoError(t, err)
+		for _, fileInfo := range fileInfos {
+			if fileInfo.File.Path == "foo" {
+				require.Equal(t, foo1.Digest, fileInfo.Digest)
+			}
+		}
+
+		for i := 0; i < 2; i++ {
+			// the second time it's cached
+			foo, err := client.InspectFileSHA256(repo, commit1.ID, "foo", "", nil)
+			require.NoError(t, err)
+			require.Equal(t, sha256Hex("foo\n"), foo.Sha256)
+		}
+		bar, err := client.InspectFileSHA256(repo, commit2.ID, "bar", "", nil)
+		require.NoError(t, err)
+		require.Equal(t, sha256Hex("bar\nbar\n"), bar.Sha256)
+		bar, err = client.InspectFileSHA256(repo, commit2.ID, "b?r", "", nil)
+		require.NoError(t, err)
+		require.Equal(t, sha256Hex("bar\nbar\n"), bar.Sha256)
+		dir, err := client.InspectFileSHA256(repo, commit1.ID, "dir", "", nil)
+		require.NoError(t, err)
+		require.Equal(t, "", dir.Digest)
+		require.Equal(t, "", dir.Sha256)
+		// partial reads don't have a digest
+		bar, err = client.InspectFile(repo, commit2.ID, "bar", commit1.ID, nil)
+		require.NoError(t, err)
+		require.Equal(t, "", bar.Digest)
+		bar, err = client.InspectFile(repo, commit2.ID, "bar", "", &pfsclient.Shard{BlockModulus: 2})
+		require.NoError(t, err)
+		require.Equal(t, "", bar.Digest)
+		bar, err = client.InspectFileSHA256(repo, commit2.ID, "bar", commit1.ID, nil)
+		require.NoError(t, err)
+		require.Equal(t, sha256Hex("bar\n"), bar.Sha256)
+
+		var buffer bytes.Buffer
+		require.NoError(t, client.GetFileVerified(repo, commit2.ID, "bar", &buffer))
+		require.Equal(t, "bar\nbar\n", buffer.String())
+	}
+	check()
+	restartServer(server, t)
+	check()
+
+	// reads of open commits see the handles in the same order every time,
+	// which is the order they have once the commit is finished
+	commit3, err := client.StartCommit(repo, commit2.ID, "")
+	require.NoError(t, err)
+	for _, handle := range []string{"c", "a", "d", "b"} {
+		writer, err := client.PutFileWriter(repo, commit3.ID, "handles", handle)
+		require.NoError(t, err)
+		_, err = writer.Write([]byte(handle + "\n"))
+		require.NoError(t, err)
+		require.NoError(t, writer.Close())
+	}
+	open, err := client.InspectFileUnsafe(repo, commit3.ID, "handles", "", nil)
+	require.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		fileInfo, err := client.InspectFileUnsafe(repo, commit3.ID, "handles", "", nil)
+		require.NoError(t, err)
+		require.Equal(t, open.Digest, fileInfo.Digest)
+	}
+	require.NoError(t, client.FinishCommit(repo, commit3.ID))
+	finished, err := client.InspectFile(repo, commit3.ID, "handles", "", nil)
+	require.NoError(t, err)
+	require.Equal(t, open.Digest, finished.Digest)
+	var buffer bytes.Buffer
+	require.NoError(t, client.GetFile(repo, commit3.ID, "handles", 0, 0, "", nil, &buffer))
+	require.Equal(t, "a\nb\nc\nd\n", buffer.String())
+}
+
+func TestPutFileURL(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "pachyderm-test-put-file-url")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	// big enough to be fetched in several chunks
+	data := make([]byte, 2*urlChunkSize+1)
+	_, err = rand.Read(data)
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "data"), data, 0666))
+	httpServer := httptest.NewServer(http.FileServer(http.Dir(dir)))
+	defer httpServer.Close()
+	otherDir, err := ioutil.TempDir("", "pachyderm-test-put-file-url")
+	require.NoError(t, err)
+	defer os.RemoveAll(otherDir)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(otherDir, "secret"), []byte("secret"), 0666))
+	require.NoError(t, os.Symlink(filepath.Join(otherDir, "secret"), filepath.Join(dir, "link")))
+	// the test server is on a loopback address so it has to be listed by name
+	client, _ := getClientAndServerWithURLAccess(t, uniqueString("/tmp/pach_test/run"), URLAccess{
+		LocalDirs: []string{dir},
+		HTTPHosts: []string{"127.0.0.1"},
+	})
+	repo := "test"
+	require.NoError(t, client.CreateRepo(repo))
+
+	commit, err := client.StartCommit(repo, "", "")
+	require.NoError(t, err)
+	require.NoError(t, client.PutFileURL(repo, commit.ID, "local", filepath.Join(dir, "data"), false))
+	require.NoError(t, client.PutFileURL(repo, commit.ID, "file", "file://"+filepath.Join(dir, "data"), false))
+	require.NoError(t, client.PutFileURL(repo, commit.ID, "http", httpServer.URL+"/data", false))
+	_, err = client.PutFile(repo, commit.ID, "overwritten", strings.NewReader("foo"))
+	require.NoError(t, err)
+	require.NoError(t, client.PutFileURL(repo, commit.ID, "overwritten", httpServer.URL+"/data", true))
+	require.YesError(t, client.PutFileURL(repo, commit.ID, "missing", httpServer.URL+"/missing", false))
+	require.YesError(t, client.PutFileURL(repo, commit.ID, "relative", "data", false))
+	require.YesError(t, client.PutFileURL(repo, commit.ID, "ftp", "ftp://example.com/data", false))
+	// only the allowed directories can be read, even through symlinks
+	err = client.PutFileURL(repo, commit.ID, "secret", filepath.Join(otherDir, "secret"), false)
+	require.Equal(t, codes.PermissionDenied, grpc.Code(err))
+	err = client.PutFileURL(repo, commit.ID, "secret", filepath.Join(dir, "..", filepath.Base(otherDir), "secret"), false)
+	require.Equal(t, codes.PermissionDenied, grpc.Code(err))
+	err = client.PutFileURL(repo, commit.ID, "secret", filepath.Join(dir, "link"), false)
+	require.Equal(t, codes.PermissionDenied, grpc.Code(err))
+	// object storage is read with pachd's credentials so it's off by default
+	err = client.PutFileURL(repo, commit.ID, "s3", "s3://bucket/data", false)
+	require.Equal(t, codes.PermissionDenied, grpc.Code(err))
+	// only the allowed hosts can be read and loopback addresses have to be
+	// allowed by name
+	localhostURL := strings.Replace(httpServer.URL, "127.0.0.1", "localhost", 1)
+	err = client.PutFileURL(repo, commit.ID, "localhost", localhostURL+"/data", false)
+	require.Equal(t, codes.PermissionDenied, grpc.Code(err))
+	_, err = URLAccess{HTTPHosts: []string{"*"}}.reader(localhostURL + "/data")
+	require.YesError(t, err)
+	require.True(t, strings.Contains(err.Error(), "may not read from"), err.Error())
+	// nothing can be read by default
+	for _, rawurl := range []string{filepath.Join(dir, "data"), httpServer.URL + "/data", "s3://bucket/data"} {
+		_, err = URLAccess{}.reader(rawurl)
+		require.YesError(t, err)
+	}
+	require.NoError(t, client.FinishCommit(repo, commit.ID))
+
+	for _, path := range []string{"local", "file", "http", "overwritten"} {
+		var buffer bytes.Buffer
+		require.NoError(t, client.GetFile(repo, commit.ID, path, 0, 0, "", nil, &buffer))
+		require.True(t, bytes.Equal(data, buffer.Bytes()), path)
+	}
+	fileInfos, err := client.ListFile(repo, commit.ID, "", "", nil, false)
+	require.NoError(t, err)
+	require.Equal(t, 4, len(fileInfos))
 }
 
 func TestDisallowReadsDuringCommit(t *testing.T) {
@@ -378,10 +1270,7 @@ func TestInspectCommit(t *testing.T) {
 	require.True(t, finished.After(commitInfo.Finished.GoTime()))
 }
 
-func TestDeleteCommitFuture(t *testing.T) {
-	// For when DeleteCommit gets implemented
-	t.Skip()
-
+func TestDeleteCommit(t *testing.T) {
 	t.Parallel()
 	client, _ := getClientAndServer(t)
 
@@ -398,18 +1287,25 @@ func TestDeleteCommitFuture(t *testing.T) {
 	require.NoError(t, client.FinishCommit(repo, commit.ID))
 
 	commitInfo, err := client.InspectCommit(repo, commit.ID)
+	require.NoError(t, err)
 	require.NotNil(t, commitInfo)
 
 	require.NoError(t, client.DeleteCommit(repo, commit.ID))
 
 	commitInfo, err = client.InspectCommit(repo, commit.ID)
+	require.YesError(t, err)
 	require.Nil(t, commitInfo)
 
+	commitInfos, err := client.ListCommit([]string{repo}, nil, pclient.CommitTypeNone, false, true)
+	require.NoError(t, err)
+	require.Equal(t, 0, len(commitInfos))
+
 	repoInfo, err := client.InspectRepo(repo)
-	require.Equal(t, 0, repoInfo.SizeBytes)
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), repoInfo.SizeBytes)
 }
 
-func TestDeleteCommit(t *testing.T) {
+func TestDeleteCommitOpen(t *testing.T) {
 	t.Parallel()
 	client, _ := getClientAndServer(t)
 
@@ -418,15 +1314,78 @@ func TestDeleteCommit(t *testing.T) {
 
 	commit, err := client.StartCommit(repo, "", "")
 	require.NoError(t, err)
+	_, err = client.PutFile(repo, commit.ID, "foo", strings.NewReader("foo\n"))
+	require.NoError(t, err)
 
-	fileContent := "foo\n"
-	_, err = client.PutFile(repo, commit.ID, "foo", strings.NewReader(fileContent))
+	require.NoError(t, client.DeleteCommit(repo, commit.ID))
+	require.YesError(t, client.FinishCommit(repo, commit.ID))
+
+	commitInfos, err := client.ListCommit([]string{repo}, nil, pclient.CommitTypeNone, false, true)
 	require.NoError(t, err)
+	require.Equal(t, 0, len(commitInfos))
+}
 
-	require.NoError(t, client.FinishCommit(repo, commit.ID))
+func TestDeleteCommitWithChildren(t *testing.T) {
+	t.Parallel()
+	client, servers := getClientAndServer(t)
 
-	// Because DeleteCommit is not supported
-	require.YesError(t, client.DeleteCommit(repo, commit.ID))
+	repo := "test"
+	require.NoError(t, client.CreateRepo(repo))
+
+	commit1, err := client.StartCommit(repo, "", "master")
+	require.NoError(t, err)
+	_, err = client.PutFile(repo, commit1.ID, "foo", strings.NewReader("foo\n"))
+	require.NoError(t, err)
+	_, err = client.PutFile(repo, commit1.ID, "bar", strings.NewReader("bar\n"))
+	require.NoError(t, err)
+	require.NoError(t, client.FinishCommit(repo, commit1.ID))
+
+	commit2, err := client.StartCommit(repo, "", "master")
+	require.NoError(t, err)
+	_, err = client.PutFile(repo, commit2.ID, "bar", strings.NewReader("bar\n"))
+	require.NoError(t, err)
+	_, err = client.PutFile(repo, commit2.ID, "buzz", strings.NewReader("buzz\n"))
+	require.NoError(t, err)
+	require.NoError(t, client.FinishCommit(repo, commit2.ID))
+
+	commit3, err := client.StartCommit(repo, "", "master")
+	require.NoError(t, err)
+	require.NoError(t, client.FinishCommit(repo, commit3.ID))
+
+	// commit1 has children so it can only be deleted with force
+	require.YesError(t, client.DeleteCommit(repo, commit1.ID))
+	require.NoError(t, client.DeleteCommitForce(repo, commit1.ID))
+
+	checkCommits := func() {
+		commitInfo, err := client.InspectCommit(repo, commit2.ID)
+		require.NoError(t, err)
+		require.Nil(t, commitInfo.ParentCommit)
+
+		var buffer bytes.Buffer
+		require.YesError(t, client.GetFile(repo, commit3.ID, "foo", 0, 0, "", nil, &buffer))
+		buffer.Reset()
+		require.NoError(t, client.GetFile(repo, commit3.ID, "bar", 0, 0, "", nil, &buffer))
+		require.Equal(t, "bar\n", buffer.String())
+		buffer.Reset()
+		require.NoError(t, client.GetFile(repo, commit3.ID, "buzz", 0, 0, "", nil, &buffer))
+		require.Equal(t, "buzz\n", buffer.String())
+	}
+	checkCommits()
+	commitInfos, err := client.ListCommit([]string{repo}, nil, pclient.CommitTypeNone, false, false)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(commitInfos))
+	// the reparented diffs should have been persisted
+	restartServer(servers, t)
+	checkCommits()
+
+	// deleting the head of a branch moves the branch back to its parent
+	require.NoError(t, client.DeleteCommit(repo, commit3.ID))
+	commit4, err := client.StartCommit(repo, "", "master")
+	require.NoError(t, err)
+	require.NoError(t, client.FinishCommit(repo, commit4.ID))
+	commitInfo, err := client.InspectCommit(repo, commit4.ID)
+	require.NoError(t, err)
+	require.Equal(t, commit2.ID, commitInfo.ParentCommit.ID)
 }
 
 func TestPutFile(t *testing.T) {
@@ -754,6 +1713,179 @@ func TestDeleteDir(t *testing.T) {
 	// TODO: test deleting "."
 }
 
+func TestDiffCommit(t *testing.T) {
+	t.Parallel()
+	client, _ := getClientAndServer(t)
+	repo := "TestDiffCommit"
+	require.NoError(t, client.CreateRepo(repo))
+
+	commit1, err := client.StartCommit(repo, "", "")
+	require.NoError(t, err)
+	for _, path := range []string{"a", "b", "dir/c"} {
+		_, err = client.PutFile(repo, commit1.ID, path, strings.NewReader(path+"\n"))
+		require.NoError(t, err)
+	}
+	require.NoError(t, client.FinishCommit(repo, commit1.ID))
+
+	commit2, err := client.StartCommit(repo, commit1.ID, "")
+	require.NoError(t, err)
+	_, err = client.PutFile(repo, commit2.ID, "a", strings.NewReader("more\n"))
+	require.NoError(t, err)
+	require.NoError(t, client.DeleteFile(repo, commit2.ID, "b"))
+	_, err = client.PutFile(repo, commit2.ID, "d", strings.NewReader("d\n"))
+	require.NoError(t, err)
+	require.NoError(t, client.FinishCommit(repo, commit2.ID))
+
+	commit3, err := client.StartCommit(repo, commit2.ID, "")
+	require.NoError(t, err)
+	require.NoError(t, client.DeleteFile(repo, commit3.ID, "dir"))
+	require.NoError(t, client.FinishCommit(repo, commit3.ID))
+
+	diff := func(fromCommitID string, toCommitID string) []string {
+		fileChanges, err := client.DiffCommit(repo, fromCommitID, toCommitID)
+		require.NoError(t, err)
+		var result []string
+		for _, fileChange := range fileChanges {
+			result = append(result, fmt.Sprintf("%s %s %d", fileChange.File.Path, fileChange.ChangeType, fileChange.SizeDeltaBytes))
+		}
+		return result
+	}
+	require.Equal(t, []string{
+		"a CHANGE_TYPE_ADDED 2",
+		"b CHANGE_TYPE_ADDED 2",
+		"dir/c CHANGE_TYPE_ADDED 6",
+	}, diff("", commit1.ID))
+	require.Equal(t, []string{
+		"a CHANGE_TYPE_MODIFIED 5",
+		"b CHANGE_TYPE_DELETED -2",
+		"d CHANGE_TYPE_ADDED 2",
+	}, diff(commit1.ID, commit2.ID))
+	require.Equal(t, []string{
+		"a CHANGE_TYPE_MODIFIED 5",
+		"b CHANGE_TYPE_DELETED -2",
+		"d CHANGE_TYPE_ADDED 2",
+		"dir/c CHANGE_TYPE_DELETED -6",
+	}, diff(commit1.ID, commit3.ID))
+	require.Equal(t, 0, len(diff(commit2.ID, commit2.ID)))
+
+	_, err = client.DiffCommit(repo, commit2.ID, commit1.ID)
+	require.YesError(t, err)
+}
+
+func TestMergeCommit(t *testing.T) {
+	t.Parallel()
+	client, servers := getClientAndServer(t)
+	repo := "TestMergeCommit"
+	require.NoError(t, client.CreateRepo(repo))
+
+	putFiles := func(commitID string, files map[string]string) {
+		for path, content := range files {
+			_, err := client.PutFile(repo, commitID, path, strings.NewReader(content))
+			require.NoError(t, err)
+		}
+	}
+	base, err := client.StartCommit(repo, "", "master")
+	require.NoError(t, err)
+	putFiles(base.ID, map[string]string{"a": "a\n", "b": "b\n", "log": "1\n", "dir/x": "x\n"})
+	require.NoError(t, client.FinishCommit(repo, base.ID))
+
+	ours, err := client.StartCommit(repo, "", "master")
+	require.NoError(t, err)
+	putFiles(ours.ID, map[string]string{"a": "ours\n", "log": "2\n", "c": "c\n"})
+	require.NoError(t, client.FinishCommit(repo, ours.ID))
+
+	theirs, err := client.StartCommit(repo, base.ID, "feature")
+	require.NoError(t, err)
+	putFiles(theirs.ID, map[string]string{"a": "theirs\n", "log": "3\n", "dir/y": "y\n"})
+	require.NoError(t, client.DeleteFile(repo, theirs.ID, "b"))
+	require.NoError(t, client.FinishCommit(repo, theirs.ID))
+
+	listCommit := func() []*pfsclient.CommitInfo {
+		commitInfos, err := client.ListCommit([]string{repo}, nil, pclient.CommitTypeNone, false, false)
+		require.NoError(t, err)
+		return commitInfos
+	}
+	// a and log were changed on both sides, the failed merge doesn't leave
+	// a commit behind
+	_, err = client.StartMergeCommit(repo, "", "master", "feature", nil)
+	require.YesError(t, err)
+	_, err = client.StartMergeCommit(repo, "", "master", "feature", []*pfsclient.MergePolicy{
+		{Path: "a", Strategy: pfsclient.MergeStrategy_MERGE_STRATEGY_OURS},
+	})
+	require.YesError(t, err)
+	require.Equal(t, 3, len(listCommit()))
+
+	merge, err := client.StartMergeCommit(repo, "", "master", "feature", []*pfsclient.MergePolicy{
+		{Path: "/", Strategy: pfsclient.MergeStrategy_MERGE_STRATEGY_THEIRS},
+		{Path: "log", Strategy: pfsclient.MergeStrategy_MERGE_STRATEGY_CONCATENATE},
+	})
+	require.NoError(t, err)
+	require.NoError(t, client.FinishCommit(repo, merge.ID))
+	oursMerge, err := client.StartMergeCommit(repo, ours.ID, "", theirs.ID, []*pfsclient.MergePolicy{
+		{Path: "/", Strategy: pfsclient.MergeStrategy_MERGE_STRATEGY_OURS},
+	})
+	require.NoError(t, err)
+	require.NoError(t, client.FinishCommit(repo, oursMerge.ID))
+	child, err := client.StartCommit(repo, "", "master")
+	require.NoError(t, err)
+	putFiles(child.ID, map[string]string{"a": "more\n"})
+	require.NoError(t, client.FinishCommit(repo, child.ID))
+
+	getFile := func(commitID string, path string) string {
+		var buffer bytes.Buffer
+		require.NoError(t, client.GetFile(repo, commitID, path, 0, 0, "", nil, &buffer))
+		return buffer.String()
+	}
+	listFile := func(commitID string, path string) []string {
+		fileInfos, err := client.ListFile(repo, commitID, path, "", nil, false)
+		require.NoError(t, err)
+		var result []string
+		for _, fileInfo := range fileInfos {
+			result = append(result, fileInfo.File.Path)
+		}
+		sort.Strings(result)
+		return result
+	}
+	check := func() {
+		commitInfo, err := client.InspectCommit(repo, merge.ID)
+		require.NoError(t, err)
+		require.Equal(t, ours.ID, commitInfo.ParentCommit.ID)
+		require.Equal(t, theirs.ID, commitInfo.MergeParent.ID)
+		// theirs isn't a leaf anymore but its history is still listed
+		listed := make(map[string]bool)
+		for _, commitInfo := range listCommit() {
+			listed[commitInfo.Commit.ID] = true
+		}
+		for _, commit := range []*pfsclient.Commit{base, ours, theirs, merge, oursMerge, child} {
+			require.True(t, listed[commit.ID])
+		}
+
+		require.Equal(t, "a\ntheirs\n", getFile(merge.ID, "a"))
+		require.Equal(t, "1\n2\n3\n", getFile(merge.ID, "log"))
+		require.Equal(t, []string{"a", "c", "dir", "log"}, listFile(merge.ID, ""))
+		require.Equal(t, []string{"dir/x", "dir/y"}, listFile(merge.ID, "dir"))
+		fileInfo, err := client.InspectFile(repo, merge.ID, "a", "", nil)
+		require.NoError(t, err)
+		require.Equal(t, merge.ID, fileInfo.CommitModified.ID)
+		require.Equal(t, "a\ntheirs\nmore\n", getFile(child.ID, "a"))
+
+		// the files only theirs changed are merged regardless of the policy
+		require.Equal(t, "a\nours\n", getFile(oursMerge.ID, "a"))
+		require.Equal(t, "1\n2\n", getFile(oursMerge.ID, "log"))
+		require.Equal(t, []string{"a", "c", "dir", "log"}, listFile(oursMerge.ID, ""))
+		require.Equal(t, []string{"dir/x", "dir/y"}, listFile(oursMerge.ID, "dir"))
+
+		fileChanges, err := client.DiffCommit(repo, theirs.ID, merge.ID)
+		require.NoError(t, err)
+		require.Equal(t, 2, len(fileChanges))
+		require.Equal(t, "c", fileChanges[0].File.Path)
+		require.Equal(t, "log", fileChanges[1].File.Path)
+	}
+	check()
+	restartServer(servers, t)
+	check()
+}
+
 func TestListCommit(t *testing.T) {
 	t.Parallel()
 	client, _ := getClientAndServer(t)
@@ -1007,13 +2139,192 @@ func generateRandomString(n int) string {
 	return string(b)
 }
 
+func TestShardSnapshot(t *testing.T) {
+	t.Parallel()
+	root := uniqueString("/tmp/pach_test/run")
+	client, servers := getClientAndServerInDir(t, root)
+	repo := "TestShardSnapshot"
+	require.NoError(t, client.CreateRepo(repo))
+	putCommit := func(parentID string) *pfsclient.Commit {
+		commit, err := client.StartCommit(repo, parentID, "")
+		require.NoError(t, err)
+		_, err = client.PutFile(repo, commit.ID, "foo", strings.NewReader("foo\n"))
+		require.NoError(t, err)
+		require.NoError(t, client.FinishCommit(repo, commit.ID))
+		return commit
+	}
+	commit1 := putCommit("")
+	commit2 := putCommit(commit1.ID)
+	for _, server := range servers {
+		serverShards, err := server.router.GetShards(0)
+		require.NoError(t, err)
+		for shard := range serverShards {
+			require.NoError(t, server.driver.SnapshotShard(shard))
+		}
+	}
+	// Make everything written so far look old, the diffs older than the
+	// snapshots. AddShard should only read the diffs written after the
+	// snapshots so we remove commit1's and corrupt commit2's.
+	age := func(pattern string, age time.Duration) {
+		paths, err := filepath.Glob(filepath.Join(root, pattern))
+		require.NoError(t, err)
+		require.True(t, len(paths) > 0)
+		for _, path := range paths {
+			modified := time.Now().Add(-age)
+			require.NoError(t, os.Chtimes(path, modified, modified))
+		}
+	}
+	age("snapshot/*", time.Hour)
+	age("diff/*/*/*", 2*time.Hour)
+	paths, err := filepath.Glob(filepath.Join(root, "diff", repo, "*", commit1.ID))
+	require.NoError(t, err)
+	for _, path := range paths {
+		require.NoError(t, os.Remove(path))
+	}
+	paths, err = filepath.Glob(filepath.Join(root, "diff", repo, "*", commit2.ID))
+	require.NoError(t, err)
+	for _, path := range paths {
+		require.NoError(t, ioutil.WriteFile(path, []byte("garbage"), 0666))
+	}
+	age("diff/*/*/*", 2*time.Hour)
+	commit3 := putCommit(commit2.ID)
+
+	restartServer(servers, t)
+	var buffer bytes.Buffer
+	require.NoError(t, client.GetFile(repo, commit1.ID, "foo", 0, 0, "", nil, &buffer))
+	require.Equal(t, "foo\n", buffer.String())
+	buffer.Reset()
+	require.NoError(t, client.GetFile(repo, commit3.ID, "foo", 0, 0, "", nil, &buffer))
+	require.Equal(t, "foo\nfoo\nfoo\n", buffer.String())
+
+	// deleting a commit deletes the snapshots which contain it
+	require.NoError(t, client.DeleteCommit(repo, commit3.ID))
+	paths, err = filepath.Glob(filepath.Join(root, "snapshot", "*"))
+	require.NoError(t, err)
+	require.Equal(t, 0, len(paths))
+}
+
+func TestCompaction(t *testing.T) {
+	t.Parallel()
+	root := uniqueString("/tmp/pach_test/run")
+	client, servers := getClientAndServerInDir(t, root)
+	repo := "TestCompaction"
+	require.NoError(t, client.CreateRepo(repo))
+	// enough commits that the files need checkpoints
+	var commits []*pfsclient.Commit
+	for i := 0; i < 150; i++ {
+		parentID := ""
+		if i > 0 {
+			parentID = commits[i-1].ID
+		}
+		commit, err := client.StartCommit(repo, parentID, "")
+		require.NoError(t, err)
+		_, err = client.PutFile(repo, commit.ID, "foo", strings.NewReader("foo\n"))
+		require.NoError(t, err)
+		_, err = client.PutFile(repo, commit.ID, fmt.Sprintf("dir/%d", i), strings.NewReader("bar\n"))
+		require.NoError(t, err)
+		require.NoError(t, client.FinishCommit(repo, commit.ID))
+		commits = append(commits, commit)
+	}
+	head := commits[len(commits)-1]
+	deleted := make(map[string]bool)
+	check := func() {
+		var expected []string
+		for _, commit := range commits {
+			if !deleted[commit.ID] {
+				expected = append(expected, commit.ID)
+			}
+		}
+		var buffer bytes.Buffer
+		require.NoError(t, client.GetFile(repo, head.ID, "foo", 0, 0, "", nil, &buffer))
+		require.Equal(t, strings.Repeat("foo\n", len(expected)), buffer.String())
+		fileInfo, err := client.InspectFile(repo, head.ID, "foo", "", nil)
+		require.NoError(t, err)
+		require.Equal(t, head.ID, fileInfo.CommitModified.ID)
+		fileInfos, err := client.ListFile(repo, head.ID, "dir", "", nil, false)
+		require.NoError(t, err)
+		require.Equal(t, len(expected), len(fileInfos))
+		childCommits := make(map[string]string)
+		for _, fileInfo := range fileInfos {
+			childCommits[fileInfo.File.Path] = fileInfo.File.Commit.ID
+		}
+		for i, commit := range commits {
+			if !deleted[commit.ID] {
+				require.Equal(t, commit.ID, childCommits[fmt.Sprintf("dir/%d", i)])
+			}
+		}
+		fileInfos, err = client.ListFile(repo, head.ID, "", "", nil, true)
+		require.NoError(t, err)
+		require.Equal(t, 2, len(fileInfos))
+		for _, fileInfo := range fileInfos {
+			if fileInfo.File.Path == "foo" {
+				require.Equal(t, uint64(4*len(expected)), fileInfo.SizeBytes)
+			}
+		}
+	}
+	check()
+	// reads from a commit don't use checkpoints
+	listFrom := func() int {
+		fileInfos, err := client.ListFile(repo, head.ID, "dir", commits[len(commits)-11].ID, nil, false)
+		require.NoError(t, err)
+		return len(fileInfos)
+	}
+	fromLen := listFrom()
+
+	for _, server := range servers {
+		serverShards, err := server.router.GetShards(0)
+		require.NoError(t, err)
+		for shard := range serverShards {
+			require.NoError(t, server.driver.CompactShard(shard))
+		}
+	}
+	// the checkpoints have been persisted
+	blockClient := getBlockClientInDir(t, root)
+	checkpoints := 0
+	for shard := uint64(0); shard < shards; shard++ {
+		listDiffClient, err := blockClient.ListDiff(context.Background(), &pfsclient.ListDiffRequest{Shard: shard})
+		require.NoError(t, err)
+		for {
+			diffInfo, err := listDiffClient.Recv()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+			checkpoints += len(diffInfo.Checkpoints)
+		}
+	}
+	require.True(t, checkpoints > 0)
+	check()
+	require.Equal(t, fromLen, listFrom())
+	restartServer(servers, t)
+	check()
+	require.Equal(t, fromLen, listFrom())
+
+	// deleting a commit drops the checkpoints which contain it
+	require.NoError(t, client.DeleteCommitForce(repo, commits[50].ID))
+	deleted[commits[50].ID] = true
+	check()
+	restartServer(servers, t)
+	check()
+}
+
 func getBlockClient(t *testing.T) pfsclient.BlockAPIClient {
-	localPort := atomic.AddInt32(&port, 1)
-	address := fmt.Sprintf("localhost:%d", localPort)
 	root := uniqueString("/tmp/pach_test/run")
 	t.Logf("root %s", root)
-	blockAPIServer, err := NewLocalBlockAPIServer(root)
+	return getBlockClientInDir(t, root)
+}
+
+// getBlockClientInDir is like getBlockClient but the block server stores
+// everything in root.
+func getBlockClientInDir(t *testing.T, root string) pfsclient.BlockAPIClient {
+	blockAPIServer, err := NewLocalBlockAPIServer(root, DefaultBlockAPIServerOptions())
 	require.NoError(t, err)
+	return serveBlockAPIServer(t, blockAPIServer)
+}
+
+func serveBlockAPIServer(t *testing.T, blockAPIServer pfsclient.BlockAPIServer) pfsclient.BlockAPIClient {
+	localPort := atomic.AddInt32(&port, 1)
+	address := fmt.Sprintf("localhost:%d", localPort)
 	ready := make(chan bool)
 	go func() {
 		err := protoserver.Serve(
@@ -1028,6 +2339,7 @@ func getBlockClient(t *testing.T) pfsclient.BlockAPIClient {
 	}()
 	<-ready
 	clientConn, err := grpc.Dial(address, grpc.WithInsecure())
+	require.NoError(t, err)
 	return pfsclient.NewBlockAPIClient(clientConn)
 }
 
@@ -1053,6 +2365,18 @@ func runServers(t *testing.T, port int32, apiServer pfsclient.APIServer,
 func getClientAndServer(t *testing.T) (pclient.APIClient, []*internalAPIServer) {
 	root := uniqueString("/tmp/pach_test/run")
 	t.Logf("root %s", root)
+	return getClientAndServerInDir(t, root)
+}
+
+// getClientAndServerInDir is like getClientAndServer but the block server
+// stores everything in root.
+func getClientAndServerInDir(t *testing.T, root string) (pclient.APIClient, []*internalAPIServer) {
+	return getClientAndServerWithURLAccess(t, root, URLAccess{})
+}
+
+// getClientAndServerWithURLAccess is like getClientAndServerInDir but
+// put-file URLs may read what urlAccess allows.
+func getClientAndServerWithURLAccess(t *testing.T, root string, urlAccess URLAccess) (pclient.APIClient, []*internalAPIServer) {
 	var ports []int32
 	for i := 0; i < servers; i++ {
 		ports = append(ports, atomic.AddInt32(&port, 1))
@@ -1065,14 +2389,14 @@ func getClientAndServer(t *testing.T) (pclient.APIClient, []*internalAPIServer)
 	var internalAPIServers []*internalAPIServer
 	for i, port := range ports {
 		address := addresses[i]
-		driver, err := drive.NewDriver(address)
+		driver, err := drive.NewDriver(address, drive.DefaultOptions())
 		require.NoError(t, err)
-		blockAPIServer, err := NewLocalBlockAPIServer(root)
+		blockAPIServer, err := NewLocalBlockAPIServer(root, DefaultBlockAPIServerOptions())
 		require.NoError(t, err)
 		hasher := pfsserver.NewHasher(shards, 1)
 		dialer := grpcutil.NewDialer(grpc.WithInsecure())
 		apiServer := NewAPIServer(hasher, shard.NewRouter(sharder, dialer, address))
-		internalAPIServer := newInternalAPIServer(hasher, shard.NewRouter(sharder, dialer, address), driver)
+		internalAPIServer := newInternalAPIServer(hasher, shard.NewRouter(sharder, dialer, address), driver, urlAccess)
 		internalAPIServers = append(internalAPIServers, internalAPIServer)
 		runServers(t, port, apiServer, internalAPIServer, blockAPIServer)
 		for i := 0; i < shards; i++ {
@@ -0,0 +1,125 @@
+package server
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	pfsclient "github.com/pachyderm/pachyderm/src/client/pfs"
+	"go.pedge.io/proto/time"
+)
+
+// Shard snapshots are stored as a sequence of DiffInfos, each one preceded by
+// its length as a uvarint.
+
+// receiveShardSnapshot reads a snapshot from server and calls write with the
+// shard and the encoded snapshot. If the stream fails reader returns an error
+// and write must discard what it's written.
+func receiveShardSnapshot(server pfsclient.BlockAPI_CreateShardSnapshotServer, write func(shard uint64, reader io.Reader) error) error {
+	shardSnapshot, err := server.Recv()
+	if err != nil {
+		if err == io.EOF {
+			return fmt.Errorf("empty shard snapshot stream")
+		}
+		return err
+	}
+	shard := shardSnapshot.Shard
+	pipeReader, pipeWriter := io.Pipe()
+	go func() {
+		writer := bufio.NewWriter(pipeWriter)
+		for {
+			for _, diffInfo := range shardSnapshot.DiffInfos {
+				if diffInfo.Diff == nil || diffInfo.Diff.Shard != shard {
+					pipeWriter.CloseWithError(fmt.Errorf("diff %v doesn't belong in the snapshot of shard %d", diffInfo.Diff, shard))
+					return
+				}
+				if err := writeDelimited(writer, diffInfo); err != nil {
+					pipeWriter.CloseWithError(err)
+					return
+				}
+			}
+			shardSnapshot, err = server.Recv()
+			if err == io.EOF {
+				pipeWriter.CloseWithError(writer.Flush())
+				return
+			}
+			if err != nil {
+				pipeWriter.CloseWithError(err)
+				return
+			}
+		}
+	}()
+	err = write(shard, pipeReader)
+	// unblocks the goroutine if write returned early
+	pipeReader.Close()
+	return err
+}
+
+// sendShardSnapshot sends the snapshot of shard in reader to server, created
+// is sent in the first message.
+func sendShardSnapshot(server pfsclient.BlockAPI_GetShardSnapshotServer, shard uint64, created time.Time, reader io.Reader) error {
+	if err := server.Send(&pfsclient.ShardSnapshot{
+		Shard:   shard,
+		Created: prototime.TimeToTimestamp(created),
+	}); err != nil {
+		return err
+	}
+	bufReader := bufio.NewReader(reader)
+	for {
+		diffInfo := &pfsclient.DiffInfo{}
+		if err := readDelimited(bufReader, diffInfo); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		if err := server.Send(&pfsclient.ShardSnapshot{
+			Shard:     shard,
+			DiffInfos: []*pfsclient.DiffInfo{diffInfo},
+		}); err != nil {
+			return err
+		}
+	}
+}
+
+// diffModifiedAfter returns true if a diff modified at modified should be
+// listed by request. Object stores only keep modification times to the second
+// so ModifiedAfter is truncated to match.
+func diffModifiedAfter(request *pfsclient.ListDiffRequest, modified time.Time) bool {
+	if request.ModifiedAfter == nil {
+		return true
+	}
+	return !modified.Before(prototime.TimestampToTime(request.ModifiedAfter).Truncate(time.Second))
+}
+
+func writeDelimited(writer io.Writer, message proto.Message) error {
+	data, err := proto.Marshal(message)
+	if err != nil {
+		return err
+	}
+	var length [binary.MaxVarintLen64]byte
+	if _, err := writer.Write(length[:binary.PutUvarint(length[:], uint64(len(data)))]); err != nil {
+		return err
+	}
+	_, err = writer.Write(data)
+	return err
+}
+
+// readDelimited returns io.EOF if reader is at the end of the stream.
+func readDelimited(reader *bufio.Reader, message proto.Message) error {
+	length, err := binary.ReadUvarint(reader)
+	if err != nil {
+		return err
+	}
+	data := make([]byte, length)
+	if _, err := io.ReadFull(reader, data); err != nil {
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	return proto.Unmarshal(data, message)
+}
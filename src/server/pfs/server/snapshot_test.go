@@ -0,0 +1,120 @@
+package server
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	pclient "github.com/pachyderm/pachyderm/src/client"
+	pfsclient "github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"go.pedge.io/proto/time"
+	"golang.org/x/net/context"
+)
+
+func TestLocalShardSnapshot(t *testing.T) {
+	t.Parallel()
+	testShardSnapshot(t, getBlockClient(t))
+}
+
+func TestObjShardSnapshot(t *testing.T) {
+	t.Parallel()
+	server, _, _ := newTestObjBlockAPIServer(t)
+	testShardSnapshot(t, serveBlockAPIServer(t, server))
+}
+
+func testShardSnapshot(t *testing.T, blockClient pfsclient.BlockAPIClient) {
+	ctx := context.Background()
+	createSnapshot := func(shard uint64, commitIDs ...string) {
+		createShardSnapshotClient, err := blockClient.CreateShardSnapshot(ctx)
+		require.NoError(t, err)
+		require.NoError(t, createShardSnapshotClient.Send(&pfsclient.ShardSnapshot{Shard: shard}))
+		for _, commitID := range commitIDs {
+			require.NoError(t, createShardSnapshotClient.Send(&pfsclient.ShardSnapshot{
+				Shard:     shard,
+				DiffInfos: []*pfsclient.DiffInfo{{Diff: pclient.NewDiff("repo", commitID, shard)}},
+			}))
+		}
+		_, err = createShardSnapshotClient.CloseAndRecv()
+		require.NoError(t, err)
+	}
+	getSnapshot := func(shard uint64) (*time.Time, []string) {
+		getShardSnapshotClient, err := blockClient.GetShardSnapshot(ctx, &pfsclient.GetShardSnapshotRequest{Shard: shard})
+		require.NoError(t, err)
+		var created *time.Time
+		var commitIDs []string
+		for {
+			shardSnapshot, err := getShardSnapshotClient.Recv()
+			if err == io.EOF {
+				return created, commitIDs
+			}
+			require.NoError(t, err)
+			require.Equal(t, shard, shardSnapshot.Shard)
+			if created == nil {
+				createdTime := prototime.TimestampToTime(shardSnapshot.Created)
+				created = &createdTime
+			}
+			for _, diffInfo := range shardSnapshot.DiffInfos {
+				commitIDs = append(commitIDs, diffInfo.Diff.Commit.ID)
+			}
+		}
+	}
+
+	created, _ := getSnapshot(0)
+	require.Nil(t, created)
+	createSnapshot(0, "a", "b")
+	createSnapshot(1)
+	created, commitIDs := getSnapshot(0)
+	require.NotNil(t, created)
+	require.True(t, time.Since(*created) < time.Minute)
+	require.Equal(t, []string{"a", "b"}, commitIDs)
+	created, commitIDs = getSnapshot(1)
+	require.NotNil(t, created)
+	require.Equal(t, 0, len(commitIDs))
+
+	// a new snapshot replaces the old one
+	createSnapshot(0, "c")
+	_, commitIDs = getSnapshot(0)
+	require.Equal(t, []string{"c"}, commitIDs)
+
+	// diffs which don't belong to the shard are rejected
+	createShardSnapshotClient, err := blockClient.CreateShardSnapshot(ctx)
+	require.NoError(t, err)
+	require.NoError(t, createShardSnapshotClient.Send(&pfsclient.ShardSnapshot{
+		Shard:     0,
+		DiffInfos: []*pfsclient.DiffInfo{{Diff: pclient.NewDiff("repo", "d", 1)}},
+	}))
+	_, err = createShardSnapshotClient.CloseAndRecv()
+	require.YesError(t, err)
+	_, commitIDs = getSnapshot(0)
+	require.Equal(t, []string{"c"}, commitIDs)
+
+	_, err = blockClient.DeleteShardSnapshot(ctx, &pfsclient.DeleteShardSnapshotRequest{Shard: 0})
+	require.NoError(t, err)
+	created, _ = getSnapshot(0)
+	require.Nil(t, created)
+	_, err = blockClient.DeleteShardSnapshot(ctx, &pfsclient.DeleteShardSnapshotRequest{Shard: 0})
+	require.NoError(t, err)
+
+	// ListDiff can skip diffs written before a snapshot
+	_, err = blockClient.CreateDiff(ctx, &pfsclient.DiffInfo{Diff: pclient.NewDiff("repo", "e", 0)})
+	require.NoError(t, err)
+	listDiffs := func(modifiedAfter time.Time) []string {
+		listDiffClient, err := blockClient.ListDiff(ctx, &pfsclient.ListDiffRequest{
+			Shard:         0,
+			ModifiedAfter: prototime.TimeToTimestamp(modifiedAfter),
+		})
+		require.NoError(t, err)
+		var commitIDs []string
+		for {
+			diffInfo, err := listDiffClient.Recv()
+			if err == io.EOF {
+				return commitIDs
+			}
+			require.NoError(t, err)
+			commitIDs = append(commitIDs, diffInfo.Diff.Commit.ID)
+		}
+	}
+	require.Equal(t, []string{"e"}, listDiffs(time.Now().Add(-time.Minute)))
+	require.Equal(t, 0, len(listDiffs(time.Now().Add(time.Minute))))
+}
@@ -0,0 +1,202 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+
+	"github.com/pachyderm/pachyderm/src/server/pkg/obj"
+)
+
+const (
+	// urlChunkSize is the size of the ranged reads used to fetch URLs.
+	urlChunkSize = 8 * 1024 * 1024
+	// urlParallelism is how many ranged reads of a URL are made at once.
+	urlParallelism = 8
+)
+
+// URLAccess controls what put-file URLs may read, the zero value doesn't
+// allow any URLs.
+type URLAccess struct {
+	// LocalDirs are the directories on pachd's host which local paths may be
+	// read from.
+	LocalDirs []string
+	// ObjectStorage allows s3:// and gs:// URLs, they're read with pachd's
+	// own credentials so every client can read any bucket that pachd can.
+	ObjectStorage bool
+	// HTTPHosts are the hosts which http(s):// URLs may be read from. A host
+	// of "*" allows any host except those which resolve to loopback or
+	// link-local addresses (which are only allowed if they're listed by
+	// name) since they'd let clients reach pachd's node and cloud metadata
+	// services.
+	HTTPHosts []string
+}
+
+// reader returns a reader for the content of rawurl, which may be an
+// http(s)://, s3:// or gs:// URL or a local path (optionally prefixed by
+// file://) if a allows it. The content is fetched with parallel ranged
+// reads.
+func (a URLAccess) reader(rawurl string) (io.ReadCloser, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, err
+	}
+	var objClient obj.Client
+	var name string
+	switch u.Scheme {
+	case "http", "https":
+		var client *http.Client
+		if client, err = a.httpClient(u); err != nil {
+			return nil, err
+		}
+		objClient = obj.NewHTTPClient(client)
+		name = rawurl
+	case "s3", "gs":
+		if !a.ObjectStorage {
+			return nil, grpcErrorf(codes.PermissionDenied, "pachyderm: %s:// URLs aren't enabled on this cluster: %s", u.Scheme, rawurl)
+		}
+		if u.Scheme == "s3" {
+			objClient, err = newAmazonURLClient(u.Host)
+		} else {
+			objClient, err = obj.NewGoogleClient(context.Background(), u.Host)
+		}
+		name = strings.TrimPrefix(u.Path, "/")
+	case "file", "":
+		if !filepath.IsAbs(u.Path) {
+			return nil, fmt.Errorf("pachyderm: local path must be absolute: %s", rawurl)
+		}
+		if name, err = a.localPath(u.Path); err != nil {
+			return nil, err
+		}
+		objClient, err = obj.NewLocalClient("/")
+	default:
+		return nil, fmt.Errorf("pachyderm: unsupported URL scheme %q: %s", u.Scheme, rawurl)
+	}
+	if err != nil {
+		return nil, err
+	}
+	objectInfo, err := objClient.Inspect(name)
+	if err != nil {
+		return nil, err
+	}
+	if objectInfo.SizeBytes <= urlChunkSize {
+		// a single plain read, which works even if ranges aren't supported
+		return objClient.Reader(name, 0, 0)
+	}
+	return obj.NewParallelReader(objClient, name, objectInfo.SizeBytes, urlChunkSize, urlParallelism), nil
+}
+
+// localPath returns path with symlinks resolved if it's under one of
+// LocalDirs.
+func (a URLAccess) localPath(path string) (string, error) {
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", err
+	}
+	for _, dir := range a.LocalDirs {
+		if dir == "" {
+			continue
+		}
+		resolvedDir, err := filepath.EvalSymlinks(dir)
+		if err != nil {
+			continue
+		}
+		rel, err := filepath.Rel(resolvedDir, resolved)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, "../") {
+			return resolved, nil
+		}
+	}
+	return "", grpcErrorf(codes.PermissionDenied, "pachyderm: %s isn't in a directory that put-file URLs may read from", path)
+}
+
+// httpClient returns a client which may read u, and anything it redirects
+// to, if u's host is allowed by HTTPHosts.
+func (a URLAccess) httpClient(u *url.URL) (*http.Client, error) {
+	if !a.hostAllowed(u) {
+		return nil, grpcErrorf(codes.PermissionDenied, "pachyderm: %s isn't a host that put-file URLs may read from", urlHost(u))
+	}
+	return &http.Client{
+		Transport: &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			Dial:  a.dial,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return fmt.Errorf("pachyderm: too many redirects")
+			}
+			if !a.hostAllowed(req.URL) {
+				return grpcErrorf(codes.PermissionDenied, "pachyderm: %s isn't a host that put-file URLs may read from", urlHost(req.URL))
+			}
+			return nil
+		},
+	}, nil
+}
+
+// dial dials addr, refusing loopback and link-local addresses unless their
+// host is listed by name in HTTPHosts. The address is resolved once and
+// dialed directly so the host can't resolve to somewhere else in between.
+func (a URLAccess) dial(network string, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	ips, err := net.LookupIP(host)
+	if err != nil {
+		return nil, err
+	}
+	if len(ips) == 0 {
+		return nil, fmt.Errorf("pachyderm: no addresses for %s", host)
+	}
+	listed := a.hostListed(host)
+	for _, ip := range ips {
+		if !listed && (ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified()) {
+			return nil, fmt.Errorf("pachyderm: %s resolves to %s which put-file URLs may not read from", host, ip)
+		}
+	}
+	return net.Dial(network, net.JoinHostPort(ips[0].String(), port))
+}
+
+// hostAllowed returns true if u may be read, subject to dial.
+func (a URLAccess) hostAllowed(u *url.URL) bool {
+	return a.hostListed("*") || a.hostListed(urlHost(u))
+}
+
+// hostListed returns true if host is in HTTPHosts.
+func (a URLAccess) hostListed(host string) bool {
+	for _, allowed := range a.HTTPHosts {
+		if allowed != "" && strings.EqualFold(allowed, host) {
+			return true
+		}
+	}
+	return false
+}
+
+// urlHost returns u's host without its port.
+func urlHost(u *url.URL) string {
+	if host, _, err := net.SplitHostPort(u.Host); err == nil {
+		return host
+	}
+	return u.Host
+}
+
+// newAmazonURLClient returns a client for an S3 bucket using the credentials
+// in /amazon-secret, they're the same credentials the block server uses.
+func newAmazonURLClient(bucket string) (obj.Client, error) {
+	var secrets []string
+	for _, name := range []string{"id", "secret", "token", "region"} {
+		secret, err := ioutil.ReadFile(filepath.Join("/amazon-secret", name))
+		if err != nil {
+			return nil, fmt.Errorf("pachyderm: no amazon credentials for s3 URL: %v", err)
+		}
+		secrets = append(secrets, string(secret))
+	}
+	return obj.NewAmazonClient(bucket, secrets[0], secrets[1], secrets[2], secrets[3])
+}
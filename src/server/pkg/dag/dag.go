@@ -30,6 +30,40 @@ func (d *DAG) NewNode(id string, parents []string) {
 	}
 }
 
+// RemoveNode removes id from the DAG, its children become children of its
+// parents.
+func (d *DAG) RemoveNode(id string) {
+	parents := d.parents[id]
+	children := d.children[id]
+	for _, childID := range children {
+		var childParents []string
+		for _, parentID := range d.parents[childID] {
+			if parentID != id {
+				childParents = append(childParents, parentID)
+			}
+		}
+		d.parents[childID] = appendMissing(childParents, parents)
+	}
+	for _, parentID := range parents {
+		var parentChildren []string
+		for _, childID := range d.children[parentID] {
+			if childID != id {
+				parentChildren = append(parentChildren, childID)
+			}
+		}
+		d.children[parentID] = appendMissing(parentChildren, children)
+		d.leaves[parentID] = len(d.children[parentID]) == 0
+	}
+	delete(d.parents, id)
+	delete(d.children, id)
+	delete(d.leaves, id)
+}
+
+// Children returns the direct children of id.
+func (d *DAG) Children(id string) []string {
+	return d.children[id]
+}
+
 // Sorted returns all nodes in a topologically sorted order
 func (d *DAG) Sorted() []string {
 	seen := make(map[string]bool)
@@ -78,6 +112,22 @@ func (d *DAG) Ghosts() []string {
 	return result
 }
 
+func appendMissing(ids []string, newIDs []string) []string {
+	for _, newID := range newIDs {
+		missing := true
+		for _, id := range ids {
+			if id == newID {
+				missing = false
+				break
+			}
+		}
+		if missing {
+			ids = append(ids, newID)
+		}
+	}
+	return ids
+}
+
 func dfs(id string, edges map[string][]string, seen map[string]bool) []string {
 	if seen[id] {
 		return nil
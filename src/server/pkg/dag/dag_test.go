@@ -83,3 +83,27 @@ func TestGhosts(t *testing.T) {
 		d.Ghosts(),
 	)
 }
+
+func TestRemoveNode(t *testing.T) {
+	d := NewDAG(map[string][]string{
+		"1": {},
+		"2": {"1"},
+		"3": {"2"},
+		"4": {"2"},
+	})
+	d.RemoveNode("2")
+	require.EqualOneOf(
+		t,
+		[]interface{}{
+			[]string{"3", "4"},
+			[]string{"4", "3"},
+		},
+		d.Children("1"),
+	)
+	require.Equal(t, []string{"1", "3"}, d.Ancestors("3", nil))
+	require.Equal(t, 0, len(d.Ghosts()))
+	d.RemoveNode("3")
+	d.RemoveNode("4")
+	require.Equal(t, []string{"1"}, d.Leaves())
+	require.Equal(t, []string{"1"}, d.Sorted())
+}
@@ -27,6 +27,9 @@ var (
 	rethinkVolumeClaimName = "rethink-volume-claim"
 	amazonSecretName       = "amazon-secret"
 	googleSecretName       = "google-secret"
+	s3SecretName           = "s3-secret"
+	azureSecretName        = "azure-secret"
+	encryptionSecretName   = "encryption-secret"
 	initName               = "pachd-init"
 	trueVal                = true
 )
@@ -37,6 +40,8 @@ const (
 	localBackend backend = iota
 	amazonBackend
 	googleBackend
+	s3Backend
+	azureBackend
 )
 
 func ServiceAccount() *api.ServiceAccount {
@@ -53,7 +58,7 @@ func ServiceAccount() *api.ServiceAccount {
 }
 
 //PachdRc TODO secrets is only necessary because dockerized kube chokes on them
-func PachdRc(shards uint64, backend backend) *api.ReplicationController {
+func PachdRc(shards uint64, backend backend, encrypted bool) *api.ReplicationController {
 	volumes := []api.Volume{
 		{
 			Name: "pach-disk",
@@ -96,6 +101,48 @@ func PachdRc(shards uint64, backend backend) *api.ReplicationController {
 			Name:      googleSecretName,
 			MountPath: "/" + googleSecretName,
 		})
+	case s3Backend:
+		backendEnvVar = server.S3BackendEnvVar
+		volumes = append(volumes, api.Volume{
+			Name: s3SecretName,
+			VolumeSource: api.VolumeSource{
+				Secret: &api.SecretVolumeSource{
+					SecretName: s3SecretName,
+				},
+			},
+		})
+		volumeMounts = append(volumeMounts, api.VolumeMount{
+			Name:      s3SecretName,
+			MountPath: "/" + s3SecretName,
+		})
+	case azureBackend:
+		backendEnvVar = server.AzureBackendEnvVar
+		volumes = append(volumes, api.Volume{
+			Name: azureSecretName,
+			VolumeSource: api.VolumeSource{
+				Secret: &api.SecretVolumeSource{
+					SecretName: azureSecretName,
+				},
+			},
+		})
+		volumeMounts = append(volumeMounts, api.VolumeMount{
+			Name:      azureSecretName,
+			MountPath: "/" + azureSecretName,
+		})
+	}
+	if encrypted {
+		volumes = append(volumes, api.Volume{
+			Name: encryptionSecretName,
+			VolumeSource: api.VolumeSource{
+				Secret: &api.SecretVolumeSource{
+					SecretName: encryptionSecretName,
+				},
+			},
+		})
+		volumeMounts = append(volumeMounts, api.VolumeMount{
+			Name:      encryptionSecretName,
+			MountPath: "/" + encryptionSecretName,
+		})
 	}
 	return &api.ReplicationController{
 		TypeMeta: unversioned.TypeMeta{
@@ -457,6 +504,70 @@ func GoogleSecret(bucket string) *api.Secret {
 	}
 }
 
+// S3Secret contains the configuration of an S3-compatible object store.
+func S3Secret(bucket string, id string, secret string, endpoint string, region string, secure bool, pathStyle bool) *api.Secret {
+	return &api.Secret{
+		TypeMeta: unversioned.TypeMeta{
+			Kind:       "Secret",
+			APIVersion: "v1",
+		},
+		ObjectMeta: api.ObjectMeta{
+			Name:   s3SecretName,
+			Labels: labels(s3SecretName),
+		},
+		Data: map[string][]byte{
+			"bucket":     []byte(bucket),
+			"id":         []byte(id),
+			"secret":     []byte(secret),
+			"endpoint":   []byte(endpoint),
+			"region":     []byte(region),
+			"secure":     []byte(strconv.FormatBool(secure)),
+			"path-style": []byte(strconv.FormatBool(pathStyle)),
+		},
+	}
+}
+
+// AzureSecret contains the credentials of an Azure Blob Storage container,
+// endpoint is "" for containers in the public Azure cloud.
+func AzureSecret(container string, account string, key string, endpoint string) *api.Secret {
+	return &api.Secret{
+		TypeMeta: unversioned.TypeMeta{
+			Kind:       "Secret",
+			APIVersion: "v1",
+		},
+		ObjectMeta: api.ObjectMeta{
+			Name:   azureSecretName,
+			Labels: labels(azureSecretName),
+		},
+		Data: map[string][]byte{
+			"container": []byte(container),
+			"account":   []byte(account),
+			"key":       []byte(key),
+			"endpoint":  []byte(endpoint),
+		},
+	}
+}
+
+// EncryptionSecret contains the keys used to encrypt objects in object
+// storage, keyID is the key used for new objects. To rotate keys add a new key
+// to the secret, point "key-id" at it and restart pachd.
+func EncryptionSecret(keyID string, key []byte) *api.Secret {
+	return &api.Secret{
+		TypeMeta: unversioned.TypeMeta{
+			Kind:       "Secret",
+			APIVersion: "v1",
+		},
+		ObjectMeta: api.ObjectMeta{
+			Name:   encryptionSecretName,
+			Labels: labels(encryptionSecretName),
+		},
+		Data: map[string][]byte{
+			"key-id": []byte(keyID),
+			keyID:    key,
+		},
+	}
+}
+
 func RethinkVolume(backend backend, name string, size int) *api.PersistentVolume {
 	spec := &api.PersistentVolume{
 		TypeMeta: unversioned.TypeMeta{
@@ -520,7 +631,7 @@ func RethinkVolumeClaim(size int) *api.PersistentVolumeClaim {
 }
 
 // WriteAssets creates the assets in a dir. It expects dir to already exist.
-func WriteAssets(w io.Writer, shards uint64, backend backend, volumeName string, volumeSize int) {
+func WriteAssets(w io.Writer, shards uint64, backend backend, volumeName string, volumeSize int, encrypted bool) {
 	encoder := codec.NewEncoder(w, &codec.JsonHandle{Indent: 2})
 
 	ServiceAccount().CodecEncodeSelf(encoder)
@@ -548,26 +659,62 @@ func WriteAssets(w io.Writer, shards uint64, backend backend, volumeName string,
 
 	PachdService().CodecEncodeSelf(encoder)
 	fmt.Fprintf(w, "\n")
-	PachdRc(shards, backend).CodecEncodeSelf(encoder)
+	PachdRc(shards, backend, encrypted).CodecEncodeSelf(encoder)
 	fmt.Fprintf(w, "\n")
 }
 
 func WriteLocalAssets(w io.Writer, shards uint64) {
-	WriteAssets(w, shards, localBackend, "", 0)
+	WriteAssets(w, shards, localBackend, "", 0, false)
 }
 
-func WriteAmazonAssets(w io.Writer, shards uint64, bucket string, id string, secret string, token string, region string, volumeName string, volumeSize int) {
-	WriteAssets(w, shards, amazonBackend, volumeName, volumeSize)
+// WriteAmazonAssets writes the assets for a cluster backed by S3, objects are
+// encrypted with encryptionKey unless it's nil.
+func WriteAmazonAssets(w io.Writer, shards uint64, bucket string, id string, secret string, token string, region string, volumeName string, volumeSize int, encryptionKeyID string, encryptionKey []byte) {
+	WriteAssets(w, shards, amazonBackend, volumeName, volumeSize, encryptionKey != nil)
 	encoder := codec.NewEncoder(w, &codec.JsonHandle{Indent: 2})
 	AmazonSecret(bucket, id, secret, token, region).CodecEncodeSelf(encoder)
 	fmt.Fprintf(w, "\n")
+	writeEncryptionSecret(w, encryptionKeyID, encryptionKey)
 }
 
-func WriteGoogleAssets(w io.Writer, shards uint64, bucket string, volumeName string, volumeSize int) {
-	WriteAssets(w, shards, googleBackend, volumeName, volumeSize)
+// WriteGoogleAssets writes the assets for a cluster backed by GCS, objects are
+// encrypted with encryptionKey unless it's nil.
+func WriteGoogleAssets(w io.Writer, shards uint64, bucket string, volumeName string, volumeSize int, encryptionKeyID string, encryptionKey []byte) {
+	WriteAssets(w, shards, googleBackend, volumeName, volumeSize, encryptionKey != nil)
 	encoder := codec.NewEncoder(w, &codec.JsonHandle{Indent: 2})
 	GoogleSecret(bucket).CodecEncodeSelf(encoder)
 	fmt.Fprintf(w, "\n")
+	writeEncryptionSecret(w, encryptionKeyID, encryptionKey)
+}
+
+// WriteS3Assets writes the assets for a cluster backed by an S3-compatible
+// object store such as MinIO or Ceph RGW, objects are encrypted with
+// encryptionKey unless it's nil.
+func WriteS3Assets(w io.Writer, shards uint64, bucket string, id string, secret string, endpoint string, region string, secure bool, pathStyle bool, encryptionKeyID string, encryptionKey []byte) {
+	WriteAssets(w, shards, s3Backend, "", 0, encryptionKey != nil)
+	encoder := codec.NewEncoder(w, &codec.JsonHandle{Indent: 2})
+	S3Secret(bucket, id, secret, endpoint, region, secure, pathStyle).CodecEncodeSelf(encoder)
+	fmt.Fprintf(w, "\n")
+	writeEncryptionSecret(w, encryptionKeyID, encryptionKey)
+}
+
+// WriteAzureAssets writes the assets for a cluster backed by Azure Blob
+// Storage, objects are encrypted with encryptionKey unless it's nil.
+func WriteAzureAssets(w io.Writer, shards uint64, container string, account string, key string, endpoint string, encryptionKeyID string, encryptionKey []byte) {
+	WriteAssets(w, shards, azureBackend, "", 0, encryptionKey != nil)
+	encoder := codec.NewEncoder(w, &codec.JsonHandle{Indent: 2})
+	AzureSecret(container, account, key, endpoint).CodecEncodeSelf(encoder)
+	fmt.Fprintf(w, "\n")
+	writeEncryptionSecret(w, encryptionKeyID, encryptionKey)
+}
+
+func writeEncryptionSecret(w io.Writer, keyID string, key []byte) {
+	if key == nil {
+		return
+	}
+	encoder := codec.NewEncoder(w, &codec.JsonHandle{Indent: 2})
+	EncryptionSecret(keyID, key).CodecEncodeSelf(encoder)
+	fmt.Fprintf(w, "\n")
 }
 
 func labels(name string) map[string]string {
@@ -2,6 +2,7 @@ package cmds
 
 import (
 	"fmt"
+	"io/ioutil"
 	"os"
 	"strconv"
 
@@ -12,19 +13,34 @@ import (
 
 func DeployCmd() *cobra.Command {
 	var shards int
+	var encryptionKeyFile string
+	var encryptionKeyID string
+	var s3Region string
+	var s3Insecure bool
+	var s3PathStyle bool
+	var azureEndpoint string
 	cmd := &cobra.Command{
-		Use:   os.Args[0] + " [amazon bucket id secret token region [volume-name volume-size-in-GB] | google bucket [volume-name volume-size-in-GB]]",
+		Use:   os.Args[0] + " [amazon bucket id secret token region [volume-name volume-size-in-GB] | google bucket [volume-name volume-size-in-GB] | s3 bucket id secret endpoint | azure container account key]",
 		Short: "Print a kubernetes manifest for a Pachyderm cluster.",
 		Long:  "Print a kubernetes manifest for a Pachyderm cluster.",
 		Run: pkgcobra.RunBoundedArgs(pkgcobra.Bounds{Min: 0, Max: 8}, func(args []string) error {
 			if len(args) == 0 {
 				assets.WriteLocalAssets(os.Stdout, uint64(shards))
 			} else {
+				var encryptionKey []byte
+				if encryptionKeyFile != "" {
+					var err error
+					encryptionKey, err = ioutil.ReadFile(encryptionKeyFile)
+					if err != nil {
+						return err
+					}
+				}
 				var volumeName string
 				var volumeSize int
 				var err error
-				// If we are given volume name and size...
-				if len(args) == 8 || len(args) == 4 {
+				// If we are given volume name and size, only amazon and google
+				// take them
+				if (args[0] == "amazon" && len(args) == 8) || (args[0] == "google" && len(args) == 4) {
 					volumeName = args[len(args)-2]
 					volumeSize, err = strconv.Atoi(args[len(args)-1])
 					if err != nil {
@@ -36,17 +52,33 @@ func DeployCmd() *cobra.Command {
 					if len(args) != 6 && len(args) != 8 {
 						return fmt.Errorf("Expected 6 or 8 args, got %d", len(args))
 					}
-					assets.WriteAmazonAssets(os.Stdout, uint64(shards), args[1], args[2], args[3], args[4], args[5], volumeName, volumeSize)
+					assets.WriteAmazonAssets(os.Stdout, uint64(shards), args[1], args[2], args[3], args[4], args[5], volumeName, volumeSize, encryptionKeyID, encryptionKey)
 				case "google":
 					if len(args) != 2 && len(args) != 4 {
 						return fmt.Errorf("Expected 2 or 4 args, got %d", len(args))
 					}
-					assets.WriteGoogleAssets(os.Stdout, uint64(shards), args[1], volumeName, volumeSize)
+					assets.WriteGoogleAssets(os.Stdout, uint64(shards), args[1], volumeName, volumeSize, encryptionKeyID, encryptionKey)
+				case "s3":
+					if len(args) != 5 {
+						return fmt.Errorf("Expected 5 args, got %d", len(args))
+					}
+					assets.WriteS3Assets(os.Stdout, uint64(shards), args[1], args[2], args[3], args[4], s3Region, !s3Insecure, s3PathStyle, encryptionKeyID, encryptionKey)
+				case "azure":
+					if len(args) != 4 {
+						return fmt.Errorf("Expected 4 args, got %d", len(args))
+					}
+					assets.WriteAzureAssets(os.Stdout, uint64(shards), args[1], args[2], args[3], azureEndpoint, encryptionKeyID, encryptionKey)
 				}
 			}
 			return nil
 		}),
 	}
 	cmd.Flags().IntVarP(&shards, "shards", "s", 32, "The static number of shards for pfs.")
+	cmd.Flags().StringVar(&s3Region, "s3-region", "", "The region of an s3 object store, most S3-compatible stores don't need it.")
+	cmd.Flags().BoolVar(&s3Insecure, "s3-insecure", false, "Connect to an s3 object store over http instead of https.")
+	cmd.Flags().BoolVar(&s3PathStyle, "s3-path-style", true, "Address buckets in an s3 object store by path (http://endpoint/bucket) instead of by host (http://bucket.endpoint).")
+	cmd.Flags().StringVar(&azureEndpoint, "azure-endpoint", "", "The URL of an azure storage account's blob service, it's only needed outside the public Azure cloud, e.g. for the Azurite emulator.")
+	cmd.Flags().StringVar(&encryptionKeyFile, "encryption-key-file", "", "A file containing a 32 byte key used to encrypt data in object storage, data isn't encrypted if this isn't set. Encryption disables pachd's on disk block cache.")
+	cmd.Flags().StringVar(&encryptionKeyID, "encryption-key-id", "1", "The ID of the key in --encryption-key-file, it's stored with encrypted data to support key rotation.")
 	return cmd
 }
@@ -3,8 +3,10 @@ package obj
 import (
 	"fmt"
 	"io"
+	"net/http"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
@@ -18,10 +20,29 @@ type amazonClient struct {
 }
 
 func newAmazonClient(bucket string, id string, secret string, token string, region string) (*amazonClient, error) {
-	session := session.New(&aws.Config{
+	return newAmazonClientFromConfig(bucket, &aws.Config{
 		Credentials: credentials.NewStaticCredentials(id, secret, token),
 		Region:      aws.String(region),
 	})
+}
+
+func newS3Client(bucket string, id string, secret string, endpoint string, region string, secure bool, pathStyle bool) (*amazonClient, error) {
+	if region == "" {
+		// S3-compatible stores mostly ignore the region but requests have
+		// to be signed with one
+		region = "us-east-1"
+	}
+	return newAmazonClientFromConfig(bucket, &aws.Config{
+		Credentials:      credentials.NewStaticCredentials(id, secret, ""),
+		Endpoint:         aws.String(endpoint),
+		Region:           aws.String(region),
+		DisableSSL:       aws.Bool(!secure),
+		S3ForcePathStyle: aws.Bool(pathStyle),
+	})
+}
+
+func newAmazonClientFromConfig(bucket string, config *aws.Config) (*amazonClient, error) {
+	session := session.New(config)
 	return &amazonClient{
 		bucket:   bucket,
 		s3:       s3.New(session),
@@ -60,7 +81,8 @@ func (c *amazonClient) Reader(name string, offset uint64, size uint64) (io.ReadC
 	if size == 0 {
 		byteRange = fmt.Sprintf("bytes=%d-", offset)
 	} else {
-		byteRange = fmt.Sprintf("bytes=%d-%d", offset, offset+size)
+		// the end of an HTTP byte range is inclusive
+		byteRange = fmt.Sprintf("bytes=%d-%d", offset, offset+size-1)
 	}
 
 	getObjectOutput, err := c.s3.GetObject(&s3.GetObjectInput{
@@ -74,8 +96,34 @@ func (c *amazonClient) Reader(name string, offset uint64, size uint64) (io.ReadC
 	return newBackoffReadCloser(getObjectOutput.Body), nil
 }
 
+func (c *amazonClient) Inspect(name string) (*ObjectInfo, error) {
+	headObjectOutput, err := c.s3.HeadObject(&s3.HeadObjectInput{
+		Bucket: aws.String(c.bucket),
+		Key:    aws.String(name),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &ObjectInfo{
+		SizeBytes: uint64(aws.Int64Value(headObjectOutput.ContentLength)),
+		Modified:  aws.TimeValue(headObjectOutput.LastModified),
+	}, nil
+}
+
 func (c *amazonClient) Delete(name string) error {
-	return nil
+	_, err := c.s3.DeleteObject(&s3.DeleteObjectInput{
+		Bucket: aws.String(c.bucket),
+		Key:    aws.String(name),
+	})
+	return err
+}
+
+func (c *amazonClient) IsNotExist(err error) bool {
+	if requestFailure, ok := err.(awserr.RequestFailure); ok && requestFailure.StatusCode() == http.StatusNotFound {
+		return true
+	}
+	awsErr, ok := err.(awserr.Error)
+	return ok && awsErr.Code() == "NoSuchKey"
 }
 
 type amazonWriter struct {
@@ -0,0 +1,354 @@
+package obj
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	azureAPIVersion = "2015-02-21"
+	azureBlockSize  = 4 * 1024 * 1024
+)
+
+// azureClient talks to Azure Blob Storage through its REST API, objects are
+// block blobs in a container. Like localClient, names which differ only by a
+// leading "/" refer to the same object.
+type azureClient struct {
+	container string
+	account   string
+	key       []byte
+	// endpoint is the URL of the account's blob service, such as
+	// https://account.blob.core.windows.net or, for the Azurite emulator,
+	// http://127.0.0.1:10000/devstoreaccount1
+	endpoint   string
+	httpClient *http.Client
+	// blockSize is the size of the blocks that objects are uploaded in
+	blockSize int
+}
+
+func newAzureClient(container string, account string, key string, endpoint string) (*azureClient, error) {
+	decodedKey, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return nil, fmt.Errorf("azure account key isn't valid base64: %v", err)
+	}
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://%s.blob.core.windows.net", account)
+	}
+	return &azureClient{
+		container:  container,
+		account:    account,
+		key:        decodedKey,
+		endpoint:   strings.TrimSuffix(endpoint, "/"),
+		httpClient: &http.Client{},
+		blockSize:  azureBlockSize,
+	}, nil
+}
+
+func (c *azureClient) Writer(name string) (io.WriteCloser, error) {
+	return newBackoffWriteCloser(&azureWriter{client: c, name: name}), nil
+}
+
+func (c *azureClient) Walk(prefix string, fn func(name string) error) error {
+	marker := ""
+	for {
+		query := url.Values{
+			"restype": {"container"},
+			"comp":    {"list"},
+			"prefix":  {strings.TrimPrefix(prefix, "/")},
+		}
+		if marker != "" {
+			query.Set("marker", marker)
+		}
+		resp, err := c.do("GET", "", query, nil, nil, http.StatusOK)
+		if err != nil {
+			return err
+		}
+		var result azureEnumerationResults
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return err
+		}
+		for _, blob := range result.Blobs {
+			name := blob.Name
+			if strings.HasPrefix(prefix, "/") {
+				name = "/" + name
+			}
+			if err := fn(name); err != nil {
+				return err
+			}
+		}
+		if result.NextMarker == "" {
+			return nil
+		}
+		marker = result.NextMarker
+	}
+}
+
+func (c *azureClient) Reader(name string, offset uint64, size uint64) (io.ReadCloser, error) {
+	header := make(http.Header)
+	// no range can be satisfied for an empty blob, not even "bytes=0-", so
+	// reads of whole blobs don't send one
+	if size != 0 {
+		header.Set("x-ms-range", fmt.Sprintf("bytes=%d-%d", offset, offset+size-1))
+	} else if offset != 0 {
+		header.Set("x-ms-range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	resp, err := c.do("GET", name, nil, header, nil, http.StatusOK, http.StatusPartialContent, http.StatusRequestedRangeNotSatisfiable)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusRequestedRangeNotSatisfiable {
+		// the range starts at or past the end of the blob
+		resp.Body.Close()
+		return ioutil.NopCloser(bytes.NewReader(nil)), nil
+	}
+	return newBackoffReadCloser(resp.Body), nil
+}
+
+func (c *azureClient) Delete(name string) error {
+	resp, err := c.do("DELETE", name, nil, nil, nil, http.StatusAccepted)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+func (c *azureClient) IsNotExist(err error) bool {
+	statusErr, ok := err.(*azureStatusError)
+	return ok && statusErr.code == http.StatusNotFound
+}
+
+func (c *azureClient) Inspect(name string) (*ObjectInfo, error) {
+	resp, err := c.do("HEAD", name, nil, nil, nil, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	modified, err := http.ParseTime(resp.Header.Get("Last-Modified"))
+	if err != nil {
+		return nil, err
+	}
+	return &ObjectInfo{
+		SizeBytes: uint64(resp.ContentLength),
+		Modified:  modified,
+	}, nil
+}
+
+// do sends a request for the blob name, or for the container if name is "",
+// and returns the response if its status is one of okStatuses. The caller
+// must close the response's body.
+func (c *azureClient) do(method string, name string, query url.Values, header http.Header, body []byte, okStatuses ...int) (*http.Response, error) {
+	u, err := url.Parse(c.endpoint + "/" + c.container)
+	if err != nil {
+		return nil, err
+	}
+	if name != "" {
+		u.Path += "/" + strings.TrimPrefix(name, "/")
+	}
+	u.RawQuery = query.Encode()
+	req, err := http.NewRequest(method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.ContentLength = int64(len(body))
+	req.Header.Set("x-ms-date", time.Now().UTC().Format(http.TimeFormat))
+	req.Header.Set("x-ms-version", azureAPIVersion)
+	req.Header.Set("Authorization", fmt.Sprintf("SharedKey %s:%s", c.account, c.sign(req)))
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	for _, status := range okStatuses {
+		if resp.StatusCode == status {
+			return resp, nil
+		}
+	}
+	defer resp.Body.Close()
+	statusErr := &azureStatusError{
+		method: method,
+		path:   u.Path,
+		status: resp.Status,
+		code:   resp.StatusCode,
+	}
+	var azureErr azureError
+	if err := xml.NewDecoder(resp.Body).Decode(&azureErr); err == nil && azureErr.Code != "" {
+		statusErr.azureErr = &azureErr
+	}
+	return nil, statusErr
+}
+
+// sign returns the Shared Key signature of req, see
+// https://msdn.microsoft.com/en-us/library/azure/dd179428.aspx
+func (c *azureClient) sign(req *http.Request) string {
+	contentLength := ""
+	if req.ContentLength != 0 {
+		contentLength = strconv.FormatInt(req.ContentLength, 10)
+	}
+	var msHeaders []string
+	for key := range req.Header {
+		if key := strings.ToLower(key); strings.HasPrefix(key, "x-ms-") {
+			msHeaders = append(msHeaders, key)
+		}
+	}
+	sort.Strings(msHeaders)
+	var canonicalHeaders bytes.Buffer
+	for _, key := range msHeaders {
+		fmt.Fprintf(&canonicalHeaders, "%s:%s\n", key, strings.TrimSpace(req.Header.Get(key)))
+	}
+	// the emulator's endpoint includes the account so it appears twice
+	canonicalResource := "/" + c.account + req.URL.EscapedPath()
+	query := req.URL.Query()
+	var params []string
+	for key := range query {
+		params = append(params, key)
+	}
+	sort.Strings(params)
+	for _, key := range params {
+		values := query[key]
+		sort.Strings(values)
+		canonicalResource += fmt.Sprintf("\n%s:%s", strings.ToLower(key), strings.Join(values, ","))
+	}
+	stringToSign := strings.Join([]string{
+		req.Method,
+		req.Header.Get("Content-Encoding"),
+		req.Header.Get("Content-Language"),
+		contentLength,
+		req.Header.Get("Content-MD5"),
+		req.Header.Get("Content-Type"),
+		req.Header.Get("Date"),
+		req.Header.Get("If-Modified-Since"),
+		req.Header.Get("If-Match"),
+		req.Header.Get("If-None-Match"),
+		req.Header.Get("If-Unmodified-Since"),
+		req.Header.Get("Range"),
+		canonicalHeaders.String() + canonicalResource,
+	}, "\n")
+	mac := hmac.New(sha256.New, c.key)
+	mac.Write([]byte(stringToSign))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// azureWriter buffers writes and uploads them as blocks, the blocks are committed when it's closed. Objects which fit in a single
+// block are uploaded in one request.
+type azureWriter struct {
+	client   *azureClient
+	name     string
+	buffer   bytes.Buffer
+	blockIDs []string
+}
+
+func (w *azureWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		n := w.client.blockSize - w.buffer.Len()
+		if n > len(p) {
+			n = len(p)
+		}
+		w.buffer.Write(p[:n])
+		written += n
+		p = p[n:]
+		if w.buffer.Len() == w.client.blockSize {
+			if err := w.putBlock(); err != nil {
+				// the data stays buffered so the block is retried by the
+				// next Write or Close
+				return written, err
+			}
+		}
+	}
+	return written, nil
+}
+
+func (w *azureWriter) Close() error {
+	// If-None-Match makes the upload fail if the blob already exists
+	header := http.Header{"If-None-Match": {"*"}}
+	if len(w.blockIDs) == 0 {
+		header.Set("x-ms-blob-type", "BlockBlob")
+		resp, err := w.client.do("PUT", w.name, nil, header, w.buffer.Bytes(), http.StatusCreated)
+		if err != nil {
+			return err
+		}
+		return resp.Body.Close()
+	}
+	if w.buffer.Len() > 0 {
+		if err := w.putBlock(); err != nil {
+			return err
+		}
+	}
+	blockList := azureBlockList{Latest: w.blockIDs}
+	body, err := xml.Marshal(blockList)
+	if err != nil {
+		return err
+	}
+	resp, err := w.client.do("PUT", w.name, url.Values{"comp": {"blocklist"}}, header, body, http.StatusCreated)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+func (w *azureWriter) putBlock() error {
+	// block IDs must all be the same length
+	blockID := base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%016d", len(w.blockIDs))))
+	resp, err := w.client.do("PUT", w.name, url.Values{"comp": {"block"}, "blockid": {blockID}}, nil, w.buffer.Bytes(), http.StatusCreated)
+	if err != nil {
+		return err
+	}
+	if err := resp.Body.Close(); err != nil {
+		return err
+	}
+	w.blockIDs = append(w.blockIDs, blockID)
+	w.buffer.Reset()
+	return nil
+}
+
+type azureEnumerationResults struct {
+	Blobs      []azureBlob `xml:"Blobs>Blob"`
+	NextMarker string
+}
+
+type azureBlob struct {
+	Name string
+}
+
+type azureBlockList struct {
+	XMLName xml.Name `xml:"BlockList"`
+	Latest  []string
+}
+
+type azureError struct {
+	Code    string
+	Message string
+}
+
+// azureStatusError is returned by do when a response's status isn't one the
+// caller expected.
+type azureStatusError struct {
+	method   string
+	path     string
+	status   string
+	code     int
+	azureErr *azureError
+}
+
+func (e *azureStatusError) Error() string {
+	if e.azureErr != nil {
+		return fmt.Sprintf("azure %s %s: %s: %s", e.method, e.path, e.azureErr.Code, e.azureErr.Message)
+	}
+	return fmt.Sprintf("azure %s %s: %s", e.method, e.path, e.status)
+}
@@ -0,0 +1,207 @@
+package obj
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+)
+
+// TestAzureClient runs against Azure Blob Storage, by default it's a minimal
+// stand-in served by the test but it can be pointed at the Azurite emulator:
+//
+//	azurite-blob -l /tmp/azurite
+//	AZURE_TEST_ENDPOINT=http://127.0.0.1:10000/devstoreaccount1 AZURE_TEST_CONTAINER=test \
+//		AZURE_TEST_ACCOUNT=devstoreaccount1 AZURE_TEST_KEY=... go test
+//
+// The container has to exist.
+func TestAzureClient(t *testing.T) {
+	endpoint := os.Getenv("AZURE_TEST_ENDPOINT")
+	container := os.Getenv("AZURE_TEST_CONTAINER")
+	account := os.Getenv("AZURE_TEST_ACCOUNT")
+	key := os.Getenv("AZURE_TEST_KEY")
+	if endpoint == "" {
+		container, account, key = "test", "account", "a2V5"
+		client, err := newAzureClient(container, account, key, "")
+		require.NoError(t, err)
+		server := httptest.NewServer(newTestAzureServer(t, client))
+		defer server.Close()
+		endpoint = server.URL + "/" + account
+	}
+	client, err := newAzureClient(container, account, key, endpoint)
+	require.NoError(t, err)
+	testConformance(t, client, "test/")
+	testConformance(t, client, "/abs/test/")
+
+	// objects bigger than a block are uploaded in several blocks
+	client.blockSize = 4
+	writer, err := client.Writer("test/blocks")
+	require.NoError(t, err)
+	for _, s := range []string{"foo\n", "bar\nbu", "zz\n"} {
+		_, err = writer.Write([]byte(s))
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+	require.Equal(t, []byte("foo\nbar\nbuzz\n"), readObject(t, client, "test/blocks", 0, 0))
+	require.Equal(t, []byte("bar\n"), readObject(t, client, "test/blocks", 4, 4))
+	writer, err = client.Writer("test/blocks")
+	require.NoError(t, err)
+	_, err = writer.Write([]byte("overwritten"))
+	require.NoError(t, err)
+	require.YesError(t, writer.Close())
+	require.NoError(t, client.Delete("test/blocks"))
+}
+
+// testAzureServer implements just enough of the Blob service REST API for
+// TestAzureClient, it checks that requests are signed by client.
+type testAzureServer struct {
+	t      *testing.T
+	client *azureClient
+
+	lock    sync.Mutex
+	blobs   map[string][]byte
+	blocks  map[string]map[string][]byte
+	pageLen int
+}
+
+func newTestAzureServer(t *testing.T, client *azureClient) *testAzureServer {
+	return &testAzureServer{
+		t:      t,
+		client: client,
+		blobs:  make(map[string][]byte),
+		blocks: make(map[string]map[string][]byte),
+		// small pages so that listing has to follow markers
+		pageLen: 2,
+	}
+}
+
+type testEnumerationResults struct {
+	XMLName    xml.Name   `xml:"EnumerationResults"`
+	Blobs      []testBlob `xml:"Blobs>Blob"`
+	NextMarker string
+}
+
+type testBlob struct {
+	Name string
+}
+
+func (s *testAzureServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if r.Header.Get("Authorization") != fmt.Sprintf("SharedKey %s:%s", s.client.account, s.client.sign(r)) {
+		s.azureError(w, http.StatusForbidden, "AuthenticationFailed")
+		return
+	}
+	// the path is /account/container/blob
+	parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 3)
+	query := r.URL.Query()
+	if len(parts) == 2 {
+		var names []string
+		for name := range s.blobs {
+			if strings.HasPrefix(name, query.Get("prefix")) && name > query.Get("marker") {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		result := testEnumerationResults{}
+		for i, name := range names {
+			if i == s.pageLen {
+				result.NextMarker = names[i-1]
+				break
+			}
+			result.Blobs = append(result.Blobs, testBlob{name})
+		}
+		xml.NewEncoder(w).Encode(result)
+		return
+	}
+	name := parts[2]
+	data, ok := s.blobs[name]
+	switch r.Method {
+	case "PUT":
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if query.Get("comp") == "block" {
+			if s.blocks[name] == nil {
+				s.blocks[name] = make(map[string][]byte)
+			}
+			s.blocks[name][query.Get("blockid")] = body
+			w.WriteHeader(http.StatusCreated)
+			return
+		}
+		if ok && r.Header.Get("If-None-Match") == "*" {
+			s.azureError(w, http.StatusConflict, "BlobAlreadyExists")
+			return
+		}
+		if query.Get("comp") == "blocklist" {
+			var blockList azureBlockList
+			if err := xml.Unmarshal(body, &blockList); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			body = nil
+			for _, blockID := range blockList.Latest {
+				block, ok := s.blocks[name][blockID]
+				if !ok {
+					s.azureError(w, http.StatusBadRequest, "InvalidBlockList")
+					return
+				}
+				body = append(body, block...)
+			}
+			delete(s.blocks, name)
+		} else if r.Header.Get("x-ms-blob-type") != "BlockBlob" {
+			http.Error(w, "unsupported blob type", http.StatusBadRequest)
+			return
+		}
+		s.blobs[name] = body
+		w.WriteHeader(http.StatusCreated)
+	case "DELETE":
+		if !ok {
+			s.azureError(w, http.StatusNotFound, "BlobNotFound")
+			return
+		}
+		delete(s.blobs, name)
+		w.WriteHeader(http.StatusAccepted)
+	case "HEAD", "GET":
+		if !ok {
+			s.azureError(w, http.StatusNotFound, "BlobNotFound")
+			return
+		}
+		w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+		status := http.StatusOK
+		if byteRange := r.Header.Get("x-ms-range"); byteRange != "" {
+			start, end := 0, len(data)-1
+			fmt.Sscanf(byteRange, "bytes=%d-%d", &start, &end)
+			if start >= len(data) {
+				s.azureError(w, http.StatusRequestedRangeNotSatisfiable, "InvalidRange")
+				return
+			}
+			if end >= len(data) {
+				end = len(data) - 1
+			}
+			data = data[start : end+1]
+			status = http.StatusPartialContent
+		}
+		w.Header().Set("Content-Length", fmt.Sprint(len(data)))
+		w.WriteHeader(status)
+		if r.Method == "GET" {
+			w.Write(data)
+		}
+	}
+}
+
+func (s *testAzureServer) azureError(w http.ResponseWriter, status int, code string) {
+	w.WriteHeader(status)
+	fmt.Fprintf(w, "<Error><Code>%s</Code><Message>%s</Message></Error>", code, http.StatusText(status))
+}
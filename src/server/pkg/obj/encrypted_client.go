@@ -0,0 +1,405 @@
+package obj
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+const (
+	// encryptedMagic starts every object written by an encryptedClient.
+	encryptedMagic = "\x00pachenc"
+	// keySize is the size of both the master keys and the per object data
+	// keys, it selects AES-256.
+	keySize = 32
+	// defaultFrameSize is the amount of plaintext encrypted in each frame,
+	// ranged reads have to decrypt whole frames.
+	defaultFrameSize = 64 * 1024
+	// gcmOverhead is the size of the tag AES-GCM adds to each sealed message.
+	gcmOverhead = 16
+	// maxKeyIDSize is the longest key ID which fits in an object header.
+	maxKeyIDSize = 255
+	// maxHeaderSize is the size of a header with the longest possible key ID.
+	maxHeaderSize = len(encryptedMagic) + 1 + maxKeyIDSize + 12 + keySize + gcmOverhead + 4
+)
+
+// encryptedClient is an envelope encryption layer on top of another Client.
+// Every object is encrypted with its own random data key, the data key is
+// encrypted with a master key and stored in the object's header along with
+// the ID of the master key. The master key used for writing can be changed
+// (rotated) at any time, objects are read with whichever key they were
+// written with as long as it's still in keys.
+//
+// An object is laid out as:
+//
+//	magic | key ID length (1 byte) | key ID | data key nonce (12 bytes) |
+//	encrypted data key (48 bytes) | frame size (4 bytes) | frames...
+//
+// Each frame is frameSize bytes of plaintext sealed with AES-GCM under the
+// data key, using the frame's index as nonce. The last frame is always
+// shorter than frameSize (it may be empty), it's sealed with different
+// additional data so that truncation at a frame boundary is detected.
+//
+// Objects without a header are an error unless allowPlaintext is set, it's
+// for migrating a store which was written before encryption was turned on.
+// Otherwise anyone who can write to the store could replace objects without
+// knowing the keys.
+type encryptedClient struct {
+	client         Client
+	keys           map[string][]byte
+	keyID          string
+	frameSize      int
+	allowPlaintext bool
+}
+
+func newEncryptedClient(client Client, keys map[string][]byte, keyID string, frameSize int, allowPlaintext bool) (*encryptedClient, error) {
+	if _, ok := keys[keyID]; !ok {
+		return nil, fmt.Errorf("encryption key %s not found", keyID)
+	}
+	if len(keyID) == 0 || len(keyID) > maxKeyIDSize {
+		return nil, fmt.Errorf("encryption key ID must be between 1 and %d bytes", maxKeyIDSize)
+	}
+	for id, key := range keys {
+		if len(key) != keySize {
+			return nil, fmt.Errorf("encryption key %s is %d bytes, should be %d", id, len(key), keySize)
+		}
+	}
+	if frameSize <= 0 {
+		return nil, fmt.Errorf("invalid frame size %d", frameSize)
+	}
+	return &encryptedClient{
+		client:         client,
+		keys:           keys,
+		keyID:          keyID,
+		frameSize:      frameSize,
+		allowPlaintext: allowPlaintext,
+	}, nil
+}
+
+func (c *encryptedClient) Writer(name string) (io.WriteCloser, error) {
+	dataKey := make([]byte, keySize)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return nil, err
+	}
+	keyNonce := make([]byte, 12)
+	if _, err := io.ReadFull(rand.Reader, keyNonce); err != nil {
+		return nil, err
+	}
+	masterAEAD, err := newAEAD(c.keys[c.keyID])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	var header bytes.Buffer
+	header.WriteString(encryptedMagic)
+	header.WriteByte(byte(len(c.keyID)))
+	header.WriteString(c.keyID)
+	header.Write(keyNonce)
+	header.Write(masterAEAD.Seal(nil, keyNonce, dataKey, []byte(c.keyID)))
+	if err := binary.Write(&header, binary.BigEndian, uint32(c.frameSize)); err != nil {
+		return nil, err
+	}
+	writer, err := c.client.Writer(name)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := writer.Write(header.Bytes()); err != nil {
+		writer.Close()
+		return nil, err
+	}
+	return &encryptedWriter{
+		writer:    writer,
+		aead:      aead,
+		frameSize: c.frameSize,
+	}, nil
+}
+
+func (c *encryptedClient) Reader(name string, offset uint64, size uint64) (io.ReadCloser, error) {
+	headerReader, err := c.client.Reader(name, 0, uint64(maxHeaderSize))
+	if err != nil {
+		return nil, err
+	}
+	header, err := readEncryptedHeader(headerReader)
+	if err := headerReader.Close(); err != nil {
+		return nil, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	if header == nil {
+		if !c.allowPlaintext {
+			return nil, fmt.Errorf("object %s isn't encrypted", name)
+		}
+		// objects which were written before encryption was turned on are
+		// read as they are
+		return c.client.Reader(name, offset, size)
+	}
+	key, ok := c.keys[header.keyID]
+	if !ok {
+		return nil, fmt.Errorf("encryption key %s for %s not found", header.keyID, name)
+	}
+	masterAEAD, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	dataKey, err := masterAEAD.Open(nil, header.keyNonce, header.encryptedKey, []byte(header.keyID))
+	if err != nil {
+		return nil, fmt.Errorf("couldn't decrypt data key for %s: %v", name, err)
+	}
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	frameSize := uint64(header.frameSize)
+	sealedFrameSize := frameSize + uint64(aead.Overhead())
+	firstFrame := offset / frameSize
+	var frames uint64
+	var sealedSize uint64
+	if size != 0 {
+		frames = (offset+size-1)/frameSize - firstFrame + 1
+		sealedSize = frames * sealedFrameSize
+	}
+	reader, err := c.client.Reader(name, uint64(header.size)+firstFrame*sealedFrameSize, sealedSize)
+	if err != nil {
+		return nil, err
+	}
+	result := &encryptedReader{
+		reader:     reader,
+		aead:       aead,
+		sealedSize: int(sealedFrameSize),
+		frame:      firstFrame,
+		frames:     frames,
+		skip:       int(offset % frameSize),
+	}
+	if size != 0 {
+		return &limitReadCloser{io.LimitReader(result, int64(size)), result}, nil
+	}
+	return result, nil
+}
+
+// Inspect returns the size of the decrypted object.
+func (c *encryptedClient) Inspect(name string) (*ObjectInfo, error) {
+	objectInfo, err := c.client.Inspect(name)
+	if err != nil {
+		return nil, err
+	}
+	headerReader, err := c.client.Reader(name, 0, uint64(maxHeaderSize))
+	if err != nil {
+		return nil, err
+	}
+	header, err := readEncryptedHeader(headerReader)
+	if err := headerReader.Close(); err != nil {
+		return nil, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	if header == nil {
+		if !c.allowPlaintext {
+			return nil, fmt.Errorf("object %s isn't encrypted", name)
+		}
+		return objectInfo, nil
+	}
+	// every frame but the last is full and the last one is never full
+	sealedSize := objectInfo.SizeBytes - uint64(header.size)
+	sealedFrameSize := uint64(header.frameSize) + gcmOverhead
+	frames := sealedSize/sealedFrameSize + 1
+	if sealedSize < frames*gcmOverhead {
+		return nil, fmt.Errorf("encrypted object %s is truncated", name)
+	}
+	objectInfo.SizeBytes = sealedSize - frames*gcmOverhead
+	return objectInfo, nil
+}
+
+func (c *encryptedClient) Delete(name string) error {
+	return c.client.Delete(name)
+}
+
+func (c *encryptedClient) IsNotExist(err error) bool {
+	return c.client.IsNotExist(err)
+}
+
+func (c *encryptedClient) Walk(prefix string, fn func(name string) error) error {
+	return c.client.Walk(prefix, fn)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// frameNonce returns the nonce for a frame, data keys are never reused so
+// the frame's index is enough to make it unique.
+func frameNonce(frame uint64) []byte {
+	nonce := make([]byte, 12)
+	binary.BigEndian.PutUint64(nonce, frame)
+	return nonce
+}
+
+// frameAdditionalData distinguishes the last frame of an object from the
+// others.
+func frameAdditionalData(last bool) []byte {
+	if last {
+		return []byte{1}
+	}
+	return []byte{0}
+}
+
+type encryptedHeader struct {
+	keyID        string
+	keyNonce     []byte
+	encryptedKey []byte
+	frameSize    uint32
+	// size is the size of the header itself
+	size int
+}
+
+// readEncryptedHeader reads an object header from reader, it returns nil if
+// the object isn't encrypted.
+func readEncryptedHeader(reader io.Reader) (*encryptedHeader, error) {
+	data := make([]byte, maxHeaderSize)
+	n, err := io.ReadFull(reader, data)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, err
+	}
+	data = data[:n]
+	if !bytes.HasPrefix(data, []byte(encryptedMagic)) {
+		return nil, nil
+	}
+	data = data[len(encryptedMagic):]
+	if len(data) < 1 || len(data) < 1+int(data[0])+12+keySize+gcmOverhead+4 {
+		return nil, fmt.Errorf("truncated encryption header")
+	}
+	result := &encryptedHeader{}
+	keyIDSize := int(data[0])
+	data = data[1:]
+	result.keyID, data = string(data[:keyIDSize]), data[keyIDSize:]
+	result.keyNonce, data = data[:12], data[12:]
+	result.encryptedKey, data = data[:keySize+gcmOverhead], data[keySize+gcmOverhead:]
+	result.frameSize = binary.BigEndian.Uint32(data)
+	if result.frameSize == 0 {
+		return nil, fmt.Errorf("invalid frame size 0 in encryption header")
+	}
+	result.size = len(encryptedMagic) + 1 + keyIDSize + 12 + keySize + gcmOverhead + 4
+	return result, nil
+}
+
+type encryptedWriter struct {
+	writer    io.WriteCloser
+	aead      cipher.AEAD
+	frameSize int
+	frame     uint64
+	buffer    []byte
+}
+
+func (w *encryptedWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		n := w.frameSize - len(w.buffer)
+		if n > len(p) {
+			n = len(p)
+		}
+		w.buffer = append(w.buffer, p[:n]...)
+		p = p[n:]
+		written += n
+		if len(w.buffer) == w.frameSize {
+			if err := w.writeFrame(false); err != nil {
+				return written, err
+			}
+		}
+	}
+	return written, nil
+}
+
+func (w *encryptedWriter) Close() error {
+	// the buffer always holds less than a full frame here
+	if err := w.writeFrame(true); err != nil {
+		w.writer.Close()
+		return err
+	}
+	return w.writer.Close()
+}
+
+func (w *encryptedWriter) writeFrame(last bool) error {
+	sealed := w.aead.Seal(nil, frameNonce(w.frame), w.buffer, frameAdditionalData(last))
+	w.frame++
+	w.buffer = w.buffer[:0]
+	_, err := w.writer.Write(sealed)
+	return err
+}
+
+type encryptedReader struct {
+	reader     io.ReadCloser
+	aead       cipher.AEAD
+	sealedSize int
+	frame      uint64
+	// frames is the number of frames left to read, 0 means read until the
+	// last frame
+	frames uint64
+	// skip is the number of bytes to drop from the beginning of the next
+	// frame
+	skip   int
+	buffer []byte
+	done   bool
+}
+
+func (r *encryptedReader) Read(p []byte) (int, error) {
+	for len(r.buffer) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if err := r.readFrame(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.buffer)
+	r.buffer = r.buffer[n:]
+	return n, nil
+}
+
+func (r *encryptedReader) readFrame() error {
+	sealed := make([]byte, r.sealedSize)
+	n, err := io.ReadFull(r.reader, sealed)
+	if err != nil && err != io.ErrUnexpectedEOF && !(err == io.EOF && r.frames != 0) {
+		if err == io.EOF {
+			return fmt.Errorf("encrypted object is truncated or offset is past its end")
+		}
+		return err
+	}
+	if n == 0 {
+		// only reachable when reading a range past the end of the object
+		r.done = true
+		return nil
+	}
+	last := n < r.sealedSize
+	data, err := r.aead.Open(nil, frameNonce(r.frame), sealed[:n], frameAdditionalData(last))
+	if err != nil {
+		return fmt.Errorf("couldn't decrypt frame %d: %v", r.frame, err)
+	}
+	r.frame++
+	if r.frames != 0 {
+		r.frames--
+		r.done = r.frames == 0
+	}
+	r.done = r.done || last
+	if r.skip > len(data) {
+		r.skip = len(data)
+	}
+	r.buffer = data[r.skip:]
+	r.skip = 0
+	return nil
+}
+
+func (r *encryptedReader) Close() error {
+	return r.reader.Close()
+}
@@ -0,0 +1,129 @@
+package obj
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+)
+
+func testKey(seed int64) []byte {
+	key := make([]byte, keySize)
+	rand.New(rand.NewSource(seed)).Read(key)
+	return key
+}
+
+func writeObject(t *testing.T, client Client, name string, data []byte) {
+	writer, err := client.Writer(name)
+	require.NoError(t, err)
+	_, err = writer.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+}
+
+func readObject(t *testing.T, client Client, name string, offset uint64, size uint64) []byte {
+	reader, err := client.Reader(name, offset, size)
+	require.NoError(t, err)
+	data, err := ioutil.ReadAll(reader)
+	require.NoError(t, err)
+	require.NoError(t, reader.Close())
+	return data
+}
+
+func TestEncryptedClient(t *testing.T) {
+	backend := newMemClient()
+	client, err := newEncryptedClient(backend, map[string][]byte{"1": testKey(1)}, "1", 100, false)
+	require.NoError(t, err)
+	for _, n := range []int{0, 1, 99, 100, 101, 1000} {
+		data := make([]byte, n)
+		rand.New(rand.NewSource(int64(n))).Read(data)
+		name := fmt.Sprintf("object-%d", n)
+		writeObject(t, client, name, data)
+		require.False(t, n > 8 && bytes.Contains(backend.objects[name].data, data[:8]))
+		require.Equal(t, data, readObject(t, client, name, 0, 0))
+		objectInfo, err := client.Inspect(name)
+		require.NoError(t, err)
+		require.Equal(t, uint64(n), objectInfo.SizeBytes)
+		for _, r := range [][2]int{{0, 1}, {50, 100}, {99, 2}, {100, 100}, {150, 0}, {999, 1}, {1200, 5}} {
+			offset, size := r[0], r[1]
+			if size == 0 && offset > n {
+				continue
+			}
+			expected := data[min(offset, n):]
+			if size != 0 {
+				expected = expected[:min(size, len(expected))]
+			}
+			require.Equal(t, expected, readObject(t, client, name, uint64(offset), uint64(size)))
+		}
+	}
+}
+
+func TestEncryptedClientKeyRotation(t *testing.T) {
+	backend := newMemClient()
+	client, err := newEncryptedClient(backend, map[string][]byte{"1": testKey(1)}, "1", 100, false)
+	require.NoError(t, err)
+	writeObject(t, client, "old", []byte("foo"))
+	rotated, err := newEncryptedClient(backend, map[string][]byte{"1": testKey(1), "2": testKey(2)}, "2", 100, false)
+	require.NoError(t, err)
+	writeObject(t, rotated, "new", []byte("bar"))
+	require.Equal(t, []byte("foo"), readObject(t, rotated, "old", 0, 0))
+	require.Equal(t, []byte("bar"), readObject(t, rotated, "new", 0, 0))
+	_, err = client.Reader("new", 0, 0)
+	require.YesError(t, err)
+}
+
+func TestEncryptedClientUnencrypted(t *testing.T) {
+	backend := newMemClient()
+	writeObject(t, backend, "plain", []byte("foo bar"))
+	client, err := newEncryptedClient(backend, map[string][]byte{"1": testKey(1)}, "1", 100, false)
+	require.NoError(t, err)
+	_, err = client.Reader("plain", 0, 0)
+	require.YesError(t, err)
+	_, err = client.Inspect("plain")
+	require.YesError(t, err)
+	// unless they're being migrated
+	client, err = newEncryptedClient(backend, map[string][]byte{"1": testKey(1)}, "1", 100, true)
+	require.NoError(t, err)
+	require.Equal(t, []byte("bar"), readObject(t, client, "plain", 4, 0))
+}
+
+func TestEncryptedClientTampering(t *testing.T) {
+	backend := newMemClient()
+	client, err := newEncryptedClient(backend, map[string][]byte{"1": testKey(1)}, "1", 100, false)
+	require.NoError(t, err)
+	writeObject(t, client, "object", make([]byte, 250))
+	object := backend.objects["object"].data
+	// truncate at a frame boundary
+	backend.objects["object"].data = object[:len(object)-(250-200+16)]
+	_, err = ioutil.ReadAll(mustReader(t, client, "object"))
+	require.YesError(t, err)
+	// flip a bit
+	backend.objects["object"].data = append([]byte{}, object...)
+	backend.objects["object"].data[len(object)-1] ^= 1
+	_, err = ioutil.ReadAll(mustReader(t, client, "object"))
+	require.YesError(t, err)
+}
+
+func TestNewEncryptedClient(t *testing.T) {
+	_, err := NewEncryptedClient(newMemClient(), map[string][]byte{"1": testKey(1)}, "2", false)
+	require.YesError(t, err)
+	_, err = NewEncryptedClient(newMemClient(), map[string][]byte{"1": []byte("short")}, "1", false)
+	require.YesError(t, err)
+}
+
+func mustReader(t *testing.T, client Client, name string) io.ReadCloser {
+	reader, err := client.Reader(name, 0, 0)
+	require.NoError(t, err)
+	return reader
+}
+
+func min(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -2,9 +2,11 @@ package obj
 
 import (
 	"io"
+	"net/http"
 
 	"golang.org/x/net/context"
 	"golang.org/x/oauth2/google"
+	"google.golang.org/api/googleapi"
 	"google.golang.org/cloud"
 	"google.golang.org/cloud/storage"
 )
@@ -62,6 +64,25 @@ func (c *googleClient) Reader(name string, offset uint64, size uint64) (io.ReadC
 	return newBackoffReadCloser(reader), nil
 }
 
+func (c *googleClient) Inspect(name string) (*ObjectInfo, error) {
+	objectAttrs, err := c.bucket.Object(name).Attrs(c.ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &ObjectInfo{
+		SizeBytes: uint64(objectAttrs.Size),
+		Modified:  objectAttrs.Updated,
+	}, nil
+}
+
 func (c *googleClient) Delete(name string) error {
 	return c.bucket.Object(name).Delete(c.ctx)
 }
+
+func (c *googleClient) IsNotExist(err error) bool {
+	if err == storage.ErrObjectNotExist {
+		return true
+	}
+	apiErr, ok := err.(*googleapi.Error)
+	return ok && apiErr.Code == http.StatusNotFound
+}
@@ -0,0 +1,165 @@
+package obj
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// httpClient reads objects from plain http(s) servers, objects are named by
+// their URL. It can't write, delete or list objects.
+type httpClient struct {
+	client *http.Client
+}
+
+func newHTTPClient(client *http.Client) *httpClient {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &httpClient{client}
+}
+
+func (c *httpClient) Writer(name string) (io.WriteCloser, error) {
+	return nil, fmt.Errorf("http objects are read only: %s", name)
+}
+
+func (c *httpClient) Reader(name string, offset uint64, size uint64) (io.ReadCloser, error) {
+	request, err := http.NewRequest("GET", name, nil)
+	if err != nil {
+		return nil, err
+	}
+	if offset != 0 || size != 0 {
+		if size == 0 {
+			request.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		} else {
+			// the end of an HTTP byte range is inclusive
+			request.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", offset, offset+size-1))
+		}
+	}
+	response, err := c.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	switch response.StatusCode {
+	case http.StatusPartialContent:
+		return response.Body, nil
+	case http.StatusOK:
+		// the server doesn't support ranges and sent the whole object, so
+		// the range is cut out of it
+		if _, err := io.CopyN(ioutil.Discard, response.Body, int64(offset)); err != nil && err != io.EOF {
+			response.Body.Close()
+			return nil, err
+		}
+		if size != 0 {
+			return &limitReadCloser{io.LimitReader(response.Body, int64(size)), response.Body}, nil
+		}
+		return response.Body, nil
+	case http.StatusRequestedRangeNotSatisfiable:
+		// offset is at (or past) the end of the object
+		response.Body.Close()
+		return ioutil.NopCloser(strings.NewReader("")), nil
+	default:
+		response.Body.Close()
+		return nil, &httpStatusError{"GET", name, response}
+	}
+}
+
+func (c *httpClient) Delete(name string) error {
+	return fmt.Errorf("http objects are read only: %s", name)
+}
+
+func (c *httpClient) Walk(prefix string, fn func(name string) error) error {
+	return fmt.Errorf("http objects can't be listed: %s", prefix)
+}
+
+func (c *httpClient) Inspect(name string) (*ObjectInfo, error) {
+	response, err := c.client.Head(name)
+	if err != nil {
+		return nil, err
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusOK || response.ContentLength < 0 {
+		// some URLs, such as presigned ones, only allow GETs
+		return c.inspectGet(name)
+	}
+	return &ObjectInfo{
+		SizeBytes: uint64(response.ContentLength),
+		Modified:  lastModified(response),
+	}, nil
+}
+
+// inspectGet inspects name with a GET of its first byte, the object's size
+// is in the Content-Range of the response, or its Content-Length if the
+// server ignores the range.
+func (c *httpClient) inspectGet(name string) (*ObjectInfo, error) {
+	request, err := http.NewRequest("GET", name, nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Range", "bytes=0-0")
+	response, err := c.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	// the body isn't read so a server which ignored the range doesn't send
+	// the whole object
+	response.Body.Close()
+	var size int64
+	switch response.StatusCode {
+	case http.StatusPartialContent:
+		contentRange := response.Header.Get("Content-Range")
+		i := strings.LastIndex(contentRange, "/")
+		if i == -1 {
+			return nil, fmt.Errorf("GET %s: invalid Content-Range %q", name, contentRange)
+		}
+		size, err = strconv.ParseInt(contentRange[i+1:], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("GET %s: invalid Content-Range %q", name, contentRange)
+		}
+	case http.StatusOK:
+		size = response.ContentLength
+	case http.StatusRequestedRangeNotSatisfiable:
+		// only an empty object can't satisfy the first byte
+		size = 0
+	default:
+		return nil, &httpStatusError{"GET", name, response}
+	}
+	if size < 0 {
+		return nil, fmt.Errorf("GET %s: the server didn't send the object's size", name)
+	}
+	return &ObjectInfo{
+		SizeBytes: uint64(size),
+		Modified:  lastModified(response),
+	}, nil
+}
+
+// lastModified returns the Last-Modified time of response, or the zero time
+// if it has none.
+func lastModified(response *http.Response) time.Time {
+	modified, err := http.ParseTime(response.Header.Get("Last-Modified"))
+	if err != nil {
+		return time.Time{}
+	}
+	return modified
+}
+
+func (c *httpClient) IsNotExist(err error) bool {
+	statusErr, ok := err.(*httpStatusError)
+	return ok && statusErr.response.StatusCode == http.StatusNotFound
+}
+
+// httpStatusError is returned when a server responds with an unexpected
+// status.
+type httpStatusError struct {
+	method   string
+	name     string
+	response *http.Response
+}
+
+func (e *httpStatusError) Error() string {
+	return fmt.Sprintf("%s %s: %s", e.method, e.name, e.response.Status)
+}
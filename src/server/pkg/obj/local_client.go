@@ -0,0 +1,135 @@
+package obj
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// localClient stores objects as files under a directory, an object's name is
+// its path relative to the directory. Names which differ only by a leading
+// "/" refer to the same object.
+type localClient struct {
+	dir string
+}
+
+func newLocalClient(dir string) (*localClient, error) {
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return nil, err
+	}
+	return &localClient{dir}, nil
+}
+
+func (c *localClient) Writer(name string) (io.WriteCloser, error) {
+	if _, err := os.Stat(c.path(name)); err == nil {
+		return nil, fmt.Errorf("object %s already exists", name)
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path(name)), 0777); err != nil {
+		return nil, err
+	}
+	// objects are written to a temporary file and linked into place on Close
+	// so that readers never see partial objects
+	file, err := ioutil.TempFile(filepath.Dir(c.path(name)), ".tmp")
+	if err != nil {
+		return nil, err
+	}
+	return &localWriter{file, c.path(name), name}, nil
+}
+
+func (c *localClient) Reader(name string, offset uint64, size uint64) (io.ReadCloser, error) {
+	file, err := os.Open(c.path(name))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := file.Seek(int64(offset), 0); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if size == 0 {
+		return file, nil
+	}
+	return &limitReadCloser{io.LimitReader(file, int64(size)), file}, nil
+}
+
+func (c *localClient) Delete(name string) error {
+	return os.Remove(c.path(name))
+}
+
+func (c *localClient) Walk(prefix string, fn func(name string) error) error {
+	// walk the deepest directory which contains everything under prefix
+	root := c.path(prefix)
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		root = filepath.Dir(root)
+	}
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if info.IsDir() || strings.HasPrefix(info.Name(), ".tmp") {
+			return nil
+		}
+		name, err := filepath.Rel(c.dir, path)
+		if err != nil {
+			return err
+		}
+		name = filepath.ToSlash(name)
+		if strings.HasPrefix(prefix, "/") {
+			name = "/" + name
+		}
+		if !strings.HasPrefix(name, prefix) {
+			return nil
+		}
+		return fn(name)
+	})
+}
+
+func (c *localClient) Inspect(name string) (*ObjectInfo, error) {
+	stat, err := os.Stat(c.path(name))
+	if err != nil {
+		return nil, err
+	}
+	return &ObjectInfo{
+		SizeBytes: uint64(stat.Size()),
+		Modified:  stat.ModTime(),
+	}, nil
+}
+
+func (c *localClient) IsNotExist(err error) bool {
+	return os.IsNotExist(err)
+}
+
+func (c *localClient) path(name string) string {
+	return filepath.Join(c.dir, filepath.FromSlash(strings.TrimPrefix(name, "/")))
+}
+
+type localWriter struct {
+	file *os.File
+	path string
+	name string
+}
+
+func (w *localWriter) Write(p []byte) (int, error) {
+	return w.file.Write(p)
+}
+
+func (w *localWriter) Close() error {
+	defer os.Remove(w.file.Name())
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+	// unlike rename, link fails if the object was created since Writer was
+	// called
+	if err := os.Link(w.file.Name(), w.path); err != nil {
+		if os.IsExist(err) {
+			return fmt.Errorf("object %s already exists", w.name)
+		}
+		return err
+	}
+	return nil
+}
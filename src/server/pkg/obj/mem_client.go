@@ -0,0 +1,129 @@
+package obj
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// memClient stores objects in memory.
+type memClient struct {
+	lock    sync.Mutex
+	objects map[string]*memObject
+}
+
+type memObject struct {
+	data     []byte
+	modified time.Time
+}
+
+func newMemClient() *memClient {
+	return &memClient{objects: make(map[string]*memObject)}
+}
+
+func (c *memClient) Writer(name string) (io.WriteCloser, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if _, ok := c.objects[name]; ok {
+		return nil, fmt.Errorf("object %s already exists", name)
+	}
+	return &memWriter{client: c, name: name}, nil
+}
+
+func (c *memClient) Reader(name string, offset uint64, size uint64) (io.ReadCloser, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	object, ok := c.objects[name]
+	if !ok {
+		return nil, memNotExistError(name)
+	}
+	data := object.data
+	if offset > uint64(len(data)) {
+		offset = uint64(len(data))
+	}
+	data = data[offset:]
+	if size != 0 && size < uint64(len(data)) {
+		data = data[:size]
+	}
+	return ioutil.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (c *memClient) Delete(name string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if _, ok := c.objects[name]; !ok {
+		return memNotExistError(name)
+	}
+	delete(c.objects, name)
+	return nil
+}
+
+func (c *memClient) Walk(prefix string, fn func(name string) error) error {
+	// fn may call back into c so it's called without the lock held
+	c.lock.Lock()
+	var names []string
+	for name := range c.objects {
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+	c.lock.Unlock()
+	sort.Strings(names)
+	for _, name := range names {
+		if err := fn(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *memClient) Inspect(name string) (*ObjectInfo, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	object, ok := c.objects[name]
+	if !ok {
+		return nil, memNotExistError(name)
+	}
+	return &ObjectInfo{
+		SizeBytes: uint64(len(object.data)),
+		Modified:  object.modified,
+	}, nil
+}
+
+func (c *memClient) IsNotExist(err error) bool {
+	_, ok := err.(memNotExistError)
+	return ok
+}
+
+// memNotExistError is returned for objects which don't exist, it holds the
+// object's name.
+type memNotExistError string
+
+func (e memNotExistError) Error() string {
+	return fmt.Sprintf("object %s not found", string(e))
+}
+
+type memWriter struct {
+	client *memClient
+	name   string
+	buffer bytes.Buffer
+}
+
+func (w *memWriter) Write(p []byte) (int, error) {
+	return w.buffer.Write(p)
+}
+
+func (w *memWriter) Close() error {
+	w.client.lock.Lock()
+	defer w.client.lock.Unlock()
+	if _, ok := w.client.objects[w.name]; ok {
+		return fmt.Errorf("object %s already exists", w.name)
+	}
+	w.client.objects[w.name] = &memObject{w.buffer.Bytes(), time.Now()}
+	return nil
+}
@@ -2,6 +2,7 @@ package obj
 
 import (
 	"io"
+	"net/http"
 	"time"
 
 	"github.com/cenkalti/backoff"
@@ -25,6 +26,22 @@ type Client interface {
 	Delete(name string) error
 	// Walk calls `fn` with the names of objects which can be found under `prefix`.
 	Walk(prefix string, fn func(name string) error) error
+	// Inspect returns info about an object.
+	// It should error if the object doesn't exist or we don't have sufficient
+	// permission to read it.
+	Inspect(name string) (*ObjectInfo, error)
+	// IsNotExist returns true if err is an error returned by one of the
+	// other methods because the object doesn't exist.
+	IsNotExist(err error) bool
+}
+
+// ObjectInfo is info about an object.
+type ObjectInfo struct {
+	// SizeBytes is the size of the object's content.
+	SizeBytes uint64
+	// Modified is when the object was last written, objects are immutable so
+	// this is when the object was created unless it was overwritten.
+	Modified time.Time
 }
 
 func NewGoogleClient(ctx context.Context, bucket string) (Client, error) {
@@ -36,6 +53,55 @@ func NewAmazonClient(bucket string, id string, secret string, token string,
 	return newAmazonClient(bucket, id, secret, token, region)
 }
 
+// NewS3Client returns a Client for an S3-compatible object store (such as
+// MinIO or Ceph RGW) at endpoint, a host with an optional port. secure selects
+// https over http and pathStyle puts the bucket in the path of requests rather
+// than in the host name, most on premises stores need it. region may be "".
+func NewS3Client(bucket string, id string, secret string, endpoint string,
+	region string, secure bool, pathStyle bool) (Client, error) {
+	return newS3Client(bucket, id, secret, endpoint, region, secure, pathStyle)
+}
+
+// NewAzureClient returns a Client for the Azure Blob Storage container in
+// account, key is the account's base64 encoded access key. endpoint is the URL
+// of the account's blob service, if it's "" the public Azure endpoint is used.
+// For the Azurite emulator it's http://127.0.0.1:10000/devstoreaccount1.
+func NewAzureClient(container string, account string, key string, endpoint string) (Client, error) {
+	return newAzureClient(container, account, key, endpoint)
+}
+
+// NewLocalClient returns a Client which stores objects as files under dir.
+func NewLocalClient(dir string) (Client, error) {
+	return newLocalClient(dir)
+}
+
+// NewMemClient returns a Client which stores objects in memory.
+func NewMemClient() Client {
+	return newMemClient()
+}
+
+// NewHTTPClient returns a read only Client for objects served over http(s),
+// objects are named by their URL and read with range requests. If client is
+// nil http.DefaultClient is used.
+func NewHTTPClient(client *http.Client) Client {
+	return newHTTPClient(client)
+}
+
+// NewEncryptedClient returns a Client which encrypts objects written to client
+// with the key keys[keyID] and decrypts objects read from it with whichever
+// key in keys they were written with. Keys must be 32 bytes. Reading objects
+// in client which weren't written encrypted is an error unless allowPlaintext
+// is set, in which case they're read unmodified.
+func NewEncryptedClient(client Client, keys map[string][]byte, keyID string, allowPlaintext bool) (Client, error) {
+	return newEncryptedClient(client, keys, keyID, defaultFrameSize, allowPlaintext)
+}
+
+// IsEncrypted returns true if client was returned by NewEncryptedClient.
+func IsEncrypted(client Client) bool {
+	_, ok := client.(*encryptedClient)
+	return ok
+}
+
 func newExponentialBackOffConfig() *backoff.ExponentialBackOff {
 	config := backoff.NewExponentialBackOff()
 	config.MaxElapsedTime = 5 * time.Minute
@@ -57,16 +123,23 @@ func newBackoffReadCloser(reader io.ReadCloser) io.ReadCloser {
 
 func (b *BackoffReadCloser) Read(data []byte) (int, error) {
 	bytesRead := 0
+	eof := false
 	err := backoff.Retry(func() error {
-		if n, err := b.reader.Read(data[bytesRead:]); err != nil {
-			bytesRead += n
-			if bytesRead == len(data) {
-				return nil
-			}
-			return err
+		n, err := b.reader.Read(data[bytesRead:])
+		bytesRead += n
+		if err == io.EOF {
+			// EOF isn't a failure, retrying it would never succeed
+			eof = true
+			return nil
 		}
-		return nil
+		if err != nil && bytesRead == len(data) {
+			return nil
+		}
+		return err
 	}, b.backoffConfig)
+	if err == nil && eof {
+		return bytesRead, io.EOF
+	}
 	return bytesRead, err
 }
 
@@ -90,14 +163,12 @@ func newBackoffWriteCloser(writer io.WriteCloser) io.WriteCloser {
 func (b *BackoffWriteCloser) Write(data []byte) (int, error) {
 	bytesWritten := 0
 	err := backoff.Retry(func() error {
-		if n, err := b.writer.Write(data[bytesWritten:]); err != nil {
-			bytesWritten += n
-			if bytesWritten == len(data) {
-				return nil
-			}
-			return err
+		n, err := b.writer.Write(data[bytesWritten:])
+		bytesWritten += n
+		if err != nil && bytesWritten == len(data) {
+			return nil
 		}
-		return nil
+		return err
 	}, b.backoffConfig)
 	return bytesWritten, err
 }
@@ -105,3 +176,12 @@ func (b *BackoffWriteCloser) Write(data []byte) (int, error) {
 func (b *BackoffWriteCloser) Close() error {
 	return b.writer.Close()
 }
+
+type limitReadCloser struct {
+	io.Reader
+	closer io.Closer
+}
+
+func (r *limitReadCloser) Close() error {
+	return r.closer.Close()
+}
@@ -0,0 +1,230 @@
+package obj
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+)
+
+// testConformance checks that client honours the contract of Client, every
+// implementation should pass it. Objects are written under prefix.
+func testConformance(t *testing.T, client Client, prefix string) {
+	data := []byte("foo\nbar\nbuzz\n")
+	writeObject(t, client, prefix+"dir/a", data)
+	writeObject(t, client, prefix+"dir/b", []byte("b"))
+	writeObject(t, client, prefix+"dir/sub/c", []byte("c"))
+	writeObject(t, client, prefix+"other", []byte("other"))
+	writeObject(t, client, prefix+"empty", nil)
+
+	// Reader with size 0 reads to the end
+	require.Equal(t, data, readObject(t, client, prefix+"dir/a", 0, 0))
+	require.Equal(t, data[4:], readObject(t, client, prefix+"dir/a", 4, 0))
+	require.Equal(t, data[4:8], readObject(t, client, prefix+"dir/a", 4, 4))
+	require.Equal(t, data[8:], readObject(t, client, prefix+"dir/a", 8, 100))
+	require.Equal(t, 0, len(readObject(t, client, prefix+"empty", 0, 0)))
+	_, err := client.Reader(prefix+"missing", 0, 0)
+	require.YesError(t, err)
+
+	// Writer errors if the object exists
+	writer, err := client.Writer(prefix + "dir/a")
+	if err == nil {
+		_, err = writer.Write([]byte("overwritten"))
+		if err == nil {
+			err = writer.Close()
+		}
+	}
+	require.YesError(t, err)
+	require.Equal(t, data, readObject(t, client, prefix+"dir/a", 0, 0))
+
+	objectInfo, err := client.Inspect(prefix + "dir/a")
+	require.NoError(t, err)
+	require.Equal(t, uint64(len(data)), objectInfo.SizeBytes)
+	require.False(t, objectInfo.Modified.IsZero())
+	_, err = client.Inspect(prefix + "missing")
+	require.YesError(t, err)
+
+	// Walk by prefix
+	require.Equal(t, []string{prefix + "dir/a", prefix + "dir/b", prefix + "dir/sub/c"}, walkObjects(t, client, prefix+"dir/"))
+	require.Equal(t, []string{prefix + "dir/a", prefix + "dir/b", prefix + "dir/sub/c"}, walkObjects(t, client, prefix+"dir"))
+	require.Equal(t, []string{prefix + "dir/sub/c"}, walkObjects(t, client, prefix+"dir/s"))
+	require.Equal(t, 0, len(walkObjects(t, client, prefix+"missing")))
+	errStop := fmt.Errorf("stop")
+	calls := 0
+	require.Equal(t, errStop, client.Walk(prefix, func(name string) error {
+		calls++
+		return errStop
+	}))
+	require.Equal(t, 1, calls)
+
+	// Delete
+	for _, name := range walkObjects(t, client, prefix) {
+		require.NoError(t, client.Delete(name))
+	}
+	require.Equal(t, 0, len(walkObjects(t, client, prefix)))
+	err = client.Delete(prefix + "dir/a")
+	require.YesError(t, err)
+	require.True(t, client.IsNotExist(err))
+	_, err = client.Inspect(prefix + "dir/a")
+	require.YesError(t, err)
+	require.True(t, client.IsNotExist(err))
+	_, err = client.Reader(prefix+"dir/a", 0, 0)
+	require.YesError(t, err)
+	require.True(t, client.IsNotExist(err))
+	require.False(t, client.IsNotExist(fmt.Errorf("some other error")))
+}
+
+func walkObjects(t *testing.T, client Client, prefix string) []string {
+	var result []string
+	require.NoError(t, client.Walk(prefix, func(name string) error {
+		result = append(result, name)
+		return nil
+	}))
+	sort.Strings(result)
+	return result
+}
+
+func TestMemClient(t *testing.T) {
+	testConformance(t, NewMemClient(), "test/")
+}
+
+func TestLocalClient(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pach_test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	client, err := NewLocalClient(dir)
+	require.NoError(t, err)
+	testConformance(t, client, "test/")
+	// object names are often absolute paths
+	testConformance(t, client, "/abs/test/")
+	writeObject(t, client, "top", nil)
+	require.Equal(t, []string{"top"}, walkObjects(t, client, ""))
+}
+
+func TestEncryptedClientConformance(t *testing.T) {
+	client, err := NewEncryptedClient(NewMemClient(), map[string][]byte{"1": testKey(1)}, "1", false)
+	require.NoError(t, err)
+	testConformance(t, client, "test/")
+}
+
+func TestHTTPClient(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pach_test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	data := []byte("foo\nbar\nbuzz\n")
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "a"), data, 0666))
+	server := httptest.NewServer(http.FileServer(http.Dir(dir)))
+	defer server.Close()
+
+	client := NewHTTPClient(nil)
+	url := server.URL + "/a"
+	require.Equal(t, data, readObject(t, client, url, 0, 0))
+	require.Equal(t, data[4:], readObject(t, client, url, 4, 0))
+	require.Equal(t, data[4:8], readObject(t, client, url, 4, 4))
+	objectInfo, err := client.Inspect(url)
+	require.NoError(t, err)
+	require.Equal(t, uint64(len(data)), objectInfo.SizeBytes)
+	_, err = client.Inspect(server.URL + "/missing")
+	require.YesError(t, err)
+	require.True(t, client.IsNotExist(err))
+	_, err = client.Reader(server.URL+"/missing", 0, 0)
+	require.YesError(t, err)
+	require.True(t, client.IsNotExist(err))
+	_, err = client.Writer(url)
+	require.YesError(t, err)
+}
+
+func TestHTTPClientWithoutRangesOrHead(t *testing.T) {
+	data := []byte("foo\nbar\nbuzz\n")
+	// like a presigned URL, only GETs are allowed and ranges are ignored
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Header().Set("Content-Length", fmt.Sprint(len(data)))
+		w.Write(data)
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(nil)
+	url := server.URL + "/a"
+	require.Equal(t, data, readObject(t, client, url, 0, 0))
+	require.Equal(t, data[:4], readObject(t, client, url, 0, 4))
+	require.Equal(t, data[4:], readObject(t, client, url, 4, 0))
+	require.Equal(t, data[4:8], readObject(t, client, url, 4, 4))
+	objectInfo, err := client.Inspect(url)
+	require.NoError(t, err)
+	require.Equal(t, uint64(len(data)), objectInfo.SizeBytes)
+	result, err := ioutil.ReadAll(NewParallelReader(client, url, uint64(len(data)), 5, 2))
+	require.NoError(t, err)
+	require.Equal(t, data, result)
+}
+
+func TestHTTPClientInspectWithGet(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pach_test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	data := []byte("foo\nbar\nbuzz\n")
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "a"), data, 0666))
+	fileServer := http.FileServer(http.Dir(dir))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "HEAD" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		fileServer.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(nil)
+	objectInfo, err := client.Inspect(server.URL + "/a")
+	require.NoError(t, err)
+	require.Equal(t, uint64(len(data)), objectInfo.SizeBytes)
+	require.False(t, objectInfo.Modified.IsZero())
+	_, err = client.Inspect(server.URL + "/missing")
+	require.YesError(t, err)
+	require.True(t, client.IsNotExist(err))
+}
+
+func TestParallelReader(t *testing.T) {
+	client := NewMemClient()
+	data := make([]byte, 1000)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	writeObject(t, client, "a", data)
+	for _, chunkSize := range []uint64{1, 7, 100, 1000, 5000} {
+		for _, parallelism := range []int{1, 3, 16} {
+			reader := NewParallelReader(client, "a", uint64(len(data)), chunkSize, parallelism)
+			result, err := ioutil.ReadAll(reader)
+			require.NoError(t, err)
+			require.NoError(t, reader.Close())
+			require.Equal(t, data, result)
+		}
+	}
+	// a prefix of the object
+	result, err := ioutil.ReadAll(NewParallelReader(client, "a", 10, 3, 2))
+	require.NoError(t, err)
+	require.Equal(t, data[:10], result)
+	// an empty object
+	result, err = ioutil.ReadAll(NewParallelReader(client, "a", 0, 3, 2))
+	require.NoError(t, err)
+	require.Equal(t, 0, len(result))
+	// reading past the end of the object errors
+	_, err = ioutil.ReadAll(NewParallelReader(client, "a", 2000, 100, 4))
+	require.YesError(t, err)
+	_, err = ioutil.ReadAll(NewParallelReader(client, "missing", 10, 3, 2))
+	require.YesError(t, err)
+	// closing early doesn't block
+	reader := NewParallelReader(client, "a", uint64(len(data)), 1, 4)
+	_, err = reader.Read(make([]byte, 1))
+	require.NoError(t, err)
+	require.NoError(t, reader.Close())
+}
@@ -0,0 +1,104 @@
+package obj
+
+import (
+	"io"
+	"io/ioutil"
+	"sync"
+)
+
+// parallelReader reads an object by fetching chunkSize ranges of it, up to
+// parallelism at a time, and returning them in order.
+type parallelReader struct {
+	pipeReader *io.PipeReader
+	done       chan struct{}
+	closeOnce  sync.Once
+}
+
+type chunk struct {
+	data []byte
+	err  error
+}
+
+// NewParallelReader returns a reader for the first size bytes of the object
+// name in client, which it fetches with up to parallelism concurrent ranged
+// reads of chunkSize bytes. At most parallelism chunks are buffered.
+func NewParallelReader(client Client, name string, size uint64, chunkSize uint64, parallelism int) io.ReadCloser {
+	if chunkSize == 0 {
+		chunkSize = size
+	}
+	if parallelism < 1 {
+		parallelism = 1
+	}
+	pipeReader, pipeWriter := io.Pipe()
+	r := &parallelReader{
+		pipeReader: pipeReader,
+		done:       make(chan struct{}),
+	}
+	// each chunk gets a channel, they're queued in order so the chunks can
+	// be fetched concurrently but written in order
+	chunks := make(chan chan chunk, parallelism-1)
+	go func() {
+		defer close(chunks)
+		for offset := uint64(0); offset < size; offset += chunkSize {
+			chunkChan := make(chan chunk, 1)
+			select {
+			case chunks <- chunkChan:
+			case <-r.done:
+				return
+			}
+			go func(offset uint64) {
+				n := chunkSize
+				if offset+n > size {
+					n = size - offset
+				}
+				chunkChan <- readChunk(client, name, offset, n)
+			}(offset)
+		}
+	}()
+	go func() {
+		for chunkChan := range chunks {
+			chunk := <-chunkChan
+			if chunk.err != nil {
+				pipeWriter.CloseWithError(chunk.err)
+				r.close()
+				return
+			}
+			if _, err := pipeWriter.Write(chunk.data); err != nil {
+				// the reader was closed
+				r.close()
+				return
+			}
+		}
+		pipeWriter.Close()
+	}()
+	return r
+}
+
+func readChunk(client Client, name string, offset uint64, size uint64) chunk {
+	reader, err := client.Reader(name, offset, size)
+	if err != nil {
+		return chunk{err: err}
+	}
+	defer reader.Close()
+	data, err := ioutil.ReadAll(io.LimitReader(reader, int64(size)))
+	if err != nil {
+		return chunk{err: err}
+	}
+	if uint64(len(data)) != size {
+		return chunk{err: io.ErrUnexpectedEOF}
+	}
+	return chunk{data: data}
+}
+
+func (r *parallelReader) Read(p []byte) (int, error) {
+	return r.pipeReader.Read(p)
+}
+
+func (r *parallelReader) Close() error {
+	r.close()
+	return r.pipeReader.Close()
+}
+
+func (r *parallelReader) close() {
+	r.closeOnce.Do(func() { close(r.done) })
+}
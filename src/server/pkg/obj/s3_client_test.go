@@ -0,0 +1,131 @@
+package obj
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+)
+
+// TestS3Client runs against an S3-compatible store, by default it's a minimal
+// stand-in served by the test but it can be pointed at a real one such as a
+// local MinIO:
+//
+//	minio server /tmp/minio
+//	S3_TEST_ENDPOINT=localhost:9000 S3_TEST_BUCKET=test S3_TEST_ID=... S3_TEST_SECRET=... go test
+func TestS3Client(t *testing.T) {
+	endpoint := os.Getenv("S3_TEST_ENDPOINT")
+	bucket := os.Getenv("S3_TEST_BUCKET")
+	id := os.Getenv("S3_TEST_ID")
+	secret := os.Getenv("S3_TEST_SECRET")
+	if endpoint == "" {
+		server := httptest.NewServer(newTestS3Server())
+		defer server.Close()
+		endpoint = strings.TrimPrefix(server.URL, "http://")
+		bucket, id, secret = "test", "id", "secret"
+	}
+	client, err := NewS3Client(bucket, id, secret, endpoint, "", false, true)
+	require.NoError(t, err)
+	writeObject(t, client, "test/a", []byte("foo\nbar\n"))
+	writeObject(t, client, "test/b", []byte("buzz"))
+	require.Equal(t, []byte("foo\nbar\n"), readObject(t, client, "test/a", 0, 0))
+	require.Equal(t, []byte("bar\n"), readObject(t, client, "test/a", 4, 0))
+	require.Equal(t, []byte("o\nb"), readObject(t, client, "test/a", 2, 3))
+	objectInfo, err := client.Inspect("test/a")
+	require.NoError(t, err)
+	require.Equal(t, uint64(8), objectInfo.SizeBytes)
+	var names []string
+	require.NoError(t, client.Walk("test/", func(name string) error {
+		names = append(names, name)
+		return nil
+	}))
+	sort.Strings(names)
+	require.Equal(t, []string{"test/a", "test/b"}, names)
+	require.NoError(t, client.Delete("test/a"))
+	require.NoError(t, client.Delete("test/b"))
+	_, err = client.Inspect("test/a")
+	require.YesError(t, err)
+}
+
+// testS3Server implements just enough of the S3 API, with path style
+// addressing, for TestS3Client.
+type testS3Server struct {
+	lock    sync.Mutex
+	objects map[string][]byte
+}
+
+func newTestS3Server() *testS3Server {
+	return &testS3Server{objects: make(map[string][]byte)}
+}
+
+type testListBucketResult struct {
+	XMLName     xml.Name `xml:"ListBucketResult"`
+	IsTruncated bool
+	Contents    []testS3Object
+}
+
+type testS3Object struct {
+	Key  string
+	Size int
+}
+
+func (s *testS3Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	// the path is /bucket/key
+	parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 2)
+	if len(parts) == 1 {
+		result := testListBucketResult{}
+		for key, data := range s.objects {
+			if strings.HasPrefix(key, r.URL.Query().Get("prefix")) {
+				result.Contents = append(result.Contents, testS3Object{key, len(data)})
+			}
+		}
+		xml.NewEncoder(w).Encode(result)
+		return
+	}
+	key := parts[1]
+	data, ok := s.objects[key]
+	switch r.Method {
+	case "PUT":
+		data, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		s.objects[key] = data
+	case "DELETE":
+		delete(s.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	case "HEAD", "GET":
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+		var start, end int
+		if n, _ := fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-%d", &start, &end); n == 2 {
+			data = data[start : end+1]
+			w.Header().Set("Content-Length", fmt.Sprint(len(data)))
+			w.WriteHeader(http.StatusPartialContent)
+		} else if n == 1 {
+			data = data[start:]
+			w.Header().Set("Content-Length", fmt.Sprint(len(data)))
+			w.WriteHeader(http.StatusPartialContent)
+		} else {
+			w.Header().Set("Content-Length", fmt.Sprint(len(data)))
+		}
+		if r.Method == "GET" {
+			w.Write(data)
+		}
+	}
+}
@@ -124,6 +124,9 @@ func (a *apiServer) CreateJob(ctx context.Context, request *ppsclient.CreateJobR
 	jobID := getJobID(request)
 
 	startCommitRequest := &pfsclient.StartCommitRequest{}
+	for _, input := range request.Inputs {
+		startCommitRequest.Provenance = append(startCommitRequest.Provenance, input.Commit)
+	}
 
 	// If JobInfo.Pipeline is set, use the pipeline repo
 	if request.Pipeline != nil {